@@ -28,64 +28,150 @@ import (
 type config struct {
 	// input fields
 	CPEsAt int
+	// skip the input lines which can't be read or parsed, with a warning, instead of matching what can be parsed
+	SkipInvalid bool
 	// output fields
 	CVEsAt     int
 	MatchesAt  int
 	CWEsAt     int
 	ProviderAt int
+	// output references, only the ones with any of the tags (comma separated) if set
+	ReferencesAt  int
+	ReferenceTags string
 	// output score fields
-	CVSS2At int
-	CVSS3At int
-	CVSSAt  int
+	CVSS2At         int
+	CVSS3At         int
+	CVSS3VectorAt   int
+	CVSS3SeverityAt int
+	CVSSAt          int
+	PublishedAt     int
+	ModifiedAt      int
+	// output extended attributes of the matched CPEs
+	SWEditionAt int
+	TargetSWAt  int
+	TargetHWAt  int
+	OtherAt     int
 	// output deleted fields
 	EraseFields fieldsToSkip // []int
+	// output format
+	JSON     bool
+	CSV      bool
+	CSVComma string
+	// bind the CPE names in output as URIs if "2.2", as formatted strings if "2.3"
+	OutCPEFormat string
+	// output the matched CPEs as they were written in the input, instead of binding them
+	VerbatimMatches bool
+	// explain the matches on stderr
+	Explain bool
+	// output only the highest scored CVE per matched CPE
+	Top bool
+	// output only the CVEs scored at least MinCVSS, or rated at least MinSeverity; unscored ones unless DropUnscored
+	MinCVSS      float64
+	MinSeverity  string
+	DropUnscored bool
+	// output only the number of results, grouped by cve, cpe or severity if CountBy is set
+	Count   bool
+	CountBy string
+	// output the number of CVEs, max CVSS score and number of critical CVEs per vendor of the matched CPEs,
+	// ordered by the VendorSummarySort column
+	VendorSummary     bool
+	VendorSummarySort string
 
 	// separators
 	InFieldSeparator   string
 	InRecordSeparator  string
 	OutFieldSeparator  string
 	OutRecordSeparator string
+	// terminate output records with NUL instead of newline
+	NulTerminated bool
 
 	// optimizations
 	NumProcessors  int
 	IndexDict      bool
+	IndexFile      string
 	CacheSize      int64
 	RequireVersion bool
 
+	// feed entries
+	IncludeRejected bool
+
+	// known false positives: file with (CPE pattern, CVE ID) rules, and whether to log suppressed matches
+	SuppressFile  string
+	LogSuppressed bool
+
 	// profiling
 	CPUProfile    string
 	MemoryProfile string
 
+	// input files, stdin is read if empty
+	Inputs multiString // []string
+
 	// feeds
 	FeedOverrides multiString // []string
 	Feeds         map[string][]string
 
-	provider string
+	provider     string
+	suppressions suppressions
+	lines        *lineCounts // counted in -skip_invalid mode, if set
 }
 
 func (cfg *config) addFlags() {
 	// input
+	flag.Var(&cfg.Inputs, "i", "read input from this file instead of stdin, can be specified multiple times to concatenate files; gzip-compressed files are decompressed")
 	flag.IntVar(&cfg.CPEsAt, "cpe", 0, "look for CPE names in input at this position (starts with 1)")
+	flag.BoolVar(&cfg.SkipInvalid, "skip_invalid", false, "skip the input lines which can't be read or have CPE names which can't be parsed, with a warning naming the line; exit with an error only if all lines are invalid. By default such CPE names are logged as errors and the rest of the line is matched")
+	flag.Var(flag.Lookup("skip_invalid").Value, "skip-invalid", "same as -skip_invalid")
 
 	// output
 	flag.IntVar(&cfg.CVEsAt, "cve", 0, "output CVEs at this position (starts with 1)")
 	flag.IntVar(&cfg.MatchesAt, "matches", 0, "output CPEs that matches CVE at this position; 0 disables the output")
 	flag.IntVar(&cfg.CWEsAt, "cwe", 0, "output problem types (CWEs) at this position (starts with 1)")
+	flag.IntVar(&cfg.ReferencesAt, "refs", 0, "output reference URLs at this position (starts with 1)")
+	flag.StringVar(&cfg.ReferenceTags, "ref_tags", "", "comma separated list of reference tags (e.g. Patch,Exploit,\"Vendor Advisory\"): only output references with any of these tags; case insensitive")
 	flag.IntVar(&cfg.ProviderAt, "provider_field", 0, "where should the provider be placed in the output (starts with 1).")
 	flag.IntVar(&cfg.CVSS2At, "cvss2", 0, "output CVSS 2.0 base score at this position (starts with 1)")
 	flag.IntVar(&cfg.CVSS3At, "cvss3", 0, "output CVSS 3.0 base score at this position (starts with 1)")
+	flag.IntVar(&cfg.CVSS3VectorAt, "cvss3_vector", 0, "output CVSS 3.0 vector at this position (starts with 1); empty if CVE has no CVSS 3.0 data")
+	flag.IntVar(&cfg.CVSS3SeverityAt, "cvss3_severity", 0, "output CVSS 3.0 base severity (LOW, MEDIUM, HIGH, CRITICAL) at this position (starts with 1); empty if CVE has no CVSS 3.0 data")
 	flag.IntVar(&cfg.CVSSAt, "cvss", 0, "output CVSS base score (v3 if available, v2 otherwise) at this position (starts with 1)")
+	flag.IntVar(&cfg.PublishedAt, "published", 0, "output the time CVE was published at, in RFC3339 (UTC), at this position (starts with 1); empty if unknown; in JSON mode, output it if set")
+	flag.IntVar(&cfg.ModifiedAt, "modified", 0, "output the time CVE was last modified at, in RFC3339 (UTC), at this position (starts with 1); empty if unknown; in JSON mode, output it if set")
+	flag.IntVar(&cfg.SWEditionAt, "sw_edition", 0, "output sw_edition attributes of the matched CPEs, in the order of -matches, at this position (starts with 1); in JSON mode, output them if set")
+	flag.IntVar(&cfg.TargetSWAt, "target_sw", 0, "output target_sw attributes of the matched CPEs, in the order of -matches, at this position (starts with 1); in JSON mode, output them if set")
+	flag.IntVar(&cfg.TargetHWAt, "target_hw", 0, "output target_hw attributes of the matched CPEs, in the order of -matches, at this position (starts with 1); in JSON mode, output them if set")
+	flag.IntVar(&cfg.OtherAt, "other", 0, "output other attributes of the matched CPEs, in the order of -matches, at this position (starts with 1); in JSON mode, output them if set")
+	flag.StringVar(&cfg.OutCPEFormat, "out_cpe_format", "", "bind the CPE names in output as URIs (2.2) or as formatted strings (2.3), the input ones included; by default, the matched CPEs are output as URIs and the input ones as they are")
+	flag.Var(flag.Lookup("out_cpe_format").Value, "out-cpe-format", "same as -out_cpe_format")
+	flag.BoolVar(&cfg.VerbatimMatches, "verbatim_matches", false, "output the matched CPEs as they are written in the input, instead of bound as URIs; equivalent input CPEs of the same line are all output as the first one of them")
+	flag.BoolVar(&cfg.JSON, "json", false, "output a JSON object per match, one per line, instead of delimiter-separated fields; output positions are ignored")
+	flag.BoolVar(&cfg.CSV, "csv", false, "output RFC 4180 CSV: fields are separated by -csv_comma instead of -o, lines end with CRLF and the first line is the header with the names of the fields; input fields are named field1, field2 and so on, except for the cpe one")
+	flag.StringVar(&cfg.CSVComma, "csv_comma", ",", "with -csv, output fields delimiter")
+	flag.BoolVar(&cfg.Top, "top", false, "output only the CVE with the highest CVSS base score (v3 if available, v2 otherwise) for every matched CPE; ties are broken in favour of the greatest CVE ID")
+	flag.Float64Var(&cfg.MinCVSS, "min_cvss", 0, "output only the CVEs with CVSS base score (v3 if available, v2 otherwise) of at least this value; 0 outputs all")
+	flag.Var(flag.Lookup("min_cvss").Value, "min-cvss", "same as -min_cvss")
+	flag.StringVar(&cfg.MinSeverity, "min_severity", "", "output only the CVEs of at least this severity (LOW, MEDIUM, HIGH or CRITICAL), as the CVSS 3.0 base severity of their CVSS base score (v3 if available, v2 otherwise)")
+	flag.Var(flag.Lookup("min_severity").Value, "min-severity", "same as -min_severity")
+	flag.BoolVar(&cfg.DropUnscored, "drop_unscored", false, "with -min_cvss or -min_severity, don't output the CVEs without CVSS scores either; they're output by default")
+	flag.BoolVar(&cfg.Count, "count", false, "instead of the results, output the number of them, as it would be with the other flags; output positions are ignored")
+	flag.StringVar(&cfg.CountBy, "count_by", "", "with -count, also output the number of results per cve, cpe (every matched CPE is counted) or severity (CVSS 3.0 base severity, UNKNOWN if CVE has no CVSS 3.0 data), one per line, in the decreasing order")
+	flag.Var(flag.Lookup("count_by").Value, "count-by", "same as -count_by")
+	flag.BoolVar(&cfg.VendorSummary, "vendor_summary", false, "instead of the results, output per vendor of the matched CPEs the number of CVEs, their max CVSS base score (v3 if available, v2 otherwise) and the number of CRITICAL ones, as it would be with the other flags; output positions are ignored")
+	flag.StringVar(&cfg.VendorSummarySort, "vendor_summary_sort", "", "with -vendor_summary, order the vendors by this column: vendor (alphabetically), cves, max_cvss or critical (in the decreasing order); ties are ordered by vendor. Default is cves")
+	flag.BoolVar(&cfg.Explain, "explain", false, "for every match, print to stderr the cpe_match entries (with version bounds) of the CVE configuration that matched and the operators of the nodes they are in")
 	flag.Var(&cfg.EraseFields, "e", "comma separated list of fields to erase from output; starts at 1, supports ranges (e.g. 1-3); processed before the vulnerablitie field added")
 
 	// separators
-	flag.StringVar(&cfg.InFieldSeparator, "d", "\t", "input columns delimiter")
+	flag.StringVar(&cfg.InFieldSeparator, "d", "\t", "input columns delimiter; \\t stands for tab")
 	flag.StringVar(&cfg.InRecordSeparator, "d2", ",", "inner input columns delimiter: separates elements of list passed into a CSV columns")
-	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter")
+	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter; \\t stands for tab")
 	flag.StringVar(&cfg.OutRecordSeparator, "o2", ",", "inner output columns delimiter: separates elements of lists in output CSV columns")
+	flag.BoolVar(&cfg.NulTerminated, "0", false, "terminate output records with NUL instead of newline; fields aren't quoted then, but the output columns delimiter and NUL characters in them are replaced with spaces")
 
 	// optimizations
-	flag.IntVar(&cfg.NumProcessors, "nproc", 1, "number of concurrent goroutines that perform CVE lookup")
-	flag.BoolVar(&cfg.IndexDict, "idxd", false, "build and use an index for CVE dictionary: increases the processing speed, but might miss some matches")
+	flag.IntVar(&cfg.NumProcessors, "nproc", 1, "number of concurrent goroutines that perform CVE lookup; output order follows the input regardless")
+	flag.IntVar(&cfg.NumProcessors, "threads", 1, "same as -nproc")
+	flag.BoolVar(&cfg.IndexDict, "idxd", false, "build and use an index for CVE dictionary, and an exact one for the CPEs concrete in part, vendor and product: increases the processing speed, but might miss some matches")
+	flag.StringVar(&cfg.IndexFile, "idxd_file", "", "with -idxd, load the index from this file instead of building it; the index is rebuilt and saved there when the file is missing or the feeds changed. With multiple providers, the provider name is appended to the file name")
 	flag.Int64Var(&cfg.CacheSize, "cache_size", 0, "limit the cache size to this amount in bytes; 0 removes the limit, -1 disables caching")
 	flag.BoolVar(&cfg.RequireVersion, "require_version", false, "ignore matches of CPEs with version ANY")
 
@@ -94,7 +180,10 @@ func (cfg *config) addFlags() {
 	flag.StringVar(&cfg.MemoryProfile, "memprofile", "", "file to store memory profile data to; empty value disables memory profiling")
 
 	// feeds
+	flag.BoolVar(&cfg.IncludeRejected, "include_rejected", false, "match CVEs marked as rejected by NVD; they're ignored by default")
 	flag.Var(&cfg.FeedOverrides, "r", "overRide: path to override feed, can be specified multiple times")
+	flag.StringVar(&cfg.SuppressFile, "suppress", "", "path to a file with known false positive matches to drop, one per line: CPE name, which can contain wildcards, and CVE ID separated by whitespace; lines starting with # are ignored")
+	flag.BoolVar(&cfg.LogSuppressed, "log_suppressed", false, "log suppressed matches to stderr")
 }
 
 func (cfg *config) addFeedsFromArgs(provider string, feedFiles ...string) {
@@ -116,10 +205,13 @@ func (cfg *config) validate() error {
 		}
 	}
 
+	if cfg.NumProcessors <= 0 {
+		return fmt.Errorf("-nproc value is invalid %d", cfg.NumProcessors)
+	}
 	if cfg.CPEsAt <= 0 {
 		return fmt.Errorf("-cpe flag wasn't provided")
 	}
-	if cfg.CVEsAt <= 0 {
+	if cfg.CVEsAt <= 0 && !cfg.JSON && !cfg.Count && !cfg.VendorSummary {
 		return fmt.Errorf("-cve flag wasn't provided")
 	}
 	if cfg.MatchesAt < 0 {
@@ -128,18 +220,95 @@ func (cfg *config) validate() error {
 	if cfg.CWEsAt < 0 {
 		return fmt.Errorf("-cwe value is invalid %d", cfg.CWEsAt)
 	}
-	if cfg.CVSSAt < 0 {
+	if cfg.ReferencesAt < 0 {
+		return fmt.Errorf("-refs value is invalid %d", cfg.ReferencesAt)
+	}
+	if cfg.CVSS2At < 0 {
 		return fmt.Errorf("-cvss2 value is invalid %d", cfg.CVSS2At)
 	}
 	if cfg.CVSS3At < 0 {
-		return fmt.Errorf("-cvss2 value is invalid %d", cfg.CVSS3At)
+		return fmt.Errorf("-cvss3 value is invalid %d", cfg.CVSS3At)
+	}
+	if cfg.CVSS3VectorAt < 0 {
+		return fmt.Errorf("-cvss3_vector value is invalid %d", cfg.CVSS3VectorAt)
+	}
+	if cfg.CVSS3SeverityAt < 0 {
+		return fmt.Errorf("-cvss3_severity value is invalid %d", cfg.CVSS3SeverityAt)
 	}
 	if cfg.CVSSAt < 0 {
 		return fmt.Errorf("-cvss value is invalid %d", cfg.CVSSAt)
 	}
+	if cfg.PublishedAt < 0 {
+		return fmt.Errorf("-published value is invalid %d", cfg.PublishedAt)
+	}
+	if cfg.ModifiedAt < 0 {
+		return fmt.Errorf("-modified value is invalid %d", cfg.ModifiedAt)
+	}
+	if cfg.CSV && cfg.JSON {
+		return fmt.Errorf("-csv and -json are mutually exclusive")
+	}
+	if err := validateOutCPEFormat(cfg.OutCPEFormat); err != nil {
+		return err
+	}
+	if cfg.VerbatimMatches && cfg.OutCPEFormat != "" {
+		return fmt.Errorf("-verbatim_matches and -out_cpe_format are mutually exclusive")
+	}
+	if cfg.NulTerminated && (cfg.CSV || cfg.JSON) {
+		return fmt.Errorf("-0 can't be used with -csv or -json")
+	}
+	// separators are easier to pass on the command line escaped
+	cfg.InFieldSeparator = unescapeSeparator(cfg.InFieldSeparator)
+	cfg.OutFieldSeparator = unescapeSeparator(cfg.OutFieldSeparator)
+	if cfg.CSV && len(cfg.CSVComma) != 1 {
+		return fmt.Errorf("-csv_comma value is invalid %q: must be a single character", cfg.CSVComma)
+	}
+	if err := validateThreshold(cfg); err != nil {
+		return err
+	}
+	if cfg.CountBy != "" && !cfg.Count {
+		return fmt.Errorf("-count_by requires -count")
+	}
+	if err := validateCountBy(cfg.CountBy); err != nil {
+		return err
+	}
+	if cfg.Count && cfg.VendorSummary {
+		return fmt.Errorf("-count and -vendor_summary are mutually exclusive")
+	}
+	if err := validateVendorSummary(cfg); err != nil {
+		return err
+	}
+	for _, attr := range cfg.matchAttrs() {
+		if attr.at < 0 {
+			return fmt.Errorf("-%s value is invalid %d", attr.name, attr.at)
+		}
+	}
 	return nil
 }
 
+// unescapeSeparator returns the tab character for \t escape sequence and s as it is otherwise
+func unescapeSeparator(s string) string {
+	if s == `\t` {
+		return "\t"
+	}
+	return s
+}
+
+// matchAttr is an attribute of the matched CPEs to output at the position
+type matchAttr struct {
+	name string
+	at   int
+}
+
+// matchAttrs returns the attributes of the matched CPEs which can be output, with their configured positions
+func (cfg *config) matchAttrs() []matchAttr {
+	return []matchAttr{
+		{"sw_edition", cfg.SWEditionAt},
+		{"target_sw", cfg.TargetSWAt},
+		{"target_hw", cfg.TargetHWAt},
+		{"other", cfg.OtherAt},
+	}
+}
+
 func readConfigFile(file string) (config, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -187,6 +356,9 @@ func readConfigFile(file string) (config, error) {
 		if cfg.OutRecordSeparator == "" {
 			cfg.OutRecordSeparator = ","
 		}
+		if cfg.CSVComma == "" {
+			cfg.CSVComma = ","
+		}
 		if cfg.NumProcessors == 0 {
 			cfg.NumProcessors = 1
 		}
@@ -198,6 +370,7 @@ func readConfigFile(file string) (config, error) {
 func writeConfigFileDefinition(w io.Writer) {
 	cfg := config{
 		EraseFields:   fieldsToSkip{1: true},
+		Inputs:        multiString{"input file"},
 		FeedOverrides: multiString{"override feed path"},
 		Feeds:         map[string][]string{"provider": []string{"feed file 1", "feed file 2"}},
 	}
@@ -0,0 +1,108 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// countKeys tell how the results are grouped in -count mode, by the value of -count_by
+var countKeys = map[string]func(*result) []string{
+	"cve": func(r *result) []string { return []string{r.finding.ID} },
+	"cpe": func(r *result) []string { return r.matches },
+	"severity": func(r *result) []string {
+		if s := r.finding.CVSS3Severity; s != "" {
+			return []string{s}
+		}
+		return []string{"UNKNOWN"}
+	},
+}
+
+// resultCounter counts the results in -count mode instead of writing them out
+type resultCounter struct {
+	key    func(*result) []string // nil if results aren't grouped
+	total  int
+	counts map[string]int
+}
+
+func newResultCounter(countBy string) *resultCounter {
+	return &resultCounter{key: countKeys[countBy], counts: map[string]int{}}
+}
+
+func (c *resultCounter) add(r *result) {
+	c.total++
+	if c.key == nil {
+		return
+	}
+	for _, k := range c.key(r) {
+		c.counts[k]++
+	}
+}
+
+// jsonCounts is a representation of the counts in JSON output mode
+type jsonCounts struct {
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts,omitempty"`
+}
+
+// write writes the counts, ordered by decreasing count and then by key, followed by the total;
+// only the total is written if the results aren't grouped
+func (c *resultCounter) write(w recordWriter, enc *json.Encoder, asJSON bool) error {
+	if asJSON {
+		counts := c.counts
+		if c.key == nil {
+			counts = nil
+		}
+		return enc.Encode(jsonCounts{Total: c.total, Counts: counts})
+	}
+	if c.key == nil {
+		return writeFlush(w, []string{strconv.Itoa(c.total)})
+	}
+	keys := make([]string, 0, len(c.counts))
+	for k := range c.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ni, nj := c.counts[keys[i]], c.counts[keys[j]]; ni != nj {
+			return ni > nj
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		if err := w.Write([]string{k, strconv.Itoa(c.counts[k])}); err != nil {
+			return err
+		}
+	}
+	return writeFlush(w, []string{"total", strconv.Itoa(c.total)})
+}
+
+func writeFlush(w recordWriter, rec []string) error {
+	if err := w.Write(rec); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// validateCountBy returns an error if results can't be grouped by countBy
+func validateCountBy(countBy string) error {
+	if _, ok := countKeys[countBy]; !ok && countBy != "" {
+		return fmt.Errorf("-count_by value is invalid %q: must be cve, cpe or severity", countBy)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputCount(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr3))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	in := "cpe:/a:foo:bar:1.0\ncpe:/a:foo:baz:1.0\ncpe:/a:foo:bar:1.0,cpe:/a:foo:bar:1.0"
+	cfg := config{
+		NumProcessors:      2,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	run := func(cfg config) string {
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		return strings.TrimSpace(w.String())
+	}
+	lines := strings.Split(run(cfg), "\n")
+
+	cfg.Count = true
+	if got, expect := run(cfg), strconv.Itoa(len(lines)); got != expect {
+		t.Errorf("count: got %q, expected %q", got, expect)
+	}
+	cases := map[string]string{
+		"cve":      "CVE-2019-0001;2\nCVE-2019-0002;2\nCVE-2019-0003;2\ntotal;6",
+		"cpe":      "cpe:/a:foo:bar:1.0;6\ntotal;6",
+		"severity": "CRITICAL;2\nMEDIUM;2\nUNKNOWN;2\ntotal;6",
+	}
+	for countBy, expect := range cases {
+		cfg.CountBy = countBy
+		if got := run(cfg); got != expect {
+			t.Errorf("count by %s: got:\n%s\nexpected:\n%s", countBy, got, expect)
+		}
+	}
+	cfg.JSON = true
+	cfg.CountBy = "severity"
+	if got, expect := run(cfg), `{"total":6,"counts":{"CRITICAL":2,"MEDIUM":2,"UNKNOWN":2}}`; got != expect {
+		t.Errorf("JSON count: got %s, expected %s", got, expect)
+	}
+}
+
+func TestValidateCount(t *testing.T) {
+	cfg := config{
+		Feeds:         map[string][]string{"": {"feed.json"}},
+		NumProcessors: 1,
+		CPEsAt:        1,
+		Count:         true,
+	}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("-count shouldn't require -cve: %v", err)
+	}
+	cfg.CountBy = "vendor"
+	if err := cfg.validate(); err == nil {
+		t.Error("unknown -count_by value should be rejected")
+	}
+	cfg.CountBy, cfg.Count = "cve", false
+	if err := cfg.validate(); err == nil {
+		t.Error("-count_by without -count should be rejected")
+	}
+}
@@ -15,96 +15,251 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/stats"
 	"github.com/facebookincubator/nvdtools/wfn"
 	"github.com/facebookincubator/flog"
 )
 
-func processAll(in <-chan []string, out chan<- []string, caches map[string]*cvefeed.Cache, cfg config, nlines *uint64) {
-	cpesAt := cfg.CPEsAt - 1
-	for rec := range in {
-		if cpesAt >= len(rec) {
-			flog.Errorf("not enough fields in input (%d)", len(rec))
-			continue
-		}
-		if stats.AreLogged() {
-			stats.IncrementCounter("line.total")
+// result is a vulnerability matched by an input record
+type result struct {
+	rec      []string // input record
+	provider string
+	finding  cvefeed.Finding
+	matches  []string          // matched CPE names
+	attrs    []*wfn.Attributes // matched CPEs, in the order of matches
+	explain  []string          // explanation of the match, in -explain mode
+}
+
+// jsonResult is a representation of the result in JSON output mode
+type jsonResult struct {
+	Fields        []string            `json:"fields"`
+	CVE           string              `json:"cve"`
+	Matches       []string            `json:"matches"`
+	CWEs          []string            `json:"cwes,omitempty"`
+	References    []cvefeed.Reference `json:"references,omitempty"`
+	CVSS2         float64             `json:"cvss2"`
+	CVSS3         float64             `json:"cvss3"`
+	CVSS3Vector   string              `json:"cvss3_vector,omitempty"`
+	CVSS3Severity string              `json:"cvss3_severity,omitempty"`
+	CVSS          float64             `json:"cvss"`
+	Published     string              `json:"published,omitempty"`
+	Modified      string              `json:"modified,omitempty"`
+	Provider      string              `json:"provider,omitempty"`
+	Attributes    map[string][]string `json:"attributes,omitempty"`
+	Range         *jsonRange          `json:"range,omitempty"`
+}
+
+// jsonRange is the strongest of the vulnerable cpe_match entries which matched, see cvefeed.Finding.Range
+type jsonRange struct {
+	CPE                   string `json:"cpe"`
+	VersionStartIncluding string `json:"version_start_including,omitempty"`
+	VersionStartExcluding string `json:"version_start_excluding,omitempty"`
+	VersionEndIncluding   string `json:"version_end_including,omitempty"`
+	VersionEndExcluding   string `json:"version_end_excluding,omitempty"`
+}
+
+// cvss returns CVSS v3 base score if available, v2 otherwise
+func (r *result) cvss() float64 {
+	if cvss := r.finding.CVSS3; cvss != 0 {
+		return cvss
+	}
+	return r.finding.CVSS2
+}
+
+// timestamp formats the time in RFC3339, in UTC; zero time is formatted as empty string
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// attr returns the values of the named attribute of the matched CPEs, bound as in formatted string
+func (r *result) attr(name string) []string {
+	values := make([]string, len(r.attrs))
+	for i, attr := range r.attrs {
+		if attr != nil {
+			values[i], _ = attr.BindAttrToFmtString(name)
 		}
-		cpeList := strings.Split(rec[cpesAt], cfg.InRecordSeparator)
-		cpes := make([]*wfn.Attributes, 0, len(cpeList))
-		for _, uri := range cpeList {
-			if stats.AreLogged() {
-				stats.IncrementCounter("cpe.total")
-			}
-			attr, err := wfn.Parse(uri)
-			if err != nil {
-				flog.Errorf("couldn't parse uri %q: %v", uri, err)
-				continue
+	}
+	return values
+}
+
+// references returns the references of the CVE, only the ones with any of the configured tags if set
+func (r *result) references(cfg config) []cvefeed.Reference {
+	refs := r.finding.Vuln.References()
+	if cfg.ReferenceTags == "" {
+		return refs
+	}
+	var filtered []cvefeed.Reference
+	for _, ref := range refs {
+		for _, tag := range strings.Split(cfg.ReferenceTags, ",") {
+			if ref.HasTag(strings.TrimSpace(tag)) {
+				filtered = append(filtered, ref)
+				break
 			}
-			cpes = append(cpes, attr)
-		}
-		rec[cpesAt] = strings.Join(cpeList, cfg.OutRecordSeparator)
-
-		// if performance seems to be the issue, we could try to make these cache.Get's concurrent:
-		//
-		// wg := sync.WaitGroup{}
-		// for provider, cache := range caches {
-		// 	provider, cache := provider, cache
-		// 	wg.Add(1)
-		// 	go func() {
-		// 		defer wg.Done()
-		// 		for _, matches := range cache.Get(cpes) {
-		// ...
-		for provider, cache := range caches {
-			for _, matches := range cache.Get(cpes) {
-				ml := len(matches.CPEs)
-				if stats.AreLogged() {
-					stats.IncrementCounterBy("cpe.match", int64(ml))
-					if ml != 0 {
-						stats.IncrementCounter("line.match")
-					}
-				}
-				matchingCPEs := make([]string, ml)
-				for i, attr := range matches.CPEs {
-					if attr == nil {
-						flog.Errorf("%s matches nil CPE", matches.CVE.ID())
-						continue
-					}
-					matchingCPEs[i] = (*wfn.Attributes)(attr).BindToURI()
-				}
-				rec2 := make([]string, len(rec))
-				copy(rec2, rec)
-				cvss := matches.CVE.CVSSv3BaseScore()
-				if cvss == 0 {
-					cvss = matches.CVE.CVSSv2BaseScore()
-				}
-				rec2 = cfg.EraseFields.appendAt(
-					rec2,
-					cfg.CVEsAt-1, matches.CVE.ID(),
-					cfg.MatchesAt-1, strings.Join(matchingCPEs, cfg.OutRecordSeparator),
-					cfg.CWEsAt-1, strings.Join(matches.CVE.CWEs(), cfg.OutRecordSeparator),
-					cfg.CVSS2At-1, fmt.Sprintf("%.1f", matches.CVE.CVSSv2BaseScore()),
-					cfg.CVSS3At-1, fmt.Sprintf("%.1f", matches.CVE.CVSSv3BaseScore()),
-					cfg.CVSSAt-1, fmt.Sprintf("%.1f", cvss),
-					cfg.ProviderAt-1, provider,
-				)
-				out <- rec2
+		}
+	}
+	return filtered
+}
+
+// text returns the output record with the result fields placed as per config
+func (r *result) text(cfg config) []string {
+	rec := make([]string, len(r.rec))
+	copy(rec, r.rec)
+	return cfg.EraseFields.appendAt(
+		rec,
+		cfg.CVEsAt-1, r.finding.ID,
+		cfg.MatchesAt-1, strings.Join(r.matches, cfg.OutRecordSeparator),
+		cfg.CWEsAt-1, strings.Join(r.finding.CWEs, cfg.OutRecordSeparator),
+		cfg.ReferencesAt-1, strings.Join(referenceURLs(r.references(cfg)), cfg.OutRecordSeparator),
+		cfg.CVSS2At-1, fmt.Sprintf("%.1f", r.finding.CVSS2),
+		cfg.CVSS3At-1, fmt.Sprintf("%.1f", r.finding.CVSS3),
+		cfg.CVSS3VectorAt-1, r.finding.CVSS3Vector,
+		cfg.CVSS3SeverityAt-1, r.finding.CVSS3Severity,
+		cfg.CVSSAt-1, fmt.Sprintf("%.1f", r.cvss()),
+		cfg.ProviderAt-1, r.provider,
+		cfg.PublishedAt-1, timestamp(cvefeed.Published(r.finding.Vuln)),
+		cfg.ModifiedAt-1, timestamp(cvefeed.LastModified(r.finding.Vuln)),
+		cfg.SWEditionAt-1, strings.Join(r.attr("sw_edition"), cfg.OutRecordSeparator),
+		cfg.TargetSWAt-1, strings.Join(r.attr("target_sw"), cfg.OutRecordSeparator),
+		cfg.TargetHWAt-1, strings.Join(r.attr("target_hw"), cfg.OutRecordSeparator),
+		cfg.OtherAt-1, strings.Join(r.attr("other"), cfg.OutRecordSeparator),
+	)
+}
+
+// header returns the names of the output fields, as placed by text, for the input records of n fields
+func (cfg config) header(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("field%d", i+1)
+	}
+	if at := cfg.CPEsAt - 1; at < n {
+		names[at] = "cpe"
+	}
+	return cfg.EraseFields.appendAt(
+		names,
+		cfg.CVEsAt-1, "cve",
+		cfg.MatchesAt-1, "matches",
+		cfg.CWEsAt-1, "cwe",
+		cfg.ReferencesAt-1, "refs",
+		cfg.CVSS2At-1, "cvss2",
+		cfg.CVSS3At-1, "cvss3",
+		cfg.CVSS3VectorAt-1, "cvss3_vector",
+		cfg.CVSS3SeverityAt-1, "cvss3_severity",
+		cfg.CVSSAt-1, "cvss",
+		cfg.ProviderAt-1, "provider",
+		cfg.PublishedAt-1, "published",
+		cfg.ModifiedAt-1, "modified",
+		cfg.SWEditionAt-1, "sw_edition",
+		cfg.TargetSWAt-1, "target_sw",
+		cfg.TargetHWAt-1, "target_hw",
+		cfg.OtherAt-1, "other",
+	)
+}
+
+// json returns the JSON representation of the result; input fields are erased as per config
+func (r *result) json(cfg config) *jsonResult {
+	rec := make([]string, len(r.rec))
+	copy(rec, r.rec)
+	var attrs map[string][]string
+	for _, attr := range cfg.matchAttrs() {
+		if attr.at > 0 {
+			if attrs == nil {
+				attrs = make(map[string][]string)
 			}
+			attrs[attr.name] = r.attr(attr.name)
+		}
+	}
+	var published, modified string
+	if cfg.PublishedAt > 0 {
+		published = timestamp(cvefeed.Published(r.finding.Vuln))
+	}
+	if cfg.ModifiedAt > 0 {
+		modified = timestamp(cvefeed.LastModified(r.finding.Vuln))
+	}
+	var rng *jsonRange
+	if r := r.finding.Range; r != nil {
+		rng = &jsonRange{
+			CPE:                   r.CPE,
+			VersionStartIncluding: r.VersionStartIncluding,
+			VersionStartExcluding: r.VersionStartExcluding,
+			VersionEndIncluding:   r.VersionEndIncluding,
+			VersionEndExcluding:   r.VersionEndExcluding,
+		}
+	}
+	return &jsonResult{
+		Fields:        cfg.EraseFields.skipFields(rec),
+		CVE:           r.finding.ID,
+		Matches:       r.matches,
+		CWEs:          r.finding.CWEs,
+		References:    r.references(cfg),
+		CVSS2:         r.finding.CVSS2,
+		CVSS3:         r.finding.CVSS3,
+		CVSS3Vector:   r.finding.CVSS3Vector,
+		CVSS3Severity: r.finding.CVSS3Severity,
+		CVSS:          r.cvss(),
+		Published:     published,
+		Modified:      modified,
+		Provider:      r.provider,
+		Attributes:    attrs,
+		Range:         rng,
+	}
+}
+
+func referenceURLs(refs []cvefeed.Reference) []string {
+	urls := make([]string, len(refs))
+	for i, ref := range refs {
+		urls[i] = ref.URL
+	}
+	return urls
+}
+
+// job is an input record tagged with its position in the input
+type job struct {
+	seq int
+	rec []string
+	err error // read error, if any
+}
+
+// jobResults are all the results for an input record, tagged with its position in the input
+type jobResults struct {
+	seq     int
+	nfields int // number of fields in the input record
+	results []*result
+}
+
+func processAll(ctx context.Context, in <-chan job, out chan<- jobResults, caches map[string]*cvefeed.Cache, cfg config, nlines *uint64) {
+	for j := range in {
+		var results []*result
+		if err := cfg.invalidJob(j); err != nil {
+			flog.Warningf("skipping invalid input line %d: %v", j.seq+1, err)
+		} else {
+			results = processRecord(ctx, j.rec, caches, cfg)
 		}
+		// the results are sent even if empty, so the writer could advance to the next record
+		out <- jobResults{j.seq, len(j.rec), results}
 
 		n := atomic.AddUint64(nlines, 1)
 		if n > 0 {
@@ -119,16 +274,168 @@ func processAll(in <-chan []string, out chan<- []string, caches map[string]*cvef
 	}
 }
 
-func processInput(in io.Reader, out io.Writer, caches map[string]*cvefeed.Cache, cfg config) chan struct{} {
+// processRecord matches CPEs from the input record against all caches.
+// Caches are only read from, so it's safe to call it concurrently.
+// Results are sorted by provider and CVE ID to make the output deterministic.
+// No results are returned if ctx is done before all caches are matched against.
+func processRecord(ctx context.Context, rec []string, caches map[string]*cvefeed.Cache, cfg config) []*result {
+	cpesAt := cfg.CPEsAt - 1
+	if cpesAt >= len(rec) {
+		flog.Errorf("not enough fields in input (%d)", len(rec))
+		return nil
+	}
+	if stats.AreLogged() {
+		stats.IncrementCounter("line.total")
+	}
+	cpeList := strings.Split(rec[cpesAt], cfg.InRecordSeparator)
+	cpes := make([]*wfn.Attributes, 0, len(cpeList))
+	var verbatim verbatimCPEs
+	if cfg.VerbatimMatches {
+		verbatim = make(verbatimCPEs, len(cpeList))
+	}
+	for i, uri := range cpeList {
+		if stats.AreLogged() {
+			stats.IncrementCounter("cpe.total")
+		}
+		attr, err := wfn.Parse(uri)
+		if err != nil {
+			flog.Errorf("couldn't parse uri %q: %v", uri, err)
+			continue
+		}
+		cpes = append(cpes, attr)
+		if verbatim != nil {
+			verbatim.add(attr, uri)
+		}
+		if cfg.OutCPEFormat != "" {
+			cpeList[i] = cfg.bindCPE(attr)
+		}
+	}
+	rec[cpesAt] = strings.Join(cpeList, cfg.OutRecordSeparator)
+
+	// if performance seems to be the issue, we could try to make these cache.Get's concurrent:
+	//
+	// wg := sync.WaitGroup{}
+	// for provider, cache := range caches {
+	// 	provider, cache := provider, cache
+	// 	wg.Add(1)
+	// 	go func() {
+	// 		defer wg.Done()
+	// 		for _, matches := range cache.Get(cpes) {
+	// ...
+	var results []*result
+	for provider, cache := range caches {
+		found, err := cache.GetContext(ctx, cpes)
+		if err != nil {
+			return nil
+		}
+		for _, matches := range found {
+			matches.CPEs = cfg.suppressions.filter(matches.CVE.ID(), matches.CPEs, cfg.LogSuppressed)
+			if len(matches.CPEs) == 0 {
+				continue
+			}
+			ml := len(matches.CPEs)
+			if stats.AreLogged() {
+				stats.IncrementCounterBy("cpe.match", int64(ml))
+				if ml != 0 {
+					stats.IncrementCounter("line.match")
+				}
+			}
+			matchingCPEs := make([]string, ml)
+			for i, attr := range matches.CPEs {
+				if attr == nil {
+					flog.Errorf("%s matches nil CPE", matches.CVE.ID())
+					continue
+				}
+				matchingCPEs[i] = cfg.bindCPE(attr)
+			}
+			attrs := make([]*wfn.Attributes, ml)
+			copy(attrs, matches.CPEs)
+			sort.Sort(byURI{matchingCPEs, attrs})
+			// the order is the same as without -verbatim_matches
+			if verbatim != nil {
+				for i, attr := range attrs {
+					if attr != nil {
+						matchingCPEs[i] = verbatim.name(&cfg, attr)
+					}
+				}
+			}
+			res := &result{
+				rec:      rec,
+				provider: provider,
+				finding:  cvefeed.NewFinding(matches, cfg.RequireVersion),
+				matches:  matchingCPEs,
+				attrs:    attrs,
+			}
+			if cfg.belowThreshold(res) {
+				continue
+			}
+			if cfg.Explain {
+				// the platforms the match depends on aren't among the matched CPEs, but they are explained too
+				res.explain = explain(provider, matches.CVE, cpes, cfg.RequireVersion)
+			}
+			results = append(results, res)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].provider != results[j].provider {
+			return results[i].provider < results[j].provider
+		}
+		return results[i].finding.ID < results[j].finding.ID
+	})
+	if cfg.Top {
+		results = topResults(results)
+	}
+	return results
+}
+
+// byURI sorts the matched CPE names along with their attributes
+type byURI struct {
+	uris  []string
+	attrs []*wfn.Attributes
+}
+
+func (s byURI) Len() int           { return len(s.uris) }
+func (s byURI) Less(i, j int) bool { return s.uris[i] < s.uris[j] }
+func (s byURI) Swap(i, j int) {
+	s.uris[i], s.uris[j] = s.uris[j], s.uris[i]
+	s.attrs[i], s.attrs[j] = s.attrs[j], s.attrs[i]
+}
+
+// processInput matches the records read from in and writes the results to out in background;
+// the returned channel is closed when all results are written.
+// Once ctx is done, no more records are read and the ones in progress are dropped.
+func processInput(ctx context.Context, in io.Reader, out io.Writer, caches map[string]*cvefeed.Cache, cfg config) chan struct{} {
 	done := make(chan struct{})
-	procIn := make(chan []string)
-	procOut := make(chan []string)
+	procIn := make(chan job)
+	procOut := make(chan jobResults)
 
 	r := csv.NewReader(in)
 	r.Comma = rune(cfg.InFieldSeparator[0])
 
-	w := csv.NewWriter(out)
-	w.Comma = rune(cfg.OutFieldSeparator[0])
+	var w recordWriter
+	if cfg.NulTerminated {
+		w = newNulWriter(out, cfg.OutFieldSeparator[:1])
+	} else {
+		cw := csv.NewWriter(out)
+		cw.Comma = rune(cfg.OutFieldSeparator[0])
+		if cfg.CSV {
+			cw.Comma = rune(cfg.CSVComma[0])
+			cw.UseCRLF = true
+		}
+		w = cw
+	}
+	// in JSON mode, results are streamed one object per line
+	enc := json.NewEncoder(out)
+	// in count mode, results are only counted and the counts are written at the end
+	var counter *resultCounter
+	if cfg.Count {
+		counter = newResultCounter(cfg.CountBy)
+	}
+	// in vendor summary mode, results are rolled up per vendor and the summary is written at the end
+	var summary *vendorSummary
+	if cfg.VendorSummary {
+		summary = newVendorSummary()
+	}
 
 	// spawn processing goroutines
 	var linesProcessed uint64
@@ -136,18 +443,66 @@ func processInput(in io.Reader, out io.Writer, caches map[string]*cvefeed.Cache,
 	procWG.Add(cfg.NumProcessors)
 	for i := 0; i < cfg.NumProcessors; i++ {
 		go func() {
-			processAll(procIn, procOut, caches, cfg, &linesProcessed)
+			processAll(ctx, procIn, procOut, caches, cfg, &linesProcessed)
 			procWG.Done()
 		}()
 	}
 
-	// write processed results in background
+	// write processed results in background in the input order:
+	// results which came out of order are buffered until all preceding results are written
 	go func() {
-		for rec := range procOut {
-			if err := w.Write(rec); err != nil {
+		pending := make(map[int]jobResults)
+		next := 0
+		for jr := range procOut {
+			pending[jr.seq] = jr
+			for {
+				jr, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				// the header is made for the first input record
+				if cfg.CSV && next == 0 && counter == nil && summary == nil {
+					if err := w.Write(cfg.header(jr.nfields)); err != nil {
+						flog.Errorf("write error: %v", err)
+					}
+				}
+				next++
+				for _, res := range jr.results {
+					for _, line := range res.explain {
+						fmt.Fprintln(explainOutput, line)
+					}
+					if counter != nil {
+						counter.add(res)
+						continue
+					}
+					if summary != nil {
+						summary.add(res)
+						continue
+					}
+					if cfg.JSON {
+						if err := enc.Encode(res.json(cfg)); err != nil {
+							flog.Errorf("write error: %v", err)
+						}
+						continue
+					}
+					if err := w.Write(res.text(cfg)); err != nil {
+						flog.Errorf("write error: %v", err)
+					}
+				}
+				w.Flush()
+			}
+		}
+		// counts of the aborted run would be incomplete
+		if counter != nil && ctx.Err() == nil {
+			if err := counter.write(w, enc, cfg.JSON); err != nil {
+				flog.Errorf("write error: %v", err)
+			}
+		}
+		if summary != nil && ctx.Err() == nil {
+			if err := summary.write(w, enc, cfg); err != nil {
 				flog.Errorf("write error: %v", err)
 			}
-			w.Flush()
 		}
 		if err := w.Error(); err != nil {
 			flog.Errorf("write error: %v", err)
@@ -157,15 +512,24 @@ func processInput(in io.Reader, out io.Writer, caches map[string]*cvefeed.Cache,
 
 	start := time.Now()
 	// main goroutine reads input and sends it to processors
-	for line := 1; ; line++ {
+	for line := 1; ctx.Err() == nil; line++ {
 		rec, err := r.Read()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
-			flog.Errorf("read error at line %d: %v", line, err)
+			// in -skip_invalid mode, the line is reported by the processor skipping it
+			if !cfg.SkipInvalid {
+				flog.Errorf("read error at line %d: %v", line, err)
+			}
+		}
+		select {
+		case procIn <- job{line - 1, rec, err}:
+		case <-ctx.Done():
 		}
-		procIn <- rec
+	}
+	if err := ctx.Err(); err != nil {
+		flog.Errorf("processing aborted: %v", err)
 	}
 
 	close(procIn)
@@ -188,6 +552,25 @@ func init() {
 	flag.Set("logtostderr", "true")
 }
 
+// notifyContext returns a copy of parent which is canceled when one of the signals arrives or stop is called;
+// signal.NotifyContext does the same, but needs go1.16
+func notifyContext(parent context.Context, signals ...os.Signal) (ctx context.Context, stop context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, signals...)
+	go func() {
+		select {
+		case <-c:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(c)
+		cancel()
+	}
+}
+
 func main() {
 	// we do it like this because if we exit in Main, deferred functions don't get called
 	os.Exit(Main())
@@ -201,6 +584,10 @@ func Main() int {
 	cfgFile := flag.String("config", "", "path to a config file (JSON or TOML); see usage to see how it's configured (pass -v=1 flag for verbose help). Mutually exclusive with command line flags => when used, other flags are ignored")
 	flag.Parse()
 
+	// loading and processing are aborted on interrupt
+	ctx, stop := notifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	var err error
 	if *cfgFile != "" {
 		// override config from config file
@@ -211,6 +598,9 @@ func Main() int {
 		cfg.addFeedsFromArgs(*provider, flag.Args()...)
 		err = cfg.validate()
 	}
+	if err == nil && cfg.SuppressFile != "" {
+		cfg.suppressions, err = readSuppressions(cfg.SuppressFile)
+	}
 	if err != nil {
 		flog.Error(err)
 		flag.Usage()
@@ -230,7 +620,11 @@ func Main() int {
 	var overrides cvefeed.Dictionary
 	dicts := map[string]cvefeed.Dictionary{} // provider -> dictionary
 	for provider, files := range cfg.Feeds {
-		dict, err := cvefeed.LoadJSONDictionary(files...)
+		dict, err := cvefeed.LoadJSONDictionaryContext(ctx, cvefeed.DictionaryOptions{IncludeRejected: cfg.IncludeRejected, Logger: logging.Flog}, files...)
+		if ctx.Err() != nil {
+			flog.Errorf("loading dictionaries aborted: %v", ctx.Err())
+			return 1
+		}
 		if err != nil {
 			flog.Errorf("failed to load dictionary for provider %s: %v", provider, err)
 		}
@@ -249,7 +643,8 @@ func Main() int {
 		return -1
 	}
 
-	overrides, err = cvefeed.LoadJSONDictionary(cfg.FeedOverrides...)
+	// overrides only amend configurations of the feed entries, whatever their descriptions say
+	overrides, err = cvefeed.LoadJSONDictionaryContext(ctx, cvefeed.DictionaryOptions{IncludeRejected: true, Logger: logging.Flog}, cfg.FeedOverrides...)
 	if err != nil {
 		flog.Error(err)
 		return -1
@@ -268,14 +663,15 @@ func Main() int {
 
 	caches := map[string]*cvefeed.Cache{}
 	for provider, dict := range dicts {
-		caches[provider] = cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetMaxSize(cfg.CacheSize)
+		caches[provider] = cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetMaxSize(cfg.CacheSize).SetLogger(logging.Flog)
 	}
 
 	if cfg.IndexDict {
 		start = time.Now()
 		flog.V(1).Info("indexing dictionaries...")
 		for provider, cache := range caches {
-			cache.Idx = cvefeed.NewIndex(dicts[provider])
+			cache.Idx = loadIndex(cfg, provider, dicts[provider])
+			cache.ExactIdx = cvefeed.NewExactIndex(dicts[provider])
 			if flog.V(2) {
 				var named, total int
 				for k, v := range cache.Idx {
@@ -300,7 +696,17 @@ func Main() int {
 		defer pprof.StopCPUProfile()
 	}
 
-	done := processInput(os.Stdin, os.Stdout, caches, cfg)
+	in, err := openInputs(cfg.Inputs, os.Stdin)
+	if err != nil {
+		flog.Error(err)
+		return 1
+	}
+	defer in.Close()
+
+	if cfg.SkipInvalid {
+		cfg.lines = &lineCounts{}
+	}
+	done := processInput(ctx, in, os.Stdout, caches, cfg)
 
 	if cfg.MemoryProfile != "" {
 		f, err := os.Create(cfg.MemoryProfile)
@@ -316,5 +722,66 @@ func Main() int {
 	}
 
 	<-done
+	if ctx.Err() != nil {
+		return 1
+	}
+	if cfg.lines != nil && cfg.lines.allInvalid() {
+		flog.Errorf("all %d input lines are invalid", cfg.lines.total)
+		return 1
+	}
 	return 0
 }
+
+// loadIndex loads the index of dict, the dictionary of provider, saved in -idxd_file (with provider appended, if set)
+// or builds it; if -idxd_file is set and the saved index is missing or stale, the new one is saved there
+func loadIndex(cfg config, provider string, dict cvefeed.Dictionary) cvefeed.Index {
+	path := cfg.IndexFile
+	if path == "" {
+		return cvefeed.NewIndex(dict)
+	}
+	if provider != "" {
+		path += "." + provider
+	}
+	hash, err := indexSourceHash(cfg, provider)
+	if err != nil {
+		flog.Errorf("couldn't use saved index: %v", err)
+		return cvefeed.NewIndex(dict)
+	}
+	if f, err := os.Open(path); err == nil {
+		idx, err := cvefeed.LoadIndex(f, dict, hash)
+		f.Close()
+		if err == nil {
+			flog.V(1).Infof("loaded index from %q", path)
+			return idx
+		}
+		flog.V(1).Infof("rebuilding index %q: %v", path, err)
+	}
+	idx := cvefeed.NewIndex(dict)
+	f, err := os.Create(path)
+	if err != nil {
+		flog.Errorf("couldn't save index: %v", err)
+		return idx
+	}
+	if err := idx.Save(f, hash); err != nil {
+		flog.Errorf("couldn't save index to %q: %v", path, err)
+	}
+	if err := f.Close(); err != nil {
+		flog.Errorf("couldn't save index to %q: %v", path, err)
+	}
+	return idx
+}
+
+// indexSourceHash returns the hash of what the index of the provider's dictionary is built from: its feeds,
+// the override feeds applied to it and the options the feeds are loaded with
+func indexSourceHash(cfg config, provider string) ([]byte, error) {
+	h := sha256.New()
+	for _, paths := range [][]string{cfg.Feeds[provider], cfg.FeedOverrides} {
+		sum, err := cvefeed.FeedsHash(paths...)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(sum)
+	}
+	fmt.Fprintf(h, "include_rejected=%t", cfg.IncludeRejected)
+	return h.Sum(nil), nil
+}
@@ -16,9 +16,17 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed"
 )
@@ -96,7 +104,7 @@ func TestProcessInput(t *testing.T) {
 			t.Run(fmt.Sprintf("cache#%d case #%d", cacheID+1, i+1), func(t *testing.T) {
 				var w bytes.Buffer
 				r := strings.NewReader(c.in)
-				done := processInput(r, &w, singleCache(cache), cfg)
+				done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 				<-done
 				got := strings.Split(strings.TrimSpace(w.String()), "\n")
 				if len(got) != len(c.out) {
@@ -140,7 +148,7 @@ func TestProcessInputFalsePositives(t *testing.T) {
 	}
 	var w bytes.Buffer
 	r := strings.NewReader(in)
-	done := processInput(r, &w, singleCache(cache), cfg)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 	<-done
 	out := strings.TrimSpace(w.String())
 	if out != "" {
@@ -170,7 +178,7 @@ func TestProcessInputRequireVersion(t *testing.T) {
 	}
 	var w bytes.Buffer
 	r := strings.NewReader(in)
-	done := processInput(r, &w, singleCache(cache), cfg)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 	<-done
 	out := strings.TrimSpace(w.String())
 	if out != "" {
@@ -178,6 +186,333 @@ func TestProcessInputRequireVersion(t *testing.T) {
 	}
 }
 
+// cancelingReader cancels the context once the first part of the input is read
+type cancelingReader struct {
+	first, rest io.Reader
+	cancel      context.CancelFunc
+}
+
+func (r *cancelingReader) Read(p []byte) (int, error) {
+	if r.first != nil {
+		n, err := r.first.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		r.first = nil
+		r.cancel()
+	}
+	return r.rest.Read(p)
+}
+
+func TestProcessInputCancelled(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr3))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      2,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ";",
+	}
+	const nlines = 1000
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := &cancelingReader{
+		first:  strings.NewReader("cpe:/a:foo:bar:1.0\n"),
+		rest:   strings.NewReader(strings.Repeat("cpe:/a:foo:bar:1.0\n", nlines-1)),
+		cancel: cancel,
+	}
+	var w bytes.Buffer
+	done := processInput(ctx, in, &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("processing wasn't aborted")
+	}
+	// every line matches 3 CVEs, only the lines read before cancellation could be processed
+	if n := strings.Count(w.String(), "\n"); n >= 3*nlines {
+		t.Fatalf("all %d lines were processed after cancellation", nlines)
+	}
+}
+
+func TestProcessInputCVSS3(t *testing.T) {
+	in := "cpe:/a:foo:bar:1.0"
+	expect := []string{
+		"cpe:/a:foo:bar:1.0;CVE-2019-0001;5.0;0.0;;;5.0",
+		"cpe:/a:foo:bar:1.0;CVE-2019-0002;0.0;9.8;CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H;CRITICAL;9.8",
+		"cpe:/a:foo:bar:1.0;CVE-2019-0003;4.3;6.1;CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N;MEDIUM;6.1",
+	}
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr3))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		CVSS2At:            3,
+		CVSS3At:            4,
+		CVSS3VectorAt:      5,
+		CVSS3SeverityAt:    6,
+		CVSSAt:             7,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cache), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	sort.Strings(got)
+	if strings.Join(got, "\n") != strings.Join(expect, "\n") {
+		t.Fatalf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expect, "\n"))
+	}
+}
+
+func TestProcessInputCWE(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrCWEs))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		CWEsAt:             3,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "|",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader("cpe:/a:foo:bar:1.0"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	if got, expect := strings.TrimSpace(w.String()), "cpe:/a:foo:bar:1.0;CVE-2019-0004;CWE-79|CWE-352|NVD-CWE-noinfo"; got != expect {
+		t.Fatalf("got %q, expected %q", got, expect)
+	}
+}
+
+func TestProcessInputExtendedAttributes(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrTargets))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             1,
+		MatchesAt:          2,
+		TargetSWAt:         3,
+		TargetHWAt:         4,
+		EraseFields:        fieldsToSkip{0: true},
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "|",
+	}
+	in := strings.Join([]string{
+		"cpe:2.3:a:openssl:openssl:1.1.1:*:*:*:*:linux:x86_64:*",
+		"cpe:2.3:a:openssl:openssl:1.1.1:*:*:*:*:iphone_os:arm64:*",
+		"cpe:2.3:a:openssl:openssl:1.1.1:*:*:*:*:android:arm64:*",
+	}, ",")
+	expect := []string{
+		// target_hw is arm64
+		"CVE-2019-0020;cpe:/a:openssl:openssl:1.1.1::~~~android~arm64~|cpe:/a:openssl:openssl:1.1.1::~~~iphone_os~arm64~;android|iphone_os;arm64|arm64",
+		// target_sw is android
+		"CVE-2019-0021;cpe:/a:openssl:openssl:1.1.1::~~~android~arm64~;android;arm64",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	if got := strings.Split(strings.TrimSpace(w.String()), "\n"); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expect, "\n"))
+	}
+
+	cfg.JSON = true
+	w.Reset()
+	done = processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	var res jsonResult
+	if err := json.NewDecoder(&w).Decode(&res); err != nil {
+		t.Fatalf("couldn't decode JSON output: %v", err)
+	}
+	attrs := map[string][]string{"target_sw": {"android", "iphone_os"}, "target_hw": {"arm64", "arm64"}}
+	if !reflect.DeepEqual(res.Attributes, attrs) {
+		t.Fatalf("got attributes %v, expected %v", res.Attributes, attrs)
+	}
+}
+
+func TestProcessInputReferences(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrReferences))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cases := []struct {
+		tags   string
+		expect string
+	}{
+		{"", "cpe:/a:foo:bar:1.0;CVE-2019-0005;https://example.com/patch|https://example.com/exploit|https://example.com/advisory"},
+		{"Patch", "cpe:/a:foo:bar:1.0;CVE-2019-0005;https://example.com/patch"},
+		{"exploit, Vendor Advisory", "cpe:/a:foo:bar:1.0;CVE-2019-0005;https://example.com/exploit|https://example.com/advisory"},
+		{"Mitigation", "cpe:/a:foo:bar:1.0;CVE-2019-0005;"},
+	}
+	for _, c := range cases {
+		t.Run(c.tags, func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      1,
+				CPEsAt:             1,
+				CVEsAt:             2,
+				ReferencesAt:       3,
+				ReferenceTags:      c.tags,
+				InFieldSeparator:   ",",
+				OutFieldSeparator:  ";",
+				InRecordSeparator:  ",",
+				OutRecordSeparator: "|",
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader("cpe:/a:foo:bar:1.0"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+			<-done
+			if got := strings.TrimSpace(w.String()); got != c.expect {
+				t.Fatalf("got %q, expected %q", got, c.expect)
+			}
+		})
+	}
+}
+
+func TestProcessInputJSON(t *testing.T) {
+	in := "host1;cpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0"
+	barRange := &jsonRange{CPE: "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0"}
+	expect := []jsonResult{
+		{
+			Fields:   []string{"host1", "cpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0"},
+			CVE:      "CVE-2019-0001",
+			Matches:  []string{"cpe:/a:foo:bar:1.0"},
+			CVSS2:    5.0,
+			CVSS:     5.0,
+			Provider: "test",
+			Range:    barRange,
+		},
+		{
+			Fields:        []string{"host1", "cpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0"},
+			CVE:           "CVE-2019-0002",
+			Matches:       []string{"cpe:/a:foo:bar:1.0"},
+			CVSS3:         9.8,
+			CVSS3Vector:   "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			CVSS3Severity: "CRITICAL",
+			CVSS:          9.8,
+			Provider:      "test",
+			Range:         barRange,
+		},
+		{
+			Fields:        []string{"host1", "cpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0"},
+			CVE:           "CVE-2019-0003",
+			Matches:       []string{"cpe:/a:foo:bar:1.0"},
+			CVSS2:         4.3,
+			CVSS3:         6.1,
+			CVSS3Vector:   "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N",
+			CVSS3Severity: "MEDIUM",
+			CVSS:          6.1,
+			Provider:      "test",
+			Range:         barRange,
+		},
+	}
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr3))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             2,
+		JSON:               true,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cache), cfg)
+	<-done
+	var got []jsonResult
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		var res jsonResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("couldn't parse output line %q: %v", line, err)
+		}
+		got = append(got, res)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].CVE < got[j].CVE })
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got:\n%+v\nexpected:\n%+v", got, expect)
+	}
+}
+
+func TestProcessInputDates(t *testing.T) {
+	in := "cpe:/a:foo:bar:1.0"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrDates))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		PublishedAt:        3,
+		ModifiedAt:         4,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	expect := []string{
+		"cpe:/a:foo:bar:1.0;CVE-2019-0030;2019-01-01T00:00:00Z;2019-06-11T15:29:30Z",
+		"cpe:/a:foo:bar:1.0;CVE-2019-0031;2015-03-10T18:59:00Z;2015-03-11T01:00:00Z",
+		"cpe:/a:foo:bar:1.0;CVE-2019-0032;;",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	if got := strings.Split(strings.TrimSpace(w.String()), "\n"); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got:\n%q\nexpected:\n%q", got, expect)
+	}
+
+	cfg.JSON = true
+	w.Reset()
+	done = processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		var res jsonResult
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatalf("couldn't parse output line %q: %v", line, err)
+		}
+		got = append(got, strings.Join([]string{in, res.CVE, res.Published, res.Modified}, ";"))
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("JSON: got:\n%q\nexpected:\n%q", got, expect)
+	}
+}
+
 func BenchmarkProcessInputJSON(t *testing.B) {
 	in := `1;2;3;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194
 1;2;3;cpe:/o::centos_linux:7.5.1804,cpe:/a::chardet:2.2.1,cpe:/a::javapackages:1.0.0,cpe:/a::kitchen:1.1.1,cpe:/a::nose:1.3.7,cpe:/a::python-dateutil:1.5,cpe:/a::pytz:2016.10,cpe:/a::setuptools:0.9.8,cpe:/a::chardet:2.2.1,cpe:/a::javapackages:1.0.0,cpe:/a::kitchen:1.1.1,cpe:/a::nose:1.3.7,cpe:/a::python-dateutil:1.5,cpe:/a::pytz:2016.10,cpe:/a::setuptools:0.9.8
@@ -204,11 +539,165 @@ func BenchmarkProcessInputJSON(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		var w bytes.Buffer
 		r := strings.NewReader(in)
-		done := processInput(r, &w, singleCache(cache), cfg)
+		done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 		<-done
 	}
 }
 
+func TestProcessInputCSV(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrCWEs))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             2,
+		CVEsAt:             3,
+		CWEsAt:             4,
+		CSV:                true,
+		CSVComma:           ",",
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  "+",
+		OutRecordSeparator: ",",
+	}
+	// the first field has both the comma and the quote, the CWEs are joined with the comma
+	in := `"web ""01"", eu";cpe:/a:foo:bar:1.0`
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	expect := "field1,cpe,cve,cwe\r\n" + `"web ""01"", eu",cpe:/a:foo:bar:1.0,CVE-2019-0004,"CWE-79,CWE-352,NVD-CWE-noinfo"` + "\r\n"
+	if got := w.String(); got != expect {
+		t.Fatalf("got %q, expected %q", got, expect)
+	}
+	recs, err := csv.NewReader(&w).ReadAll()
+	if err != nil {
+		t.Fatalf("couldn't read the output back: %v", err)
+	}
+	expectRecs := [][]string{
+		{"field1", "cpe", "cve", "cwe"},
+		{`web "01", eu`, "cpe:/a:foo:bar:1.0", "CVE-2019-0004", "CWE-79,CWE-352,NVD-CWE-noinfo"},
+	}
+	if !reflect.DeepEqual(recs, expectRecs) {
+		t.Fatalf("got %q, expected %q", recs, expectRecs)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	cfg := config{
+		CPEsAt:          2,
+		CVEsAt:          1,
+		MatchesAt:       4,
+		CWEsAt:          5,
+		ReferencesAt:    6,
+		CVSS2At:         7,
+		CVSS3At:         8,
+		CVSS3VectorAt:   9,
+		CVSS3SeverityAt: 10,
+		CVSSAt:          11,
+		ProviderAt:      12,
+		SWEditionAt:     13,
+		TargetSWAt:      14,
+		TargetHWAt:      15,
+		OtherAt:         16,
+		EraseFields:     fieldsToSkip{2: true},
+	}
+	expect := []string{"cve", "field1", "cpe", "matches", "cwe", "refs", "cvss2", "cvss3", "cvss3_vector", "cvss3_severity", "cvss", "provider", "sw_edition", "target_sw", "target_hw", "other"}
+	if got := cfg.header(3); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got %q, expected %q", got, expect)
+	}
+	// every output field is named
+	vulns, err := cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrCWEs))
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	res := &result{rec: []string{"a", "b", "c"}, finding: cvefeed.NewFinding(cvefeed.MatchResult{CVE: vulns[0]}, false)}
+	if got := res.text(cfg); len(got) != len(expect) {
+		t.Fatalf("got %d fields in the output, %d in the header", len(got), len(expect))
+	}
+}
+
+func TestProcessInputOrder(t *testing.T) {
+	var in strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&in, "host%d;cpe:/a:foo:bar:1.0,cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194\n", i)
+		fmt.Fprintf(&in, "host%d;cpe:/a:foo:baz:1.0\n", i)
+	}
+	caches := map[string]*cvefeed.Cache{}
+	for provider, feed := range map[string]string{"p1": testDictJSONStr, "p2": testDictJSONStr3} {
+		feed := feed
+		dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+			return cvefeed.ParseJSON(bytes.NewBufferString(feed))
+		}, "")
+		if err != nil {
+			t.Fatalf("couldn't parse JSON dictionary: %v", err)
+		}
+		caches[provider] = cvefeed.NewCache(dict)
+	}
+	cfg := config{
+		CPEsAt:             2,
+		CVEsAt:             3,
+		MatchesAt:          4,
+		ProviderAt:         5,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  "|",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "&",
+	}
+	var expect string
+	for _, n := range []int{1, 2, 4, 16} {
+		cfg.NumProcessors = n
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in.String()), &w, caches, cfg)
+		<-done
+		if n == 1 {
+			expect = w.String()
+			if expect == "" {
+				t.Fatal("no matches")
+			}
+			continue
+		}
+		if got := w.String(); got != expect {
+			t.Fatalf("output with %d threads differs from the one with 1 thread:\n%s\nexpected:\n%s", n, got, expect)
+		}
+	}
+}
+
+func BenchmarkProcessInputThreads(b *testing.B) {
+	var in strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&in, "host%d;cpe:/o::centos_linux:7.5.1804,cpe:/a::chardet:2.2.1,cpe:/a::kitchen:1.1.1,cpe:/a:adobe:flash_player:24.0.0.%d\n", i, i)
+	}
+	testDict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		b.Fatalf("couldn't parse dictionary: %v", err)
+	}
+	for _, n := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("threads=%d", n), func(b *testing.B) {
+			cache := cvefeed.NewCache(testDict).SetMaxSize(-1)
+			cfg := config{
+				NumProcessors:      n,
+				CPEsAt:             2,
+				CVEsAt:             3,
+				MatchesAt:          4,
+				InFieldSeparator:   ";",
+				OutFieldSeparator:  "|",
+				InRecordSeparator:  ",",
+				OutRecordSeparator: "&",
+			}
+			for i := 0; i < b.N; i++ {
+				var w bytes.Buffer
+				done := processInput(context.Background(), strings.NewReader(in.String()), &w, singleCache(cache), cfg)
+				<-done
+			}
+		})
+	}
+}
+
 func getSkip(ff []int) fieldsToSkip {
 	set := make(map[int]bool)
 	for _, f := range ff {
@@ -380,3 +869,38 @@ var testDictJSONStr = `{
 }`
 
 var testDictJSONStr2 = `{"CVE_data_format":"","CVE_data_type":"","CVE_data_version":"","CVE_Items":[{"cve":{"affects":{"vendor":{"vendor_data":[{"product":{"product_data":[{"product_name":"d100","version":{"version_data":[{"version_value":"*"}]}}]},"vendor_name":"huaweidevice"}]}},"CVE_data_meta":{"ASSIGNER":"cve@mitre.org","ID":"CVE-2009-2273"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0","description":{"description_data":[{"lang":"en","value":"The default configuration of the Wi-Fi component on the Huawei D100 does not use encryption, which makes it easier for remote attackers to obtain sensitive information by sniffing the network."}]},"problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-310"}]}]},"references":{"reference_data":[{"name":"20090630 Multiple Flaws in Huawei D100","refsource":"BUGTRAQ","url":"http://www.securityfocus.com/archive/1/archive/1/504645/100/0/threaded"}]}},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe":[{"cpe22Uri":"cpe:/h:huaweidevice:d100","cpe23Uri":"cpe:2.3:h:huaweidevice:d100:*:*:*:*:*:*:*:*","vulnerable":true}],"operator":"AND"}]},"impact":{"baseMetricV2":{"cvssV2":{"accessComplexity":"LOW","accessVector":"NETWORK","authentication":"NONE","availabilityImpact":"NONE","baseScore":5,"confidentialityImpact":"PARTIAL","integrityImpact":"NONE","vectorString":"(AV:N/AC:L/Au:N/C:P/I:N/A:N)","version":"2.0"},"exploitabilityScore":10,"impactScore":2.9,"severity":"MEDIUM"}},"lastModifiedDate":"2009-07-01T04:00Z","publishedDate":"2009-07-01T13:00Z"}]}`
+
+// v2 only, v3 only and both
+var testDictJSONStr3 = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0001"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV2":{"cvssV2":{"baseScore":5,"vectorString":"(AV:N/AC:L/Au:N/C:P/I:N/A:N)","version":"2.0"},"severity":"MEDIUM"}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0002"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":9.8,"baseSeverity":"CRITICAL","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","version":"3.0"}}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0003"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV2":{"cvssV2":{"baseScore":4.3,"vectorString":"(AV:N/AC:M/Au:N/C:N/I:P/A:N)","version":"2.0"},"severity":"MEDIUM"},"baseMetricV3":{"cvssV3":{"baseScore":6.1,"baseSeverity":"MEDIUM","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N","version":"3.0"}}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"}]}`
+
+var testDictJSONStrCWEs = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0004"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0","problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-79"},{"lang":"en","value":"CWE-352"}]},{"description":[{"lang":"en","value":"CWE-79"},{"lang":"en","value":"NVD-CWE-noinfo"}]}]}},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"}]}`
+
+var testDictJSONStrReferences = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0005"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0","references":{"reference_data":[{"url":"https://example.com/patch","refsource":"MISC","tags":["Patch"]},{"url":"https://example.com/exploit","refsource":"MISC","tags":["Exploit","Third Party Advisory"]},{"url":"https://example.com/patch","refsource":"CONFIRM"},{"url":"https://example.com/advisory","refsource":"CONFIRM","tags":["Vendor Advisory"]}]}},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"}]}`
+
+var testDictJSONStrTargets = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0020"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:arm64:*","versionEndExcluding":"1.1.2","vulnerable":true}],"operator":"OR"}]},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0021"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:openssl:openssl:*:*:*:*:*:android:*:*","versionEndExcluding":"1.1.2","vulnerable":true}],"operator":"OR"}]},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"}]}`
+
+var testDictJSONStrDates = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0030"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"lastModifiedDate":"2019-06-11T17:29:30.123+02:00","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0031"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"lastModifiedDate":"2015-03-10T21:00-0400","publishedDate":"2015-03-10T14:59-0400"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0032"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]}}]}`
+
+func TestFlagSpellings(t *testing.T) {
+	for _, args := range [][]string{
+		{"-skip_invalid", "-out_cpe_format", "2.3", "-min_cvss", "7", "-min_severity", "HIGH", "-count_by", "cve"},
+		{"-skip-invalid", "-out-cpe-format", "2.3", "-min-cvss", "7", "-min-severity", "HIGH", "-count-by", "cve"},
+	} {
+		cfg := parseFlags(t, args...)
+		if !cfg.SkipInvalid || cfg.OutCPEFormat != "2.3" || cfg.MinCVSS != 7 || cfg.MinSeverity != "HIGH" || cfg.CountBy != "cve" {
+			t.Errorf("%q: flags weren't set: %+v", args, cfg)
+		}
+	}
+}
+
+// parseFlags parses args into the config as the command line flags
+func parseFlags(t *testing.T, args ...string) config {
+	defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet("cpe2cve", flag.ContinueOnError)
+	var cfg config
+	cfg.addFlags()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		t.Fatalf("%q: %v", args, err)
+	}
+	return cfg
+}
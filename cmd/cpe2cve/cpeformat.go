@@ -0,0 +1,59 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// bindCPE binds the CPE name for the output as per -out_cpe_format: as formatted string for 2.3, as URI otherwise
+func (cfg *config) bindCPE(attr *wfn.Attributes) string {
+	if cfg.OutCPEFormat == "2.3" {
+		return attr.BindToFmtString()
+	}
+	return attr.BindToURI()
+}
+
+// verbatimCPEs maps the CPEs of an input record to the names they were parsed from, in -verbatim_matches mode;
+// the CPEs are looked up by value, as the matched ones can be equal copies of the input ones, e.g. cached
+type verbatimCPEs map[string]string
+
+// add records that attr was parsed from name, unless an equal CPE was already parsed from another name before
+func (v verbatimCPEs) add(attr *wfn.Attributes, name string) {
+	key := attr.BindToFmtString()
+	if _, ok := v[key]; !ok {
+		v[key] = name
+	}
+}
+
+// name returns the name attr was parsed from, or attr bound as per -out_cpe_format if it's unknown
+func (v verbatimCPEs) name(cfg *config, attr *wfn.Attributes) string {
+	if name, ok := v[attr.BindToFmtString()]; ok {
+		return name
+	}
+	return cfg.bindCPE(attr)
+}
+
+// validateOutCPEFormat returns an error if format isn't one of the supported CPE bindings
+func validateOutCPEFormat(format string) error {
+	switch format {
+	case "", "2.2", "2.3":
+		return nil
+	default:
+		return fmt.Errorf("-out_cpe_format value is invalid %q: must be 2.2 or 2.3", format)
+	}
+}
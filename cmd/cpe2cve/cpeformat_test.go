@@ -0,0 +1,133 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputOutCPEFormat(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrCWEs))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	// URIs and formatted strings can be mixed in the input
+	in := "cpe:/a:foo:bar:1.0,cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*,cpe:/a:foo:bar:1.1::~~~android~~,cpe:/a:foo:bar:2.0"
+	cases := []struct {
+		format string
+		expect string
+	}{
+		{
+			format: "",
+			expect: "cpe:/a:foo:bar:1.0|cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*|cpe:/a:foo:bar:1.1::~~~android~~|cpe:/a:foo:bar:2.0;CVE-2019-0004;" +
+				"cpe:/a:foo:bar:1.0|cpe:/a:foo:bar:1.1::~~~android~~|cpe:/a:foo:bar:1.5",
+		},
+		{
+			format: "2.2",
+			expect: "cpe:/a:foo:bar:1.0|cpe:/a:foo:bar:1.5|cpe:/a:foo:bar:1.1::~~~android~~|cpe:/a:foo:bar:2.0;CVE-2019-0004;" +
+				"cpe:/a:foo:bar:1.0|cpe:/a:foo:bar:1.1::~~~android~~|cpe:/a:foo:bar:1.5",
+		},
+		{
+			format: "2.3",
+			expect: "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*|cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*|cpe:2.3:a:foo:bar:1.1:*:*:*:*:android:*:*|cpe:2.3:a:foo:bar:2.0:*:*:*:*:*:*:*;CVE-2019-0004;" +
+				"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*|cpe:2.3:a:foo:bar:1.1:*:*:*:*:android:*:*|cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*",
+		},
+	}
+	for _, c := range cases {
+		cfg := config{
+			NumProcessors:      1,
+			CPEsAt:             1,
+			CVEsAt:             2,
+			MatchesAt:          3,
+			OutCPEFormat:       c.format,
+			InFieldSeparator:   ";",
+			OutFieldSeparator:  ";",
+			InRecordSeparator:  ",",
+			OutRecordSeparator: "|",
+		}
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		if got := strings.TrimSpace(w.String()); got != c.expect {
+			t.Errorf("-out_cpe_format=%q:\ngot      %q\nexpected %q", c.format, got, c.expect)
+		}
+	}
+}
+
+func TestProcessInputVerbatimMatches(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrCWEs))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	// valid, but not as the CPEs are bound: mixed case, trailing empty components, formatted strings
+	in := "cpe:/a:Foo:Bar:1.0::,cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*,cpe:/a:foo:bar:2.0\n" +
+		"cpe:/a:foo:BAR:1.1,cpe:2.3:a:foo:bar:1.1:*:*:*:*:*:*:*"
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		MatchesAt:          3,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "|",
+	}
+	run := func(cfg config) string {
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		return strings.TrimSpace(w.String())
+	}
+
+	expect := "cpe:/a:Foo:Bar:1.0::|cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*|cpe:/a:foo:bar:2.0;CVE-2019-0004;cpe:/a:foo:bar:1.0|cpe:/a:foo:bar:1.5\n" +
+		"cpe:/a:foo:BAR:1.1|cpe:2.3:a:foo:bar:1.1:*:*:*:*:*:*:*;CVE-2019-0004;cpe:/a:foo:bar:1.1"
+	if got := run(cfg); got != expect {
+		t.Errorf("default:\ngot      %q\nexpected %q", got, expect)
+	}
+	cfg.VerbatimMatches = true
+	expect = "cpe:/a:Foo:Bar:1.0::|cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*|cpe:/a:foo:bar:2.0;CVE-2019-0004;cpe:/a:Foo:Bar:1.0::|cpe:2.3:a:foo:bar:1.5:*:*:*:*:*:*:*\n" +
+		"cpe:/a:foo:BAR:1.1|cpe:2.3:a:foo:bar:1.1:*:*:*:*:*:*:*;CVE-2019-0004;cpe:/a:foo:BAR:1.1"
+	if got := run(cfg); got != expect {
+		t.Errorf("-verbatim_matches:\ngot      %q\nexpected %q", got, expect)
+	}
+
+	cfg.Feeds = map[string][]string{"": {"feed.json"}}
+	cfg.OutCPEFormat = "2.3"
+	if err := cfg.validate(); err == nil {
+		t.Error("-verbatim_matches with -out_cpe_format should be rejected")
+	}
+}
+
+func TestValidateOutCPEFormat(t *testing.T) {
+	for _, format := range []string{"", "2.2", "2.3"} {
+		if err := validateOutCPEFormat(format); err != nil {
+			t.Errorf("%q: unexpected error: %v", format, err)
+		}
+	}
+	for _, format := range []string{"2.0", "uri", "2.3 "} {
+		if err := validateOutCPEFormat(format); err == nil {
+			t.Errorf("%q: expected an error", format)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// explainOutput receives the explanations of matches in -explain mode
+var explainOutput io.Writer = os.Stderr
+
+// explain describes the cpe_match entries of vuln which matched cpes, one line per entry, e.g.
+// CVE-2019-0001: cpe:/a:acme:widget:1.3 matched cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:* [>= 1.0, < 1.5] vulnerable in AND/OR node
+func explain(provider string, vuln cvefeed.Vuln, cpes []*wfn.Attributes, requireVersion bool) []string {
+	id := vuln.ID()
+	if provider != "" {
+		id = provider + " " + id
+	}
+	var lines []string
+	for _, e := range cvefeed.Explain(vuln, cpes, requireVersion) {
+		m := e.CPEMatch
+		uri := m.Cpe23Uri
+		if uri == "" {
+			uri = m.Cpe22Uri
+		}
+		var bounds []string
+		for _, b := range []struct{ op, ver string }{
+			{">=", m.VersionStartIncluding},
+			{">", m.VersionStartExcluding},
+			{"<=", m.VersionEndIncluding},
+			{"<", m.VersionEndExcluding},
+		} {
+			if b.ver != "" {
+				bounds = append(bounds, b.op+" "+b.ver)
+			}
+		}
+		if len(bounds) != 0 {
+			uri += " [" + strings.Join(bounds, ", ") + "]"
+		}
+		kind := "vulnerable"
+		if !m.Vulnerable {
+			kind = "not vulnerable"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s matched %s %s in %s node",
+			id, e.Attributes.BindToURI(), uri, kind, strings.Join(e.Operators, "/")))
+	}
+	return lines
+}
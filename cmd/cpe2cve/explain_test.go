@@ -0,0 +1,63 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputExplain(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONLayered))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		Explain:            true,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	var w, explained bytes.Buffer
+	defer func(out io.Writer) { explainOutput = out }(explainOutput)
+	explainOutput = &explained
+	done := processInput(context.Background(), strings.NewReader("cpe:/a:acme:widget:1.3,cpe:/o:linux:linux_kernel:4.0"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+
+	if !strings.Contains(w.String(), "CVE-2019-1000") {
+		t.Fatalf("expected a match, got %q", w.String())
+	}
+	expect := []string{
+		"test CVE-2019-1000: cpe:/a:acme:widget:1.3 matched cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:* [>= 1.0, < 1.5] vulnerable in AND/OR node",
+		"test CVE-2019-1000: cpe:/o:linux:linux_kernel:4.0 matched cpe:2.3:o:linux:linux_kernel:*:*:*:*:*:*:*:* not vulnerable in AND/OR node",
+	}
+	got := strings.Split(strings.TrimSpace(explained.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(expect, "\n") {
+		t.Fatalf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expect, "\n"))
+	}
+}
+
+var testDictJSONLayered = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-1000"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"AND","children":[{"operator":"OR","cpe_match":[{"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"1.0","vulnerable":true},{"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0","versionEndExcluding":"1.5","vulnerable":true},{"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"2.0","vulnerable":true}]},{"operator":"OR","cpe_match":[{"cpe23Uri":"cpe:2.3:o:linux:linux_kernel:*:*:*:*:*:*:*:*","vulnerable":false}]}]}]},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"}]}`
@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// openInputs returns a reader of all files in paths concatenated, or of stdin if there are none.
+// Gzip-compressed inputs are decompressed; a newline is added to inputs which don't end with one,
+// so the last record of one file doesn't run into the first record of the next.
+func openInputs(paths []string, stdin io.Reader) (io.ReadCloser, error) {
+	if len(paths) == 0 {
+		r, err := decompress(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("can't read stdin: %v", err)
+		}
+		return inputs{Reader: r}, nil
+	}
+	var readers []io.Reader
+	var files inputs
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			files.Close()
+			return nil, err
+		}
+		files.files = append(files.files, f)
+		r, err := decompress(f)
+		if err != nil {
+			files.Close()
+			return nil, fmt.Errorf("can't read %q: %v", path, err)
+		}
+		readers = append(readers, &terminated{r: r})
+	}
+	files.Reader = io.MultiReader(readers...)
+	return files, nil
+}
+
+// decompress returns a reader of r decompressed, if it's gzip-compressed, or of r as is
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == io.EOF {
+		return br, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header[0] == 0x1f && header[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// inputs reads the input and closes the files it's read from
+type inputs struct {
+	io.Reader
+	files []*os.File
+}
+
+func (in inputs) Close() error {
+	var err error
+	for _, f := range in.files {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// terminated reads r and adds a newline at the end, unless r is empty or already ends with one
+type terminated struct {
+	r    io.Reader
+	last byte
+	done bool
+}
+
+func (t *terminated) Read(p []byte) (int, error) {
+	if t.done {
+		return 0, io.EOF
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.last = p[n-1]
+	}
+	if err != io.EOF {
+		return n, err
+	}
+	t.done = true
+	if t.last == 0 || t.last == '\n' {
+		return n, io.EOF
+	}
+	if n < len(p) {
+		p[n] = '\n'
+		return n + 1, io.EOF
+	}
+	// no room for the newline, return it on the next call
+	t.done = false
+	t.r = strings.NewReader("\n")
+	return n, nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenInputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cpe2cve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	gz := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.Bytes()
+	}
+	first := write("first.tsv", []byte("a\tcpe:/a:vendor:product:1\nb\tcpe:/a:vendor:product:2")) // no trailing newline
+	second := write("second.tsv", []byte("c\tcpe:/a:vendor:product:3\n"))
+	empty := write("empty.tsv", nil)
+	zipped := write("zipped.tsv.gz", gz("d\tcpe:/a:vendor:product:4\n"))
+
+	cases := []struct {
+		name   string
+		paths  []string
+		stdin  []byte
+		expect string
+	}{
+		{
+			name:   "single file",
+			paths:  []string{second},
+			expect: "c\tcpe:/a:vendor:product:3\n",
+		},
+		{
+			name:   "multiple files",
+			paths:  []string{first, empty, second},
+			expect: "a\tcpe:/a:vendor:product:1\nb\tcpe:/a:vendor:product:2\nc\tcpe:/a:vendor:product:3\n",
+		},
+		{
+			name:   "gzipped file",
+			paths:  []string{second, zipped},
+			expect: "c\tcpe:/a:vendor:product:3\nd\tcpe:/a:vendor:product:4\n",
+		},
+		{
+			name:   "stdin",
+			stdin:  []byte("e\tcpe:/a:vendor:product:5"),
+			expect: "e\tcpe:/a:vendor:product:5",
+		},
+		{
+			name:   "gzipped stdin",
+			stdin:  gz("f\tcpe:/a:vendor:product:6\n"),
+			expect: "f\tcpe:/a:vendor:product:6\n",
+		},
+		{
+			name:  "empty stdin",
+			stdin: nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in, err := openInputs(c.paths, bytes.NewReader(c.stdin))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+			data, err := ioutil.ReadAll(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != c.expect {
+				t.Fatalf("got %q, expected %q", data, c.expect)
+			}
+		})
+	}
+
+	if _, err := openInputs([]string{second, filepath.Join(dir, "missing.tsv")}, nil); err == nil {
+		t.Fatal("expected an error for missing file")
+	}
+}
+
+func TestTerminatedSmallBuffer(t *testing.T) {
+	r := &terminated{r: bytes.NewReader([]byte("ab"))}
+	var got []byte
+	p := make([]byte, 1)
+	for {
+		n, err := r.Read(p)
+		got = append(got, p[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	if string(got) != "ab\n" {
+		t.Fatalf("got %q, expected %q", got, "ab\n")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// lineCounts counts the input lines processed in -skip_invalid mode and the ones skipped as invalid
+type lineCounts struct {
+	total   uint64
+	invalid uint64
+}
+
+// allInvalid returns true if there were some input lines and all of them were invalid
+func (c *lineCounts) allInvalid() bool {
+	total := atomic.LoadUint64(&c.total)
+	return total != 0 && atomic.LoadUint64(&c.invalid) == total
+}
+
+// checkRecord returns an error if the input record lacks the CPE names field or any of the CPE names can't be parsed
+func (cfg config) checkRecord(rec []string) error {
+	cpesAt := cfg.CPEsAt - 1
+	if cpesAt >= len(rec) {
+		return fmt.Errorf("not enough fields in input (%d)", len(rec))
+	}
+	for _, uri := range strings.Split(rec[cpesAt], cfg.InRecordSeparator) {
+		if _, err := wfn.Parse(uri); err != nil {
+			return fmt.Errorf("couldn't parse uri %q: %v", uri, err)
+		}
+	}
+	return nil
+}
+
+// invalidJob returns the reason to skip the job in -skip_invalid mode, nil if it's valid or the mode is off;
+// the jobs are counted in cfg.lines, if set
+func (cfg config) invalidJob(j job) error {
+	if !cfg.SkipInvalid {
+		return nil
+	}
+	err := j.err
+	if err == nil {
+		err = cfg.checkRecord(j.rec)
+	}
+	if cfg.lines != nil {
+		atomic.AddUint64(&cfg.lines.total, 1)
+		if err != nil {
+			atomic.AddUint64(&cfg.lines.invalid, 1)
+		}
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputSkipInvalid(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrTop))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cases := []struct {
+		in          string
+		skipInvalid bool
+		expect      []string
+		allInvalid  bool
+	}{
+		{
+			// the unparseable CPE name is dropped, the rest of its line is matched
+			in:     "cpe:/a:foo:bar:1.0\nbogus\ncpe:/a:foo:baz:1.0,bogus",
+			expect: []string{"CVE-2019-0009", "CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012", "CVE-2019-0013", "CVE-2019-0014"},
+		},
+		{
+			in:          "cpe:/a:foo:bar:1.0\nbogus\ncpe:/a:foo:baz:1.0,bogus",
+			skipInvalid: true,
+			expect:      []string{"CVE-2019-0009", "CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012"},
+		},
+		{
+			in:          "bogus\ncpe:/a:foo:baz:1.0,bogus",
+			skipInvalid: true,
+			allInvalid:  true,
+		},
+		{
+			in:          "",
+			skipInvalid: true,
+		},
+	}
+	for _, c := range cases {
+		cfg := config{
+			NumProcessors:      2,
+			CPEsAt:             1,
+			CVEsAt:             2,
+			SkipInvalid:        c.skipInvalid,
+			InFieldSeparator:   ";",
+			OutFieldSeparator:  ";",
+			InRecordSeparator:  ",",
+			OutRecordSeparator: ",",
+			lines:              &lineCounts{},
+		}
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(c.in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		var got []string
+		for _, line := range strings.Fields(w.String()) {
+			got = append(got, strings.Split(line, ";")[1])
+		}
+		if strings.Join(got, " ") != strings.Join(c.expect, " ") {
+			t.Errorf("%q, -skip_invalid=%t: got %v, expected %v", c.in, c.skipInvalid, got, c.expect)
+		}
+		if cfg.lines.allInvalid() != c.allInvalid {
+			t.Errorf("%q, -skip_invalid=%t: all lines invalid %t, expected %t", c.in, c.skipInvalid, !c.allInvalid, c.allInvalid)
+		}
+	}
+}
+
+func TestCheckRecord(t *testing.T) {
+	cfg := config{CPEsAt: 2, InRecordSeparator: ","}
+	cases := []struct {
+		rec []string
+		ok  bool
+	}{
+		{[]string{"a", "cpe:/a:foo:bar:1.0"}, true},
+		{[]string{"a", "cpe:/a:foo:bar:1.0,cpe:2.3:o:linux:linux_kernel:4.0:*:*:*:*:*:*:*"}, true},
+		{[]string{"a", "cpe:/a:foo:bar:1.0,bogus"}, false},
+		{[]string{"cpe:/a:foo:bar:1.0"}, false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if err := cfg.checkRecord(c.rec); (err == nil) != c.ok {
+			t.Errorf("%q: got error %v, expected valid %t", c.rec, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// recordWriter writes output records; it's implemented by *csv.Writer
+type recordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// nulWriter writes records terminated by NUL character, with fields separated by comma.
+// Fields aren't quoted: the comma and NUL characters in them are replaced with spaces instead,
+// so the output stays unambiguous while newlines can be a part of the fields.
+type nulWriter struct {
+	w        *bufio.Writer
+	comma    string
+	sanitize *strings.Replacer
+	err      error
+}
+
+func newNulWriter(w io.Writer, comma string) *nulWriter {
+	return &nulWriter{
+		w:        bufio.NewWriter(w),
+		comma:    comma,
+		sanitize: strings.NewReplacer(comma, " ", "\x00", " "),
+	}
+}
+
+// Write writes a single record; it's buffered until Flush is called
+func (w *nulWriter) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	for i, field := range record {
+		if i != 0 {
+			w.w.WriteString(w.comma)
+		}
+		w.w.WriteString(w.sanitize.Replace(field))
+	}
+	_, w.err = w.w.Write([]byte{0})
+	return w.err
+}
+
+// Flush writes any buffered records to the underlying writer
+func (w *nulWriter) Flush() {
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+}
+
+// Error returns the first error occurred during Write or Flush
+func (w *nulWriter) Error() error {
+	return w.err
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputSeparators(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr3))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	in := "\"multi\nline\tname\"\tcpe:/a:foo:bar:1.0\n"
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             2,
+		CVEsAt:             3,
+		InFieldSeparator:   "\t",
+		OutFieldSeparator:  "\t",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	run := func(cfg config) string {
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		return w.String()
+	}
+
+	// fields with the delimiter or newlines are quoted
+	expect := "\"multi\nline\tname\"\tcpe:/a:foo:bar:1.0\tCVE-2019-0001\n" +
+		"\"multi\nline\tname\"\tcpe:/a:foo:bar:1.0\tCVE-2019-0002\n" +
+		"\"multi\nline\tname\"\tcpe:/a:foo:bar:1.0\tCVE-2019-0003\n"
+	if got := run(cfg); got != expect {
+		t.Errorf("tab delimited: got %q, expected %q", got, expect)
+	}
+
+	// fields aren't quoted, but the delimiter is replaced
+	cfg.NulTerminated = true
+	expect = "multi\nline name\tcpe:/a:foo:bar:1.0\tCVE-2019-0001\x00" +
+		"multi\nline name\tcpe:/a:foo:bar:1.0\tCVE-2019-0002\x00" +
+		"multi\nline name\tcpe:/a:foo:bar:1.0\tCVE-2019-0003\x00"
+	if got := run(cfg); got != expect {
+		t.Errorf("NUL terminated: got %q, expected %q", got, expect)
+	}
+
+	cfg.Count = true
+	if got, expect := run(cfg), "3\x00"; got != expect {
+		t.Errorf("NUL terminated count: got %q, expected %q", got, expect)
+	}
+}
+
+func TestValidateSeparators(t *testing.T) {
+	cfg := config{
+		Feeds:             map[string][]string{"": {"feed.json"}},
+		NumProcessors:     1,
+		CPEsAt:            1,
+		CVEsAt:            2,
+		InFieldSeparator:  `\t`,
+		OutFieldSeparator: `\t`,
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.InFieldSeparator != "\t" || cfg.OutFieldSeparator != "\t" {
+		t.Errorf("separators weren't unescaped: %q, %q", cfg.InFieldSeparator, cfg.OutFieldSeparator)
+	}
+	cfg.NulTerminated = true
+	if err := cfg.validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, c := range []config{{CSV: true}, {JSON: true}} {
+		c.Feeds, c.NumProcessors, c.CPEsAt, c.CVEsAt = cfg.Feeds, 1, 1, 2
+		c.InFieldSeparator, c.OutFieldSeparator, c.CSVComma = "\t", "\t", ","
+		c.NulTerminated = true
+		if err := c.validate(); err == nil {
+			t.Errorf("-0 with -csv=%t -json=%t: expected an error", c.CSV, c.JSON)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// suppression is a rule that drops matches of CPEs matching a pattern against a specific CVE
+type suppression struct {
+	cpe  *wfn.Attributes
+	cve  string
+	line int
+}
+
+// suppressions is a list of known false positive (CPE, CVE) matches
+type suppressions []suppression
+
+// readSuppressions reads suppression rules from a file, see parseSuppressions for its format
+func readSuppressions(path string) (suppressions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rules, err := parseSuppressions(f)
+	if err != nil {
+		return nil, fmt.Errorf("can't read suppressions from %q: %v", path, err)
+	}
+	return rules, nil
+}
+
+// parseSuppressions parses rules, one per line: a CPE name followed by a CVE ID, separated by whitespace.
+// CPE name can contain wildcards, e.g. cpe:2.3:a:vendor:product:1.*:*:*:*:*:*:*:*.
+// Empty lines and lines starting with # are ignored.
+func parseSuppressions(r io.Reader) (suppressions, error) {
+	var rules suppressions
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected CPE name and CVE ID, got %q", line, s)
+		}
+		attrs, err := wfn.Parse(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		rules = append(rules, suppression{cpe: attrs, cve: fields[1], line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// filter returns the CPEs matching the CVE which aren't suppressed by any of the rules;
+// if log is true, suppressed matches are logged to stderr
+func (rules suppressions) filter(cve string, cpes []*wfn.Attributes, log bool) []*wfn.Attributes {
+	if len(rules) == 0 {
+		return cpes
+	}
+	kept := make([]*wfn.Attributes, 0, len(cpes))
+	for _, attrs := range cpes {
+		if rule := rules.find(cve, attrs); rule != nil {
+			if log {
+				flog.Infof("suppressed %s: %s (rule at line %d)", cve, attrs.BindToURI(), rule.line)
+			}
+			continue
+		}
+		kept = append(kept, attrs)
+	}
+	return kept
+}
+
+func (rules suppressions) find(cve string, attrs *wfn.Attributes) *suppression {
+	for i, rule := range rules {
+		if strings.EqualFold(rule.cve, cve) && rule.cpe.WildcardMatch(attrs) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
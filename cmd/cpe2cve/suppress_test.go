@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputSuppress(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr3))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	rules, err := parseSuppressions(strings.NewReader(`
+# bad NVD data, reported upstream
+cpe:2.3:a:foo:bar:1.*:*:*:*:*:*:*:*  CVE-2019-0002
+cpe:/a:foo:bar:1.5.*                 cve-2019-0003
+cpe:/a:foo:baz                       CVE-2019-0001
+`))
+	if err != nil {
+		t.Fatalf("couldn't parse suppressions: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      2,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+		suppressions:       rules,
+	}
+	in := "cpe:/a:foo:bar:1.0\ncpe:/a:foo:bar:1.5.2\ncpe:/a:foo:bar:0.9"
+	expect := []string{
+		"cpe:/a:foo:bar:1.0;CVE-2019-0001",
+		"cpe:/a:foo:bar:1.0;CVE-2019-0003",
+		"cpe:/a:foo:bar:1.5.2;CVE-2019-0001",
+		"cpe:/a:foo:bar:0.9;CVE-2019-0001",
+		"cpe:/a:foo:bar:0.9;CVE-2019-0002",
+		"cpe:/a:foo:bar:0.9;CVE-2019-0003",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	if got := strings.Split(strings.TrimSpace(w.String()), "\n"); strings.Join(got, "\n") != strings.Join(expect, "\n") {
+		t.Fatalf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expect, "\n"))
+	}
+}
+
+func TestParseSuppressionsErrors(t *testing.T) {
+	cases := []string{
+		"cpe:/a:foo:bar",
+		"cpe:/a:foo:bar CVE-2019-0001 extra",
+		"foo:bar CVE-2019-0001",
+	}
+	for _, c := range cases {
+		if _, err := parseSuppressions(strings.NewReader(c)); err == nil {
+			t.Errorf("%q: expected an error", c)
+		}
+	}
+}
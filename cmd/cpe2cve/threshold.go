@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// severityScores are the lowest CVSS v3 base scores of the severities
+var severityScores = map[string]float64{
+	"LOW":      0.1,
+	"MEDIUM":   4.0,
+	"HIGH":     7.0,
+	"CRITICAL": 9.0,
+}
+
+// minScore returns the lowest CVSS score of the results to output, as set with -min_cvss or -min_severity;
+// 0 means all the results are output
+func (cfg *config) minScore() float64 {
+	if cfg.MinSeverity != "" {
+		return severityScores[strings.ToUpper(cfg.MinSeverity)]
+	}
+	return cfg.MinCVSS
+}
+
+// belowThreshold returns true if the result should be dropped, because its CVSS score (see result.cvss) is lower
+// than the minimal one, or because it has no score and -drop_unscored is set
+func (cfg *config) belowThreshold(res *result) bool {
+	min := cfg.minScore()
+	if min == 0 {
+		return false
+	}
+	score := res.cvss()
+	if score == 0 {
+		return cfg.DropUnscored
+	}
+	return score < min
+}
+
+// validateThreshold returns an error if -min_cvss or -min_severity values are invalid
+func validateThreshold(cfg *config) error {
+	if cfg.MinCVSS < 0 || cfg.MinCVSS > 10 {
+		return fmt.Errorf("-min_cvss value is invalid %v: must be between 0 and 10", cfg.MinCVSS)
+	}
+	if cfg.MinSeverity == "" {
+		return nil
+	}
+	if cfg.MinCVSS != 0 {
+		return fmt.Errorf("-min_cvss and -min_severity are mutually exclusive")
+	}
+	if _, ok := severityScores[strings.ToUpper(cfg.MinSeverity)]; !ok {
+		return fmt.Errorf("-min_severity value is invalid %q: must be LOW, MEDIUM, HIGH or CRITICAL", cfg.MinSeverity)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputThreshold(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrTop))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	// CVE-2019-0009 is scored 4.3 (v2), CVE-2019-0010 7.5 (v3, 5.0 in v2), CVE-2019-0011 9.8 (v3),
+	// CVE-2019-0012 9.8 (v2); the ones of foo:baz have no scores
+	in := "cpe:/a:foo:bar:1.0\ncpe:/a:foo:baz:1.0"
+	cases := []struct {
+		minCVSS      float64
+		minSeverity  string
+		dropUnscored bool
+		expect       []string
+	}{
+		{
+			expect: []string{"CVE-2019-0009", "CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012", "CVE-2019-0013", "CVE-2019-0014"},
+		},
+		{
+			minCVSS: 7.5,
+			expect:  []string{"CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012", "CVE-2019-0013", "CVE-2019-0014"},
+		},
+		{
+			minCVSS:      7.6,
+			dropUnscored: true,
+			expect:       []string{"CVE-2019-0011", "CVE-2019-0012"},
+		},
+		{
+			minSeverity: "medium",
+			expect:      []string{"CVE-2019-0009", "CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012", "CVE-2019-0013", "CVE-2019-0014"},
+		},
+		{
+			minSeverity:  "HIGH",
+			dropUnscored: true,
+			expect:       []string{"CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012"},
+		},
+		{
+			minSeverity: "CRITICAL",
+			expect:      []string{"CVE-2019-0011", "CVE-2019-0012", "CVE-2019-0013", "CVE-2019-0014"},
+		},
+		{
+			// without a threshold, unscored results are kept anyway
+			dropUnscored: true,
+			expect:       []string{"CVE-2019-0009", "CVE-2019-0010", "CVE-2019-0011", "CVE-2019-0012", "CVE-2019-0013", "CVE-2019-0014"},
+		},
+	}
+	for _, c := range cases {
+		cfg := config{
+			NumProcessors:      1,
+			CPEsAt:             1,
+			CVEsAt:             2,
+			MinCVSS:            c.minCVSS,
+			MinSeverity:        c.minSeverity,
+			DropUnscored:       c.dropUnscored,
+			InFieldSeparator:   ";",
+			OutFieldSeparator:  ";",
+			InRecordSeparator:  ",",
+			OutRecordSeparator: ",",
+		}
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		var got []string
+		for _, line := range strings.Fields(w.String()) {
+			got = append(got, strings.Split(line, ";")[1])
+		}
+		if strings.Join(got, " ") != strings.Join(c.expect, " ") {
+			t.Errorf("-min_cvss=%v -min_severity=%q -drop_unscored=%t: got %v, expected %v",
+				c.minCVSS, c.minSeverity, c.dropUnscored, got, c.expect)
+		}
+	}
+}
+
+func TestValidateThreshold(t *testing.T) {
+	cases := []struct {
+		minCVSS     float64
+		minSeverity string
+		ok          bool
+	}{
+		{0, "", true},
+		{7, "", true},
+		{10, "", true},
+		{0, "high", true},
+		{-1, "", false},
+		{10.1, "", false},
+		{0, "NONE", false},
+		{0, "severe", false},
+		{7, "HIGH", false},
+	}
+	for _, c := range cases {
+		cfg := config{MinCVSS: c.minCVSS, MinSeverity: c.minSeverity}
+		if err := validateThreshold(&cfg); (err == nil) != c.ok {
+			t.Errorf("-min_cvss=%v -min_severity=%q: unexpected error %v", c.minCVSS, c.minSeverity, err)
+		}
+	}
+}
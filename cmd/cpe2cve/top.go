@@ -0,0 +1,48 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// topResults returns, for every matched CPE, only the result with the highest CVSS score (see result.cvss);
+// ties, including results without scores, are broken in favour of the greatest CVE ID.
+// A result is kept once even if it's the top one for several CPEs; the order of results is preserved.
+func topResults(results []*result) []*result {
+	top := map[string]*result{} // matched CPE -> top result
+	for _, res := range results {
+		for _, cpe := range res.matches {
+			if best, ok := top[cpe]; !ok || isWorse(best, res) {
+				top[cpe] = res
+			}
+		}
+	}
+	keep := make(map[*result]bool, len(top))
+	for _, res := range top {
+		keep[res] = true
+	}
+	filtered := results[:0]
+	for _, res := range results {
+		if keep[res] {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered
+}
+
+// isWorse returns true if r1 has a lower CVSS score than r2 or, if the scores are equal, a lesser CVE ID
+func isWorse(r1, r2 *result) bool {
+	if s1, s2 := r1.cvss(), r2.cvss(); s1 != s2 {
+		return s1 < s2
+	}
+	return r1.finding.ID < r2.finding.ID
+}
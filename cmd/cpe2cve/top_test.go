@@ -0,0 +1,60 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputTop(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStrTop))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		CVSSAt:             3,
+		Top:                true,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+	}
+	// CVE-2019-0011 and CVE-2019-0012 are tied at 9.8 (v3 and v2 score), CVE-2019-0013 and CVE-2019-0014 have no scores
+	in := "cpe:/a:foo:bar:1.0\ncpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0\ncpe:/a:foo:baz:1.0\ncpe:/a:foo:bar:3.0"
+	expect := []string{
+		"cpe:/a:foo:bar:1.0;CVE-2019-0012;9.8",
+		"cpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0;CVE-2019-0012;9.8",
+		"cpe:/a:foo:bar:1.0,cpe:/a:foo:baz:1.0;CVE-2019-0014;0.0",
+		"cpe:/a:foo:baz:1.0;CVE-2019-0014;0.0",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	if got := strings.Split(strings.TrimSpace(w.String()), "\n"); strings.Join(got, "\n") != strings.Join(expect, "\n") {
+		t.Fatalf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expect, "\n"))
+	}
+}
+
+var testDictJSONStrTop = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0010"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":7.5,"version":"3.0"}},"baseMetricV2":{"cvssV2":{"baseScore":5.0,"version":"2.0"}}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0011"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":9.8,"version":"3.0"}},"baseMetricV2":{"cvssV2":{"baseScore":5.0,"version":"2.0"}}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0012"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV2":{"cvssV2":{"baseScore":9.8,"version":"2.0"}}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0009"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV2":{"cvssV2":{"baseScore":4.3,"version":"2.0"}}},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0013"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:baz:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"},{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0014"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:baz:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{},"lastModifiedDate":"2019-01-01T00:00Z","publishedDate":"2019-01-01T00:00Z"}]}`
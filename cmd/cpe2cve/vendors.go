@@ -0,0 +1,140 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// vendorSummary rolls up the results per vendor of the matched CPEs in -vendor_summary mode instead of writing them out
+type vendorSummary struct {
+	vendors map[string]*vendorStats
+}
+
+// vendorStats are the summary of the results of a vendor
+type vendorStats struct {
+	Vendor   string  `json:"vendor"`
+	CVEs     int     `json:"cves"`
+	MaxCVSS  float64 `json:"max_cvss"`
+	Critical int     `json:"critical"`
+
+	seen map[string]bool // CVE IDs already counted
+}
+
+// vendorSortKeys tell how the vendors are ordered, by the value of -vendor_summary_sort:
+// the vendor names alphabetically, the other columns in the decreasing order
+var vendorSortKeys = map[string]func(s1, s2 *vendorStats) bool{
+	"vendor":   func(s1, s2 *vendorStats) bool { return s1.Vendor < s2.Vendor },
+	"cves":     func(s1, s2 *vendorStats) bool { return s1.CVEs > s2.CVEs },
+	"max_cvss": func(s1, s2 *vendorStats) bool { return s1.MaxCVSS > s2.MaxCVSS },
+	"critical": func(s1, s2 *vendorStats) bool { return s1.Critical > s2.Critical },
+}
+
+func newVendorSummary() *vendorSummary {
+	return &vendorSummary{vendors: map[string]*vendorStats{}}
+}
+
+// add counts the CVE of the result once for every vendor of the matched CPEs, unless it was already counted for it;
+// the CVE is critical if its CVSS score (see result.cvss) is rated CRITICAL, as with -min_severity
+func (s *vendorSummary) add(r *result) {
+	for _, vendor := range r.attr("vendor") {
+		if vendor == "" {
+			continue
+		}
+		vs := s.vendors[vendor]
+		if vs == nil {
+			vs = &vendorStats{Vendor: vendor, seen: map[string]bool{}}
+			s.vendors[vendor] = vs
+		}
+		if vs.seen[r.finding.ID] {
+			continue
+		}
+		vs.seen[r.finding.ID] = true
+		vs.CVEs++
+		score := r.cvss()
+		if score > vs.MaxCVSS {
+			vs.MaxCVSS = score
+		}
+		if score >= severityScores["CRITICAL"] {
+			vs.Critical++
+		}
+	}
+}
+
+// sorted returns the vendors ordered by the sortBy column, then by vendor name
+func (s *vendorSummary) sorted(sortBy string) []*vendorStats {
+	less := vendorSortKeys[sortBy]
+	if less == nil {
+		less = vendorSortKeys["cves"]
+	}
+	vendors := make([]*vendorStats, 0, len(s.vendors))
+	for _, vs := range s.vendors {
+		vendors = append(vendors, vs)
+	}
+	sort.Slice(vendors, func(i, j int) bool {
+		if less(vendors[i], vendors[j]) {
+			return true
+		}
+		if less(vendors[j], vendors[i]) {
+			return false
+		}
+		return vendors[i].Vendor < vendors[j].Vendor
+	})
+	return vendors
+}
+
+// write writes the summary, one vendor per line (or JSON object): vendor, number of CVEs, max CVSS score and
+// number of critical CVEs; in CSV mode, the first line is the header with the names of the columns
+func (s *vendorSummary) write(w recordWriter, enc *json.Encoder, cfg config) error {
+	vendors := s.sorted(cfg.VendorSummarySort)
+	if cfg.JSON {
+		for _, vs := range vendors {
+			if err := enc.Encode(vs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if cfg.CSV {
+		if err := w.Write([]string{"vendor", "cves", "max_cvss", "critical"}); err != nil {
+			return err
+		}
+	}
+	for _, vs := range vendors {
+		rec := []string{vs.Vendor, strconv.Itoa(vs.CVEs), fmt.Sprintf("%.1f", vs.MaxCVSS), strconv.Itoa(vs.Critical)}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// validateVendorSummary returns an error if the vendors can't be ordered by -vendor_summary_sort
+func validateVendorSummary(cfg *config) error {
+	if cfg.VendorSummarySort == "" {
+		return nil
+	}
+	if !cfg.VendorSummary {
+		return fmt.Errorf("-vendor_summary_sort requires -vendor_summary")
+	}
+	if _, ok := vendorSortKeys[cfg.VendorSummarySort]; !ok {
+		return fmt.Errorf("-vendor_summary_sort value is invalid %q: must be vendor, cves, max_cvss or critical", cfg.VendorSummarySort)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputVendorSummary(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		var vulns []cvefeed.Vuln
+		for _, feed := range []string{testDictJSONStr3, testDictJSONStrVendors} {
+			vs, err := cvefeed.ParseJSON(bytes.NewBufferString(feed))
+			if err != nil {
+				return nil, err
+			}
+			vulns = append(vulns, vs...)
+		}
+		return vulns, nil
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	in := "cpe:/a:foo:bar:1.0\ncpe:/a:acme:widget:1.0,cpe:/a:foo:bar:1.0\ncpe:/a:acme:widget:1.0"
+	cfg := config{
+		NumProcessors:      2,
+		CPEsAt:             1,
+		InFieldSeparator:   ";",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+		VendorSummary:      true,
+	}
+	run := func(cfg config) string {
+		var w bytes.Buffer
+		done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		return strings.TrimSpace(w.String())
+	}
+
+	// foo: CVE-2019-0001..3 and CVE-2020-0002, matched on two lines but counted once
+	// acme: CVE-2020-0001..3, CVE-2020-0003 is critical by its CVSS v2 score
+	cases := map[string]string{
+		"":         "foo;4;9.8;1\nacme;3;10.0;2",
+		"cves":     "foo;4;9.8;1\nacme;3;10.0;2",
+		"vendor":   "acme;3;10.0;2\nfoo;4;9.8;1",
+		"max_cvss": "acme;3;10.0;2\nfoo;4;9.8;1",
+		"critical": "acme;3;10.0;2\nfoo;4;9.8;1",
+	}
+	for sortBy, expect := range cases {
+		cfg.VendorSummarySort = sortBy
+		if got := run(cfg); got != expect {
+			t.Errorf("vendor summary sorted by %q: got:\n%s\nexpected:\n%s", sortBy, got, expect)
+		}
+	}
+
+	cfg.VendorSummarySort = "vendor"
+	cfg.CSV, cfg.CSVComma = true, ","
+	if got, expect := run(cfg), "vendor,cves,max_cvss,critical\r\nacme,3,10.0,2\r\nfoo,4,9.8,1"; got != expect {
+		t.Errorf("CSV vendor summary: got %q, expected %q", got, expect)
+	}
+	cfg.CSV, cfg.JSON = false, true
+	expect := `{"vendor":"acme","cves":3,"max_cvss":10,"critical":2}` + "\n" + `{"vendor":"foo","cves":4,"max_cvss":9.8,"critical":1}`
+	if got := run(cfg); got != expect {
+		t.Errorf("JSON vendor summary: got:\n%s\nexpected:\n%s", got, expect)
+	}
+}
+
+func TestValidateVendorSummary(t *testing.T) {
+	cfg := config{
+		Feeds:         map[string][]string{"": {"feed.json"}},
+		NumProcessors: 1,
+		CPEsAt:        1,
+		VendorSummary: true,
+	}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("-vendor_summary shouldn't require -cve: %v", err)
+	}
+	cfg.VendorSummarySort = "product"
+	if err := cfg.validate(); err == nil {
+		t.Error("unknown -vendor_summary_sort value should be rejected")
+	}
+	cfg.VendorSummarySort = "critical"
+	cfg.Count = true
+	if err := cfg.validate(); err == nil {
+		t.Error("-count with -vendor_summary should be rejected")
+	}
+	cfg.Count, cfg.VendorSummary = false, false
+	if err := cfg.validate(); err == nil {
+		t.Error("-vendor_summary_sort without -vendor_summary should be rejected")
+	}
+}
+
+var testDictJSONStrVendors = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[` +
+	`{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0001"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":9.1,"baseSeverity":"CRITICAL","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N","version":"3.0"}}},"lastModifiedDate":"2020-01-01T00:00Z","publishedDate":"2020-01-01T00:00Z"},` +
+	`{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0002"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*","vulnerable":true},{"cpe23Uri":"cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":7.5,"baseSeverity":"HIGH","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N","version":"3.0"}}},"lastModifiedDate":"2020-01-01T00:00Z","publishedDate":"2020-01-01T00:00Z"},` +
+	`{"cve":{"CVE_data_meta":{"ID":"CVE-2020-0003"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV2":{"cvssV2":{"baseScore":10,"vectorString":"(AV:N/AC:L/Au:N/C:C/I:C/A:C)","version":"2.0"},"severity":"HIGH"}},"lastModifiedDate":"2020-01-01T00:00Z","publishedDate":"2020-01-01T00:00Z"}` +
+	`]}`
@@ -37,6 +37,7 @@ func main() {
 	erase := flag.String("e", "", "comma separated list of columns to erase, optional")
 	unmap := flag.Bool("x", false, "erase all columns mapped from -cpe_{field}, optional")
 	lower := flag.Bool("lower", false, "force cpe output to be lower case, optional")
+	header := flag.Bool("header", false, "first line is a header, -cpe_{field} may refer to columns by name, optional")
 
 	flag.Parse()
 
@@ -61,16 +62,14 @@ func main() {
 		}
 	}
 
-	if *unmap {
-		eraseCols.Merge(NewIntSet(acm.Columns()...))
-	}
-
 	p := &Processor{
-		InputComma:        rune((*idelim)[0]),
-		OutputComma:       rune((*odelim)[0]),
-		CPEToLower:        *lower,
-		CPEOutputColumn:   *idx,
-		EraseInputColumns: eraseCols,
+		InputComma:         rune((*idelim)[0]),
+		OutputComma:        rune((*odelim)[0]),
+		CPEToLower:         *lower,
+		CPEOutputColumn:    *idx,
+		EraseInputColumns:  eraseCols,
+		EraseMappedColumns: *unmap,
+		Header:             *header,
 	}
 
 	err = p.Process(acm, os.Stdin, os.Stdout)
@@ -82,11 +81,13 @@ func main() {
 
 // Processor is a CSV processor.
 type Processor struct {
-	InputComma        rune   // input comma character
-	OutputComma       rune   // output comma character
-	CPEToLower        bool   // whether the output cpe should be forced lower case
-	CPEOutputColumn   int    // index to add cpe column in the output, after erases
-	EraseInputColumns IntSet // input columns to erase before output
+	InputComma         rune   // input comma character
+	OutputComma        rune   // output comma character
+	CPEToLower         bool   // whether the output cpe should be forced lower case
+	CPEOutputColumn    int    // index to add cpe column in the output, after erases
+	EraseInputColumns  IntSet // input columns to erase before output
+	EraseMappedColumns bool   // whether to also erase the columns mapped to cpe attributes
+	Header             bool   // whether the first line is a header with column names
 }
 
 // Process reads CSV from r and writes CSV + CPE to w.
@@ -101,6 +102,39 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 
 	line := 0
 
+	var header []string
+	if p.Header {
+		line++
+
+		cols, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error parsing header: %v", err)
+		}
+
+		if err = acm.ResolveNames(cols); err != nil {
+			return fmt.Errorf("error parsing header: %v", err)
+		}
+
+		header = cols
+	} else if names := acm.Names(); len(names) > 0 {
+		return fmt.Errorf("columns referenced by name require a header: %s", strings.Join(names, ", "))
+	}
+
+	eraseCols := p.EraseInputColumns
+	if p.EraseMappedColumns {
+		eraseCols = NewIntSet(acm.Columns()...)
+		eraseCols.Merge(p.EraseInputColumns)
+	}
+
+	if header != nil {
+		header = RemoveColumns(header, eraseCols)
+		header = InsertColumn(header, "cpe", p.CPEOutputColumn)
+		writer.Write(header)
+	}
+
 	for {
 		line++
 
@@ -117,7 +151,7 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 			return fmt.Errorf("error parsing columns in line %d: %v", line, err)
 		}
 
-		cols = RemoveColumns(cols, p.EraseInputColumns)
+		cols = RemoveColumns(cols, eraseCols)
 		cols = InsertColumn(cols, cpe, p.CPEOutputColumn)
 
 		writer.Write(cols)
@@ -127,6 +161,7 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 }
 
 // AttributeColumnMap maps CSV columns to WFN Attribute fields.
+// Columns are 1-based indices, or names resolved from a header by ResolveNames.
 type AttributeColumnMap struct {
 	Part      int
 	Vendor    int
@@ -139,27 +174,109 @@ type AttributeColumnMap struct {
 	TargetHW  int
 	Other     int
 	Language  int
+
+	// NA sets the unmapped attributes to NA instead of ANY.
+	NA bool
+
+	names map[*int]string // columns referenced by name, pending ResolveNames
 }
 
 // AddFlags adds configuration flags to the given FlagSet.
 func (acm *AttributeColumnMap) AddFlags(fs *flag.FlagSet) {
-	fs.IntVar(&acm.Part, "cpe_part", 0, "part cpe column index")
-	fs.IntVar(&acm.Vendor, "cpe_vendor", 0, "vendor cpe column index")
-	fs.IntVar(&acm.Product, "cpe_product", 0, "product cpe column index")
-	fs.IntVar(&acm.Version, "cpe_version", 0, "version cpe column index")
-	fs.IntVar(&acm.Update, "cpe_update", 0, "update cpe column index")
-	fs.IntVar(&acm.Edition, "cpe_edition", 0, "edition cpe column index")
-	fs.IntVar(&acm.SWEdition, "cpe_swedition", 0, "swedition cpe column index")
-	fs.IntVar(&acm.TargetSW, "cpe_targetsw", 0, "targetsw cpe column index")
-	fs.IntVar(&acm.TargetHW, "cpe_targethw", 0, "targethw cpe column index")
-	fs.IntVar(&acm.Other, "cpe_other", 0, "other cpe column index")
-	fs.IntVar(&acm.Language, "cpe_language", 0, "language cpe column index")
+	fs.Var(acm.column(&acm.Part), "cpe_part", "part cpe column index or name")
+	fs.Var(acm.column(&acm.Vendor), "cpe_vendor", "vendor cpe column index or name")
+	fs.Var(acm.column(&acm.Product), "cpe_product", "product cpe column index or name")
+	fs.Var(acm.column(&acm.Version), "cpe_version", "version cpe column index or name")
+	fs.Var(acm.column(&acm.Update), "cpe_update", "update cpe column index or name")
+	fs.Var(acm.column(&acm.Edition), "cpe_edition", "edition cpe column index or name")
+	fs.Var(acm.column(&acm.SWEdition), "cpe_swedition", "swedition cpe column index or name")
+	fs.Var(acm.column(&acm.TargetSW), "cpe_targetsw", "targetsw cpe column index or name")
+	fs.Var(acm.column(&acm.TargetHW), "cpe_targethw", "targethw cpe column index or name")
+	fs.Var(acm.column(&acm.Other), "cpe_other", "other cpe column index or name")
+	fs.Var(acm.column(&acm.Language), "cpe_language", "language cpe column index or name")
+	fs.BoolVar(&acm.NA, "cpe_na", false, "set unmapped cpe attributes to NA instead of ANY, optional")
+}
+
+func (acm *AttributeColumnMap) column(idx *int) *columnValue {
+	return &columnValue{acm: acm, idx: idx}
+}
+
+// Names returns the sorted list of columns referenced by name.
+func (acm *AttributeColumnMap) Names() []string {
+	names := make([]string, 0, len(acm.names))
+	for _, name := range acm.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveNames sets the index of columns referenced by name from the
+// given header. Names are matched case insensitively.
+func (acm *AttributeColumnMap) ResolveNames(header []string) error {
+	for idx, name := range acm.names {
+		found := false
+		for i, col := range header {
+			if strings.EqualFold(strings.TrimSpace(col), name) {
+				*idx = i + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("column %q not found in header", name)
+		}
+		delete(acm.names, idx)
+	}
+	return nil
+}
+
+// columnValue implements the flag.Value interface for a column
+// of AttributeColumnMap, given as a 1-based index or a name.
+type columnValue struct {
+	acm *AttributeColumnMap
+	idx *int
+}
+
+// String implements the flag.Value interface.
+func (c *columnValue) String() string {
+	if c.acm == nil || c.idx == nil {
+		return "0"
+	}
+	if name, ok := c.acm.names[c.idx]; ok {
+		return name
+	}
+	return strconv.Itoa(*c.idx)
+}
+
+// Set implements the flag.Value interface.
+func (c *columnValue) Set(v string) error {
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 {
+			return fmt.Errorf("invalid column index %d", n)
+		}
+		*c.idx = n
+		delete(c.acm.names, c.idx)
+		return nil
+	}
+	if v = strings.TrimSpace(v); v == "" {
+		return fmt.Errorf("empty column name")
+	}
+	if c.acm.names == nil {
+		c.acm.names = make(map[*int]string)
+	}
+	c.acm.names[c.idx] = v
+	*c.idx = 0
+	return nil
 }
 
 // CPE returns a CPE by mapping cols to the configured column indices.
 func (acm *AttributeColumnMap) CPE(cols []string, lower bool) (string, error) {
 	var err error
-	attr := wfn.NewAttributesWithNA()
+	attr := wfn.NewAttributesWithAny()
+	if acm.NA {
+		attr = wfn.NewAttributesWithNA()
+	}
 
 	m := map[int]*string{
 		acm.Part:      &attr.Part,
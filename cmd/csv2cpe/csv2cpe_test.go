@@ -82,19 +82,19 @@ func TestProcessor(t *testing.T) {
 		out   string
 	}{
 		{
-			[]string{"-cpe_product=1", "-cpe_version=2"},
+			[]string{"-cpe_na", "-cpe_product=1", "-cpe_version=2"},
 			NewIntSet(1, 2, 3),
 			"Foo\t1.0...\tdelet\ta\nbar\t2.0\tdelet\tb",
 			"a,cpe:/-:-:foo:1.0:-:-:-\nb,cpe:/-:-:bar:2.0:-:-:-\n",
 		},
 		{
-			[]string{"-cpe_part=1", "-cpe_product=2", "-cpe_product=4"},
+			[]string{"-cpe_na", "-cpe_part=1", "-cpe_product=2", "-cpe_product=4"},
 			NewIntSet(1, 2, 3),
 			"a\tb\tc\n",
 			"cpe:/a:-:-:-:-:-:-\n",
 		},
 		{
-			[]string{"-cpe_part=1", "-cpe_product=2", "-cpe_version=3"},
+			[]string{"-cpe_na", "-cpe_part=1", "-cpe_product=2", "-cpe_version=3"},
 			NewIntSet(1, 2, 3),
 			"a\tbash\t4.4\n",
 			"cpe:/a:-:bash:4.4:-:-:-\n",
@@ -137,3 +137,106 @@ func TestProcessor(t *testing.T) {
 	}
 
 }
+
+func TestProcessorHeader(t *testing.T) {
+	cases := []struct {
+		name   string
+		flags  []string
+		header bool
+		unmap  bool
+		in     string
+		out    string
+	}{
+		{
+			name:   "header with reordered columns",
+			flags:  []string{"-cpe_vendor=Vendor", "-cpe_product=product", "-cpe_version= Version "},
+			header: true,
+			in:     "host\tversion\towner\tproduct\tvendor\nfoo\t1.1.1b\tbob\topenssl\topenssl\n",
+			out:    "host,cpe,version,owner,product,vendor\nfoo,cpe:/:openssl:openssl:1.1.1b,1.1.1b,bob,openssl,openssl\n",
+		},
+		{
+			name:   "header with names and indices, erase mapped",
+			flags:  []string{"-cpe_na", "-cpe_part=1", "-cpe_product=name", "-cpe_version=3"},
+			header: true,
+			unmap:  true,
+			in:     "part\tname\tver\thost\na\tbash\t4.4\tfoo\n",
+			out:    "host,cpe\nfoo,cpe:/a:-:bash:4.4:-:-:-\n",
+		},
+		{
+			name:  "headerless positional",
+			flags: []string{"-cpe_version=1", "-cpe_product=3"},
+			in:    "4.4\tfoo\tbash\n",
+			out:   "4.4,cpe:/::bash:4.4,foo,bash\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+			acm := &AttributeColumnMap{}
+			acm.AddFlags(fs)
+
+			err := fs.Parse(c.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var stdin, stdout bytes.Buffer
+
+			p := &Processor{
+				InputComma:         rune('\t'),
+				OutputComma:        rune(','),
+				CPEToLower:         true,
+				CPEOutputColumn:    2,
+				EraseMappedColumns: c.unmap,
+				Header:             c.header,
+			}
+
+			stdin.Write([]byte(c.in))
+
+			err = p.Process(acm, &stdin, &stdout)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out := stdout.String(); out != c.out {
+				t.Fatalf("unexpected output:\nwant: %q\nhave: %q\n", c.out, out)
+			}
+		})
+	}
+}
+
+func TestProcessorHeaderErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		flags  []string
+		header bool
+		in     string
+	}{
+		{"name without header", []string{"-cpe_product=product"}, false, "bash\n"},
+		{"name not in header", []string{"-cpe_product=product"}, true, "name\nbash\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+			acm := &AttributeColumnMap{}
+			acm.AddFlags(fs)
+
+			err := fs.Parse(c.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var stdin, stdout bytes.Buffer
+			stdin.Write([]byte(c.in))
+
+			p := &Processor{InputComma: rune('\t'), OutputComma: rune(','), Header: c.header}
+			if err = p.Process(acm, &stdin, &stdout); err == nil {
+				t.Fatalf("expected an error, got output %q", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// cvediff compares two outputs of cpe2cve, e.g. of nightly scans, and reports the (CPE, CVE) pairs which were added
+// (newly introduced vulnerabilities) and removed (remediated ones) in the second output.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/flog"
+)
+
+type config struct {
+	cpesAt             int
+	cvesAt             int
+	inFieldSeparator   string
+	inRecordSeparator  string
+	outFieldSeparator  string
+	outRecordSeparator string
+	json               bool
+	byCPE              bool
+}
+
+func (cfg *config) addFlags() {
+	flag.IntVar(&cfg.cpesAt, "cpe", 0, "position of the field with the matching CPE names in cpe2cve output (starts at 1), i.e. -matches of cpe2cve")
+	flag.IntVar(&cfg.cvesAt, "cve", 0, "position of the field with the CVE in cpe2cve output (starts at 1)")
+	flag.StringVar(&cfg.inFieldSeparator, "d", "\t", "input columns delimiter")
+	flag.StringVar(&cfg.inRecordSeparator, "d2", ",", "inner input columns delimiter: separates the CPE names in the matches column")
+	flag.StringVar(&cfg.outFieldSeparator, "o", "\t", "output columns delimiter")
+	flag.StringVar(&cfg.outRecordSeparator, "o2", ",", "inner output columns delimiter: separates the CVEs in -by_cpe mode")
+	flag.BoolVar(&cfg.json, "json", false, "inputs are produced by cpe2cve -json; -cpe, -cve and delimiters are ignored")
+	flag.BoolVar(&cfg.byCPE, "by_cpe", false, "output a line per CPE with the lists of added and removed CVEs instead of a line per (CPE, CVE) pair")
+}
+
+func (cfg *config) validate() error {
+	if cfg.json {
+		return nil
+	}
+	if cfg.cpesAt <= 0 || cfg.cvesAt <= 0 {
+		return fmt.Errorf("both -cpe and -cve must be set to positive values")
+	}
+	if cfg.inFieldSeparator == "" {
+		return fmt.Errorf("-d can't be empty")
+	}
+	return nil
+}
+
+// pair is a CVE matched by a CPE
+type pair struct {
+	cpe, cve string
+}
+
+// pairs is a set of (CPE, CVE) pairs
+type pairs map[pair]struct{}
+
+// readPairs reads the set of (CPE, CVE) pairs from cpe2cve output
+func readPairs(in io.Reader, cfg config) (pairs, error) {
+	set := pairs{}
+	if cfg.json {
+		dec := json.NewDecoder(in)
+		for {
+			var res struct {
+				CVE     string   `json:"cve"`
+				Matches []string `json:"matches"`
+			}
+			if err := dec.Decode(&res); err != nil {
+				if err == io.EOF {
+					return set, nil
+				}
+				return nil, err
+			}
+			for _, cpe := range res.Matches {
+				set[pair{cpe, res.CVE}] = struct{}{}
+			}
+		}
+	}
+
+	r := csv.NewReader(in)
+	r.Comma = rune(cfg.inFieldSeparator[0])
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				return set, nil
+			}
+			return nil, err
+		}
+		if len(rec) < cfg.cpesAt || len(rec) < cfg.cvesAt {
+			return nil, fmt.Errorf("record %d: not enough fields (%d)", line, len(rec))
+		}
+		cve := rec[cfg.cvesAt-1]
+		for _, cpe := range strings.Split(rec[cfg.cpesAt-1], cfg.inRecordSeparator) {
+			if cpe != "" {
+				set[pair{cpe, cve}] = struct{}{}
+			}
+		}
+	}
+}
+
+// diff returns the pairs which are in new but not in old (added) and the ones in old and not in new (removed),
+// sorted by CPE and CVE
+func diff(old, new pairs) (added, removed []pair) {
+	for p := range new {
+		if _, ok := old[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	for p := range old {
+		if _, ok := new[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+	sortPairs(added)
+	sortPairs(removed)
+	return added, removed
+}
+
+func sortPairs(ps []pair) {
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].cpe != ps[j].cpe {
+			return ps[i].cpe < ps[j].cpe
+		}
+		return ps[i].cve < ps[j].cve
+	})
+}
+
+// writeDiff writes the added pairs prefixed with + and the removed ones prefixed with -;
+// in -by_cpe mode, a line per CPE with the lists of added and removed CVEs is written instead
+func writeDiff(out io.Writer, added, removed []pair, cfg config) error {
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSeparator[0])
+	if !cfg.byCPE {
+		for _, p := range added {
+			w.Write([]string{"+", p.cpe, p.cve})
+		}
+		for _, p := range removed {
+			w.Write([]string{"-", p.cpe, p.cve})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	type change struct{ added, removed []string }
+	changes := map[string]*change{}
+	get := func(cpe string) *change {
+		c := changes[cpe]
+		if c == nil {
+			c = &change{}
+			changes[cpe] = c
+		}
+		return c
+	}
+	for _, p := range added {
+		c := get(p.cpe)
+		c.added = append(c.added, p.cve)
+	}
+	for _, p := range removed {
+		c := get(p.cpe)
+		c.removed = append(c.removed, p.cve)
+	}
+	cpes := make([]string, 0, len(changes))
+	for cpe := range changes {
+		cpes = append(cpes, cpe)
+	}
+	sort.Strings(cpes)
+	for _, cpe := range cpes {
+		c := changes[cpe]
+		w.Write([]string{cpe, strings.Join(c.added, cfg.outRecordSeparator), strings.Join(c.removed, cfg.outRecordSeparator)})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func readFile(path string, cfg config) (pairs, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	set, err := readPairs(f, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return set, nil
+}
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] old_cpe2cve_output new_cpe2cve_output\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "output: +<TAB>CPE<TAB>CVE for added pairs, -<TAB>CPE<TAB>CVE for removed ones;\n")
+		fmt.Fprintf(os.Stderr, "        CPE<TAB>added CVEs<TAB>removed CVEs with -by_cpe\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := cfg.validate(); err != nil {
+		flog.Fatal(err)
+	}
+	old, err := readFile(flag.Arg(0), cfg)
+	if err != nil {
+		flog.Fatal(err)
+	}
+	new, err := readFile(flag.Arg(1), cfg)
+	if err != nil {
+		flog.Fatal(err)
+	}
+	added, removed := diff(old, new)
+	if err := writeDiff(os.Stdout, added, removed, cfg); err != nil {
+		flog.Fatal(err)
+	}
+}
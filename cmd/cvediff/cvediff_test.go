@@ -0,0 +1,141 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cfg := config{
+		cpesAt:             3,
+		cvesAt:             2,
+		inFieldSeparator:   "\t",
+		inRecordSeparator:  ",",
+		outFieldSeparator:  " ",
+		outRecordSeparator: ",",
+	}
+	cases := []struct {
+		name     string
+		old, new string
+		expect   []string
+		byCPE    []string
+	}{
+		{
+			name: "overlapping",
+			old: "host1\tCVE-2018-0001\tcpe:/a:gnu:glibc:2.28\n" +
+				"host1\tCVE-2018-0002\tcpe:/a:gnu:glibc:2.28,cpe:/a:haxx:curl:7.55.0\n" +
+				"host2\tCVE-2018-0003\tcpe:/a:haxx:curl:7.55.0\n",
+			// reordered, the same pair from another host, a new pair and a removed one
+			new: "host2\tCVE-2018-0002\tcpe:/a:haxx:curl:7.55.0\n" +
+				"host1\tCVE-2018-0002\tcpe:/a:gnu:glibc:2.28\n" +
+				"host2\tCVE-2018-0003\tcpe:/a:haxx:curl:7.55.0\n" +
+				"host1\tCVE-2018-0004\tcpe:/a:gnu:glibc:2.28\n",
+			expect: []string{
+				"+ cpe:/a:gnu:glibc:2.28 CVE-2018-0004",
+				"- cpe:/a:gnu:glibc:2.28 CVE-2018-0001",
+			},
+			byCPE: []string{
+				"cpe:/a:gnu:glibc:2.28 CVE-2018-0004 CVE-2018-0001",
+			},
+		},
+		{
+			name: "disjoint",
+			old:  "host1\tCVE-2018-0001\tcpe:/a:gnu:glibc:2.28\n",
+			new:  "host1\tCVE-2018-0003\tcpe:/a:haxx:curl:7.55.0\nhost1\tCVE-2018-0002\tcpe:/a:haxx:curl:7.55.0\n",
+			expect: []string{
+				"+ cpe:/a:haxx:curl:7.55.0 CVE-2018-0002",
+				"+ cpe:/a:haxx:curl:7.55.0 CVE-2018-0003",
+				"- cpe:/a:gnu:glibc:2.28 CVE-2018-0001",
+			},
+			byCPE: []string{
+				"cpe:/a:gnu:glibc:2.28  CVE-2018-0001",
+				"cpe:/a:haxx:curl:7.55.0 CVE-2018-0002,CVE-2018-0003 ",
+			},
+		},
+		{
+			name: "same",
+			old:  "host1\tCVE-2018-0001\tcpe:/a:gnu:glibc:2.28\nhost1\tCVE-2018-0002\tcpe:/a:gnu:glibc:2.28\n",
+			new:  "host1\tCVE-2018-0002\tcpe:/a:gnu:glibc:2.28\nhost1\tCVE-2018-0001\tcpe:/a:gnu:glibc:2.28\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			old, err := readPairs(strings.NewReader(c.old), cfg)
+			if err != nil {
+				t.Fatalf("couldn't read old pairs: %v", err)
+			}
+			new, err := readPairs(strings.NewReader(c.new), cfg)
+			if err != nil {
+				t.Fatalf("couldn't read new pairs: %v", err)
+			}
+			added, removed := diff(old, new)
+			// added and removed partition the symmetric difference
+			for _, p := range added {
+				if _, ok := old[p]; ok {
+					t.Errorf("added %v is in the old set", p)
+				}
+			}
+			for _, p := range removed {
+				if _, ok := new[p]; ok {
+					t.Errorf("removed %v is in the new set", p)
+				}
+			}
+			for _, mode := range []struct {
+				byCPE  bool
+				expect []string
+			}{{false, c.expect}, {true, c.byCPE}} {
+				var out bytes.Buffer
+				cfg := cfg
+				cfg.byCPE = mode.byCPE
+				if err := writeDiff(&out, added, removed, cfg); err != nil {
+					t.Fatalf("couldn't write diff: %v", err)
+				}
+				got := strings.TrimSuffix(out.String(), "\n")
+				if expect := strings.Join(mode.expect, "\n"); got != expect {
+					t.Errorf("by CPE %t: got\n%s\nexpected\n%s", mode.byCPE, got, expect)
+				}
+			}
+		})
+	}
+}
+
+func TestReadPairsJSON(t *testing.T) {
+	in := `{"fields":["host1"],"cve":"CVE-2018-0001","matches":["cpe:/a:gnu:glibc:2.28","cpe:/a:haxx:curl:7.55.0"],"cvss2":0,"cvss3":0,"cvss":0}
+{"fields":["host2"],"cve":"CVE-2018-0001","matches":["cpe:/a:gnu:glibc:2.28"],"cvss2":0,"cvss3":0,"cvss":0}
+`
+	set, err := readPairs(strings.NewReader(in), config{json: true})
+	if err != nil {
+		t.Fatalf("couldn't read pairs: %v", err)
+	}
+	if len(set) != 2 {
+		t.Fatalf("expected 2 pairs, got %d: %v", len(set), set)
+	}
+	for _, p := range []pair{{"cpe:/a:gnu:glibc:2.28", "CVE-2018-0001"}, {"cpe:/a:haxx:curl:7.55.0", "CVE-2018-0001"}} {
+		if _, ok := set[p]; !ok {
+			t.Errorf("pair %v is missing", p)
+		}
+	}
+}
+
+func TestReadPairsShortRecord(t *testing.T) {
+	_, err := readPairs(strings.NewReader("host1\tCVE-2018-0001\tcpe:/a:gnu:glibc:2.28\nhost1\n"), config{cpesAt: 3, cvesAt: 2, inFieldSeparator: "\t", inRecordSeparator: ","})
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("record %d", 2)) {
+		t.Fatalf("expected an error about record 2, got %v", err)
+	}
+}
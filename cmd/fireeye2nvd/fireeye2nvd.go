@@ -16,11 +16,13 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/fireeye/api"
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
@@ -31,6 +33,8 @@ const (
 	userAgent = "fireeye2nvd"
 )
 
+var pageSize = flag.Int("page_size", api.DefaultPageSize, "number of vulnerabilities requested at once; 0 disables pagination")
+
 func Read(r io.Reader, c chan runner.Convertible) error {
 	var vulns map[string]*schema.Vulnerability
 	if err := json.NewDecoder(r).Decode(&vulns); err != nil {
@@ -59,7 +63,7 @@ func FetchSince(baseURL, userAgent string, since int64) (<-chan runner.Convertib
 		return nil, fmt.Errorf("can't create client")
 	}
 
-	return client.FetchAllVulnerabilities(since)
+	return client.SetPageSize(*pageSize).SetLogger(logging.Std).FetchAllVulnerabilities(since)
 }
 
 func main() {
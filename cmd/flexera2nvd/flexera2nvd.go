@@ -21,6 +21,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/flexera/api"
 	"github.com/facebookincubator/nvdtools/providers/flexera/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
@@ -51,7 +52,7 @@ func FetchSince(baseURL, userAgent string, since int64) (<-chan runner.Convertib
 	}
 
 	client := api.NewClient(baseURL, userAgent, apiKey)
-	return client.FetchAllVulnerabilities(since)
+	return client.SetLogger(logging.Std).FetchAllVulnerabilities(since)
 }
 
 func main() {
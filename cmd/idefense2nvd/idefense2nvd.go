@@ -21,6 +21,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/idefense/api"
 	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
@@ -50,7 +51,7 @@ func FetchSince(baseURL, userAgent string, since int64) (<-chan runner.Convertib
 		return nil, fmt.Errorf("please set IDEFENSE_TOKEN in environment")
 	}
 	client := api.NewClient(baseURL, userAgent, apiKey)
-	return client.FetchAllVulnerabilities(since)
+	return client.SetLogger(logging.Std).FetchAllVulnerabilities(since)
 }
 
 func main() {
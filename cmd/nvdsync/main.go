@@ -22,6 +22,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/nvd"
 	"github.com/facebookincubator/flog"
 )
@@ -36,14 +37,17 @@ func main() {
 		cpefeed   nvd.CPE
 		timeout   time.Duration
 		userAgent string
+		incr      bool
 		source    = nvd.NewSourceConfig()
 	)
 
 	flag.Var(&cvefeed, "cve_feed", cvefeed.Help())
 	flag.Var(&cpefeed, "cpe_feed", cpefeed.Help())
 	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "sync timeout")
+	flag.BoolVar(&incr, "incremental", false, "only sync the modified and recent CVE feeds")
 	flag.StringVar(&userAgent, "user_agent", nvd.UserAgent(), "HTTP request User-Agent header")
 	source.AddFlags(flag.CommandLine)
+	source.Logger = logging.Flog
 
 	flag.Usage = func() {
 		fmt.Printf("nvdsync %s\n\n", nvd.Version)
@@ -68,8 +72,13 @@ func main() {
 	}
 	flog.Infof("Using http User-Agent: %s", nvd.UserAgent())
 
+	var cvesync nvd.Syncer = cvefeed
+	if incr {
+		cvesync = cvefeed.Incremental()
+	}
+
 	dfs := nvd.Sync{
-		Feeds:    []nvd.Syncer{cvefeed, cpefeed},
+		Feeds:    []nvd.Syncer{cvesync, cpefeed},
 		Source:   source,
 		LocalDir: localdir,
 	}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// genschema writes the schemas of schema directory into schemafiles.go, see go:generate in schema.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	paths, err := filepath.Glob(filepath.Join("schema", "*"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(paths)
+	license, err := ioutil.ReadFile("schema.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var b bytes.Buffer
+	b.Write(license[:bytes.Index(license, []byte("\n\n"))+2])
+	b.WriteString("// Code generated by `go run genschema.go`, DO NOT EDIT.\n\npackage main\n\n")
+	b.WriteString("// schemaFiles are the files of schema directory: the NVD JSON 1.1 feed schema and the CVE and CVSS schemas\n")
+	b.WriteString("// it refers to, as published at https://csrc.nist.gov/schema/nvd/feed/1.1/\n")
+	b.WriteString("var schemaFiles = map[string]string{\n")
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&b, "%q: %s,\n", filepath.Base(path), quote(string(data)))
+	}
+	b.WriteString("}\n")
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("schemafiles.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// quote returns s as a raw string literal, so that the generated file can be reviewed as the schema itself,
+// unless s can't be one
+func quote(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
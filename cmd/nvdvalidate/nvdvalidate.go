@@ -0,0 +1,97 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nvdvalidate validates NVD CVE JSON 1.1 feeds against the feed schema before they're loaded,
+// e.g. third-party or hand-edited ones, and reports the values which don't follow it with their JSON paths.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/facebookincubator/flog"
+)
+
+// validateFile validates the feed file, which is decompressed if it's gzip-compressed,
+// and returns up to maxErrors validation errors (all of them if maxErrors isn't positive)
+func validateFile(s *schema, file string, maxErrors int) ([]validationError, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var in io.Reader = bufio.NewReader(f)
+	if header, err := in.(*bufio.Reader).Peek(2); err == nil && header[0] == 0x1f && header[1] == 0x8b {
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		in = zr
+	}
+	v := &validator{max: maxErrors}
+	if err := v.validateFeed(s, in); err != nil {
+		return v.errs, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return v.errs, nil
+}
+
+// validateFiles validates the feed files, writes the errors to out and returns false if any of them is invalid
+func validateFiles(out io.Writer, files []string, maxErrors int) bool {
+	s, err := parseSchema(schemaFiles, feedSchemaFile)
+	if err != nil {
+		flog.Fatal(err)
+	}
+	valid := true
+	for _, file := range files {
+		errs, err := validateFile(s, file, maxErrors)
+		for _, e := range errs {
+			fmt.Fprintf(out, "%s: %s\n", file, e)
+		}
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", file, err)
+		}
+		if err != nil || len(errs) != 0 {
+			valid = false
+		}
+	}
+	return valid
+}
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvdcve-1.1-2019.json[.gz]...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "output: file: JSON path: error, for every value which doesn't follow NVD JSON 1.1 feed schema;\n")
+		fmt.Fprintf(os.Stderr, "        exits with status 1 if any of the feeds is invalid\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	maxErrors := flag.Int("max_errors", 10, "report up to this many errors per feed; 0 reports all of them")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if !validateFiles(os.Stdout, flag.Args(), *maxErrors) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaFilesGenerated(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("schema", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(schemaFiles) {
+		t.Fatalf("%d schema files, but %d generated ones: run go generate", len(paths), len(schemaFiles))
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if schemaFiles[filepath.Base(path)] != string(data) {
+			t.Errorf("%s differs from the generated one: run go generate", path)
+		}
+	}
+}
+
+func TestValidateFeed(t *testing.T) {
+	s, err := parseSchema(schemaFiles, feedSchemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		feed   string
+		expect []string
+	}{
+		{"valid", testFeed, nil},
+		{
+			"type error",
+			strings.Replace(testFeed, `"baseScore":9.8`, `"baseScore":"9.8"`, 1),
+			[]string{"$.CVE_Items[0].impact.baseMetricV3.cvssV3.baseScore: expected number, got string"},
+		},
+		{
+			"missing required",
+			strings.Replace(testFeed, `"vulnerable":true,`, ``, 1),
+			[]string{`$.CVE_Items[0].configurations.nodes[0].children[0].cpe_match[0]: missing required property "vulnerable"`},
+		},
+		{
+			"enum and range",
+			strings.Replace(strings.Replace(testFeed, `"scope":"UNCHANGED"`, `"scope":"U"`, 1), `"impactScore":5.9`, `"impactScore":59`, 1),
+			[]string{
+				`$.CVE_Items[0].impact.baseMetricV3.cvssV3.scope: expected one of UNCHANGED, CHANGED, got "U"`,
+				"$.CVE_Items[0].impact.baseMetricV3.impactScore: 59 is greater than the maximum 10",
+			},
+		},
+		{
+			"pattern",
+			strings.Replace(strings.Replace(testFeed, `"ID":"CVE-2019-0001"`, `"ID":"CVE-19-1"`, 1), `"AV:N/AC:L/Au:N/C:P/I:P/A:P"`, `"AV:N/AC:L/Au:N/C:P/I:P/A:X"`, 1),
+			[]string{
+				`$.CVE_Items[0].cve.CVE_data_meta.ID: "CVE-19-1" doesn't match pattern ^CVE-[0-9]{4}-[0-9]{4,}$`,
+				`$.CVE_Items[0].impact.baseMetricV2.cvssV2.vectorString: "AV:N/AC:L/Au:N/C:P/I:P/A:X" doesn't match pattern ` +
+					`^((AV:[NAL]|AC:[LMH]|Au:[MSN]|[CIA]:[NPC]|E:(U|POC|F|H|ND)|RL:(OF|TF|W|U|ND)|RC:(UC|UR|C|ND)|CDP:(N|L|LM|MH|H|ND)|TD:(N|L|M|H|ND)|[CIA]R:(L|M|H|ND))/)*` +
+					`(AV:[NAL]|AC:[LMH]|Au:[MSN]|[CIA]:[NPC]|E:(U|POC|F|H|ND)|RL:(OF|TF|W|U|ND)|RC:(UC|UR|C|ND)|CDP:(N|L|LM|MH|H|ND)|TD:(N|L|M|H|ND)|[CIA]R:(L|M|H|ND))$`,
+			},
+		},
+		{
+			"not an array",
+			`{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":null}`,
+			[]string{"$.CVE_Items: expected array, got null"},
+		},
+		{
+			"missing items",
+			`{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":4}`,
+			[]string{"$.CVE_data_version: expected string, got number", `$: missing required property "CVE_Items"`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := &validator{}
+			if err := v.validateFeed(s, strings.NewReader(c.feed)); err != nil {
+				t.Fatalf("validation failed: %v", err)
+			}
+			var got []string
+			for _, e := range v.errs {
+				got = append(got, e.String())
+			}
+			if !reflect.DeepEqual(got, c.expect) {
+				t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(c.expect, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	files := map[string]string{
+		"feed.schema": `{"type":"object","properties":{"items":{"$ref":"item.json#/definitions/item"}},"additionalProperties":false}`,
+		"item.json":   `{"definitions":{"item":{"type":["string","null"],"maxLength":3}}}`,
+		"format.json": `{"properties":{"date":{"type":"string","format":"date-time"}}}`,
+		"ref.json":    `{"properties":{"date":{"$ref":"#/definitions/date"}}}`,
+	}
+	s, err := parseSchema(files, "feed.schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &validator{}
+	v.validate(s, map[string]interface{}{"items": "abcd", "other": nil}, "$")
+	var got []string
+	for _, e := range v.errs {
+		got = append(got, e.String())
+	}
+	expect := []string{`$.items: "abcd" is longer than 3 characters`, `$: unexpected property "other"`}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expect, "\n"))
+	}
+	// the keywords which aren't supported, or references which can't be resolved, fail the schema
+	for _, name := range []string{"format.json", "ref.json", "missing.json"} {
+		if _, err := parseSchema(files, name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdvalidate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(testFeed))
+	zw.Close()
+	// every item has two errors
+	invalid := `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[` +
+		strings.Repeat(`{"cve":{"data_type":"CVE"},"publishedDate":1},`, 10) +
+		`{"cve":{}}]}`
+	files := map[string][]byte{
+		"valid.json.gz":  gz.Bytes(),
+		"invalid.json":   []byte(invalid),
+		"truncated.json": []byte(testFeed[:len(testFeed)/2]),
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	var out bytes.Buffer
+	if !validateFiles(&out, []string{path("valid.json.gz")}, 10) || out.Len() != 0 {
+		t.Errorf("valid feed should pass, got:\n%s", out.String())
+	}
+	out.Reset()
+	if validateFiles(&out, []string{path("valid.json.gz"), path("invalid.json")}, 3) {
+		t.Error("invalid feed should fail")
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], path("invalid.json")+": $.CVE_Items[0].cve: missing required property") {
+		t.Errorf("expected first 3 errors of invalid.json, got:\n%s", out.String())
+	}
+	out.Reset()
+	if validateFiles(&out, []string{path("truncated.json")}, 10) || !strings.Contains(out.String(), "invalid JSON") {
+		t.Errorf("truncated feed should fail as invalid JSON, got:\n%s", out.String())
+	}
+	out.Reset()
+	if validateFiles(&out, []string{path("missing.json")}, 10) || out.Len() == 0 {
+		t.Error("missing feed should fail")
+	}
+}
+
+var testFeed = `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_data_numberOfCVEs":"1","CVE_data_timestamp":"2019-06-01T07:00Z","CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2019-0001","ASSIGNER":"cve@mitre.org"},
+ "problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-787"}]}]},
+ "references":{"reference_data":[{"url":"https://example.com/advisory","name":"https://example.com/advisory","refsource":"MISC","tags":["Vendor Advisory"]}]},
+ "description":{"description_data":[{"lang":"en","value":"Overflow in acme widget on Linux."}]}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"AND","children":[
+   {"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"1.4","cpe_name":[]}]},
+   {"operator":"OR","negate":false,"cpe_match":[{"vulnerable":false,"cpe23Uri":"cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"}]}]}]},
+ "impact":{
+  "baseMetricV3":{"cvssV3":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","attackVector":"NETWORK","attackComplexity":"LOW","privilegesRequired":"NONE","userInteraction":"NONE","scope":"UNCHANGED","confidentialityImpact":"HIGH","integrityImpact":"HIGH","availabilityImpact":"HIGH","baseScore":9.8,"baseSeverity":"CRITICAL"},"exploitabilityScore":3.9,"impactScore":5.9},
+  "baseMetricV2":{"cvssV2":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:P/I:P/A:P","accessVector":"NETWORK","accessComplexity":"LOW","authentication":"NONE","confidentialityImpact":"PARTIAL","integrityImpact":"PARTIAL","availabilityImpact":"PARTIAL","baseScore":7.5},"severity":"HIGH","exploitabilityScore":10.0,"impactScore":6.4,"acInsufInfo":false,"obtainAllPrivilege":false,"obtainUserPrivilege":false,"obtainOtherPrivilege":false,"userInteractionRequired":false}},
+ "publishedDate":"2019-05-01T00:00Z","lastModifiedDate":"2019-05-02T00:00Z"}]}`
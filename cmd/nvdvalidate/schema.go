@@ -0,0 +1,458 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate go run genschema.go
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// feedSchemaFile is the name of the feed schema in schemaFiles, which go generate makes of schema directory
+const feedSchemaFile = "nvd_cve_feed_json_1.1.schema"
+
+// schema is a JSON schema (draft 4). Only a subset of the validation keywords is supported:
+// the schemas using the other ones fail to parse, so that nothing they require is silently skipped.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaTypes        `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Enum                 []interface{}      `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Definitions          map[string]*schema `json:"definitions"`
+
+	ref          *schema        // the schema Ref refers to
+	additional   *schema        // the schema of additionalProperties, if it's one
+	noAdditional bool           // additionalProperties is false
+	pattern      *regexp.Regexp // compiled Pattern
+}
+
+// schemaKeywords are the keywords schema supports; the annotations are accepted, but don't affect validation
+var schemaKeywords = map[string]bool{
+	"$ref": true, "type": true, "properties": true, "additionalProperties": true, "required": true,
+	"items": true, "minItems": true, "maxItems": true, "enum": true, "minimum": true, "maximum": true,
+	"minLength": true, "maxLength": true, "pattern": true, "definitions": true,
+	// annotations; license is used by the CVSS schemas of FIRST
+	"$schema": true, "id": true, "title": true, "description": true, "default": true, "license": true,
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it fails on the keywords schema doesn't support
+func (s *schema) UnmarshalJSON(data []byte) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for k := range keywords {
+		if !schemaKeywords[k] {
+			return fmt.Errorf("unsupported schema keyword %q", k)
+		}
+	}
+	type plain schema // without the UnmarshalJSON method
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	switch strings.TrimSpace(string(s.AdditionalProperties)) {
+	case "", "true":
+	case "false":
+		s.noAdditional = true
+	default:
+		if err := json.Unmarshal(s.AdditionalProperties, &s.additional); err != nil {
+			return err
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	return nil
+}
+
+// schemaTypes is the value of type keyword, which is either a type or an array of types
+type schemaTypes []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var typ string
+	if err := json.Unmarshal(data, &typ); err == nil {
+		*t = schemaTypes{typ}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// parseSchema parses the schema file and the schemas it refers to from files, which maps the file names to their
+// contents, and resolves the references. The references are either local ones, to the definitions of the same file
+// (#/definitions/name), or to other files of the same directory, optionally followed by the definition in them.
+func parseSchema(files map[string]string, name string) (*schema, error) {
+	docs := map[string]*schema{}
+	var load func(name string) (*schema, error)
+	load = func(name string) (*schema, error) {
+		if doc, ok := docs[name]; ok {
+			return doc, nil
+		}
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("can't read schema: %s not found", name)
+		}
+		var doc schema
+		if err := json.Unmarshal([]byte(data), &doc); err != nil {
+			return nil, fmt.Errorf("can't parse schema %s: %v", name, err)
+		}
+		docs[name] = &doc
+		var resolve func(s *schema) error
+		resolve = func(s *schema) error {
+			if s == nil {
+				return nil
+			}
+			for _, children := range []map[string]*schema{s.Properties, s.Definitions} {
+				for _, child := range children {
+					if err := resolve(child); err != nil {
+						return err
+					}
+				}
+			}
+			for _, child := range []*schema{s.Items, s.additional} {
+				if err := resolve(child); err != nil {
+					return err
+				}
+			}
+			if s.Ref == "" {
+				return nil
+			}
+			file, fragment := s.Ref, ""
+			if i := strings.IndexByte(s.Ref, '#'); i != -1 {
+				file, fragment = s.Ref[:i], s.Ref[i+1:]
+			}
+			target := &doc
+			if file != "" {
+				var err error
+				if target, err = load(path.Join(path.Dir(name), path.Base(file))); err != nil {
+					return err
+				}
+			}
+			if fragment != "" {
+				def := strings.TrimPrefix(fragment, "/definitions/")
+				if def == fragment || target.Definitions[def] == nil {
+					return fmt.Errorf("can't resolve schema reference %q in %s", s.Ref, name)
+				}
+				target = target.Definitions[def]
+			}
+			s.ref = target
+			return nil
+		}
+		if err := resolve(&doc); err != nil {
+			return nil, err
+		}
+		return &doc, nil
+	}
+	return load(name)
+}
+
+// resolved returns the schema s refers to, or s itself if it isn't a reference
+func (s *schema) resolved() *schema {
+	for s != nil && s.ref != nil {
+		s = s.ref
+	}
+	return s
+}
+
+// property returns the schema the property of the objects following s follows;
+// the second return value is false if s doesn't allow the property
+func (s *schema) property(name string) (*schema, bool) {
+	if p, ok := s.Properties[name]; ok {
+		return p.resolved(), true
+	}
+	if s.noAdditional {
+		return nil, false
+	}
+	return s.additional.resolved(), true
+}
+
+// validationError is a value which doesn't follow the schema
+type validationError struct {
+	Path    string // JSON path of the value, e.g. $.CVE_Items[0].cve
+	Message string
+}
+
+func (e validationError) String() string {
+	return e.Path + ": " + e.Message
+}
+
+// validator collects the validation errors, up to max of them if max is positive
+type validator struct {
+	errs []validationError
+	max  int
+}
+
+// full returns true if no more errors should be collected
+func (v *validator) full() bool {
+	return v.max > 0 && len(v.errs) >= v.max
+}
+
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	if !v.full() {
+		v.errs = append(v.errs, validationError{path, fmt.Sprintf(format, args...)})
+	}
+}
+
+// validate validates the value decoded with json.Decoder.UseNumber against s
+func (v *validator) validate(s *schema, value interface{}, path string) {
+	s = s.resolved()
+	if s == nil || v.full() {
+		return
+	}
+	if len(s.Type) != 0 && !hasType(value, s.Type) {
+		v.errorf(path, "expected %s, got %s", strings.Join(s.Type, " or "), typeOf(value))
+		return
+	}
+	if len(s.Enum) != 0 && !inEnum(s.Enum, value) {
+		values := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			values[i] = fmt.Sprint(e)
+		}
+		v.errorf(path, "expected one of %s, got %s", strings.Join(values, ", "), jsonString(value))
+		return
+	}
+	switch value := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(value)
+		if s.MinLength != nil && n < *s.MinLength {
+			v.errorf(path, "%s is shorter than %d characters", jsonString(value), *s.MinLength)
+		} else if s.MaxLength != nil && n > *s.MaxLength {
+			v.errorf(path, "%s is longer than %d characters", jsonString(value), *s.MaxLength)
+		} else if s.pattern != nil && !s.pattern.MatchString(value) {
+			v.errorf(path, "%s doesn't match pattern %s", jsonString(value), s.Pattern)
+		}
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			v.errorf(path, "invalid number %s", value)
+		} else if s.Minimum != nil && f < *s.Minimum {
+			v.errorf(path, "%s is less than the minimum %v", value, *s.Minimum)
+		} else if s.Maximum != nil && f > *s.Maximum {
+			v.errorf(path, "%s is greater than the maximum %v", value, *s.Maximum)
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				v.errorf(path, "missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.validateProperty(s, name, value[name], path)
+		}
+	case []interface{}:
+		v.checkItems(s, len(value), path)
+		for i, item := range value {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// validateProperty validates the property of the object at path, which follows s
+func (v *validator) validateProperty(s *schema, name string, value interface{}, path string) {
+	prop, ok := s.property(name)
+	if !ok {
+		v.errorf(path, "unexpected property %q", name)
+		return
+	}
+	v.validate(prop, value, path+"."+name)
+}
+
+// checkItems checks the number of the items of the array at path, which follows s
+func (v *validator) checkItems(s *schema, n int, path string) {
+	if s.MinItems != nil && n < *s.MinItems {
+		v.errorf(path, "expected at least %d items, got %d", *s.MinItems, n)
+	} else if s.MaxItems != nil && n > *s.MaxItems {
+		v.errorf(path, "expected at most %d items, got %d", *s.MaxItems, n)
+	}
+}
+
+// validateFeed validates the (decompressed) feed read from in against the schema of the feed.
+// CVE_Items are decoded and validated one at a time, so the whole feed is never held in memory.
+// The error is returned if the feed isn't valid JSON, e.g. it's truncated.
+func (v *validator) validateFeed(s *schema, in io.Reader) error {
+	s = s.resolved()
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		v.errorf("$", "expected object, got %v", t)
+		return nil
+	}
+	seen := map[string]bool{}
+	for dec.More() && !v.full() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := t.(string)
+		seen[name] = true
+		path := "$." + name
+		prop, ok := s.property(name)
+		if !ok || prop == nil || len(prop.Type) != 1 || prop.Type[0] != "array" {
+			var value interface{}
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			v.validateProperty(s, name, value, "$")
+			continue
+		}
+		// arrays, i.e. CVE_Items, are streamed
+		t, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := t.(json.Delim); !ok || d != '[' {
+			v.errorf(path, "expected array, got %s", tokenType(t))
+			if _, ok := t.(json.Delim); ok {
+				// the rest of the object can't be told from the rest of the feed
+				return nil
+			}
+			continue
+		}
+		n := 0
+		for ; dec.More() && !v.full(); n++ {
+			var item interface{}
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			v.validate(prop.Items, item, fmt.Sprintf("%s[%d]", path, n))
+		}
+		if v.full() {
+			return nil
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		v.checkItems(prop, n, path)
+	}
+	if v.full() {
+		return nil
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for _, name := range s.Required {
+		if !seen[name] {
+			v.errorf("$", "missing required property %q", name)
+		}
+	}
+	return nil
+}
+
+// hasType returns true if the value is of any of the JSON schema types
+func hasType(value interface{}, types []string) bool {
+	for _, typ := range types {
+		switch typ {
+		case "integer":
+			if n, ok := value.(json.Number); ok {
+				if _, err := n.Int64(); err == nil {
+					return true
+				}
+			}
+		case "number":
+			if _, ok := value.(json.Number); ok {
+				return true
+			}
+		default:
+			if typeOf(value) == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON schema type of the decoded value
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// tokenType returns the JSON schema type of the value starting with token t
+func tokenType(t json.Token) string {
+	if t == json.Delim('{') {
+		return "object"
+	}
+	return typeOf(t)
+}
+
+func jsonString(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// inEnum returns true if the value equals any of the enum values, compared as JSON
+func inEnum(enum []interface{}, value interface{}) bool {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return false
+		}
+		value = f
+	}
+	s := jsonString(value)
+	for _, e := range enum {
+		if jsonString(e) == s {
+			return true
+		}
+	}
+	return false
+}
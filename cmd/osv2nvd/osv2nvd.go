@@ -0,0 +1,44 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/providers/osv"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Println("Usage: osv2nvd <osv-records-dir>")
+		fmt.Println("Example:")
+		fmt.Println("gsutil cp gs://osv-vulnerabilities/npm/all.zip . && unzip all.zip -d npm")
+		fmt.Println("osv2nvd npm > osv-npm.cve.json")
+		os.Exit(1)
+	}
+
+	feed, err := osv.Convert(os.Args[1])
+	if err != nil {
+		flog.Fatal(err)
+	}
+
+	err = json.NewEncoder(os.Stdout).Encode(feed)
+	if err != nil {
+		flog.Fatal(err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 	"github.com/facebookincubator/nvdtools/providers/rbs/api"
 	"github.com/facebookincubator/nvdtools/providers/rbs/schema"
@@ -61,7 +62,7 @@ func FetchSince(baseURL, userAgent string, since int64) (<-chan runner.Convertib
 	if err != nil {
 		return nil, fmt.Errorf("can't create a client: %v", err)
 	}
-	return client.FetchAllVulnerabilities(since)
+	return client.SetLogger(logging.Std).FetchAllVulnerabilities(since)
 }
 
 func main() {
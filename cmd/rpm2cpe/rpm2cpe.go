@@ -82,6 +82,7 @@ type config struct {
 	inFieldSep  string
 	outFieldSep string
 	skip        fieldsToSkip
+	rpmOpts     cpeparse.RPMNameOptions
 }
 
 func (c *config) addFlags() {
@@ -91,6 +92,9 @@ func (c *config) addFlags() {
 	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
 	flag.Var(&c.skip, "e", "optional comma-separated list of input fields that should be dropped from output (starts with 1) "+
 		"rpm name is extracted before dropping fields, CPE is added after that")
+	flag.BoolVar(&c.rpmOpts.EVR, "evr", false, "put [epoch:]version-release in the CPE version, instead of version in version and release in update")
+	flag.BoolVar(&c.rpmOpts.StripDist, "strip_dist", false, "remove distribution tag (e.g. el8) from the release")
+	flag.Var(flag.Lookup("strip_dist").Value, "strip-dist", "same as -strip_dist")
 }
 
 func sayErr(status int, msg string, args ...interface{}) {
@@ -130,7 +134,7 @@ func processRecord(fields []string, cfg config) ([]string, error) {
 	}
 	attr := wfn.NewAttributesWithNA()
 	attr.Vendor = wfn.Any
-	if err := cpeparse.FromRPMName(attr, fields[cfg.rpmField-1]); err != nil {
+	if err := cpeparse.FromRPMNameWithOptions(attr, fields[cfg.rpmField-1], cfg.rpmOpts); err != nil {
 		return nil, fmt.Errorf("couldn't parse RPM name from field %q: %v", fields[cfg.rpmField-1], err)
 	}
 	cpe := attr.BindToURI()
@@ -18,6 +18,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpeparse"
 )
 
 func TestSkipFields(t *testing.T) {
@@ -91,3 +93,48 @@ func TestProcessRecord(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessRecordOptions(t *testing.T) {
+	cases := []struct {
+		opts cpeparse.RPMNameOptions
+		in   string
+		out  string
+	}{
+		{
+			cpeparse.RPMNameOptions{},
+			"bash-0:4.4.19-12.el8.x86_64.rpm",
+			"cpe:/a::bash:4.4.19:12.el8:~-~-~-~x86_64~-:-",
+		},
+		{
+			cpeparse.RPMNameOptions{StripDist: true},
+			"bash-0:4.4.19-12.el8.x86_64.rpm",
+			"cpe:/a::bash:4.4.19:12:~-~-~-~x86_64~-:-",
+		},
+		{
+			cpeparse.RPMNameOptions{EVR: true},
+			"openssl-1:1.1.1g-15.el8_3.x86_64.rpm",
+			"cpe:/a::openssl:1%3a1.1.1g-15.el8_3:-:~-~-~-~x86_64~-:-",
+		},
+		{
+			cpeparse.RPMNameOptions{EVR: true, StripDist: true},
+			"openssl-1:1.1.1g-15.el8_3.x86_64.rpm",
+			"cpe:/a::openssl:1%3a1.1.1g-15:-:~-~-~-~x86_64~-:-",
+		},
+		{
+			cpeparse.RPMNameOptions{EVR: true, StripDist: true},
+			"curl-7.61.1-18.el8_4.1.x86_64.rpm",
+			"cpe:/a::curl:7.61.1-18.1:-:~-~-~-~x86_64~-:-",
+		},
+	}
+	for _, c := range cases {
+		cfg := config{rpmField: 1, cpeField: 1, rpmOpts: c.opts}
+		record, err := processRecord([]string{c.in}, cfg)
+		if err != nil {
+			t.Errorf("%q %+v: unexpected failure: %v", c.in, c.opts, err)
+			continue
+		}
+		if out := record[0]; c.out != out {
+			t.Errorf("%q %+v:\nhave: %q\nwant: %q", c.in, c.opts, out, c.out)
+		}
+	}
+}
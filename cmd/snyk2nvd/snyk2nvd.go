@@ -23,6 +23,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 	"github.com/facebookincubator/nvdtools/providers/snyk/api"
 	"github.com/facebookincubator/nvdtools/providers/snyk/schema"
@@ -62,7 +63,7 @@ func FetchSince(baseURL, userAgent string, since int64) (<-chan runner.Convertib
 
 	client := api.NewClient(baseURL, userAgent, consumerID, secret)
 
-	advs, err := client.FetchAllVulnerabilities(since)
+	advs, err := client.SetLogger(logging.Std).FetchAllVulnerabilities(since)
 	return lf.filter(advs), err
 }
 
@@ -0,0 +1,150 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// maxGuesses limits the number of candidates returned by GuessCPE
+const maxGuesses = 10
+
+// versionRegex matches the words of a product string which look like version numbers, e.g. 2.4.54, v10 or 1.1.1q
+var versionRegex = regexp.MustCompile(`^v?[0-9]+(\.[0-9a-z]+)*$`)
+
+// GuessCPE makes a best-effort guess of the CPE names of a product described by free text, e.g. "Apache HTTP Server 2.4.54".
+// The text is split into words, the last word which looks like a version is used as the version of the candidates,
+// and the rest is compared to the dictionary titles and names of products.
+// Candidates are (part, vendor, product) of matching dictionary items with the guessed version (or ANY, if the text has no version),
+// ranked by the share of words they have in common with the text, the best candidate first.
+// An error is returned if the text has no words or the version can't be used in a CPE name.
+func (idx *Index) GuessCPE(text string) ([]*wfn.Attributes, error) {
+	words, version := splitVersion(uniqueWords(normalizeTitle(text)))
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no product words in %q", text)
+	}
+	guessed := wfn.Any
+	if version != "" {
+		attrs, err := wfn.NewBuilder().Version(version).Build()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't use version of %q: %v", text, err)
+		}
+		guessed = attrs.Version
+	}
+
+	type candidate struct {
+		name  *wfn.Attributes
+		key   string
+		score float64
+	}
+	best := make(map[string]*candidate)
+	seen := make(map[int]bool)
+	for _, w := range words {
+		for _, i := range idx.words[w] {
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+			for _, j := range idx.resolve(i, make(map[int]bool)) {
+				name := idx.name(j)
+				key := fmt.Sprintf("%s:%s:%s", name.Part, name.Vendor, name.Product)
+				score := idx.similarity(j, words)
+				if c, ok := best[key]; !ok || c.score < score {
+					best[key] = &candidate{name: name, key: key, score: score}
+				}
+			}
+		}
+	}
+
+	candidates := make([]*candidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].key < candidates[j].key
+	})
+	if len(candidates) > maxGuesses {
+		candidates = candidates[:maxGuesses]
+	}
+
+	guesses := make([]*wfn.Attributes, len(candidates))
+	for i, c := range candidates {
+		guess := wfn.NewAttributesWithAny()
+		guess.Part, guess.Vendor, guess.Product = c.name.Part, c.name.Vendor, c.name.Product
+		guess.Version = guessed
+		guesses[i] = guess
+	}
+	return guesses, nil
+}
+
+// similarity returns the Jaccard index of the given words and words of the i-th item titles or its name,
+// whichever is the highest; version-like words are ignored
+func (idx *Index) similarity(i int, words []string) float64 {
+	name := idx.name(i)
+	texts := []string{wfn.StripSlashes(name.Vendor + " " + name.Product)}
+	for _, title := range idx.items[i].Title {
+		texts = append(texts, title)
+	}
+	var best float64
+	for _, text := range texts {
+		other, _ := splitVersion(uniqueWords(normalizeTitle(text)))
+		if score := jaccard(words, other); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// splitVersion separates the last version-like word from the rest of the words
+func splitVersion(words []string) (rest []string, version string) {
+	for i := len(words) - 1; i >= 0; i-- {
+		if versionRegex.MatchString(words[i]) {
+			if version == "" {
+				version = strings.TrimPrefix(words[i], "v")
+			}
+			continue
+		}
+		rest = append(rest, words[i])
+	}
+	for i, j := 0, len(rest)-1; i < j; i, j = i+1, j-1 {
+		rest[i], rest[j] = rest[j], rest[i]
+	}
+	return rest, version
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, w := range a {
+		set[w] = true
+	}
+	common := 0
+	for _, w := range b {
+		if set[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
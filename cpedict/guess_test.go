@@ -0,0 +1,110 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"strings"
+	"testing"
+)
+
+const testGuessDict = `
+<?xml version='1.0' encoding='UTF-8'?>
+<cpe-list xmlns="http://cpe.mitre.org/dictionary/2.0" xmlns:cpe-23="http://scap.nist.gov/schema/cpe-extension/2.3">
+  <cpe-item name="cpe:/a:apache:http_server:2.4.53">
+    <title xml:lang="en-US">Apache Software Foundation Apache HTTP Server 2.4.53</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:apache:http_server:2.4.53:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:apache:tomcat:9.0.64">
+    <title xml:lang="en-US">Apache Software Foundation Tomcat 9.0.64</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:apache:tomcat:9.0.64:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:apache:http_server_mod_proxy:1.0">
+    <title xml:lang="en-US">Apache HTTP Server mod_proxy module 1.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:apache:http_server_mod_proxy:1.0:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:openssl:openssl:1.1.1p">
+    <title xml:lang="en-US">OpenSSL Project OpenSSL 1.1.1p</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:openssl:openssl:1.1.1p:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:igor_sysoev:nginx:1.0" deprecated="true" deprecation_date="2013-03-24T16:01:18.500Z">
+    <title xml:lang="en-US">Igor Sysoev nginx 1.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:igor_sysoev:nginx:1.0:*:*:*:*:*:*:*">
+      <cpe-23:deprecation date="2013-03-24T12:01:18.500-04:00">
+        <cpe-23:deprecated-by name="cpe:2.3:a:nginx:nginx:1.0:*:*:*:*:*:*:*" type="NAME_CORRECTION"/>
+      </cpe-23:deprecation>
+    </cpe-23:cpe23-item>
+  </cpe-item>
+  <cpe-item name="cpe:/a:nginx:nginx:1.0">
+    <title xml:lang="en-US">Nginx 1.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:nginx:nginx:1.0:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:microsoft:edge_chromium:100.0">
+    <title xml:lang="en-US">Microsoft Edge (Chromium-based) 100.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:microsoft:edge_chromium:100.0:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:microsoft:edge:-">
+    <title xml:lang="en-US">Microsoft Edge</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:microsoft:edge:-:*:*:*:*:*:*:*"/>
+  </cpe-item>
+</cpe-list>
+`
+
+func TestGuessCPE(t *testing.T) {
+	idx, err := Load(strings.NewReader(testGuessDict))
+	if err != nil {
+		t.Fatalf("failed to load dictionary: %v", err)
+	}
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"Apache HTTP Server 2.4.54", "cpe:2.3:a:apache:http_server:2.4.54:*:*:*:*:*:*:*"},
+		{"apache httpd server v2.4.54", "cpe:2.3:a:apache:http_server:2.4.54:*:*:*:*:*:*:*"},
+		{"Apache Tomcat 9.0.65", "cpe:2.3:a:apache:tomcat:9.0.65:*:*:*:*:*:*:*"},
+		{"OpenSSL 1.1.1q", "cpe:2.3:a:openssl:openssl:1.1.1q:*:*:*:*:*:*:*"},
+		{"nginx/1.23.1", "cpe:2.3:a:nginx:nginx:1.23.1:*:*:*:*:*:*:*"},
+		{"Microsoft Edge", "cpe:2.3:a:microsoft:edge:*:*:*:*:*:*:*:*"},
+		{"Microsoft Edge Chromium 106.0.1370.42", "cpe:2.3:a:microsoft:edge_chromium:106.0.1370.42:*:*:*:*:*:*:*"},
+	}
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			guesses, err := idx.GuessCPE(c.text)
+			if err != nil {
+				t.Fatalf("GuessCPE(%q) failed: %v", c.text, err)
+			}
+			if len(guesses) == 0 {
+				t.Fatalf("GuessCPE(%q): no guesses", c.text)
+			}
+			if got := guesses[0].BindToFmtString(); got != c.want {
+				t.Fatalf("GuessCPE(%q): expected %s, got %s", c.text, c.want, got)
+			}
+		})
+	}
+}
+
+func TestGuessCPENoMatch(t *testing.T) {
+	idx, err := Load(strings.NewReader(testGuessDict))
+	if err != nil {
+		t.Fatalf("failed to load dictionary: %v", err)
+	}
+	if guesses, err := idx.GuessCPE("Oracle Database 19c"); err != nil || len(guesses) != 0 {
+		t.Fatalf("expected no guesses, got %v, %v", guesses, err)
+	}
+	for _, text := range []string{"", " 1.0 ", "--"} {
+		if _, err := idx.GuessCPE(text); err == nil {
+			t.Errorf("GuessCPE(%q) should fail", text)
+		}
+	}
+}
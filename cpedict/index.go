@@ -0,0 +1,205 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Index is a searchable view of the CPE dictionary which maps product titles to canonical CPE names.
+type Index struct {
+	items   []CPEItem
+	byName  map[string]int
+	byTitle map[string][]int
+	// words maps every word found in titles to indices of items which contain it
+	words map[string][]int
+}
+
+// Load decodes the dictionary XML (e.g. official-cpe-dictionary_v2.3.xml) and builds an Index from it.
+func Load(r io.Reader) (*Index, error) {
+	dict, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewIndex(dict), nil
+}
+
+// NewIndex builds an Index from the given dictionary.
+func NewIndex(dict *CPEList) *Index {
+	idx := &Index{
+		items:   dict.Items,
+		byName:  make(map[string]int, len(dict.Items)),
+		byTitle: make(map[string][]int),
+		words:   make(map[string][]int),
+	}
+	for i, item := range idx.items {
+		for _, name := range []NamePattern{item.Name, item.CPE23.Name} {
+			if name != (NamePattern{}) {
+				idx.byName[wfn.Attributes(name).BindToFmtString()] = i
+			}
+		}
+		seen := make(map[string]bool)
+		for _, title := range item.Title {
+			norm := normalizeTitle(title)
+			if norm == "" || seen[norm] {
+				continue
+			}
+			seen[norm] = true
+			idx.byTitle[norm] = append(idx.byTitle[norm], i)
+			for _, w := range uniqueWords(norm) {
+				if n := len(idx.words[w]); n == 0 || idx.words[w][n-1] != i {
+					idx.words[w] = append(idx.words[w], i)
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// LookupCPE returns canonical (non-deprecated) CPE names of products with the given title.
+// Titles are compared case-insensitively, ignoring punctuation; if no title matches exactly,
+// the items whose titles contain every word of the given title are returned.
+// Deprecated items are resolved by following the deprecation chain to the current names.
+func (idx *Index) LookupCPE(title string) []*wfn.Attributes {
+	norm := normalizeTitle(title)
+	if norm == "" {
+		return nil
+	}
+	found := idx.byTitle[norm]
+	if len(found) == 0 {
+		found = idx.lookupWords(uniqueWords(norm))
+	}
+	var attrs []*wfn.Attributes
+	seen := make(map[int]bool)
+	for _, i := range found {
+		for _, j := range idx.resolve(i, make(map[int]bool)) {
+			if seen[j] {
+				continue
+			}
+			seen[j] = true
+			attrs = append(attrs, idx.name(j))
+		}
+	}
+	return attrs
+}
+
+// lookupWords returns indices of items which contain all words in their titles, in dictionary order.
+func (idx *Index) lookupWords(words []string) []int {
+	if len(words) == 0 {
+		return nil
+	}
+	counts := make(map[int]int)
+	for _, w := range words {
+		for _, i := range idx.words[w] {
+			counts[i]++
+		}
+	}
+	var found []int
+	for _, i := range idx.words[words[0]] {
+		if counts[i] == len(words) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// resolve follows the deprecation chain of the i-th item and returns the indices of current items;
+// visited guards against cycles in the dictionary.
+func (idx *Index) resolve(i int, visited map[int]bool) []int {
+	if visited[i] {
+		return nil
+	}
+	visited[i] = true
+	item := idx.items[i]
+	if !item.Deprecated {
+		return []int{i}
+	}
+	var names []*wfn.Attributes
+	if item.CPE23.Deprecation != nil {
+		for _, depBy := range item.CPE23.Deprecation.DeprecatedBy {
+			name := wfn.Attributes(depBy.Name)
+			names = append(names, &name)
+		}
+	}
+	if len(names) == 0 && item.DeprecatedBy != nil {
+		names = append(names, (*wfn.Attributes)(item.DeprecatedBy))
+	}
+	var result []int
+	for _, name := range names {
+		for _, j := range idx.deprecatedBy(name) {
+			result = append(result, idx.resolve(j, visited)...)
+		}
+	}
+	return result
+}
+
+// deprecatedBy returns indices of items named by the deprecated-by reference.
+// The reference may contain wildcards and refer to a family of products.
+func (idx *Index) deprecatedBy(name *wfn.Attributes) []int {
+	if j, ok := idx.byName[name.BindToFmtString()]; ok {
+		return []int{j}
+	}
+	var found []int
+	for j := range idx.items {
+		if wfn.Match(name, idx.name(j)) {
+			found = append(found, j)
+		}
+	}
+	return found
+}
+
+// name returns CPE 2.3 name of the i-th item, falling back to CPE 2.2 name if the former is missing
+func (idx *Index) name(i int) *wfn.Attributes {
+	item := idx.items[i]
+	name := wfn.Attributes(item.CPE23.Name)
+	if name == (wfn.Attributes{}) {
+		name = wfn.Attributes(item.Name)
+	}
+	return &name
+}
+
+// normalizeTitle lowercases the title and replaces runs of punctuation and spaces with a single space
+func normalizeTitle(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+func uniqueWords(s string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		if !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
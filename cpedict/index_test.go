@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestIndexLookupCPE(t *testing.T) {
+	xmlStr := `
+<?xml version='1.0' encoding='UTF-8'?>
+<cpe-list xmlns="http://cpe.mitre.org/dictionary/2.0" xmlns:cpe-23="http://scap.nist.gov/schema/cpe-extension/2.3">
+  <generator>
+    <product_name>National Vulnerability Database (NVD)</product_name>
+    <product_version>3.20</product_version>
+    <schema_version>2.3</schema_version>
+    <timestamp>2018-04-25T03:50:11.922Z</timestamp>
+  </generator>
+  <cpe-item name="cpe:/a:adobe:acrobat:8.0">
+    <title xml:lang="en-US">Adobe Acrobat 8.0</title>
+    <title xml:lang="ja-JP">アドビシステムズ アクロバット 8.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:adobe:acrobat:8.0:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:adobe:acrobat_reader:8.0.0" deprecated="true" deprecation_date="2011-03-24T16:01:18.500Z">
+    <title xml:lang="en-US">Adobe Acrobat Reader 8.0.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:adobe:acrobat_reader:8.0.0:*:*:*:*:*:*:*">
+      <cpe-23:deprecation date="2011-03-24T12:01:18.500-04:00">
+        <cpe-23:deprecated-by name="cpe:2.3:a:adobe:reader:8.0.0:*:*:*:*:*:*:*" type="NAME_CORRECTION"/>
+      </cpe-23:deprecation>
+    </cpe-23:cpe23-item>
+  </cpe-item>
+  <cpe-item name="cpe:/a:adobe:reader:8.0.0" deprecated="true" deprecation_date="2012-03-24T16:01:18.500Z">
+    <title xml:lang="en-US">Adobe Reader 8.0.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:adobe:reader:8.0.0:*:*:*:*:*:*:*">
+      <cpe-23:deprecation date="2012-03-24T12:01:18.500-04:00">
+        <cpe-23:deprecated-by name="cpe:2.3:a:adobe:acrobat_reader_dc:8.0:*:*:*:*:*:*:*" type="NAME_CORRECTION"/>
+      </cpe-23:deprecation>
+    </cpe-23:cpe23-item>
+  </cpe-item>
+  <cpe-item name="cpe:/a:adobe:acrobat_reader_dc:8.0">
+    <title xml:lang="en-US">Adobe Acrobat Reader DC 8.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:adobe:acrobat_reader_dc:8.0:*:*:*:*:*:*:*"/>
+  </cpe-item>
+  <cpe-item name="cpe:/a:acme:loop:1.0" deprecated="true" deprecation_date="2012-03-24T16:01:18.500Z">
+    <title xml:lang="en-US">ACME Loop 1.0</title>
+    <cpe-23:cpe23-item name="cpe:2.3:a:acme:loop:1.0:*:*:*:*:*:*:*">
+      <cpe-23:deprecation date="2012-03-24T12:01:18.500-04:00">
+        <cpe-23:deprecated-by name="cpe:2.3:a:acme:loop:1.0:*:*:*:*:*:*:*" type="NAME_CORRECTION"/>
+      </cpe-23:deprecation>
+    </cpe-23:cpe23-item>
+  </cpe-item>
+</cpe-list>
+`
+	idx, err := Load(strings.NewReader(xmlStr))
+	if err != nil {
+		t.Fatalf("failed to load dictionary: %v", err)
+	}
+	cases := []struct {
+		title string
+		want  []string
+	}{
+		{"Adobe Acrobat 8.0", []string{"cpe:2.3:a:adobe:acrobat:8.0:*:*:*:*:*:*:*"}},
+		{"  adobe   ACROBAT 8.0 ", []string{"cpe:2.3:a:adobe:acrobat:8.0:*:*:*:*:*:*:*"}},
+		{"アドビシステムズ アクロバット 8.0", []string{"cpe:2.3:a:adobe:acrobat:8.0:*:*:*:*:*:*:*"}},
+		{"Adobe Reader 8.0.0", []string{"cpe:2.3:a:adobe:acrobat_reader_dc:8.0:*:*:*:*:*:*:*"}},
+		{"Adobe Acrobat Reader 8.0.0", []string{"cpe:2.3:a:adobe:acrobat_reader_dc:8.0:*:*:*:*:*:*:*"}},
+		{"acrobat reader", []string{"cpe:2.3:a:adobe:acrobat_reader_dc:8.0:*:*:*:*:*:*:*"}},
+		{"Adobe 8.0", []string{
+			"cpe:2.3:a:adobe:acrobat:8.0:*:*:*:*:*:*:*",
+			"cpe:2.3:a:adobe:acrobat_reader_dc:8.0:*:*:*:*:*:*:*",
+		}},
+		{"ACME Loop 1.0", nil},
+		{"Microsoft Windows", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			var got []string
+			for _, attrs := range idx.LookupCPE(c.title) {
+				got = append(got, attrs.BindToFmtString())
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("LookupCPE(%q): expected %v, got %v", c.title, c.want, got)
+			}
+		})
+	}
+}
+
+func TestIndexDeprecatedByWildcard(t *testing.T) {
+	deprecated := NamePattern(*mustParse(t, "cpe:/a:vendor:old:1.0"))
+	depBy := NamePattern(*mustParse(t, "cpe:2.3:a:vendor:new:*:*:*:*:*:*:*:*"))
+	dict := &CPEList{
+		Items: []CPEItem{
+			{
+				Name:       deprecated,
+				Deprecated: true,
+				CPE23: CPE23Item{
+					Name:        deprecated,
+					Deprecation: &Deprecation{DeprecatedBy: []DeprecatedInfo{{Name: depBy}}},
+				},
+				Title: TextType{"en-US": "Vendor Old 1.0"},
+			},
+			{Name: NamePattern(*mustParse(t, "cpe:/a:vendor:new:1.0")), Title: TextType{"en-US": "Vendor New 1.0"}},
+			{Name: NamePattern(*mustParse(t, "cpe:/a:vendor:new:2.0")), Title: TextType{"en-US": "Vendor New 2.0"}},
+		},
+	}
+	var got []string
+	for _, attrs := range NewIndex(dict).LookupCPE("vendor old 1.0") {
+		got = append(got, attrs.BindToFmtString())
+	}
+	want := []string{
+		"cpe:2.3:a:vendor:new:1.0:*:*:*:*:*:*:*",
+		"cpe:2.3:a:vendor:new:2.0:*:*:*:*:*:*:*",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func mustParse(t *testing.T, s string) *wfn.Attributes {
+	attrs, err := wfn.Parse(s)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", s, err)
+	}
+	return attrs
+}
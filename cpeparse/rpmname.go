@@ -16,13 +16,44 @@ package cpeparse
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
-// FieldsFromRPMName returns name, version, release and acrhitecture parsed from RPM package name
-func FieldsFromRPMName(s string) (name, ver, rel, arch string, err error) {
+// RPMNameOptions configures how FromRPMNameWithOptions maps RPM package name to CPE attributes
+type RPMNameOptions struct {
+	// EVR puts [epoch:]version-release into the version attribute, instead of
+	// version into version and release into update
+	EVR bool
+	// StripDist removes the distribution tag (e.g. el8) from the release
+	StripDist bool
+}
+
+// distTag matches a component of RPM release that is the distribution tag, e.g. el8, el7_9, fc34
+var distTag = regexp.MustCompile(`^(el|fc|amzn|ol|mga)[0-9][0-9_]*$`)
+
+// StripRPMDist removes the distribution tag from RPM release, e.g. 4.el8 becomes 4, 1.el8_4.1 becomes 1.1
+// module tags (e.g. 1.module+el8.4.0+10525+4d9e6c29) are removed along with everything that follows them
+func StripRPMDist(rel string) string {
+	parts := strings.Split(rel, ".")
+	stripped := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if strings.HasPrefix(part, "module+") {
+			break
+		}
+		if distTag.MatchString(part) {
+			continue
+		}
+		stripped = append(stripped, part)
+	}
+	return strings.Join(stripped, ".")
+}
+
+// splitRPMName splits RPM package name into name, epoch, version, release and architecture
+// epoch may precede either name (E:N-V-R.A) or version (N-E:V-R.A)
+func splitRPMName(s string) (name, epoch, ver, rel, arch string) {
 	pkg := s
 	// extension
 	if strings.HasSuffix(pkg, ".rpm") {
@@ -30,35 +61,49 @@ func FieldsFromRPMName(s string) (name, ver, rel, arch string, err error) {
 	}
 	// architecture
 	if i := strings.LastIndexByte(pkg, '.'); i != -1 {
-		if arch, err = wfn.WFNize(pkg[i+1:]); err != nil {
-			err = fmt.Errorf("couldn't parse architecture from RPM package name %q: %v", s, err)
-			return
-		}
-		if arch == "noarch" || arch == "src" {
-			arch = wfn.Any
-		}
+		arch = pkg[i+1:]
 		pkg = pkg[:i]
 	}
 	// release
 	if i := strings.LastIndexByte(pkg, '-'); i != -1 {
-		if rel, err = wfn.WFNize(pkg[i+1:]); err != nil {
-			err = fmt.Errorf("couldn't parse release from RPM package name %q: %v", s, err)
-			return
-		}
+		rel = pkg[i+1:]
 		pkg = pkg[:i]
 	}
 	// version
 	if i := strings.LastIndexByte(pkg, '-'); i != -1 {
-		if ver, err = wfn.WFNize(pkg[i+1:]); err != nil {
-			err = fmt.Errorf("couldn't parse version from RPM package name %q: %v", s, err)
-			return
-		}
+		ver = pkg[i+1:]
 		pkg = pkg[:i]
 	}
-	// epoch -- we don't use it
-	i := strings.IndexByte(pkg, ':')
+	if i := strings.IndexByte(ver, ':'); i != -1 {
+		epoch, ver = ver[:i], ver[i+1:]
+	}
 	// name
-	if name, err = wfn.WFNize(strings.ToLower(pkg[i+1:])); err != nil {
+	if i := strings.IndexByte(pkg, ':'); i != -1 {
+		epoch, pkg = pkg[:i], pkg[i+1:]
+	}
+	name = pkg
+	return
+}
+
+// FieldsFromRPMName returns name, version, release and acrhitecture parsed from RPM package name
+func FieldsFromRPMName(s string) (name, ver, rel, arch string, err error) {
+	rawName, _, rawVer, rawRel, rawArch := splitRPMName(s)
+	if arch, err = wfn.WFNize(rawArch); err != nil {
+		err = fmt.Errorf("couldn't parse architecture from RPM package name %q: %v", s, err)
+		return
+	}
+	if arch == "noarch" || arch == "src" {
+		arch = wfn.Any
+	}
+	if rel, err = wfn.WFNize(rawRel); err != nil {
+		err = fmt.Errorf("couldn't parse release from RPM package name %q: %v", s, err)
+		return
+	}
+	if ver, err = wfn.WFNize(rawVer); err != nil {
+		err = fmt.Errorf("couldn't parse version from RPM package name %q: %v", s, err)
+		return
+	}
+	if name, err = wfn.WFNize(strings.ToLower(rawName)); err != nil {
 		err = fmt.Errorf("couldn't parse name from RPM package name %q", s)
 		return
 	}
@@ -67,6 +112,11 @@ func FieldsFromRPMName(s string) (name, ver, rel, arch string, err error) {
 
 // FromRPMName parses CPE name from RPM package name
 func FromRPMName(attr *wfn.Attributes, s string) error {
+	return FromRPMNameWithOptions(attr, s, RPMNameOptions{})
+}
+
+// FromRPMNameWithOptions parses CPE name from RPM package name, as configured by opts
+func FromRPMNameWithOptions(attr *wfn.Attributes, s string, opts RPMNameOptions) error {
 	var err error
 	name, ver, rel, arch, err := FieldsFromRPMName(s)
 	if err != nil {
@@ -78,6 +128,28 @@ func FromRPMName(attr *wfn.Attributes, s string) error {
 	if ver == wfn.Any {
 		return fmt.Errorf("no version found in RPM name %q", s)
 	}
+	if opts.StripDist || opts.EVR {
+		_, epoch, rawVer, rawRel, _ := splitRPMName(s)
+		if opts.StripDist {
+			rawRel = StripRPMDist(rawRel)
+		}
+		if rel, err = wfn.WFNize(rawRel); err != nil {
+			return fmt.Errorf("couldn't parse release from RPM package name %q: %v", s, err)
+		}
+		if opts.EVR {
+			evr := rawVer
+			if epoch != "" {
+				evr = epoch + ":" + evr
+			}
+			if rawRel != "" {
+				evr += "-" + rawRel
+			}
+			if ver, err = wfn.WFNize(evr); err != nil {
+				return fmt.Errorf("couldn't parse version from RPM package name %q: %v", s, err)
+			}
+			rel = wfn.NA
+		}
+	}
 	attr.Part = "a" // TODO: figure out the way to properly detect os packages (linux_kernel or smth)
 	attr.Product = name
 	attr.Version = ver
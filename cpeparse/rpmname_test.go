@@ -32,6 +32,8 @@ func TestFromRPMName(t *testing.T) {
 		{"name-1.0-1.noarch.rpm", "cpe:2.3:a:*:name:1.0:1:*:*:*:*:*:*", false},
 		{"NaMe-1.0-1.i386.rpm", "cpe:2.3:a:*:name:1.0:1:*:*:*:*:i386:*", false},
 		{"NaMe-1.0-1.src.rpm", "cpe:2.3:a:*:name:1.0:1:*:*:*:*:*:*", false},
+		{"name-2:1.0-1.el8.x86_64.rpm", "cpe:2.3:a:*:name:1.0:1.el8:*:*:*:*:x86_64:*", false},
+		{"2:name-1.0-1.el8.x86_64", "cpe:2.3:a:*:name:1.0:1.el8:*:*:*:*:x86_64:*", false},
 	}
 	for _, c := range cases {
 		var attr wfn.Attributes
@@ -52,6 +54,51 @@ func TestFromRPMName(t *testing.T) {
 	}
 }
 
+func TestFromRPMNameWithOptions(t *testing.T) {
+	cases := []struct {
+		pkgName string
+		opts    RPMNameOptions
+		cpe     string
+	}{
+		{"name-1.2.3-4.el8.x86_64.rpm", RPMNameOptions{EVR: true}, `cpe:2.3:a:*:name:1.2.3-4.el8:-:*:*:*:*:x86_64:*`},
+		{"name-2:1.2.3-4.el8.x86_64.rpm", RPMNameOptions{EVR: true}, `cpe:2.3:a:*:name:2\:1.2.3-4.el8:-:*:*:*:*:x86_64:*`},
+		{"2:name-1.2.3-4.el8.x86_64.rpm", RPMNameOptions{EVR: true}, `cpe:2.3:a:*:name:2\:1.2.3-4.el8:-:*:*:*:*:x86_64:*`},
+		{"name-2:1.2.3-4.el8.x86_64.rpm", RPMNameOptions{EVR: true, StripDist: true}, `cpe:2.3:a:*:name:2\:1.2.3-4:-:*:*:*:*:x86_64:*`},
+		{"name-2:1.2.3-4.el8.x86_64.rpm", RPMNameOptions{StripDist: true}, `cpe:2.3:a:*:name:1.2.3:4:*:*:*:*:x86_64:*`},
+		{"name-1.2.3-0.3.rc1.fc34.noarch.rpm", RPMNameOptions{EVR: true, StripDist: true}, `cpe:2.3:a:*:name:1.2.3-0.3.rc1:-:*:*:*:*:*:*`},
+		{"name-1.2.3-0.3.rc1.fc34.noarch.rpm", RPMNameOptions{EVR: true}, `cpe:2.3:a:*:name:1.2.3-0.3.rc1.fc34:-:*:*:*:*:*:*`},
+	}
+	for _, c := range cases {
+		var attr wfn.Attributes
+		if err := FromRPMNameWithOptions(&attr, c.pkgName, c.opts); err != nil {
+			t.Errorf("%q %+v: unexpected failure: %v", c.pkgName, c.opts, err)
+			continue
+		}
+		if s := attr.BindToFmtString(); s != c.cpe {
+			t.Errorf("%q %+v: expected %q got %q", c.pkgName, c.opts, c.cpe, s)
+		}
+	}
+}
+
+func TestStripRPMDist(t *testing.T) {
+	cases := map[string]string{
+		"":                                "",
+		"1":                               "1",
+		"4.el8":                           "4",
+		"3.el7_9":                         "3",
+		"1.el8_4.1":                       "1.1",
+		"0.3.rc1.fc34":                    "0.3.rc1",
+		"2.amzn2.0.1":                     "2.0.1",
+		"1.module+el8.4.0+10525+4d9e6c29": "1",
+		"1.elf":                           "1.elf",
+	}
+	for rel, want := range cases {
+		if have := StripRPMDist(rel); have != want {
+			t.Errorf("StripRPMDist(%q): expected %q got %q", rel, want, have)
+		}
+	}
+}
+
 func BenchmarkFromRPMName(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		var attr wfn.Attributes
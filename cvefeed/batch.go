@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// GetBatch matches each of cpes on its own: the i-th result is what Get would return for cpes[i] alone.
+// The inputs concrete in part, vendor and product are grouped by these attributes, and the candidate entries
+// are looked up in the indices once per group, which saves redundant lookups when matching many versions
+// of the same products. Nil inputs have no results, and the results aren't cached.
+func (c *Cache) GetBatch(cpes []*wfn.Attributes) [][]MatchResult {
+	res, _ := c.GetBatchContext(context.Background(), cpes)
+	return res
+}
+
+// GetBatchContext is like GetBatch, but matching stops as soon as ctx is done, and ctx.Err() is returned then
+func (c *Cache) GetBatchContext(ctx context.Context, cpes []*wfn.Attributes) ([][]MatchResult, error) {
+	results := make([][]MatchResult, len(cpes))
+	var groups []string
+	members := map[string][]int{}
+	for i, cpe := range cpes {
+		if cpe == nil {
+			continue
+		}
+		key, ok := exactKey(cpe)
+		if !ok {
+			// the candidates depend on the attributes which aren't concrete, match it on its own
+			res, err := c.match(ctx, cpes[i:i+1])
+			if err != nil {
+				return nil, err
+			}
+			results[i] = res
+			continue
+		}
+		if _, ok := members[key]; !ok {
+			groups = append(groups, key)
+		}
+		members[key] = append(members[key], i)
+	}
+	for _, key := range groups {
+		idx := members[key]
+		dict := c.candidates(cpes[idx[0] : idx[0]+1])
+		for _, i := range idx {
+			res, err := c.matchDict(ctx, cpes[i:i+1], dict)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = res
+		}
+	}
+	return results, nil
+}
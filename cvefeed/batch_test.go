@@ -0,0 +1,123 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestGetBatch(t *testing.T) {
+	dict, _ := benchmarkDict(100)
+	for _, feed := range []string{testJSONdict, testJSONdictAND} {
+		vulns, err := ParseJSON(bytes.NewBufferString(feed))
+		if err != nil {
+			t.Fatalf("failed to parse the dictionary: %v", err)
+		}
+		for _, v := range vulns {
+			dict[v.ID()] = v
+		}
+	}
+	var cpes []*wfn.Attributes
+	for _, version := range []string{"0\\.9", "1\\.0", "2\\.0", "3\\.1", wfn.Any} {
+		for i := 0; i < 20; i++ {
+			cpes = append(cpes, &wfn.Attributes{Part: "a", Vendor: fmt.Sprintf("vendor%d", i%5), Product: fmt.Sprintf("product%d", i%5), Version: version})
+		}
+	}
+	cpes = append(cpes,
+		&wfn.Attributes{Part: "a", Vendor: "adobe", Product: "flash_player", Version: "28\\.0\\.0\\.137"},
+		&wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "windows"},
+		&wfn.Attributes{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.4"},
+		&wfn.Attributes{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.0"},
+		// not concrete, matched on their own
+		&wfn.Attributes{Part: "a", Vendor: wfn.Any, Product: "ie", Version: "5\\.4"},
+		&wfn.Attributes{Part: "a", Vendor: "vendor1", Product: "product*", Version: "1\\.0"},
+		nil,
+	)
+
+	indices := map[string]func(*Cache){
+		"none":  func(*Cache) {},
+		"index": func(c *Cache) { c.Idx = NewIndex(c.Dict) },
+		"exact": func(c *Cache) { c.ExactIdx = NewExactIndex(c.Dict) },
+	}
+	for name, setIndex := range indices {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(dict).SetMaxSize(-1)
+			setIndex(cache)
+			results := cache.GetBatch(cpes)
+			if len(results) != len(cpes) {
+				t.Fatalf("expected %d results, got %d", len(cpes), len(results))
+			}
+			var matched int
+			for i, cpe := range cpes {
+				var expect []string
+				if cpe != nil {
+					expect = matchIDs(cache.Get([]*wfn.Attributes{cpe}))
+				}
+				if got := matchIDs(results[i]); fmt.Sprint(got) != fmt.Sprint(expect) {
+					t.Errorf("%v: got %v, expected %v", cpe, got, expect)
+				}
+				if len(results[i]) != 0 {
+					matched++
+				}
+			}
+			if matched == 0 {
+				t.Fatal("nothing matched")
+			}
+		})
+	}
+}
+
+func TestGetBatchContext(t *testing.T) {
+	dict, inputs := benchmarkDict(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res, err := NewCache(dict).GetBatchContext(ctx, inputs[0]); err != context.Canceled || res != nil {
+		t.Fatalf("expected %v, got %v, %v", context.Canceled, res, err)
+	}
+}
+
+// benchmarkBatch matches 500 versions of 10 products, each indexed with 500 entries
+func benchmarkBatch(b *testing.B, match func(*Cache, []*wfn.Attributes)) {
+	dict, _ := benchmarkDict(5000)
+	cache := NewCache(dict).SetMaxSize(-1)
+	cache.Idx = NewIndex(dict)
+	var cpes []*wfn.Attributes
+	for i := 0; i < 500; i++ {
+		cpes = append(cpes, &wfn.Attributes{Part: "a", Vendor: fmt.Sprintf("vendor%d", i%10), Product: fmt.Sprintf("product%d", i%10), Version: fmt.Sprintf("1\\.%d", i)})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		match(cache, cpes)
+	}
+}
+
+func BenchmarkGetOneByOne(b *testing.B) {
+	benchmarkBatch(b, func(c *Cache, cpes []*wfn.Attributes) {
+		for _, cpe := range cpes {
+			c.Get([]*wfn.Attributes{cpe})
+		}
+	})
+}
+
+func BenchmarkGetBatch(b *testing.B) {
+	benchmarkBatch(b, func(c *Cache, cpes []*wfn.Attributes) {
+		c.GetBatch(cpes)
+	})
+}
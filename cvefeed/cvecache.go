@@ -16,11 +16,13 @@ package cvefeed
 
 import (
 	"bytes"
+	"context"
+	"sort"
 	"sync"
 	"unsafe"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
-	"github.com/facebookincubator/flog"
 )
 
 const cacheEvictPercentage = 0.1 // every eviction cycle invalidates this part of cache size at once
@@ -32,24 +34,54 @@ type Index map[string][]Vuln
 func NewIndex(d Dictionary) Index {
 	idx := Index{}
 	for _, entry := range d {
-		set := map[string]bool{}
-		for _, cpe := range entry.Config() {
-			// Can happen, for instance, when the feed contains illegal binding of CPE name. Unfortunately, it happens to NVD,
-			// e.g. embedded ? in cpe:2.3:a:disney:where\\'s_my_perry?_free:1.5.1:*:*:*:*:android:*:* of CVE-2014-5606
-			if cpe == nil {
-				continue
-			}
-			product := cpe.Product
-			if wfn.HasWildcard(product) {
-				product = wfn.Any
-			}
-			if !set[product] {
-				set[product] = true
-				idx[product] = append(idx[product], entry)
+		idx.Add(entry)
+	}
+	return idx
+}
+
+// Add adds the entry to the index under all the products it mentions
+func (idx Index) Add(entry Vuln) {
+	for product := range indexKeys(entry) {
+		idx[product] = append(idx[product], entry)
+	}
+}
+
+// Remove removes the entry with the same ID as entry from the index.
+// The entry is expected to be the one which was added: it's only looked up under the products it mentions.
+func (idx Index) Remove(entry Vuln) {
+	id := entry.ID()
+	for product := range indexKeys(entry) {
+		entries := idx[product]
+		for i, e := range entries {
+			if e.ID() == id {
+				entries = append(entries[:i], entries[i+1:]...)
+				break
 			}
 		}
+		if len(entries) == 0 {
+			delete(idx, product)
+		} else {
+			idx[product] = entries
+		}
 	}
-	return idx
+}
+
+// indexKeys returns a set of products the entry is indexed by
+func indexKeys(entry Vuln) map[string]bool {
+	set := map[string]bool{}
+	for _, cpe := range entry.Config() {
+		// Can happen, for instance, when the feed contains illegal binding of CPE name. Unfortunately, it happens to NVD,
+		// e.g. embedded ? in cpe:2.3:a:disney:where\\'s_my_perry?_free:1.5.1:*:*:*:*:android:*:* of CVE-2014-5606
+		if cpe == nil {
+			continue
+		}
+		product := cpe.Product
+		if wfn.HasWildcard(product) {
+			product = wfn.Any
+		}
+		set[product] = true
+	}
+	return set
 }
 
 // MatchResult stores CVE and a slice of CPEs that matched it
@@ -62,6 +94,7 @@ type MatchResult struct {
 type cachedCVEs struct {
 	res           []MatchResult
 	ready         chan struct{}
+	err           error // set if computing the value was cancelled
 	size          int64
 	evictionIndex int // position in eviction queue
 }
@@ -100,6 +133,10 @@ type Cache struct {
 	RequireVersion bool  // ignore matching specifications that have Version == ANY
 	MaxSize        int64 // maximum size of the cache, 0 -- unlimited, -1 -- no caching
 	size           int64 // current size of the cache
+	// ExactIdx, if set, is used instead of Idx to look up the entries for CPEs concrete in part, vendor and product
+	ExactIdx ExactIndex
+	// Logger, if set, receives the problems found while matching, they are discarded otherwise
+	Logger logging.Logger
 }
 
 // NewCache creates new Cache instance with dictionary dict.
@@ -115,6 +152,13 @@ func (c *Cache) SetRequireVersion(requireVersion bool) *Cache {
 	return c
 }
 
+// SetLogger sets the logger which receives the problems found while matching.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetLogger(logger logging.Logger) *Cache {
+	c.Logger = logger
+	return c
+}
+
 // SetMaxSize sets maximum size of the cache to some pre-defined value,
 // size of 0 disables eviction (makes the cache grow indefinitely),
 // negative size disables caching.
@@ -127,9 +171,16 @@ func (c *Cache) SetMaxSize(size int64) *Cache {
 // Get returns slice of CVEs for CPE names from cpes parameter;
 // if CVEs aren't cached (and the feature is enabled) it finds them in cveDict and caches the results
 func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
+	res, _ := c.GetContext(context.Background(), cpes)
+	return res
+}
+
+// GetContext is like Get, but matching stops as soon as ctx is done, between the entries of the dictionary,
+// and ctx.Err() is returned then; results of the cancelled matching aren't cached.
+func (c *Cache) GetContext(ctx context.Context, cpes []*wfn.Attributes) ([]MatchResult, error) {
 	// negative max size of the cache disables caching
 	if c.MaxSize < 0 {
-		return c.match(cpes)
+		return c.match(ctx, cpes)
 	}
 
 	// otherwise, let's get to the business
@@ -142,18 +193,35 @@ func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
 	if cves != nil {
 		// value is being computed, wait till ready
 		c.mu.Unlock()
-		<-cves.ready
+		select {
+		case <-cves.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if cves.err != nil {
+			// the goroutine computing the value was cancelled and dropped it, try computing it again
+			return c.GetContext(ctx, cpes)
+		}
 		c.mu.Lock() // TODO: XXX: ugly, consider using atomic.Value instead
 		cves.evictionIndex = c.evictionQ.touch(cves.evictionIndex)
 		c.mu.Unlock()
-		return cves.res
+		return cves.res, nil
 	}
 	// first request; the goroutine that sent it computes the value
 	cves = &cachedCVEs{ready: make(chan struct{})}
 	c.data[key] = cves
 	c.mu.Unlock()
 	// now other requests for same key wait on the channel, and the requests for the different keys aren't blocked
-	cves.res = c.match(cpes)
+	res, err := c.match(ctx, cpes)
+	if err != nil {
+		c.mu.Lock()
+		delete(c.data, key)
+		c.mu.Unlock()
+		cves.err = err
+		close(cves.ready)
+		return nil, err
+	}
+	cves.res = res
 	cves.updateResSize(key)
 	c.mu.Lock()
 	c.size += cves.size
@@ -163,16 +231,25 @@ func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
 	cves.evictionIndex = c.evictionQ.push(key)
 	c.mu.Unlock()
 	close(cves.ready)
-	return cves.res
+	return cves.res, nil
 }
 
 // match will return all match results based on the given cpes
-func (c *Cache) match(cpes []*wfn.Attributes) []MatchResult {
-	d := c.Dict
+func (c *Cache) match(ctx context.Context, cpes []*wfn.Attributes) ([]MatchResult, error) {
+	return c.matchDict(ctx, cpes, c.candidates(cpes))
+}
+
+// candidates returns the entries which may match cpes: those found in the indices, if set, or the whole dictionary
+func (c *Cache) candidates(cpes []*wfn.Attributes) Dictionary {
+	if c.ExactIdx != nil {
+		if d, ok := c.dictFromExactIndex(cpes); ok {
+			return d
+		}
+	}
 	if c.Idx != nil {
-		d = c.dictFromIndex(cpes)
+		return c.dictFromIndex(cpes)
 	}
-	return c.matchDict(cpes, d)
+	return c.Dict
 }
 
 // dictFromIndex creates CVE dictionary from entries indexed by CPE names
@@ -194,7 +271,7 @@ func (c *Cache) dictFromIndex(cpes []*wfn.Attributes) Dictionary {
 
 	for _, cpe := range cpes {
 		if cpe == nil { // should never happen
-			flog.Warning("nil CPE in list")
+			logging.OrNop(c.Logger).Warnf("nil CPE in list")
 			continue
 		}
 		if cpe.Product != wfn.Any {
@@ -206,14 +283,38 @@ func (c *Cache) dictFromIndex(cpes []*wfn.Attributes) Dictionary {
 	return d
 }
 
-// match matches the CPE names against internal vulnerability dictionary and returns a slice of matching resutls
-func (c *Cache) matchDict(cpes []*wfn.Attributes, dict Dictionary) (results []MatchResult) {
+// match matches the CPE names against internal vulnerability dictionary and returns a slice of matching resutls.
+// Every vulnerability is reported once, with the distinct CPEs which matched it via any of its configuration
+// branches; results are sorted by CVE ID. Matching stops with ctx.Err() as soon as ctx is done.
+func (c *Cache) matchDict(ctx context.Context, cpes []*wfn.Attributes, dict Dictionary) ([]MatchResult, error) {
+	var results []MatchResult
 	for _, v := range dict {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if matches := v.Match(cpes, c.RequireVersion); len(matches) > 0 {
-			results = append(results, MatchResult{v, matches})
+			results = append(results, MatchResult{v, uniqueAttrs(matches)})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CVE.ID() < results[j].CVE.ID() })
+	return results, nil
+}
+
+// uniqueAttrs removes the attributes equal to the preceding ones, e.g. the same CPE listed twice in the input
+func uniqueAttrs(attrs []*wfn.Attributes) []*wfn.Attributes {
+	if len(attrs) < 2 {
+		return attrs
+	}
+	seen := make(map[wfn.Attributes]bool, len(attrs))
+	unique := attrs[:0]
+	for _, attr := range attrs {
+		if attr == nil || seen[*attr] {
+			continue
 		}
+		seen[*attr] = true
+		unique = append(unique, attr)
 	}
-	return results
+	return unique
 }
 
 // evict the least recently used records untile nbytes of capacity is achieved or no more records left.
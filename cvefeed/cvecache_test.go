@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// cancelingVuln cancels the context as soon as it's matched against
+type cancelingVuln struct {
+	Vuln
+	cancel context.CancelFunc
+}
+
+func (v cancelingVuln) Match(attrs []*wfn.Attributes, requireVersion bool) []*wfn.Attributes {
+	v.cancel()
+	return v.Vuln.Match(attrs, requireVersion)
+}
+
+func TestCacheGetContext(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdict))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	inventory := []*wfn.Attributes{{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.4"}}
+	expect := len(NewCache(dict).Get(inventory))
+	if expect == 0 {
+		t.Fatal("test inventory doesn't match the dictionary")
+	}
+
+	for _, size := range []int64{0, -1} {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		canceling := make(Dictionary, len(dict))
+		for id, v := range dict {
+			canceling[id] = cancelingVuln{v, cancel}
+		}
+		cache := NewCache(canceling).SetMaxSize(size)
+		// the context is cancelled by the first entry matched
+		if res, err := cache.GetContext(ctx, inventory); err != context.Canceled || res != nil {
+			t.Fatalf("max size %d: expected %v, got %d results, %v", size, context.Canceled, len(res), err)
+		}
+		if len(cache.data) != 0 {
+			t.Fatalf("max size %d: results of cancelled matching were cached", size)
+		}
+		if res, err := cache.GetContext(context.Background(), inventory); err != nil || len(res) != expect {
+			t.Fatalf("max size %d: expected %d results, got %d, %v", size, expect, len(res), err)
+		}
+	}
+}
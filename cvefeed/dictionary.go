@@ -15,11 +15,17 @@
 package cvefeed
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"os"
-	"strings"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
 )
 
 // Dictionary is a slice of entries
@@ -41,45 +47,391 @@ func (d *Dictionary) Override(d2 Dictionary) {
 	}
 }
 
+// Merge updates Dictionary d to match the Dictionary d2, e.g. a freshly loaded version of the same feeds:
+// entries of d2 replace entries of d with the same ID, new entries are added and entries that aren't in d2 are dropped.
+// If idx is not nil, it's expected to be built from d and it's updated only for the entries that changed.
+// Merge doesn't do any locking: the caller must guarantee exclusive (write) access to d and idx while it runs.
+// Results cached by a Cache using d are not invalidated, so a new Cache should be created afterwards.
+func (d *Dictionary) Merge(d2 Dictionary, idx Index) {
+	if d == nil {
+		return
+	}
+	if *d == nil {
+		*d = make(Dictionary)
+	}
+	for k, cve := range *d {
+		if _, ok := d2[k]; !ok {
+			delete(*d, k)
+			if idx != nil {
+				idx.Remove(cve)
+			}
+		}
+	}
+	for k, cve := range d2 {
+		if old, ok := (*d)[k]; ok {
+			if old == cve {
+				continue
+			}
+			if idx != nil {
+				idx.Remove(old)
+			}
+		}
+		(*d)[k] = cve
+		if idx != nil {
+			idx.Add(cve)
+		}
+	}
+}
+
+// RangeMatch is a cpe_match entry of a vulnerability configuration which matched a CPE
+type RangeMatch struct {
+	ID                    string `json:"id"`
+	CPE                   string `json:"cpe"`
+	VersionStartIncluding string `json:"version_start_including,omitempty"`
+	VersionStartExcluding string `json:"version_start_excluding,omitempty"`
+	VersionEndIncluding   string `json:"version_end_including,omitempty"`
+	VersionEndExcluding   string `json:"version_end_excluding,omitempty"`
+}
+
+// cpeMatchReporter is implemented by vulnerabilities which can tell which of their cpe_match entries matched
+type cpeMatchReporter interface {
+	MatchedCPEs(attr *wfn.Attributes, requireVersion bool) []*schema.NVDCVEFeedJSON10DefCPEMatch
+}
+
+// MatchingRanges returns the cpe_match entries, with their version bounds, of all vulnerabilities in d matching cpe.
+// Only the vulnerabilities whose whole configuration matches cpe are considered; each of their vulnerable
+// cpe_match entries which matches on its own is reported, so the same ID may appear more than once.
+// Results are sorted by ID, entries of the same vulnerability are kept in the feed order.
+func (d Dictionary) MatchingRanges(cpe *wfn.Attributes) []RangeMatch {
+	if cpe == nil {
+		return nil
+	}
+	attrs := []*wfn.Attributes{cpe}
+	var ranges []RangeMatch
+	for id, v := range d {
+		if len(v.Match(attrs, false)) == 0 {
+			continue
+		}
+		if r, ok := unwrapOverrides(v).(cpeMatchReporter); ok {
+			ranges = append(ranges, rangeMatches(id, r, cpe, false)...)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].ID < ranges[j].ID })
+	return ranges
+}
+
+// rangeMatches returns the cpe_match entries of the vulnerability id reported by r as matching cpe
+func rangeMatches(id string, r cpeMatchReporter, cpe *wfn.Attributes, requireVersion bool) []RangeMatch {
+	var ranges []RangeMatch
+	for _, m := range r.MatchedCPEs(cpe, requireVersion) {
+		uri := m.Cpe23Uri
+		if uri == "" {
+			uri = m.Cpe22Uri
+		}
+		ranges = append(ranges, RangeMatch{
+			ID:                    id,
+			CPE:                   uri,
+			VersionStartIncluding: m.VersionStartIncluding,
+			VersionStartExcluding: m.VersionStartExcluding,
+			VersionEndIncluding:   m.VersionEndIncluding,
+			VersionEndExcluding:   m.VersionEndExcluding,
+		})
+	}
+	return ranges
+}
+
+// BestMatchingRanges is like MatchingRanges, but reports every vulnerability at most once, with the strongest
+// of its cpe_match entries which matched cpe: the one naming the exact version, or else the one with the most
+// version bounds. Of the equally strong entries the first one in the feed order is chosen.
+func (d Dictionary) BestMatchingRanges(cpe *wfn.Attributes) []RangeMatch {
+	return bestRanges(d.MatchingRanges(cpe))
+}
+
+// bestRanges keeps the strongest of the consecutive entries of the same vulnerability
+func bestRanges(ranges []RangeMatch) []RangeMatch {
+	var best []RangeMatch
+	for _, r := range ranges {
+		if n := len(best); n != 0 && best[n-1].ID == r.ID {
+			if r.strength() > best[n-1].strength() {
+				best[n-1] = r
+			}
+			continue
+		}
+		best = append(best, r)
+	}
+	return best
+}
+
+// strength ranks how precisely the entry matches: an exact version is stronger than a range,
+// a range with both bounds is stronger than the one with a single bound, which is stronger than any version
+func (r RangeMatch) strength() int {
+	bounds := 0
+	for _, b := range []string{r.VersionStartIncluding, r.VersionStartExcluding, r.VersionEndIncluding, r.VersionEndExcluding} {
+		if b != "" {
+			bounds++
+		}
+	}
+	if bounds == 0 {
+		if attr, err := wfn.Parse(r.CPE); err == nil && attr.Version != wfn.Any && attr.Version != wfn.NA {
+			return 3
+		}
+	}
+	return bounds
+}
+
+// Each calls fn for every entry of d in the order of their IDs, until fn returns false
+func (d Dictionary) Each(fn func(id string, v Vuln) bool) {
+	ids := make([]string, 0, len(d))
+	for id := range d {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !fn(id, d[id]) {
+			return
+		}
+	}
+}
+
+// versionComparatorSetter is implemented by vulnerabilities which can compare versions with custom comparators
+type versionComparatorSetter interface {
+	SetVersionComparator(vendor, product string, cmp nvd.VersionComparator)
+}
+
+// aliasAdder is implemented by vulnerabilities which can match CPE names under another name
+type aliasAdder interface {
+	AddAlias(from, to *wfn.Attributes)
+}
+
+// AddAlias makes all entries of d match the CPE names matching from in part, vendor and product also as if
+// these attributes were the ones of to, e.g. the vendor name used in the inventory also as the one used by NVD;
+// ANY attributes of from match any value and ANY attributes of to leave the value as is, see nvd.Vuln.AddAlias.
+// It must be called before d is used for matching and the indexes are built from it: results cached by a Cache
+// using d are not invalidated.
+func (d Dictionary) AddAlias(from, to *wfn.Attributes) {
+	var add func(v Vuln)
+	add = func(v Vuln) {
+		if o, ok := v.(*overriden); ok {
+			add(o.Vuln)
+			add(o.override)
+			return
+		}
+		if a, ok := v.(aliasAdder); ok {
+			a.AddAlias(from, to)
+		}
+	}
+	for _, v := range d {
+		add(v)
+	}
+}
+
+// versionUnknownPolicySetter is implemented by vulnerabilities which can be told how to match unknown versions
+type versionUnknownPolicySetter interface {
+	SetVersionUnknownPolicy(p nvd.VersionUnknownPolicy)
+}
+
+// SetVersionUnknownPolicy sets how all entries of d are matched by the CPE names of unknown (ANY) version.
+// It must be called before d is used for matching: results cached by a Cache using d are not invalidated.
+func (d Dictionary) SetVersionUnknownPolicy(p nvd.VersionUnknownPolicy) {
+	var set func(v Vuln)
+	set = func(v Vuln) {
+		if o, ok := v.(*overriden); ok {
+			set(o.Vuln)
+			set(o.override)
+			return
+		}
+		if s, ok := v.(versionUnknownPolicySetter); ok {
+			s.SetVersionUnknownPolicy(p)
+		}
+	}
+	for _, v := range d {
+		set(v)
+	}
+}
+
+// SetVersionComparator makes all entries of d compare the versions of the given vendor and product with cmp
+// when matching version ranges, instead of nvd.CompareSmartVersions; nil cmp restores the default.
+// Vendor and product are WFN attribute values, as in the feed, e.g. "microsoft" and "internet_explorer".
+// It must be called before d is used for matching: results cached by a Cache using d are not invalidated.
+func (d Dictionary) SetVersionComparator(vendor, product string, cmp nvd.VersionComparator) {
+	var set func(v Vuln)
+	set = func(v Vuln) {
+		if o, ok := v.(*overriden); ok {
+			set(o.Vuln)
+			set(o.override)
+			return
+		}
+		if s, ok := v.(versionComparatorSetter); ok {
+			s.SetVersionComparator(vendor, product, cmp)
+		}
+	}
+	for _, v := range d {
+		set(v)
+	}
+}
+
+// DictionaryOptions control which of the loaded entries are kept in a Dictionary
+type DictionaryOptions struct {
+	// IncludeRejected keeps the entries marked as rejected by NVD (see Vuln.Rejected), they are dropped by default
+	IncludeRejected bool
+	// ModifiedSince, if set, drops the entries last modified before it; the entries which don't tell
+	// when they were last modified are kept
+	ModifiedSince time.Time
+	// IncludeCWEs, if set, keeps only the entries with any of these problem types (see Vuln.CWEs), e.g. "CWE-89";
+	// values are compared as they are
+	IncludeCWEs []string
+	// Lenient skips the entries which don't follow the feed schema instead of failing the whole feed, see ParseJSONLenient;
+	// the dictionary is returned along with their errors
+	Lenient bool
+	// MatchCriteria, if set, resolves the cpeMatch entries of NVD CVE API 2.0 responses which reference
+	// the match criteria of the CPE Match Feed by their matchCriteriaId, see LoadMatchCriteria
+	MatchCriteria MatchCriteria
+	// Logger, if set, receives the problems found in the configurations of the loaded entries,
+	// e.g. unknown node operators; they are discarded otherwise
+	Logger logging.Logger
+	// MaxConfigurationDepth limits the nesting of configuration nodes of the entries, the nodes nested deeper
+	// are skipped; nvd.DefaultMaxConfigurationDepth if not positive
+	MaxConfigurationDepth int
+	// Workers limits how many feeds are loaded at once, all of them are if not positive
+	Workers int
+}
+
+// modificationReporter is implemented by vulnerabilities which know when they were last modified
+type modificationReporter interface {
+	LastModified() time.Time
+}
+
+// keep returns true if v should be kept in the Dictionary loaded with opts
+func (opts DictionaryOptions) keep(v Vuln) bool {
+	if !opts.IncludeRejected && v.Rejected() {
+		return false
+	}
+	if len(opts.IncludeCWEs) != 0 && !hasAny(v.CWEs(), opts.IncludeCWEs) {
+		return false
+	}
+	if !opts.ModifiedSince.IsZero() {
+		if r, ok := unwrapOverrides(v).(modificationReporter); ok {
+			if t := r.LastModified(); !t.IsZero() && t.Before(opts.ModifiedSince) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasAny returns true if any of values is in set
+func hasAny(values, set []string) bool {
+	for _, v := range values {
+		for _, s := range set {
+			if v == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // LoadJSONDictionary parses dictionary from multiple NVD vulenrability feed JSON files
 func LoadJSONDictionary(paths ...string) (Dictionary, error) {
-	return LoadFeed(loadJSONFile, paths...)
+	return LoadJSONDictionaryWithOptions(DictionaryOptions{}, paths...)
+}
+
+// LoadJSONDictionaryWithOptions is like LoadJSONDictionary, but entries are filtered according to opts.
+// Entries are filtered as soon as they're parsed, so the ones which are dropped are never held in memory together.
+func LoadJSONDictionaryWithOptions(opts DictionaryOptions, paths ...string) (Dictionary, error) {
+	return LoadJSONDictionaryContext(context.Background(), opts, paths...)
+}
+
+// LoadJSONDictionaryContext is like LoadJSONDictionaryWithOptions, but loading is aborted as soon as ctx is done:
+// all feeds stop being parsed before their next entry and ctx.Err() is returned.
+// Identical cpe_match criteria of the entries of all feeds are kept in memory once, see nvd.Interner.
+func LoadJSONDictionaryContext(ctx context.Context, opts DictionaryOptions, paths ...string) (Dictionary, error) {
+	convert := nvd.ConvertOptions{Logger: opts.Logger, Interner: &nvd.Interner{}, MaxConfigurationDepth: opts.MaxConfigurationDepth}
+	return loadFeed(ctx, func(path string) ([]Vuln, error) {
+		return loadJSONFileFunc(ctx, parseOptions{path: path, keep: opts.keep, lenient: opts.Lenient, criteria: opts.MatchCriteria, convert: convert})
+	}, opts, paths...)
 }
 
 // LoadFeed calls loadFunc for each file in paths and returns the combined outputs in a Dictionary.
+// Rejected entries are dropped. The errors of the feeds which failed, or of the entries skipped in lenient mode,
+// are returned as FeedErrors, along with the entries loaded nonetheless.
 func LoadFeed(loadFunc func(string) ([]Vuln, error), paths ...string) (Dictionary, error) {
+	return LoadFeedWithOptions(loadFunc, DictionaryOptions{}, paths...)
+}
+
+// LoadFeedWithOptions is like LoadFeed, but entries are filtered according to opts
+func LoadFeedWithOptions(loadFunc func(string) ([]Vuln, error), opts DictionaryOptions, paths ...string) (Dictionary, error) {
+	return loadFeed(context.Background(), loadFunc, opts, paths...)
+}
+
+// loadFeed is like LoadFeedWithOptions, but returns ctx.Err() if ctx is done by the time all feeds are loaded;
+// loadFunc is expected to return promptly after that.
+// Feeds are loaded concurrently, opts.Workers at a time. Entries with the same ID, e.g. found in a yearly feed and
+// in the modified one, resolve to the most recently modified one; of the equally recent ones (or the ones which
+// don't tell when they were last modified) the one loaded from the latest of paths, or later in the same feed, wins.
+func loadFeed(ctx context.Context, loadFunc func(string) ([]Vuln, error), opts DictionaryOptions, paths ...string) (Dictionary, error) {
+	type loadedFeed struct {
+		at   int // index of the feed in paths
+		vuln []Vuln
+	}
+	type loadedVuln struct {
+		at       int
+		modified time.Time
+	}
 	dict := make(Dictionary)
 	var wg sync.WaitGroup
 	done := make(chan struct{})
 	errDone := make(chan struct{})
-	dictChan := make(chan []Vuln, 1)
+	dictChan := make(chan loadedFeed, 1)
 	errChan := make(chan error, 1)
-	for _, path := range paths {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = len(paths)
+	}
+	sem := make(chan struct{}, workers)
+	for at, path := range paths {
 		wg.Add(1)
-		go func(path string) {
+		go func(at int, path string) {
 			defer wg.Done()
+			sem <- struct{}{}
 			feed, err := loadFunc(path)
-			if err != nil {
-				errChan <- fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
-				return
+			<-sem
+			for _, err := range feedLoadErrors(path, err) {
+				errChan <- err
 			}
-			dictChan <- feed
-		}(path)
+			// the entries of the feed loaded leniently come along with the errors of the dropped ones
+			if feed != nil {
+				dictChan <- loadedFeed{at, feed}
+			}
+		}(at, path)
 	}
 	go func() {
+		loaded := make(map[string]loadedVuln)
 		for d := range dictChan {
-			for _, cve := range d {
-				if cveid := cve.ID(); cveid != "" {
-					dict[cveid] = cve
+			for _, cve := range d.vuln {
+				if !opts.keep(cve) {
+					continue
+				}
+				cveid := cve.ID()
+				if cveid == "" {
+					continue
+				}
+				modified := LastModified(cve)
+				if prev, ok := loaded[cveid]; ok {
+					if modified.Before(prev.modified) || modified.Equal(prev.modified) && d.at < prev.at {
+						continue
+					}
 				}
+				loaded[cveid] = loadedVuln{d.at, modified}
+				dict[cveid] = cve
 			}
 		}
 		close(done)
 	}()
-	var errs []string
+	var errs []error
 	go func() {
 		for e := range errChan {
-			errs = append(errs, e.Error())
+			errs = append(errs, e)
 		}
 		close(errDone)
 	}()
@@ -88,18 +440,50 @@ func LoadFeed(loadFunc func(string) ([]Vuln, error), paths ...string) (Dictionar
 	close(errChan)
 	<-done
 	<-errDone
-	if len(errs) > 0 {
-		return dict, errors.New(strings.Join(errs, "\n"))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return dict, feedErrors(errs)
+}
+
+// feedLoadErrors returns the errors of loading the feed at path, with FeedErrors flattened; *FeedFormatError and
+// *FeedIOError tell the path of the feed, the other errors are prefixed with it
+func feedLoadErrors(path string, err error) []error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case FeedErrors:
+		var errs []error
+		for _, err := range e {
+			errs = append(errs, feedLoadErrors(path, err)...)
+		}
+		return errs
+	case *FeedFormatError:
+		if e.Path == "" {
+			withPath := *e
+			withPath.Path = path
+			return []error{&withPath}
+		}
+		return []error{e}
+	case *FeedIOError:
+		if e.Path == "" {
+			withPath := *e
+			withPath.Path = path
+			return []error{&withPath}
+		}
+		return []error{e}
 	}
-	return dict, nil
+	return []error{fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)}
 }
 
-// loadJSONFile parses dictionary from NVD vulnerability feed JSON file
-func loadJSONFile(path string) ([]Vuln, error) {
-	f, err := os.Open(path)
+// loadJSONFileFunc parses dictionary from NVD vulnerability feed JSON file at opts.path, as per opts,
+// and stops parsing when ctx is done.
+// The file is decompressed on the fly if it's gzip-compressed, which is detected by the gzip header.
+func loadJSONFileFunc(ctx context.Context, opts parseOptions) ([]Vuln, error) {
+	f, err := os.Open(opts.path)
 	if err != nil {
-		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+		return nil, &FeedIOError{Path: opts.path, Err: err}
 	}
 	defer f.Close()
-	return ParseJSON(f)
+	return parseJSONFiltered(ctx, f, opts)
 }
@@ -0,0 +1,1025 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestDictionaryMerge(t *testing.T) {
+	load := func(feed string) Dictionary {
+		dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+			return ParseJSON(bytes.NewBufferString(feed))
+		}, "")
+		if err != nil {
+			t.Fatalf("could not load test JSON feed: %v", err)
+		}
+		return dict
+	}
+	dict := load(testJSONdict)
+	idx := NewIndex(dict)
+	unchanged := dict["TESTVE-2018-0002"]
+	update := load(testJSONdictUpdate)
+	update["TESTVE-2018-0002"] = unchanged
+
+	dict.Merge(update, idx)
+
+	if len(dict) != 2 {
+		t.Fatalf("expected 2 entries after merge, got %d", len(dict))
+	}
+	if _, ok := dict["CVE-2002-2436"]; ok {
+		t.Fatal("entry missing from the new feed wasn't dropped")
+	}
+	if dict["TESTVE-2018-0002"] != unchanged {
+		t.Fatal("unchanged entry was replaced")
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "a", Vendor: "microsoft", Product: "edge", Version: "1\\.0"},
+	}
+	if mm := dict["TESTVE-2018-0001"].Match(inventory, false); len(mm) != 1 {
+		t.Fatal("modified entry wasn't replaced")
+	}
+
+	// incrementally updated index should be the same as the one built from scratch
+	if got, expect := indexIDs(idx), indexIDs(NewIndex(dict)); !equalIndexIDs(got, expect) {
+		t.Fatalf("index wasn't updated correctly:\ngot %v\nexpected %v", got, expect)
+	}
+}
+
+func TestDictionaryMatchingRanges(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictRanges))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	cases := []struct {
+		name   string
+		cpe    *wfn.Attributes
+		expect []RangeMatch
+	}{
+		{
+			name: "several ranges of the same CVE",
+			cpe:  &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "1\\.3"},
+			expect: []RangeMatch{
+				{ID: "TESTVE-2019-0001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0", VersionEndExcluding: "1.5"},
+				{ID: "TESTVE-2019-0001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartExcluding: "1.2", VersionEndIncluding: "1.4"},
+				{ID: "TESTVE-2019-0002", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionEndIncluding: "1.3"},
+			},
+		},
+		{
+			name: "single range",
+			cpe:  &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "2\\.1"},
+			expect: []RangeMatch{
+				{ID: "TESTVE-2019-0001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "2.0", VersionEndExcluding: "3.0"},
+			},
+		},
+		{
+			name: "no match",
+			cpe:  &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "5\\.0"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := dict.MatchingRanges(c.cpe); !reflect.DeepEqual(got, c.expect) {
+				t.Fatalf("got\n%+v\nexpected\n%+v", got, c.expect)
+			}
+		})
+	}
+}
+
+func TestDictionaryBestMatchingRanges(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictRanges))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	cpe := &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "1\\.3"}
+	expect := []RangeMatch{
+		{ID: "TESTVE-2019-0001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0", VersionEndExcluding: "1.5"},
+		{ID: "TESTVE-2019-0002", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionEndIncluding: "1.3"},
+	}
+	if got := dict.BestMatchingRanges(cpe); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got\n%+v\nexpected\n%+v", got, expect)
+	}
+
+	// TESTVE-2019-0001 is reachable by two nodes and the inventory lists the same CPE twice
+	results := NewCache(dict).Get([]*wfn.Attributes{cpe, cpe})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for i, id := range []string{"TESTVE-2019-0001", "TESTVE-2019-0002"} {
+		if got := results[i].CVE.ID(); got != id {
+			t.Errorf("result %d: expected %s, got %s", i, id, got)
+		}
+		if len(results[i].CPEs) != 1 {
+			t.Errorf("result %d: expected 1 matching CPE, got %d", i, len(results[i].CPEs))
+		}
+	}
+}
+
+func TestDictionaryRejected(t *testing.T) {
+	load := func(opts DictionaryOptions) Dictionary {
+		dict, err := LoadFeedWithOptions(func(_ string) ([]Vuln, error) {
+			return ParseJSON(bytes.NewBufferString(testJSONdictRejected))
+		}, opts, "")
+		if err != nil {
+			t.Fatalf("could not load test JSON feed: %v", err)
+		}
+		return dict
+	}
+	dict := load(DictionaryOptions{})
+	if len(dict) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(dict))
+	}
+	if _, ok := dict["TESTVE-2019-1001"]; ok {
+		t.Fatal("rejected entry wasn't excluded")
+	}
+	if v, ok := dict["TESTVE-2019-1002"]; !ok || v.Rejected() {
+		t.Fatal("disputed entry should be kept and not be marked as rejected")
+	}
+
+	dict = load(DictionaryOptions{IncludeRejected: true})
+	if len(dict) != 2 {
+		t.Fatalf("expected 2 entries with rejected included, got %d", len(dict))
+	}
+	if v, ok := dict["TESTVE-2019-1001"]; !ok || !v.Rejected() {
+		t.Fatal("rejected entry should be kept and marked as rejected")
+	}
+}
+
+func TestDictionaryModifiedSince(t *testing.T) {
+	td, err := ioutil.TempDir("", "cvefeed-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	path := filepath.Join(td, "feed.json")
+	if err := ioutil.WriteFile(path, []byte(testJSONdictModified), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := DictionaryOptions{ModifiedSince: time.Date(2019, time.May, 1, 8, 0, 0, 0, time.UTC)}
+	expect := []string{"TESTVE-2019-2002", "TESTVE-2019-2004", "TESTVE-2019-2005"}
+	loads := map[string]func() (Dictionary, error){
+		"LoadFeedWithOptions": func() (Dictionary, error) {
+			return LoadFeedWithOptions(func(_ string) ([]Vuln, error) {
+				return ParseJSON(bytes.NewBufferString(testJSONdictModified))
+			}, opts, "")
+		},
+		"LoadJSONDictionaryWithOptions": func() (Dictionary, error) {
+			return LoadJSONDictionaryWithOptions(opts, path)
+		},
+	}
+	for name, load := range loads {
+		t.Run(name, func(t *testing.T) {
+			dict, err := load()
+			if err != nil {
+				t.Fatalf("could not load test JSON feed: %v", err)
+			}
+			var ids []string
+			for id := range dict {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, expect) {
+				t.Fatalf("got %v, expected %v", ids, expect)
+			}
+		})
+	}
+}
+
+func TestDictionaryIncludeCWEs(t *testing.T) {
+	td, err := ioutil.TempDir("", "cvefeed-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	path := filepath.Join(td, "feed.json")
+	if err := ioutil.WriteFile(path, []byte(testJSONdictCWEs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		cwes   []string
+		expect []string
+	}{
+		{nil, []string{"TESTVE-2021-0001", "TESTVE-2021-0002", "TESTVE-2021-0003", "TESTVE-2021-0004"}},
+		{[]string{"CWE-89", "CWE-78", "CWE-77"}, []string{"TESTVE-2021-0001", "TESTVE-2021-0003"}},
+		{[]string{"CWE-20"}, []string{"TESTVE-2021-0003"}},
+		{[]string{"NVD-CWE-noinfo"}, []string{"TESTVE-2021-0004"}},
+		// values are compared exactly
+		{[]string{"cwe-89", "CWE-8", "89"}, nil},
+	}
+	for _, c := range cases {
+		opts := DictionaryOptions{IncludeCWEs: c.cwes}
+		dict, err := LoadJSONDictionaryWithOptions(opts, path)
+		if err != nil {
+			t.Fatalf("could not load test JSON feed: %v", err)
+		}
+		var ids []string
+		dict.Each(func(id string, _ Vuln) bool {
+			ids = append(ids, id)
+			return true
+		})
+		if !reflect.DeepEqual(ids, c.expect) {
+			t.Errorf("%v: got %v, expected %v", c.cwes, ids, c.expect)
+		}
+	}
+}
+
+func TestLoadJSONDictionaryContext(t *testing.T) {
+	data := benchmarkFeed(t, 100)
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var paths []string
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("feed%d.json", i))
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	dict, err := LoadJSONDictionaryContext(context.Background(), DictionaryOptions{}, paths...)
+	if err != nil || len(dict) == 0 {
+		t.Fatalf("failed to load dictionary: %d entries, %v", len(dict), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if dict, err := LoadJSONDictionaryContext(ctx, DictionaryOptions{}, paths...); err != context.Canceled || dict != nil {
+		t.Fatalf("expected %v, got %d entries, %v", context.Canceled, len(dict), err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if dict, err := LoadJSONDictionaryContext(ctx, DictionaryOptions{}, paths...); err != context.DeadlineExceeded || dict != nil {
+		t.Fatalf("expected %v, got %d entries, %v", context.DeadlineExceeded, len(dict), err)
+	}
+}
+
+func TestLoadJSONDictionaryWorkers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feeds := []struct {
+		name  string
+		items []string
+	}{
+		{"nvdcve-1.1-2019.json", []string{
+			testFeedItem("CVE-2019-0001", 5.0, "2019-03-01T00:00Z"),
+			testFeedItem("CVE-2019-0002", 5.0, "2019-03-01T00:00Z"),
+		}},
+		// the modified feed isn't the last one, but its updated entry is the most recent one
+		{"nvdcve-1.1-modified.json", []string{
+			testFeedItem("CVE-2019-0001", 9.8, "2020-06-01T00:00Z"),
+			testFeedItem("CVE-2020-0001", 1.0, "2020-01-01T00:00Z"),
+			testFeedItem("CVE-2020-0002", 1.0, "2020-02-01T00:00Z"),
+		}},
+		{"nvdcve-1.1-2020.json", []string{
+			testFeedItem("CVE-2020-0001", 7.5, "2020-05-01T00:00Z"),
+			testFeedItem("CVE-2020-0002", 7.5, "2020-02-01T00:00Z"),
+		}},
+		{"nvdcve-1.1-2021.json", []string{
+			testFeedItem("CVE-2021-0001", 4.3, "2021-01-01T00:00Z"),
+		}},
+	}
+	var paths []string
+	for _, f := range feeds {
+		path := filepath.Join(dir, f.name)
+		data := `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[` + strings.Join(f.items, ",") + `]}`
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	expect := map[string]float64{
+		"CVE-2019-0001": 9.8, // more recently modified in the modified feed
+		"CVE-2019-0002": 5.0,
+		"CVE-2020-0001": 7.5, // more recently modified in the yearly feed
+		"CVE-2020-0002": 7.5, // modified at the same time, the later feed wins
+		"CVE-2021-0001": 4.3,
+	}
+	for _, workers := range []int{0, 1, 2, 8} {
+		for i := 0; i < 10; i++ {
+			dict, err := LoadJSONDictionaryWithOptions(DictionaryOptions{Workers: workers}, paths...)
+			if err != nil {
+				t.Fatalf("workers %d: failed to load dictionary: %v", workers, err)
+			}
+			got := make(map[string]float64, len(dict))
+			for id, v := range dict {
+				got[id] = v.CVSSv3BaseScore()
+			}
+			if !reflect.DeepEqual(got, expect) {
+				t.Fatalf("workers %d: got %v, expected %v", workers, got, expect)
+			}
+		}
+	}
+}
+
+// testFeedItem returns NVD JSON 1.1 feed item of the given ID, CVSS v3 base score and last modification date
+func testFeedItem(id string, score float64, modified string) string {
+	return fmt.Sprintf(`{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":%q}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]},
+ "impact":{"baseMetricV3":{"cvssV3":{"version":"3.1","baseScore":%.1f}}},
+ "publishedDate":"2019-01-01T00:00Z","lastModifiedDate":%q}`, id, score, modified)
+}
+
+// warningsLogger records the messages logged at warning level
+type warningsLogger []string
+
+func (l *warningsLogger) Debugf(string, ...interface{}) {}
+func (l *warningsLogger) Infof(string, ...interface{})  {}
+func (l *warningsLogger) Warnf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+func (l *warningsLogger) Errorf(string, ...interface{}) {}
+
+func TestLoadJSONDictionaryLogger(t *testing.T) {
+	f, err := ioutil.TempFile("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	feed := strings.Replace(testJSONdict, `"operator": "OR"`, `"operator": "NOR"`, 1)
+	if _, err := f.WriteString(feed); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var logger warningsLogger
+	if _, err := LoadJSONDictionaryWithOptions(DictionaryOptions{Logger: &logger}, f.Name()); err != nil {
+		t.Fatalf("failed to load dictionary: %v", err)
+	}
+	expect := warningsLogger{`unknown operator, defaulting to OR: got "NOR"`}
+	if !reflect.DeepEqual(logger, expect) {
+		t.Fatalf("got warnings %q, expected %q", logger, expect)
+	}
+}
+
+func TestDictionaryEach(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdict))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	if len(dict) < 2 {
+		t.Fatalf("test feed should have at least 2 entries, got %d", len(dict))
+	}
+	seen := make(map[string]int)
+	var ids []string
+	dict.Each(func(id string, v Vuln) bool {
+		if v != dict[id] {
+			t.Errorf("%s: entry doesn't match the dictionary one", id)
+		}
+		seen[id]++
+		ids = append(ids, id)
+		return true
+	})
+	if len(seen) != len(dict) {
+		t.Fatalf("visited %d entries out of %d", len(seen), len(dict))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("%s was visited %d times", id, n)
+		}
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("entries weren't visited in order: %v", ids)
+	}
+
+	var n int
+	dict.Each(func(string, Vuln) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Fatalf("iteration didn't stop, %d entries visited", n)
+	}
+}
+
+func TestDictionarySetVersionUnknownPolicy(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictRanges))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	in := []*wfn.Attributes{{Part: "a", Vendor: "acme", Product: "widget"}}
+	cases := []struct {
+		policy nvd.VersionUnknownPolicy
+		expect []string
+	}{
+		// TESTVE-2019-0002 is the only one with the range without the lower bound
+		{nvd.VersionUnknownDefault, []string{"TESTVE-2019-0002"}},
+		{nvd.VersionUnknownConservative, []string{"TESTVE-2019-0001", "TESTVE-2019-0002"}},
+		{nvd.VersionUnknownStrict, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			dict.SetVersionUnknownPolicy(c.policy)
+			var ids []string
+			for _, r := range NewCache(dict).Get(in) {
+				ids = append(ids, r.CVE.ID())
+			}
+			if !reflect.DeepEqual(ids, c.expect) {
+				t.Fatalf("got %v, expected %v", ids, c.expect)
+			}
+		})
+	}
+}
+
+func TestDictionarySetVersionComparator(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictComparator))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	// versions are month.year: 3.2024 is after 11.2023, although smart comparison says otherwise
+	monthYear := func(v1, v2 string) int {
+		key := func(v string) string {
+			parts := strings.SplitN(v, ".", 2)
+			if len(parts) != 2 {
+				return v
+			}
+			return fmt.Sprintf("%s.%02s", parts[1], parts[0])
+		}
+		return strings.Compare(key(v1), key(v2))
+	}
+	tool := []*wfn.Attributes{{Part: "a", Vendor: "acme", Product: "tool", Version: "3\\.2024"}}
+	other := []*wfn.Attributes{{Part: "a", Vendor: "acme", Product: "other", Version: "3\\.2024"}}
+	matches := func() []string {
+		var ids []string
+		for _, in := range [][]*wfn.Attributes{tool, other} {
+			for _, r := range NewCache(dict).Get(in) {
+				ids = append(ids, r.CVE.ID()+"/"+r.CPEs[0].Product)
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if got, expect := matches(), []string{"TESTVE-2023-2001/tool", "TESTVE-2023-2002/other"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("default comparator: got %v, expected %v", got, expect)
+	}
+	dict.SetVersionComparator("acme", "tool", monthYear)
+	if got, expect := matches(), []string{"TESTVE-2023-2002/other"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("custom comparator: got %v, expected %v", got, expect)
+	}
+	dict.SetVersionComparator("acme", "tool", nil)
+	if got, expect := matches(), []string{"TESTVE-2023-2001/tool", "TESTVE-2023-2002/other"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("restored comparator: got %v, expected %v", got, expect)
+	}
+}
+
+func TestDictionaryAddAlias(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictAlias))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	inventory := []*wfn.Attributes{
+		{Part: "a", Vendor: "elastic", Product: "kibana", Version: "6\\.3"},
+		{Part: "a", Vendor: "acme", Product: "widget_pro", Version: "2\\.0"},
+		// aliases ignore case
+		{Part: "a", Vendor: "Elastic", Product: "kibana", Version: "6\\.3"},
+	}
+	caches := func() map[string]*Cache {
+		exact := NewCache(dict)
+		exact.ExactIdx = NewExactIndex(dict)
+		indexed := NewCache(dict)
+		indexed.Idx = NewIndex(dict)
+		return map[string]*Cache{"dictionary": NewCache(dict), "index": indexed, "exact index": exact}
+	}
+	matches := func(c *Cache) []string {
+		var ids []string
+		for _, cpe := range inventory {
+			for _, r := range c.Get([]*wfn.Attributes{cpe}) {
+				ids = append(ids, r.CVE.ID()+"/"+r.CPEs[0].Vendor+":"+r.CPEs[0].Product)
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	for name, c := range caches() {
+		if got := matches(c); len(got) != 0 {
+			t.Fatalf("%s: no aliases: should not match, got %v", name, got)
+		}
+	}
+	dict.AddAlias(&wfn.Attributes{Part: "a", Vendor: "elastic"}, &wfn.Attributes{Vendor: "elasticsearch"})
+	dict.AddAlias(&wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget_pro"}, &wfn.Attributes{Product: "widget"})
+	expect := []string{"TESTVE-2020-3001/Elastic:kibana", "TESTVE-2020-3001/elastic:kibana", "TESTVE-2020-3002/acme:widget_pro"}
+	for name, c := range caches() {
+		if got := matches(c); !reflect.DeepEqual(got, expect) {
+			t.Errorf("%s: got %v, expected %v", name, got, expect)
+		}
+	}
+	ranges := dict.MatchingRanges(inventory[0])
+	if len(ranges) != 1 || ranges[0].CPE != "cpe:2.3:a:elasticsearch:kibana:*:*:*:*:*:*:*:*" {
+		t.Errorf("aliased CPE should match the canonical range, got %+v", ranges)
+	}
+	// aliases are only tried for the names matching them
+	other := &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "3\\.0"}
+	if got := NewCache(dict).Get([]*wfn.Attributes{other}); len(got) != 0 {
+		t.Errorf("%v is out of range, got %v", other, got)
+	}
+}
+
+func indexIDs(idx Index) map[string][]string {
+	ids := make(map[string][]string, len(idx))
+	for product, entries := range idx {
+		for _, e := range entries {
+			ids[product] = append(ids[product], e.ID())
+		}
+		sort.Strings(ids[product])
+	}
+	return ids
+}
+
+func equalIndexIDs(a, b map[string][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, ids := range a {
+		if len(ids) != len(b[k]) {
+			return false
+		}
+		for i := range ids {
+			if ids[i] != b[k][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var testJSONdictUpdate = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "1",
+"CVE_data_timestamp" : "2018-07-31T07:00Z",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "TESTVE-2018-0001",
+        "ASSIGNER" : "cve@mitre.org"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            {
+              "vulnerable" : true,
+              "cpe23Uri" : "cpe:2.3:a:microsoft:edge:*:*:*:*:*:*:*:*"
+            }
+          ]
+        }
+      ]
+    }
+  }
+]
+}
+`
+
+var testJSONdictRanges = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "3",
+"CVE_data_timestamp" : "2019-05-01T07:00Z",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "TESTVE-2019-0001",
+        "ASSIGNER" : "cve@mitre.org"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            {
+              "vulnerable" : true,
+              "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*",
+              "versionStartIncluding" : "1.0",
+              "versionEndExcluding" : "1.5"
+            },
+            {
+              "vulnerable" : true,
+              "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*",
+              "versionStartIncluding" : "2.0",
+              "versionEndExcluding" : "3.0"
+            }
+          ]
+        },
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            {
+              "vulnerable" : true,
+              "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*",
+              "versionStartExcluding" : "1.2",
+              "versionEndIncluding" : "1.4"
+            },
+            {
+              "vulnerable" : false,
+              "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*",
+              "versionEndIncluding" : "1.9"
+            }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "TESTVE-2019-0002",
+        "ASSIGNER" : "cve@mitre.org"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            {
+              "vulnerable" : true,
+              "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*",
+              "versionEndIncluding" : "1.3"
+            }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "TESTVE-2019-0003",
+        "ASSIGNER" : "cve@mitre.org"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "AND",
+          "children" : [
+            {
+              "operator" : "OR",
+              "cpe_match" : [ {
+                "vulnerable" : true,
+                "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"
+              } ]
+            },
+            {
+              "operator" : "OR",
+              "cpe_match" : [ {
+                "vulnerable" : false,
+                "cpe23Uri" : "cpe:2.3:o:acme:os:*:*:*:*:*:*:*:*"
+              } ]
+            }
+          ]
+        }
+      ]
+    }
+  }
+]
+}
+`
+
+var testJSONdictRejected = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "2",
+"CVE_data_timestamp" : "2019-05-01T07:00Z",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "TESTVE-2019-1001",
+        "ASSIGNER" : "cve@mitre.org"
+      },
+      "description" : {
+        "description_data" : [ {
+          "lang" : "en",
+          "value" : "** REJECT ** DO NOT USE THIS CANDIDATE NUMBER. Reason: This candidate is a duplicate of TESTVE-2019-1002."
+        } ]
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : true,
+            "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"
+          } ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "TESTVE-2019-1002",
+        "ASSIGNER" : "cve@mitre.org"
+      },
+      "description" : {
+        "description_data" : [ {
+          "lang" : "en",
+          "value" : "** DISPUTED ** Widget allows remote attackers to do things. NOTE: the vendor disputes this."
+        } ]
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [ {
+            "vulnerable" : true,
+            "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"
+          } ]
+        }
+      ]
+    }
+  }
+]
+}
+`
+
+var testJSONdictComparator = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "2",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2023-2001" }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:tool:*:*:*:*:*:*:*:*", "versionEndExcluding" : "11.2023" }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2023-2002" }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:other:*:*:*:*:*:*:*:*", "versionEndExcluding" : "11.2023" }
+          ]
+        }
+      ]
+    }
+  }
+]
+}
+`
+
+var testJSONdictAlias = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "2",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2020-3001" }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:elasticsearch:kibana:*:*:*:*:*:*:*:*", "versionEndExcluding" : "6.4" }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2020-3002" }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", "versionEndIncluding" : "2.1" }
+          ]
+        }
+      ]
+    }
+  }
+]
+}
+`
+
+// testJSONdictCWEs has entries with SQL injection, XSS, both OS command injection and improper input validation,
+// and unknown problem types
+var testJSONdictCWEs = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "4",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2021-0001" },
+      "problemtype" : { "problemtype_data" : [ { "description" : [ { "lang" : "en", "value" : "CWE-89" } ] } ] }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:shop:1.0:*:*:*:*:*:*:*" }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2021-0002" },
+      "problemtype" : { "problemtype_data" : [ { "description" : [ { "lang" : "en", "value" : "CWE-79" } ] } ] }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:shop:1.0:*:*:*:*:*:*:*" }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2021-0003" },
+      "problemtype" : { "problemtype_data" : [ { "description" : [ { "lang" : "en", "value" : "CWE-78" },{ "lang" : "en", "value" : "CWE-20" } ] } ] }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:shop:1.0:*:*:*:*:*:*:*" }
+          ]
+        }
+      ]
+    }
+  },
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : { "ID" : "TESTVE-2021-0004" },
+      "problemtype" : { "problemtype_data" : [ { "description" : [ { "lang" : "en", "value" : "NVD-CWE-noinfo" } ] } ] }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            { "vulnerable" : true, "cpe23Uri" : "cpe:2.3:a:acme:shop:1.0:*:*:*:*:*:*:*" }
+          ]
+        }
+      ]
+    }
+  }
+]
+}
+`
+
+// testJSONdictModified has entries modified before and after 2019-05-01T08:00Z, in different timestamp formats,
+// and one without the modification time
+var testJSONdictModified = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "5",
+"CVE_Items" : [
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2019-2001"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}]}]},"lastModifiedDate":"2019-01-01T00:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2019-2002"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}]}]},"lastModifiedDate":"2019-06-01T00:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2019-2003"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}]}]},"lastModifiedDate":"2019-05-01T09:00+02:00"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2019-2004"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}]}]},"lastModifiedDate":"2019-05-01T11:30:00.000+02:00"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2019-2005"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}]}]}}
+]
+}
+`
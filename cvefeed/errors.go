@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FeedFormatError is returned when the feed isn't valid JSON or doesn't follow the feed schema
+type FeedFormatError struct {
+	// Path is the path of the feed file, empty if the feed wasn't loaded from a file
+	Path string
+	// Offset is the offset in the (decompressed) feed at which the malformed value starts
+	Offset int64
+	// CVE is the ID of the malformed entry, empty if it's unknown
+	CVE string
+	Err error
+}
+
+// Error implements error interface
+func (e *FeedFormatError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("cvefeed: malformed feed")
+	if e.Path != "" {
+		fmt.Fprintf(&sb, " %q", e.Path)
+	}
+	fmt.Fprintf(&sb, " at offset %d", e.Offset)
+	if e.CVE != "" {
+		fmt.Fprintf(&sb, " (%s)", e.CVE)
+	}
+	fmt.Fprintf(&sb, ": %v", e.Err)
+	return sb.String()
+}
+
+// Unwrap returns the underlying error, e.g. *json.SyntaxError
+func (e *FeedFormatError) Unwrap() error {
+	return e.Err
+}
+
+// FeedIOError is returned when the feed can't be opened or read
+type FeedIOError struct {
+	// Path is the path of the feed file, empty if the feed wasn't loaded from a file
+	Path string
+	Err  error
+}
+
+// Error implements error interface
+func (e *FeedIOError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("cvefeed: can't read feed: %v", e.Err)
+	}
+	return fmt.Sprintf("cvefeed: can't read feed %q: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error, e.g. *fs.PathError
+func (e *FeedIOError) Unwrap() error {
+	return e.Err
+}
+
+// FeedErrors is returned when a feed was parsed leniently and some of its entries were skipped, or when only some of
+// the feeds failed to load: it holds the error of each of them
+type FeedErrors []error
+
+// Error implements error interface
+func (e FeedErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors, for errors.Is and errors.As of the Go versions which support it
+func (e FeedErrors) Unwrap() []error {
+	return e
+}
+
+// feedErrors returns FeedErrors of errs, or nil if there are none
+func feedErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return FeedErrors(errs)
+}
+
+// errReader remembers the error of reading from the underlying reader, other than io.EOF,
+// which tells the feed which can't be read apart from the malformed one
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+// Read implements io.Reader interface
+func (r *errReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
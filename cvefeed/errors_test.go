@@ -0,0 +1,191 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testJSONcorrupt is a feed with the second entry not following the schema: impact isn't an object
+var testJSONcorrupt = `{"CVE_data_type":"CVE","CVE_Items":[
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0001"}},"configurations":{"nodes":[]}},
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0002"}},"configurations":{"nodes":[]},"impact":"high"},
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0003"}},"configurations":{"nodes":[]}}
+]}`
+
+// testJSONapi20corrupt is an NVD CVE API 2.0 response with the second entry not following the schema
+var testJSONapi20corrupt = `{"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+{"cve":{"id":"CVE-2019-0001"}},
+{"cve":{"id":"CVE-2019-0002","descriptions":"none"}},
+{"cve":{"id":"CVE-2019-0003"}}
+]}`
+
+func vulnIDs(vulns []Vuln) []string {
+	var ids []string
+	for _, v := range vulns {
+		ids = append(ids, v.ID())
+	}
+	return ids
+}
+
+// feedFormatError returns err if it's *FeedFormatError, or the first of FeedErrors err is
+func feedFormatError(err error) (*FeedFormatError, bool) {
+	if errs, ok := err.(FeedErrors); ok && len(errs) != 0 {
+		err = errs[0]
+	}
+	ferr, ok := err.(*FeedFormatError)
+	return ferr, ok
+}
+
+func TestParseJSONCorruptItem(t *testing.T) {
+	for name, feed := range map[string]string{"1.x": testJSONcorrupt, "API 2.0": testJSONapi20corrupt} {
+		t.Run(name, func(t *testing.T) {
+			offset := int64(strings.Index(feed, "\n{\"cve\":{\"CVE_data_meta\":{\"ID\":\"CVE-2019-0002\""))
+			if offset < 0 {
+				offset = int64(strings.Index(feed, "\n{\"cve\":{\"id\":\"CVE-2019-0002\""))
+			}
+			checkErr := func(err error) {
+				ferr, ok := feedFormatError(err)
+				if !ok {
+					t.Fatalf("expected *FeedFormatError, got %T: %v", err, err)
+				}
+				if ferr.CVE != "CVE-2019-0002" {
+					t.Errorf("expected the error of CVE-2019-0002, got %q", ferr.CVE)
+				}
+				// the offset precedes the comma separating the entry from the previous one
+				if ferr.Offset != offset-1 {
+					t.Errorf("expected the error at offset %d, got %d", offset-1, ferr.Offset)
+				}
+				if _, ok := ferr.Err.(*json.UnmarshalTypeError); !ok {
+					t.Errorf("expected *json.UnmarshalTypeError to be wrapped, got %v", ferr.Err)
+				}
+			}
+
+			// strict mode
+			vulns, err := ParseJSON(bytes.NewBufferString(feed))
+			if vulns != nil {
+				t.Errorf("strict: expected no entries, got %v", vulnIDs(vulns))
+			}
+			checkErr(err)
+
+			// lenient mode
+			vulns, err = ParseJSONLenient(bytes.NewBufferString(feed))
+			if ids, expect := vulnIDs(vulns), []string{"CVE-2019-0001", "CVE-2019-0003"}; !reflect.DeepEqual(ids, expect) {
+				t.Errorf("lenient: expected entries %v, got %v", expect, ids)
+			}
+			if errs, ok := err.(FeedErrors); !ok || len(errs) != 1 {
+				t.Fatalf("lenient: expected FeedErrors of the skipped entry, got %T: %v", err, err)
+			}
+			checkErr(err)
+		})
+	}
+}
+
+func TestParseJSONLenientSyntaxError(t *testing.T) {
+	vulns, err := ParseJSONLenient(bytes.NewBufferString(testJSONcorrupt[:len(testJSONcorrupt)-10]))
+	if _, ok := err.(*FeedFormatError); !ok || vulns != nil {
+		t.Fatalf("expected truncated feed to fail with *FeedFormatError, got %d entries and %T: %v", len(vulns), err, err)
+	}
+	if vulns, err := ParseJSONLenient(bytes.NewBufferString(testJSONdict)); err != nil || len(vulns) == 0 {
+		t.Fatalf("expected the valid feed to parse, got %d entries and %v", len(vulns), err)
+	}
+}
+
+// failingReader fails after reading n bytes
+type failingReader struct {
+	r   io.Reader
+	n   int
+	err error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, r.err
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n -= n
+	return n, err
+}
+
+func TestParseJSONIOError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	_, err := ParseJSONLenient(&failingReader{strings.NewReader(testJSONdict), 100, readErr})
+	ioErr, ok := err.(*FeedIOError)
+	if !ok {
+		t.Fatalf("expected *FeedIOError, got %T: %v", err, err)
+	}
+	if ioErr.Err != readErr {
+		t.Fatalf("expected the read error to be wrapped, got %v", ioErr.Err)
+	}
+}
+
+func TestLoadJSONDictionaryErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "corrupt.json")
+	if err := ioutil.WriteFile(path, []byte(testJSONcorrupt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lenient := range []bool{false, true} {
+		dict, err := LoadJSONDictionaryWithOptions(DictionaryOptions{Lenient: lenient}, path)
+		ferr, ok := feedFormatError(err)
+		if !ok {
+			t.Fatalf("lenient %v: expected *FeedFormatError, got %T: %v", lenient, err, err)
+		}
+		if ferr.Path != path || ferr.CVE != "CVE-2019-0002" {
+			t.Errorf("lenient %v: expected the error of CVE-2019-0002 in %q, got %v", lenient, path, ferr)
+		}
+		expect := 0
+		if lenient {
+			expect = 2
+		}
+		if len(dict) != expect {
+			t.Errorf("lenient %v: expected %d entries, got %d", lenient, expect, len(dict))
+		}
+	}
+
+	missing := filepath.Join(dir, "missing.json")
+	_, err = LoadJSONDictionary(missing)
+	errs, _ := err.(FeedErrors)
+	if len(errs) != 1 {
+		t.Fatalf("expected FeedErrors of missing file, got %T: %v", err, err)
+	}
+	ioErr, ok := errs[0].(*FeedIOError)
+	if !ok || ioErr.Path != missing || !os.IsNotExist(ioErr.Err) {
+		t.Fatalf("expected *FeedIOError of missing file, got %T: %v", err, err)
+	}
+
+	// the errors of the feeds parsed by other loaders tell the path too
+	_, err = LoadFeed(func(string) ([]Vuln, error) { return ParseJSON(strings.NewReader(testJSONcorrupt)) }, "corrupt.json")
+	if ferr, ok := feedFormatError(err); !ok || ferr.Path != "corrupt.json" {
+		t.Fatalf("expected *FeedFormatError of corrupt.json, got %T: %v", err, err)
+	}
+}
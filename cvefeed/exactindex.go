@@ -0,0 +1,100 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ExactIndex maps the (part, vendor, product) triples of CPEs to the entries in the NVD feed they're mentioned in.
+// Entries mentioning a CPE with ANY or wildcards in any of these attributes are kept under wfn.Any key,
+// as they can match any triple.
+type ExactIndex map[string][]Vuln
+
+// NewExactIndex creates new ExactIndex from a dictionary
+func NewExactIndex(d Dictionary) ExactIndex {
+	idx := ExactIndex{}
+	for _, entry := range d {
+		keys := map[string]bool{}
+		for _, cpe := range entry.Config() {
+			if cpe == nil {
+				continue
+			}
+			if key, ok := exactKey(cpe); ok {
+				keys[key] = true
+			} else {
+				keys[wfn.Any] = true
+			}
+		}
+		for key := range keys {
+			idx[key] = append(idx[key], entry)
+		}
+	}
+	return idx
+}
+
+// exactKey returns the key of cpe in ExactIndex;
+// the second return value is false if cpe isn't concrete in part, vendor and product
+func exactKey(cpe *wfn.Attributes) (string, bool) {
+	for _, v := range []string{cpe.Part, cpe.Vendor, cpe.Product} {
+		if v == wfn.Any || wfn.HasWildcard(v) {
+			return "", false
+		}
+	}
+	return exactKeyValue(cpe.Part) + ":" + exactKeyValue(cpe.Vendor) + ":" + exactKeyValue(cpe.Product), true
+}
+
+// exactKeyValue lowercases and unquotes attribute value v, so that the names which differ only in case
+// or needless quoting, e.g. "Microsoft" and "microsoft" or "flash\_player" and "flash_player", share a key
+func exactKeyValue(v string) string {
+	buf := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c == '\\' && i+1 < len(v) {
+			i++
+			c = v[i]
+		}
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
+
+// dictFromExactIndex creates CVE dictionary from entries indexed by the triples of cpes;
+// the second return value is false if some of cpes aren't concrete, so the exact index can't be used for them
+func (c *Cache) dictFromExactIndex(cpes []*wfn.Attributes) (Dictionary, bool) {
+	keys := make([]string, 0, len(cpes)+1)
+	for _, cpe := range cpes {
+		if cpe == nil {
+			continue
+		}
+		key, ok := exactKey(cpe)
+		if !ok {
+			return nil, false
+		}
+		keys = append(keys, key)
+	}
+	keys = append(keys, wfn.Any)
+
+	d := Dictionary{}
+	for _, key := range keys {
+		for _, vuln := range c.ExactIdx[key] {
+			d[vuln.ID()] = vuln
+		}
+	}
+	return d, true
+}
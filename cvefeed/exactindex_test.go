@@ -0,0 +1,129 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestExactIndex(t *testing.T) {
+	dict := Dictionary{}
+	for _, feed := range []string{testJSONdict, testJSONdictAND} {
+		vulns, err := ParseJSON(bytes.NewBufferString(feed))
+		if err != nil {
+			t.Fatalf("failed to parse the dictionary: %v", err)
+		}
+		for _, v := range vulns {
+			dict[v.ID()] = v
+		}
+	}
+	flash := &wfn.Attributes{Part: "a", Vendor: "adobe", Product: "flash_player", Version: "28\\.0\\.0\\.137"}
+	windows := &wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "windows"}
+	cases := [][]*wfn.Attributes{
+		{flash},
+		{flash, windows},
+		{{Part: "o", Vendor: "linux", Product: "linux_kernel", Version: "2\\.6\\.1"}},
+		{{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp3"}, {Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.0"}},
+		{{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.4"}},
+		{{Part: "a", Vendor: "mozilla", Product: "firefox", Version: "64\\.0"}},
+		// case and needless quoting don't affect the keys
+		{{Part: "a", Vendor: "Adobe", Product: "Flash_Player", Version: "28\\.0\\.0\\.137"}},
+		{{Part: "O", Vendor: "MICROSOFT", Product: "windows\\_xp", Update: "sp3"}, {Part: "a", Vendor: "Microsoft", Product: "IE", Version: "6\\.0"}},
+		// not concrete, exact index can't be used
+		{{Part: "a", Vendor: wfn.Any, Product: "ie", Version: "5\\.4"}},
+		{{Part: "a", Vendor: "microsoft", Product: "i*", Version: "5\\.4"}},
+		{flash, {Part: "o", Vendor: "microsoft", Product: wfn.Any}},
+	}
+	slow := NewCache(dict).SetMaxSize(-1)
+	fast := NewCache(dict).SetMaxSize(-1)
+	fast.ExactIdx = NewExactIndex(dict)
+	for i, cpes := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			expect := matchIDs(slow.Get(cpes))
+			if got := matchIDs(fast.Get(cpes)); fmt.Sprint(got) != fmt.Sprint(expect) {
+				t.Fatalf("got %v, expected %v", got, expect)
+			}
+		})
+	}
+	if _, ok := fast.dictFromExactIndex(cases[1]); !ok {
+		t.Error("exact index wasn't used for concrete CPEs")
+	}
+	if _, ok := fast.dictFromExactIndex(cases[len(cases)-1]); ok {
+		t.Error("exact index was used for CPEs with ANY product")
+	}
+}
+
+// matchIDs returns the sorted IDs of matching entries, each followed by the number of matching CPEs
+func matchIDs(results []MatchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, fmt.Sprintf("%s/%d", r.CVE.ID(), len(r.CPEs)))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// benchmarkDict returns a dictionary of n entries for products shared by many vendors, and concrete CPEs to match
+func benchmarkDict(n int) (Dictionary, [][]*wfn.Attributes) {
+	dict := Dictionary{}
+	var inputs [][]*wfn.Attributes
+	for i := 0; i < n; i++ {
+		vendor, product := fmt.Sprintf("vendor%d", i), fmt.Sprintf("product%d", i%10)
+		item := &schema.NVDCVEFeedJSON10DefCVEItem{
+			CVE: &schema.CVEJSON40{CVEDataMeta: &schema.CVEJSON40CVEDataMeta{ID: fmt.Sprintf("CVE-2019-%d", i)}},
+			Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{
+				Nodes: []*schema.NVDCVEFeedJSON10DefNode{{
+					Operator: "OR",
+					CPEMatch: []*schema.NVDCVEFeedJSON10DefCPEMatch{{
+						Cpe23Uri:            fmt.Sprintf("cpe:2.3:a:%s:%s:*:*:*:*:*:*:*:*", vendor, product),
+						VersionEndExcluding: "2.0",
+						Vulnerable:          true,
+					}},
+				}},
+			},
+		}
+		v := nvd.ToVuln(item)
+		dict[v.ID()] = v
+		inputs = append(inputs, []*wfn.Attributes{{Part: "a", Vendor: vendor, Product: product, Version: "1\\.0"}})
+	}
+	return dict, inputs
+}
+
+func benchmarkCache(b *testing.B, setIndex func(*Cache)) {
+	dict, inputs := benchmarkDict(5000)
+	cache := NewCache(dict).SetMaxSize(-1)
+	setIndex(cache)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, cpes := range inputs[:500] {
+			cache.Get(cpes)
+		}
+	}
+}
+
+func BenchmarkCacheIndex(b *testing.B) {
+	benchmarkCache(b, func(c *Cache) { c.Idx = NewIndex(c.Dict) })
+}
+
+func BenchmarkCacheExactIndex(b *testing.B) {
+	benchmarkCache(b, func(c *Cache) { c.ExactIdx = NewExactIndex(c.Dict) })
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// cveItemer is implemented by vulnerabilities which were created from NVD JSON feed entries
+type cveItemer interface {
+	CVEItem() *schema.NVDCVEFeedJSON10DefCVEItem
+}
+
+// ToFeed converts the dictionary back to NVD JSON 1.1 feed, with the entries sorted by ID.
+// The entries are exported as they were parsed, including configurations, impact metrics and descriptions,
+// regardless of the feed format (e.g. CVE API 2.0) they came from.
+// Overridden entries (see Dictionary.Override) are exported as they match: their configuration is the one of the
+// original entry AND NOT the one of the override, see effectiveItem. Entries of other types can't be represented
+// in the feed.
+func (d Dictionary) ToFeed() (*schema.NVDCVEFeedJSON10, error) {
+	ids := make([]string, 0, len(d))
+	for id := range d {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	items := make([]*schema.NVDCVEFeedJSON10DefCVEItem, 0, len(ids))
+	for _, id := range ids {
+		item, err := effectiveItem(id, d[id])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &schema.NVDCVEFeedJSON10{
+		CVEDataFormat:       "MITRE",
+		CVEDataNumberOfCVEs: strconv.Itoa(len(items)),
+		CVEDataTimestamp:    time.Now().UTC().Format(schema.TimeLayout),
+		CVEDataType:         "CVE",
+		CVEDataVersion:      "4.0",
+		CVEItems:            items,
+	}, nil
+}
+
+// effectiveItem returns the feed entry of the vulnerability v, identified by id.
+// The entry of an overridden vulnerability is the one of the original, with the configuration replaced with a single
+// AND node combining the original configuration with the negated one of the override. It matches the same CPE names
+// one at a time; of several names matched together, those matching the override exclude all the others too.
+func effectiveItem(id string, v Vuln) (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+	if o, ok := v.(*overriden); ok {
+		item, err := effectiveItem(id, o.Vuln)
+		if err != nil {
+			return nil, err
+		}
+		override, err := effectiveItem(id, o.override)
+		if err != nil {
+			return nil, err
+		}
+		effective := *item
+		effective.Configurations = &schema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+			Nodes: []*schema.NVDCVEFeedJSON10DefNode{{
+				Operator: "AND",
+				Children: []*schema.NVDCVEFeedJSON10DefNode{
+					{Operator: "OR", Children: configurationNodes(item)},
+					{Operator: "OR", Negate: true, Children: configurationNodes(override)},
+				},
+			}},
+		}
+		return &effective, nil
+	}
+	ci, ok := v.(cveItemer)
+	if !ok {
+		return nil, fmt.Errorf("cvefeed: %s (%T) can't be converted to NVD JSON feed", id, v)
+	}
+	item := ci.CVEItem()
+	if item == nil {
+		return nil, fmt.Errorf("cvefeed: %s has no NVD JSON feed entry", id)
+	}
+	return item, nil
+}
+
+// configurationNodes returns the top-level configuration nodes of the feed entry
+func configurationNodes(item *schema.NVDCVEFeedJSON10DefCVEItem) []*schema.NVDCVEFeedJSON10DefNode {
+	if item.Configurations == nil {
+		return nil
+	}
+	return item.Configurations.Nodes
+}
+
+// WriteJSON writes the dictionary to w as NVD JSON 1.1 feed, see ToFeed
+func (d Dictionary) WriteJSON(w io.Writer) error {
+	feed, err := d.ToFeed()
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(feed); err != nil {
+		return fmt.Errorf("cvefeed: can't write NVD JSON feed: %v", err)
+	}
+	return nil
+}
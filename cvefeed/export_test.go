@@ -0,0 +1,150 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestDictionaryWriteJSON(t *testing.T) {
+	for name, feed := range map[string]string{
+		"feed 1.0":       testJSONdict,
+		"feed 1.0 AND":   testJSONdictAND,
+		"feed 1.1":       testJSONfeed11,
+		"CVE API 2.0":    testJSONapi20,
+		"not vulnerable": testJSONdictNotVulnerable,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dict, err := LoadFeed(func(string) ([]Vuln, error) { return ParseJSON(bytes.NewBufferString(feed)) }, "")
+			if err != nil {
+				t.Fatalf("failed to load the dictionary: %v", err)
+			}
+			var out bytes.Buffer
+			if err := dict.WriteJSON(&out); err != nil {
+				t.Fatalf("failed to write the dictionary: %v", err)
+			}
+			got, err := LoadFeed(func(string) ([]Vuln, error) { return ParseJSON(bytes.NewReader(out.Bytes())) }, "")
+			if err != nil {
+				t.Fatalf("failed to load the written dictionary: %v", err)
+			}
+			if len(got) != len(dict) {
+				t.Fatalf("got %d entries, expected %d", len(got), len(dict))
+			}
+			inventory := exportTestInventory(dict)
+			for id, v := range dict {
+				v2, ok := got[id]
+				if !ok {
+					t.Fatalf("%s is missing", id)
+				}
+				expectItem, _ := json.Marshal(v.(cveItemer).CVEItem())
+				gotItem, _ := json.Marshal(v2.(cveItemer).CVEItem())
+				if !bytes.Equal(expectItem, gotItem) {
+					t.Errorf("%s: entry changed:\n%s\nexpected:\n%s", id, gotItem, expectItem)
+				}
+				for _, cpes := range inventory {
+					expect, matched := matchedURIs(v.Match(cpes, false)), matchedURIs(v2.Match(cpes, false))
+					if expect != matched {
+						t.Errorf("%s: %v matched %s, expected %s", id, cpes, matched, expect)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDictionaryWriteJSONOverridden(t *testing.T) {
+	dict, err := LoadFeed(func(string) ([]Vuln, error) { return ParseJSON(bytes.NewBufferString(testJSONdict)) }, "")
+	if err != nil {
+		t.Fatalf("failed to load the dictionary: %v", err)
+	}
+	overrides, err := LoadFeed(func(string) ([]Vuln, error) { return ParseJSON(bytes.NewBufferString(testJSONoverride)) }, "")
+	if err != nil {
+		t.Fatalf("failed to load the overrides: %v", err)
+	}
+	dict.Override(overrides)
+	var out bytes.Buffer
+	if err := dict.WriteJSON(&out); err != nil {
+		t.Fatalf("failed to write the dictionary: %v", err)
+	}
+	got, err := LoadFeed(func(string) ([]Vuln, error) { return ParseJSON(bytes.NewReader(out.Bytes())) }, "")
+	if err != nil {
+		t.Fatalf("failed to load the written dictionary: %v", err)
+	}
+	if len(got) != len(dict) {
+		t.Fatalf("got %d entries, expected %d", len(got), len(dict))
+	}
+	// the effective configuration matches the same CPE names one at a time
+	var inventory [][]*wfn.Attributes
+	for _, cpes := range append(exportTestInventory(dict), exportTestInventory(overrides)...) {
+		for _, cpe := range cpes {
+			inventory = append(inventory, []*wfn.Attributes{cpe})
+		}
+	}
+	for _, cpe := range []*wfn.Attributes{
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.0", Update: wfn.NA},
+		{Part: "a", Vendor: "microsoft", Product: "ie", Version: "5\\.0", Update: "patched"},
+	} {
+		inventory = append(inventory, []*wfn.Attributes{cpe})
+	}
+	for id, v := range dict {
+		v2, ok := got[id]
+		if !ok {
+			t.Fatalf("%s is missing", id)
+		}
+		for _, cpes := range inventory {
+			expect, matched := matchedURIs(v.Match(cpes, false)), matchedURIs(v2.Match(cpes, false))
+			if expect != matched {
+				t.Errorf("%s: %v matched %s, expected %s", id, cpes, matched, expect)
+			}
+		}
+	}
+}
+
+func matchedURIs(attrs []*wfn.Attributes) string {
+	uris := make([]string, len(attrs))
+	for i, attr := range attrs {
+		uris[i] = attr.BindToURI()
+	}
+	sort.Strings(uris)
+	return fmt.Sprint(uris)
+}
+
+// exportTestInventory returns the CPEs of configurations of all entries of the dictionary, one by one, with a few concrete versions, and all together
+func exportTestInventory(dict Dictionary) [][]*wfn.Attributes {
+	var all []*wfn.Attributes
+	var inventory [][]*wfn.Attributes
+	for _, v := range dict {
+		for _, cpe := range v.Config() {
+			if cpe == nil {
+				continue
+			}
+			all = append(all, cpe)
+			inventory = append(inventory, []*wfn.Attributes{cpe})
+			// concrete versions to exercise version ranges
+			for _, version := range []string{"0\\.1", "1\\.2", "99"} {
+				concrete := *cpe
+				concrete.Version = version
+				inventory = append(inventory, []*wfn.Attributes{&concrete})
+			}
+		}
+	}
+	return append(inventory, all)
+}
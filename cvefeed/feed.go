@@ -19,62 +19,296 @@
 package cvefeed
 
 import (
-	"bufio"
-	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
 )
 
-// ParseJSON parses JSON dictionary from NVD vulnerability feed
+// ParseJSON parses JSON dictionary from NVD vulnerability feed, either 1.x JSON feed or CVE API 2.0 response.
+// Errors are *FeedFormatError if the feed is malformed or *FeedIOError if it can't be read.
 func ParseJSON(in io.Reader) ([]Vuln, error) {
-	feed, err := getFeed(in)
+	return parseJSONFiltered(context.Background(), in, parseOptions{})
+}
+
+// ParseJSONContext is like ParseJSON, but parsing stops as soon as ctx is done, between the entries of the feed;
+// ctx.Err() is returned then.
+func ParseJSONContext(ctx context.Context, in io.Reader) ([]Vuln, error) {
+	return parseJSONFiltered(ctx, in, parseOptions{})
+}
+
+// ParseJSONLenient is like ParseJSON, but the entries which don't follow the feed schema are skipped instead of
+// failing the whole feed: the rest of the entries are returned along with FeedErrors of the skipped ones,
+// each of them a *FeedFormatError. The feed which isn't valid JSON still fails, it can't be parsed past the error.
+func ParseJSONLenient(in io.Reader) ([]Vuln, error) {
+	return parseJSONFiltered(context.Background(), in, parseOptions{lenient: true})
+}
+
+// parseOptions control parsing of a feed
+type parseOptions struct {
+	path     string             // path of the feed file, for errors
+	keep     func(Vuln) bool    // if set, only the entries for which it returns true are returned
+	lenient  bool               // skip the entries which don't follow the schema, see ParseJSONLenient
+	criteria MatchCriteria      // resolves matchCriteriaId references of NVD CVE API 2.0 entries, if set
+	convert  nvd.ConvertOptions // how the entries are converted into nvd.Vuln
+}
+
+// parseJSONFiltered is like ParseJSONContext, but parses the feed as per opts
+func parseJSONFiltered(ctx context.Context, in io.Reader, opts parseOptions) ([]Vuln, error) {
+	var vulns []Vuln
+	skipped, err := parseJSON(in, opts, func(v Vuln) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if opts.keep == nil || opts.keep(v) {
+			vulns = append(vulns, v)
+		}
+		return nil
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("cvefeed.ParseJSON: %v", err)
+		return nil, err
 	}
+	return vulns, feedErrors(skipped)
+}
 
-	vulns := make([]Vuln, 0, len(feed.CVEItems))
-	for _, cve := range feed.CVEItems {
+// ParseJSONFunc parses JSON dictionary from NVD vulnerability feed and calls fn for every entry as soon as it's decoded,
+// so the parser never holds more than one entry of the feed in memory.
+// Parsing stops at the first error returned by fn, that error is returned as is; errors of parsing are
+// *FeedFormatError or *FeedIOError, as in ParseJSON.
+func ParseJSONFunc(in io.Reader, fn func(Vuln) error) error {
+	_, err := parseJSON(in, parseOptions{}, fn)
+	return err
+}
+
+// feedParser streams the entries of the feed
+type feedParser struct {
+	parseOptions
+	in      *errReader
+	skipped []error // errors of the entries skipped in lenient mode
+}
+
+// parseJSON streams the feed from in, decoding CVE_Items one at a time.
+// NVD CVE API 2.0 responses are recognized by their vulnerabilities array.
+// Errors returned by fn are returned as is, the errors of the entries skipped in lenient mode are returned separately.
+func parseJSON(in io.Reader, opts parseOptions, fn func(Vuln) error) (skipped []error, err error) {
+	p := &feedParser{parseOptions: opts, in: &errReader{r: in}}
+	err = p.parse(fn)
+	return p.skipped, err
+}
+
+func (p *feedParser) parse(fn func(Vuln) error) error {
+	return p.parseArrays(map[string]func(*feedDecoder) error{
+		"CVE_Items":       func(dec *feedDecoder) error { return p.parseItems(dec, fn) },
+		"vulnerabilities": func(dec *feedDecoder) error { return p.parseAPIItems(dec, fn) },
+	})
+}
+
+// parseArrays decodes the top-level object of the feed, calling the function of arrays with the matching
+// (case-insensitively) key to decode the value of that key; values of other keys are skipped
+func (p *feedParser) parseArrays(arrays map[string]func(*feedDecoder) error) error {
+	reader, err := setupReader(p.in)
+	if err != nil {
+		return p.fail(fmt.Errorf("can't setup reader: %v", err), 0, "")
+	}
+	defer reader.Close()
+
+	dec := newFeedDecoder(reader)
+	if err := p.expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		offset := dec.InputOffset()
+		t, err := dec.Token()
+		if err != nil {
+			return p.fail(err, offset, "")
+		}
+		key, _ := t.(string)
+		var parseArray func(*feedDecoder) error
+		for name, fn := range arrays {
+			if strings.EqualFold(key, name) {
+				parseArray = fn
+				break
+			}
+		}
+		if parseArray != nil {
+			err = parseArray(dec)
+		} else {
+			offset = dec.InputOffset()
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				err = p.fail(err, offset, "")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return p.expectDelim(dec, '}')
+}
+
+// parseItems decodes the elements of CVE_Items array, which can also be null
+func (p *feedParser) parseItems(dec *feedDecoder, fn func(Vuln) error) error {
+	if ok, err := p.expectArray(dec, "CVE_Items"); !ok {
+		return err
+	}
+	for dec.More() {
+		offset := dec.InputOffset()
+		var cve *schema.NVDCVEFeedJSON10DefCVEItem
+		if err := dec.Decode(&cve); err != nil {
+			if err := p.skip(err, offset, itemID(cve)); err != nil {
+				return err
+			}
+			continue
+		}
 		if cve != nil && cve.Configurations != nil {
-			vulns = append(vulns, nvd.ToVuln(cve))
+			if err := fn(nvd.ToVulnWithOptions(cve, p.convert)); err != nil {
+				return err
+			}
+		}
+	}
+	return p.expectDelim(dec, ']')
+}
+
+// parseAPIItems decodes the elements of vulnerabilities array of NVD CVE API 2.0 response, which can also be null
+func (p *feedParser) parseAPIItems(dec *feedDecoder, fn func(Vuln) error) error {
+	if ok, err := p.expectArray(dec, "vulnerabilities"); !ok {
+		return err
+	}
+	// the decoded CVE is converted into a new feed entry, so it's reused for the next one;
+	// as the decoder leaves the missing cve as it was, the CVE without ID is treated as missing
+	var v schema.CVEAPIJSON20Vulnerability
+	for dec.More() {
+		offset := dec.InputOffset()
+		cve := getAPICVE()
+		v = schema.CVEAPIJSON20Vulnerability{CVE: cve}
+		item := &v
+		err := dec.Decode(&item)
+		if err != nil {
+			err = p.skip(err, offset, cve.ID)
+		} else if item != nil && item.CVE != nil && item.CVE.ID != "" {
+			err = fn(nvd.ToVulnWithOptions(cve.ToFeedResolved(p.criteria), p.convert))
+		}
+		putAPICVE(cve)
+		if err != nil {
+			return err
 		}
 	}
-	return vulns, nil
+	return p.expectDelim(dec, ']')
 }
 
-func getFeed(in io.Reader) (*schema.NVDCVEFeedJSON10, error) {
-	reader, err := setupReader(in)
+// expectArray consumes the start of the named array and returns true, or false if it's null or there's an error
+func (p *feedParser) expectArray(dec *feedDecoder, name string) (bool, error) {
+	offset := dec.InputOffset()
+	t, err := dec.Token()
 	if err != nil {
-		return nil, fmt.Errorf("can't setup reader: %v", err)
+		return false, p.fail(err, offset, "")
 	}
-	defer reader.Close()
+	if t == nil {
+		return false, nil
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return false, p.fail(fmt.Errorf("%s: expected array, got %v", name, t), offset, "")
+	}
+	return true, nil
+}
 
-	var feed schema.NVDCVEFeedJSON10
-	if err := json.NewDecoder(reader).Decode(&feed); err != nil {
-		return nil, err
+func (p *feedParser) expectDelim(dec *feedDecoder, delim json.Delim) error {
+	offset := dec.InputOffset()
+	t, err := dec.Token()
+	if err != nil {
+		return p.fail(err, offset, "")
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return p.fail(fmt.Errorf("expected %v, got %v", delim, t), offset, "")
+	}
+	return nil
+}
+
+// fail returns the error of parsing the feed at offset: *FeedIOError if the feed couldn't be read,
+// *FeedFormatError otherwise
+func (p *feedParser) fail(err error, offset int64, id string) error {
+	if p.in.err != nil {
+		return &FeedIOError{Path: p.path, Err: p.in.err}
 	}
-	return &feed, nil
+	return &FeedFormatError{Path: p.path, Offset: offset, CVE: id, Err: err}
 }
 
-func setupReader(in io.Reader) (src io.ReadCloser, err error) {
-	r := bufio.NewReader(in)
-	header, err := r.Peek(2)
+// skip returns the error of decoding the entry at offset, unless the entry can be skipped: in lenient mode,
+// the entries which are valid JSON but don't follow the schema are skipped and their errors are kept
+func (p *feedParser) skip(err error, offset int64, id string) error {
+	_, mismatch := err.(*json.UnmarshalTypeError)
+	err = p.fail(err, offset, id)
+	if _, ok := err.(*FeedFormatError); ok && mismatch && p.lenient {
+		p.skipped = append(p.skipped, err)
+		return nil
+	}
+	return err
+}
+
+// feedDecoder is a json.Decoder which also tells the offset in its input
+type feedDecoder struct {
+	*json.Decoder
+	in *countingReader
+}
+
+func newFeedDecoder(in io.Reader) *feedDecoder {
+	cr := &countingReader{r: in}
+	return &feedDecoder{Decoder: json.NewDecoder(cr), in: cr}
+}
+
+// InputOffset returns the offset in the input of the end of the last token decoded, i.e. of what's
+// decoded next, allowing for whitespace and commas
+func (d *feedDecoder) InputOffset() int64 {
+	buffered := d.Buffered()
+	if b, ok := buffered.(interface{ Len() int }); ok {
+		return d.in.n - int64(b.Len())
+	}
+	n, _ := io.Copy(ioutil.Discard, buffered)
+	return d.in.n - n
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements io.Reader interface
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// itemID returns the ID of the feed entry which may be only partially decoded
+func itemID(cve *schema.NVDCVEFeedJSON10DefCVEItem) string {
+	if cve == nil || cve.CVE == nil || cve.CVE.CVEDataMeta == nil {
+		return ""
+	}
+	return cve.CVE.CVEDataMeta.ID
+}
+
+func setupReader(in io.Reader) (io.ReadCloser, error) {
+	src := newFeedReader(in)
+	header, err := src.br.Peek(2)
 	if err != nil {
+		src.Close()
 		return nil, err
 	}
-	// assume plain text first
-	src = ioutil.NopCloser(r)
 	// replace with gzip.Reader if gzip'ed
 	if header[0] == 0x1f && header[1] == 0x8b { // file is gzip'ed
-		zr, err := gzip.NewReader(r)
-		if err != nil {
+		if err := src.gunzip(); err != nil {
+			src.Close()
 			return nil, err
 		}
-		src = zr
 	}
 	// TODO: maybe support .zip
 	return src, nil
@@ -0,0 +1,497 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestParseJSONFunc(t *testing.T) {
+	expect, err := ParseJSON(bytes.NewBufferString(testJSONdict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	var got []string
+	err = ParseJSONFunc(bytes.NewBufferString(testJSONdict), func(v Vuln) error {
+		got = append(got, v.ID())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got %d entries, expected %d", len(got), len(expect))
+	}
+	for i, v := range expect {
+		if got[i] != v.ID() {
+			t.Fatalf("entry %d: got %q, expected %q", i, got[i], v.ID())
+		}
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = ParseJSONFunc(bytes.NewBufferString(testJSONdict), func(v Vuln) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Fatalf("expected parsing to stop after the first entry with the callback error, got %d entries and %v", n, err)
+	}
+}
+
+func TestParseJSONGzip(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write([]byte(testJSONdict)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	compressed := gz.Bytes()
+
+	expect, err := ParseJSON(bytes.NewBufferString(testJSONdict))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	got, err := ParseJSON(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("failed to parse the compressed dictionary: %v", err)
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got %d entries, expected %d", len(got), len(expect))
+	}
+
+	td, err := ioutil.TempDir("", "cvefeed-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	feed := filepath.Join(td, "feed.json.gz")
+	if err := ioutil.WriteFile(feed, compressed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadJSONDictionary(feed)
+	if err != nil {
+		t.Fatalf("failed to load the compressed dictionary: %v", err)
+	}
+	if len(dict) != len(expect) {
+		t.Fatalf("got %d entries, expected %d", len(dict), len(expect))
+	}
+	// compression is detected by the gzip header, not by the extension
+	plain := filepath.Join(td, "plain.json.gz")
+	if err := ioutil.WriteFile(plain, []byte(testJSONdict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if dict, err = LoadJSONDictionary(plain); err != nil {
+		t.Fatalf("failed to load the non-compressed dictionary with .gz extension: %v", err)
+	}
+	if len(dict) != len(expect) {
+		t.Fatalf("got %d entries, expected %d", len(dict), len(expect))
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	cases := map[string]string{
+		"not an object":  `[]`,
+		"items not list": `{"CVE_Items": {}}`,
+		"truncated":      testJSONdict[:len(testJSONdict)/2],
+		"bad item":       `{"CVE_Items": [{"cve": 1}]}`,
+	}
+	for name, feed := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseJSON(bytes.NewBufferString(feed)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+	if vulns, err := ParseJSON(bytes.NewBufferString(`{"CVE_data_type": "CVE", "CVE_Items": null}`)); err != nil || len(vulns) != 0 {
+		t.Fatalf("expected empty feed to parse, got %d entries and %v", len(vulns), err)
+	}
+}
+
+func TestParseJSONAPI20(t *testing.T) {
+	feed, err := ParseJSON(bytes.NewBufferString(testJSONfeed11))
+	if err != nil {
+		t.Fatalf("failed to parse 1.1 feed: %v", err)
+	}
+	api, err := ParseJSON(bytes.NewBufferString(testJSONapi20))
+	if err != nil {
+		t.Fatalf("failed to parse 2.0 API response: %v", err)
+	}
+	if len(api) != len(feed) || len(feed) != 3 {
+		t.Fatalf("got %d entries from 2.0 API response and %d from 1.1 feed, expected 3", len(api), len(feed))
+	}
+	cpes := []string{
+		"cpe:/a:acme:widget:1.3",
+		"cpe:/a:acme:widget:1.5",
+		"cpe:/a:acme:widget:2.1",
+		"cpe:/a:acme:widget:1.3 cpe:/o:linux:linux_kernel:4.0",
+		"cpe:/a:acme:widget:1.3 cpe:/o:microsoft:windows:10",
+		"cpe:/a:acme:gadget:0.9",
+	}
+	matched := 0
+	for i, expect := range feed {
+		got := api[i]
+		if got.ID() != expect.ID() {
+			t.Fatalf("entry %d: got %s, expected %s", i, got.ID(), expect.ID())
+		}
+		t.Run(expect.ID(), func(t *testing.T) {
+			if !reflect.DeepEqual(got.CWEs(), expect.CWEs()) {
+				t.Errorf("CWEs: got %v, expected %v", got.CWEs(), expect.CWEs())
+			}
+			if got.CVSSv2BaseScore() != expect.CVSSv2BaseScore() || got.CVSSv2Vector() != expect.CVSSv2Vector() {
+				t.Errorf("CVSS v2: got %v %s, expected %v %s", got.CVSSv2BaseScore(), got.CVSSv2Vector(), expect.CVSSv2BaseScore(), expect.CVSSv2Vector())
+			}
+			if got.CVSSv3BaseScore() != expect.CVSSv3BaseScore() || got.CVSSv3Vector() != expect.CVSSv3Vector() || CVSSv3Severity(got) != CVSSv3Severity(expect) {
+				t.Errorf("CVSS v3: got %v %s %s, expected %v %s %s", got.CVSSv3BaseScore(), got.CVSSv3Vector(), CVSSv3Severity(got),
+					expect.CVSSv3BaseScore(), expect.CVSSv3Vector(), CVSSv3Severity(expect))
+			}
+			if got.Rejected() != expect.Rejected() {
+				t.Errorf("rejected: got %t, expected %t", got.Rejected(), expect.Rejected())
+			}
+			if !reflect.DeepEqual(got.CVEs(), expect.CVEs()) {
+				t.Errorf("CVEs: got %v, expected %v", got.CVEs(), expect.CVEs())
+			}
+			for _, cpe := range cpes {
+				var attrs []*wfn.Attributes
+				for _, s := range strings.Fields(cpe) {
+					attr, err := wfn.UnbindURI(s)
+					if err != nil {
+						t.Fatalf("can't parse %q: %v", s, err)
+					}
+					attrs = append(attrs, attr)
+				}
+				g, e := len(got.Match(attrs, false)) != 0, len(expect.Match(attrs, false)) != 0
+				if g != e {
+					t.Errorf("%s: matched %t, expected %t", cpe, g, e)
+				}
+				if e {
+					matched++
+				}
+			}
+		})
+	}
+	if matched != 5 {
+		t.Errorf("expected 5 matches in 1.1 feed, got %d", matched)
+	}
+}
+
+func TestParseJSONAPI20Metrics(t *testing.T) {
+	var resp schema.CVEAPIJSON20
+	if err := json.Unmarshal([]byte(testJSONapi20Metrics), &resp); err != nil {
+		t.Fatalf("failed to unmarshal 2.0 API response: %v", err)
+	}
+	vulns, err := ParseJSON(bytes.NewBufferString(testJSONapi20Metrics))
+	if err != nil {
+		t.Fatalf("failed to parse 2.0 API response: %v", err)
+	}
+	if len(vulns) != 2 || len(resp.Vulnerabilities) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(vulns))
+	}
+
+	// Primary v3.1 from NVD is selected, Secondary v2 is only available in the schema
+	v, metrics := vulns[0], resp.Vulnerabilities[0].CVE.Metrics
+	if v.CVSSv3BaseScore() != 7.5 || v.CVSSv3Vector() != "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H" || CVSSv3Severity(v) != "HIGH" {
+		t.Errorf("CVSS v3: got %v %s %s, expected the Primary v3.1 metric", v.CVSSv3BaseScore(), v.CVSSv3Vector(), CVSSv3Severity(v))
+	}
+	if v.CVSSv2BaseScore() != 0 || v.CVSSv2Vector() != "" {
+		t.Errorf("CVSS v2: got %v %s, Secondary metric shouldn't be selected", v.CVSSv2BaseScore(), v.CVSSv2Vector())
+	}
+	if m := metrics.PrimaryV2(); m != nil {
+		t.Errorf("expected no primary v2 metric, got %+v", m)
+	}
+	if other := metrics.OtherV2(); len(other) != 1 || other[0].Source != "secure@example.com" || other[0].CVSSData.BaseScore != 5 {
+		t.Errorf("expected the Secondary v2 metric, got %+v", other)
+	}
+	if other := metrics.OtherV3(); len(other) != 0 {
+		t.Errorf("expected no other v3 metrics, got %+v", other)
+	}
+
+	// Primary v3.0 from NVD is preferred over Secondary v3.1 and Primary v3.1 from another source
+	v, metrics = vulns[1], resp.Vulnerabilities[1].CVE.Metrics
+	if v.CVSSv3BaseScore() != 9.8 {
+		t.Errorf("CVSS v3: got %v, expected the Primary v3.0 metric from NVD", v.CVSSv3BaseScore())
+	}
+	if m := metrics.PrimaryV3(); m == nil || m.Source != schema.NVDSource || m.CVSSData.Version != "3.0" {
+		t.Errorf("unexpected primary v3 metric %+v", m)
+	}
+	if other := metrics.OtherV3(); len(other) != 2 || other[0].Type != "Secondary" || other[1].Source != "cna@example.com" {
+		t.Errorf("unexpected other v3 metrics %+v", other)
+	}
+}
+
+// cancelingReader cancels the context once n bytes are read
+type cancelingReader struct {
+	r      io.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (r *cancelingReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		r.cancel()
+	}
+	if len(p) > r.n && r.n > 0 {
+		p = p[:r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n -= n
+	return n, err
+}
+
+func TestParseJSONContext(t *testing.T) {
+	data := benchmarkFeed(t, 100)
+	vulns, err := ParseJSONContext(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to parse the feed: %v", err)
+	}
+	if len(vulns) == 0 {
+		t.Fatal("no entries parsed")
+	}
+
+	// the context is cancelled in the middle of the feed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vulns, err = ParseJSONContext(ctx, &cancelingReader{bytes.NewReader(data), len(data) / 2, cancel})
+	if err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if vulns != nil {
+		t.Fatalf("%d entries returned after cancellation", len(vulns))
+	}
+}
+
+// benchmarkFeed returns a feed of n copies of testJSONdict entries
+func benchmarkFeed(b testing.TB, n int) []byte {
+	var feed schema.NVDCVEFeedJSON10
+	if err := json.Unmarshal([]byte(testJSONdict), &feed); err != nil {
+		b.Fatal(err)
+	}
+	items := feed.CVEItems
+	feed.CVEItems = nil
+	for i := 0; i < n; i++ {
+		feed.CVEItems = append(feed.CVEItems, items...)
+	}
+	data, err := json.Marshal(&feed)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkParseJSONFunc(b *testing.B) {
+	data := benchmarkFeed(b, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := ParseJSONFunc(bytes.NewReader(data), func(Vuln) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkInterner parses a feed with many repeated cpe_match criteria, and reports the heap retained by the result
+func benchmarkInterner(b *testing.B, interner func() *nvd.Interner) {
+	data := benchmarkFeed(b, 1000)
+	var retained int64
+	var before, after runtime.MemStats
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		b.StartTimer()
+		vulns, err := parseJSONFiltered(context.Background(), bytes.NewReader(data), parseOptions{convert: nvd.ConvertOptions{Interner: interner()}})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		retained += int64(after.HeapAlloc) - int64(before.HeapAlloc)
+		runtime.KeepAlive(vulns)
+		b.StartTimer()
+	}
+	// logged instead of testing.B.ReportMetric, which needs go1.13
+	b.Logf("%d retained-B/op", retained/int64(b.N))
+}
+
+func BenchmarkParseJSON(b *testing.B) {
+	benchmarkInterner(b, func() *nvd.Interner { return nil })
+}
+
+func BenchmarkParseJSONInterned(b *testing.B) {
+	benchmarkInterner(b, func() *nvd.Interner { return &nvd.Interner{} })
+}
+
+// benchmarkAPI20 returns NVD CVE API 2.0 response of n copies of testJSONapi20 vulnerabilities
+func benchmarkAPI20(b *testing.B, n int) []byte {
+	var resp schema.CVEAPIJSON20
+	if err := json.Unmarshal([]byte(testJSONapi20), &resp); err != nil {
+		b.Fatal(err)
+	}
+	items := resp.Vulnerabilities
+	resp.Vulnerabilities = nil
+	for i := 0; i < n; i++ {
+		resp.Vulnerabilities = append(resp.Vulnerabilities, items...)
+	}
+	data, err := json.Marshal(&resp)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkParseJSONAPI20(b *testing.B) {
+	data := benchmarkAPI20(b, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := ParseJSONFunc(bytes.NewReader(data), func(Vuln) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseJSONReload parses many small gzip'ed feeds, like the periodic reload of yearly feeds does
+func BenchmarkParseJSONReload(b *testing.B) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(benchmarkFeed(b, 10)); err != nil {
+		b.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 20; j++ {
+			err := ParseJSONFunc(bytes.NewReader(data), func(Vuln) error { return nil })
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkParseJSONBatch decodes the whole feed at once, like ParseJSON used to
+func BenchmarkParseJSONBatch(b *testing.B) {
+	data := benchmarkFeed(b, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var feed schema.NVDCVEFeedJSON10
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&feed); err != nil {
+			b.Fatal(err)
+		}
+		for _, cve := range feed.CVEItems {
+			if cve != nil && cve.Configurations != nil {
+				nvd.ToVuln(cve)
+			}
+		}
+	}
+}
+
+var testJSONfeed11 = `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_data_numberOfCVEs":"3","CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2019-2001","ASSIGNER":"cve@mitre.org"},
+ "problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-79"}]}]},
+ "references":{"reference_data":[{"url":"https://example.com/advisory","name":"https://example.com/advisory","refsource":"cve@mitre.org","tags":["Vendor Advisory"]}]},
+ "description":{"description_data":[{"lang":"en","value":"XSS in acme widget."}]}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[
+   {"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0","versionEndExcluding":"1.5"},
+   {"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"2.0","versionEndIncluding":"2.1"}]}]},
+ "impact":{"baseMetricV3":{"cvssV3":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N","baseScore":6.1,"baseSeverity":"MEDIUM"},"exploitabilityScore":2.8,"impactScore":2.7},
+  "baseMetricV2":{"cvssV2":{"version":"2.0","vectorString":"AV:N/AC:M/Au:N/C:N/I:P/A:N","baseScore":4.3},"severity":"MEDIUM","exploitabilityScore":8.6,"impactScore":2.9,"userInteractionRequired":true}},
+ "publishedDate":"2019-03-01T10:29Z","lastModifiedDate":"2019-04-02T15:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2019-2002","ASSIGNER":"cve@mitre.org"},
+ "problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-787"}]}]},
+ "references":{"reference_data":[]},
+ "description":{"description_data":[{"lang":"en","value":"Overflow in acme widget on Linux."}]}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"AND","children":[
+   {"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"1.4"}]},
+   {"operator":"OR","cpe_match":[{"vulnerable":false,"cpe23Uri":"cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"},{"vulnerable":false,"cpe23Uri":"cpe:2.3:o:linux:linux_kernel:*:*:*:*:*:*:*:*"}]}]}]},
+ "impact":{"baseMetricV3":{"cvssV3":{"version":"3.0","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"},"exploitabilityScore":3.9,"impactScore":5.9}},
+ "publishedDate":"2019-05-01T00:00Z","lastModifiedDate":"2019-05-01T00:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2019-2003","ASSIGNER":"cve@mitre.org"},
+ "problemtype":{"problemtype_data":[]},
+ "references":{"reference_data":[]},
+ "description":{"description_data":[{"lang":"en","value":"** REJECT ** DO NOT USE THIS CANDIDATE NUMBER."}]}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]},
+ "impact":{},
+ "publishedDate":"2019-06-01T00:00Z","lastModifiedDate":"2019-06-02T00:00Z"}]}`
+
+var testJSONapi20 = `{"resultsPerPage":3,"startIndex":0,"totalResults":3,"format":"NVD_CVE","version":"2.0","timestamp":"2023-01-01T00:00:00.000","vulnerabilities":[
+{"cve":{"id":"CVE-2019-2001","sourceIdentifier":"cve@mitre.org","published":"2019-03-01T10:29:00.000","lastModified":"2019-04-02T15:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"XSS in acme widget."},{"lang":"es","value":"XSS en acme widget."}],
+ "metrics":{
+  "cvssMetricV31":[
+   {"source":"secure@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:U/C:L/I:N/A:N","baseScore":3.1,"baseSeverity":"LOW"}},
+   {"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N","baseScore":6.1,"baseSeverity":"MEDIUM"},"exploitabilityScore":2.8,"impactScore":2.7}],
+  "cvssMetricV2":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:M/Au:N/C:N/I:P/A:N","baseScore":4.3},"baseSeverity":"MEDIUM","exploitabilityScore":8.6,"impactScore":2.9,"userInteractionRequired":true}]},
+ "weaknesses":[{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-79"}]}],
+ "configurations":[{"nodes":[{"operator":"OR","negate":false,"cpeMatch":[
+   {"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0","versionEndExcluding":"1.5","matchCriteriaId":"00000000-0000-0000-0000-000000000001"},
+   {"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"2.0","versionEndIncluding":"2.1","matchCriteriaId":"00000000-0000-0000-0000-000000000002"}]}]}],
+ "references":[{"url":"https://example.com/advisory","source":"cve@mitre.org","tags":["Vendor Advisory"]}]}},
+{"cve":{"id":"CVE-2019-2002","sourceIdentifier":"cve@mitre.org","published":"2019-05-01T00:00:00.000","lastModified":"2019-05-01T00:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"Overflow in acme widget on Linux."}],
+ "metrics":{"cvssMetricV30":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.0","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"},"exploitabilityScore":3.9,"impactScore":5.9}]},
+ "weaknesses":[{"source":"nvd@nist.gov","type":"Primary","description":[{"lang":"en","value":"CWE-787"}]}],
+ "configurations":[{"operator":"AND","nodes":[
+   {"operator":"OR","negate":false,"cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"1.4"}]},
+   {"operator":"OR","negate":false,"cpeMatch":[{"vulnerable":false,"criteria":"cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"},{"vulnerable":false,"criteria":"cpe:2.3:o:linux:linux_kernel:*:*:*:*:*:*:*:*"}]}]}],
+ "references":[]}},
+{"cve":{"id":"CVE-2019-2003","sourceIdentifier":"cve@mitre.org","published":"2019-06-01T00:00:00.000","lastModified":"2019-06-02T00:00:00.000","vulnStatus":"Rejected",
+ "descriptions":[{"lang":"en","value":"DO NOT USE THIS CANDIDATE NUMBER."}],
+ "metrics":{},
+ "references":[]}}]}`
+
+var testJSONapi20Metrics = `{"resultsPerPage":2,"startIndex":0,"totalResults":2,"format":"NVD_CVE","version":"2.0","timestamp":"2023-01-01T00:00:00.000","vulnerabilities":[
+{"cve":{"id":"CVE-2023-0001","sourceIdentifier":"secure@example.com","published":"2023-01-01T00:00:00.000","lastModified":"2023-01-01T00:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"DoS in acme widget."}],
+ "metrics":{
+  "cvssMetricV31":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H","baseScore":7.5,"baseSeverity":"HIGH"},"exploitabilityScore":3.9,"impactScore":3.6}],
+  "cvssMetricV2":[{"source":"secure@example.com","type":"Secondary","cvssData":{"version":"2.0","vectorString":"AV:N/AC:L/Au:N/C:N/I:N/A:P","baseScore":5.0},"baseSeverity":"MEDIUM"}]},
+ "references":[]}},
+{"cve":{"id":"CVE-2023-0002","sourceIdentifier":"cna@example.com","published":"2023-01-01T00:00:00.000","lastModified":"2023-01-01T00:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"Overflow in acme widget."}],
+ "metrics":{
+  "cvssMetricV31":[
+   {"source":"secure@example.com","type":"Secondary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:U/C:L/I:N/A:N","baseScore":3.1,"baseSeverity":"LOW"}},
+   {"source":"cna@example.com","type":"Primary","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H","baseScore":7.8,"baseSeverity":"HIGH"}}],
+  "cvssMetricV30":[{"source":"nvd@nist.gov","type":"Primary","cvssData":{"version":"3.0","vectorString":"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"}}]},
+ "references":[]}}]}`
@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Finding is a vulnerability matched by some CPE names, with the details of the match resolved into plain values.
+// Unlike MatchResult it doesn't require querying the vulnerability, so it's the representation to rely on when
+// the results are reported or stored; the vulnerability is still kept in Vuln for the details not covered here.
+// Findings are marshaled to JSON without Vuln, CPEs are marshaled as formatted strings.
+type Finding struct {
+	// ID of the vulnerability, e.g. CVE-2019-0001
+	ID string `json:"cve"`
+	// CPEs are the matched CPE names, as in MatchResult
+	CPEs []*wfn.Attributes `json:"matches"`
+	// Ranges are the vulnerable cpe_match entries, with their version bounds, which matched any of CPEs;
+	// nil if the vulnerability can't tell which of its entries matched
+	Ranges []RangeMatch `json:"ranges,omitempty"`
+	// Range is the strongest of Ranges, as chosen by Dictionary.BestMatchingRanges, to report the vulnerable
+	// versions by; nil if Ranges is empty
+	Range *RangeMatch `json:"range,omitempty"`
+	// CWEs are the weaknesses the vulnerability is classified as, e.g. CWE-79
+	CWEs []string `json:"cwes,omitempty"`
+	// CVSS2 is the CVSS v2 base score, 0 if the vulnerability isn't scored with CVSS v2
+	CVSS2 float64 `json:"cvss2"`
+	// CVSS2Vector is the CVSS v2 vector, e.g. AV:N/AC:L/Au:N/C:P/I:P/A:P; empty if unknown
+	CVSS2Vector string `json:"cvss2_vector,omitempty"`
+	// CVSS3 is the CVSS v3 base score, 0 if the vulnerability isn't scored with CVSS v3
+	CVSS3 float64 `json:"cvss3"`
+	// CVSS3Vector is the CVSS v3 vector, e.g. CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H; empty if unknown
+	CVSS3Vector string `json:"cvss3_vector,omitempty"`
+	// CVSS3Severity is the severity of the CVSS v3 base score, e.g. CRITICAL; empty if unknown
+	CVSS3Severity string `json:"cvss3_severity,omitempty"`
+	// Vuln is the matched vulnerability itself
+	Vuln Vuln `json:"-"`
+}
+
+// NewFinding resolves the match result into a Finding; requireVersion is as in Cache.SetRequireVersion,
+// it tells which cpe_match entries count as the matched ones
+func NewFinding(r MatchResult, requireVersion bool) Finding {
+	f := Finding{
+		ID:            r.CVE.ID(),
+		CPEs:          r.CPEs,
+		CWEs:          r.CVE.CWEs(),
+		CVSS2:         r.CVE.CVSSv2BaseScore(),
+		CVSS2Vector:   r.CVE.CVSSv2Vector(),
+		CVSS3:         r.CVE.CVSSv3BaseScore(),
+		CVSS3Vector:   r.CVE.CVSSv3Vector(),
+		CVSS3Severity: CVSSv3Severity(r.CVE),
+		Vuln:          r.CVE,
+	}
+	if reporter, ok := unwrapOverrides(r.CVE).(cpeMatchReporter); ok {
+		for _, cpe := range r.CPEs {
+			if cpe != nil {
+				f.Ranges = append(f.Ranges, rangeMatches(f.ID, reporter, cpe, requireVersion)...)
+			}
+		}
+		if best := bestRanges(f.Ranges); len(best) != 0 {
+			f.Range = &best[0]
+		}
+	}
+	return f
+}
+
+// Findings is like Get, but returns the results resolved into findings
+func (c *Cache) Findings(cpes []*wfn.Attributes) []Finding {
+	findings, _ := c.FindingsContext(context.Background(), cpes)
+	return findings
+}
+
+// FindingsContext is like GetContext, but returns the results resolved into findings
+func (c *Cache) FindingsContext(ctx context.Context, cpes []*wfn.Attributes) ([]Finding, error) {
+	results, err := c.GetContext(ctx, cpes)
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]Finding, len(results))
+	for i, r := range results {
+		findings[i] = NewFinding(r, c.RequireVersion)
+	}
+	return findings, nil
+}
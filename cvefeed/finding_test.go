@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestCacheFindings(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONfeed11))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	cpe := &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "1\\.3"}
+	findings := NewCache(dict).Findings([]*wfn.Attributes{cpe})
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	got := findings[0]
+	if got.Vuln == nil || got.Vuln.ID() != got.ID {
+		t.Errorf("vulnerability %v isn't kept in the finding %q", got.Vuln, got.ID)
+	}
+	got.Vuln = nil
+	expect := Finding{
+		ID:   "CVE-2019-2001",
+		CPEs: []*wfn.Attributes{cpe},
+		Ranges: []RangeMatch{
+			{ID: "CVE-2019-2001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0", VersionEndExcluding: "1.5"},
+		},
+		Range:         &RangeMatch{ID: "CVE-2019-2001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0", VersionEndExcluding: "1.5"},
+		CWEs:          []string{"CWE-79"},
+		CVSS2:         4.3,
+		CVSS2Vector:   "AV:N/AC:M/Au:N/C:N/I:P/A:N",
+		CVSS3:         6.1,
+		CVSS3Vector:   "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N",
+		CVSS3Severity: "MEDIUM",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got\n%+v\nexpected\n%+v", got, expect)
+	}
+}
+
+func TestNewFindingRanges(t *testing.T) {
+	vulns, err := ParseJSON(bytes.NewBufferString(testJSONfeed11))
+	if err != nil {
+		t.Fatalf("could not parse test JSON feed: %v", err)
+	}
+	cpes := []*wfn.Attributes{
+		{Part: "a", Vendor: "acme", Product: "widget", Version: "1\\.0"},
+		{Part: "a", Vendor: "acme", Product: "widget", Version: "2\\.1"},
+	}
+	f := NewFinding(MatchResult{CVE: vulns[0], CPEs: cpes}, false)
+	expect := []RangeMatch{
+		{ID: "CVE-2019-2001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0", VersionEndExcluding: "1.5"},
+		{ID: "CVE-2019-2001", CPE: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*", VersionStartIncluding: "2.0", VersionEndIncluding: "2.1"},
+	}
+	if !reflect.DeepEqual(f.Ranges, expect) {
+		t.Fatalf("got\n%+v\nexpected\n%+v", f.Ranges, expect)
+	}
+	if f.Range == nil || *f.Range != expect[0] {
+		t.Fatalf("got range %+v, expected %+v", f.Range, expect[0])
+	}
+	// nothing but the ID is known of the vulnerability without any details
+	f = NewFinding(MatchResult{CVE: vulns[2]}, false)
+	if f.ID != "CVE-2019-2003" || f.Ranges != nil || f.Range != nil || f.CWEs != nil || f.CVSS3 != 0 || f.CVSS3Severity != "" {
+		t.Fatalf("unexpected finding %+v", f)
+	}
+}
+
+func BenchmarkNewFinding(b *testing.B) {
+	dict, inputs := benchmarkDict(500)
+	cache := NewCache(dict).SetMaxSize(-1)
+	var results []MatchResult
+	for _, cpes := range inputs {
+		results = append(results, cache.Get(cpes)...)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, r := range results {
+			NewFinding(r, false)
+		}
+	}
+}
+
+func TestCVSSv3Severity(t *testing.T) {
+	feed := `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0001"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":9.8,"baseSeverity":"CRITICAL","version":"3.1"}}}},
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0002"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":6.1,"version":"3.0"}}}},
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0003"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","vulnerable":true}],"operator":"OR"}]}}
+]}`
+	vulns, err := ParseJSON(bytes.NewBufferString(feed))
+	if err != nil {
+		t.Fatalf("could not parse test JSON feed: %v", err)
+	}
+	// stated, derived from the score and unknown
+	for i, expect := range []string{"CRITICAL", "MEDIUM", ""} {
+		if got := CVSSv3Severity(vulns[i]); got != expect {
+			t.Errorf("%s: got %q, expected %q", vulns[i].ID(), got, expect)
+		}
+	}
+	if got := CVSSv3Severity(OverrideVuln(vulns[0], vulns[2])); got != "CRITICAL" {
+		t.Errorf("overridden %s: got %q, expected %q", vulns[0].ID(), got, "CRITICAL")
+	}
+}
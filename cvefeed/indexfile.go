@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// indexFileVersion is bumped whenever the format written by Index.Save or the way entries are indexed changes
+const indexFileVersion = 2
+
+// ErrStaleIndex is returned by LoadIndex when the saved index wasn't built from the given feeds
+var ErrStaleIndex = errors.New("index: saved index doesn't match the feeds")
+
+// indexFile is what Index.Save writes: index keys map to the IDs of the entries
+type indexFile struct {
+	Version  int
+	Hash     []byte
+	Products map[string][]string
+}
+
+// FeedsHash returns the sha256 of the contents of the feed files in paths, in this order, e.g. to be saved
+// with the index built from them by Index.Save. Hashing the files is much cheaper than indexing their entries.
+func FeedsHash(paths ...string) ([]byte, error) {
+	h := sha256.New()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("index: can't hash feed: %v", err)
+		}
+		n, err := io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("index: can't hash feed %q: %v", path, err)
+		}
+		// the length separates the contents of consecutive files
+		binary.Write(h, binary.BigEndian, n)
+	}
+	return h.Sum(nil), nil
+}
+
+// Save writes the index to w, along with feedsHash, the hash of the feeds the index was built from (see FeedsHash).
+// Only the IDs of the entries are stored, so the index can be loaded back with LoadIndex
+// from the dictionary of the same feeds.
+func (idx Index) Save(w io.Writer, feedsHash []byte) error {
+	f := indexFile{
+		Version:  indexFileVersion,
+		Hash:     feedsHash,
+		Products: make(map[string][]string, len(idx)),
+	}
+	for product, vulns := range idx {
+		ids := make([]string, len(vulns))
+		for i, v := range vulns {
+			ids[i] = v.ID()
+		}
+		f.Products[product] = ids
+	}
+	if err := gob.NewEncoder(w).Encode(&f); err != nil {
+		return fmt.Errorf("index: failed to save: %v", err)
+	}
+	return nil
+}
+
+// LoadIndex reads the index saved by Index.Save and resolves its entries in the dictionary d.
+// If the index was saved with a hash other than feedsHash (e.g. the feeds were updated), refers to entries
+// which aren't in d, or was saved by an incompatible version, ErrStaleIndex is returned and the index should be rebuilt.
+// d is expected to be loaded from the feeds of feedsHash: its entries aren't checked, as it would cost
+// as much as rebuilding the index.
+func LoadIndex(r io.Reader, d Dictionary, feedsHash []byte) (Index, error) {
+	var f indexFile
+	if err := gob.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("index: failed to load: %v", err)
+	}
+	if f.Version != indexFileVersion || len(feedsHash) == 0 || !bytes.Equal(f.Hash, feedsHash) {
+		return nil, ErrStaleIndex
+	}
+	idx := make(Index, len(f.Products))
+	for product, ids := range f.Products {
+		vulns := make([]Vuln, len(ids))
+		for i, id := range ids {
+			v, ok := d[id]
+			if !ok {
+				return nil, ErrStaleIndex
+			}
+			vulns[i] = v
+		}
+		idx[product] = vulns
+	}
+	return idx, nil
+}
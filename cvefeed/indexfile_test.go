@@ -0,0 +1,222 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestIndexSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed-index-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	load := func(contents string) (Dictionary, []byte) {
+		if err := ioutil.WriteFile(feed, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dict, err := LoadJSONDictionary(feed)
+		if err != nil {
+			t.Fatalf("could not load test JSON feed: %v", err)
+		}
+		hash, err := FeedsHash(feed)
+		if err != nil {
+			t.Fatalf("could not hash test JSON feed: %v", err)
+		}
+		return dict, hash
+	}
+	dict, hash := load(testJSONdict)
+	idx := NewIndex(dict)
+	var buf bytes.Buffer
+	if err := idx.Save(&buf, hash); err != nil {
+		t.Fatalf("could not save index: %v", err)
+	}
+	saved := buf.Bytes()
+
+	reloaded, rehash := load(testJSONdict)
+	loaded, err := LoadIndex(bytes.NewReader(saved), reloaded, rehash)
+	if err != nil {
+		t.Fatalf("could not load index: %v", err)
+	}
+	if got, expect := indexIDs(loaded), indexIDs(idx); !equalIndexIDs(got, expect) {
+		t.Fatalf("loaded index differs:\ngot %v\nexpected %v", got, expect)
+	}
+
+	inventories := [][]*wfn.Attributes{
+		{{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.0"}, {Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp2"}},
+		{{Part: "a", Vendor: "microsoft", Product: "ie", Version: "6\\.0"}},
+		{{Part: "o", Vendor: "linux", Product: "linux_kernel", Version: "2\\.6\\.1"}},
+		{{Part: "a", Vendor: "mozilla", Product: "firefox", Version: "64\\.0"}},
+	}
+	matchIDs := func(idx Index, inventory []*wfn.Attributes) []string {
+		cache := NewCache(dict)
+		cache.Idx = idx
+		var ids []string
+		for _, m := range cache.Get(inventory) {
+			ids = append(ids, m.CVE.ID())
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	for i, inventory := range inventories {
+		got, expect := matchIDs(loaded, inventory), matchIDs(idx, inventory)
+		if len(got) != len(expect) {
+			t.Fatalf("inventory %d: loaded index matched %v, fresh one matched %v", i, got, expect)
+		}
+		for j := range got {
+			if got[j] != expect[j] {
+				t.Fatalf("inventory %d: loaded index matched %v, fresh one matched %v", i, got, expect)
+			}
+		}
+	}
+
+	// changed feed invalidates the saved index
+	changed, changedHash := load(testJSONdictUpdate)
+	if _, err := LoadIndex(bytes.NewReader(saved), changed, changedHash); err != ErrStaleIndex {
+		t.Fatalf("expected ErrStaleIndex for changed feed, got %v", err)
+	}
+	// as does a dictionary without the indexed entries
+	if _, err := LoadIndex(bytes.NewReader(saved), changed, hash); err != ErrStaleIndex {
+		t.Fatalf("expected ErrStaleIndex for missing entries, got %v", err)
+	}
+	if _, err := LoadIndex(bytes.NewReader(saved), dict, nil); err != ErrStaleIndex {
+		t.Fatalf("expected ErrStaleIndex without hash, got %v", err)
+	}
+
+	// garbage fails to load
+	if _, err := LoadIndex(bytes.NewReader(saved[:len(saved)/2]), dict, hash); err == nil || err == ErrStaleIndex {
+		t.Fatalf("expected decoding error for truncated index, got %v", err)
+	}
+}
+
+func TestFeedsHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed-index-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ab, c := write("ab", "ab"), write("c", "c")
+	a, bc := write("a", "a"), write("bc", "bc")
+	hash := func(paths ...string) string {
+		h, err := FeedsHash(paths...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("%x", h)
+	}
+	if hash(ab, c) == hash(a, bc) {
+		t.Error("contents split differently between the files should hash differently")
+	}
+	if hash(ab, c) == hash(c, ab) {
+		t.Error("files in a different order should hash differently")
+	}
+	if hash(ab, c) != hash(write("ab2", "ab"), c) {
+		t.Error("files of the same contents should hash the same")
+	}
+	if _, err := FeedsHash(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing feed")
+	}
+}
+
+// benchFeed writes a feed of n entries to a file in dir, for the index benchmarks
+func benchFeed(b *testing.B, dir string, n int) string {
+	var sb strings.Builder
+	sb.WriteString(`{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[`)
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2020-%05d"}},`+
+			`"configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[`+
+			`{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:vendor%d:product%d:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0"},`+
+			`{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:vendor%d:product%d:1.%d:*:*:*:*:*:*:*"}]}]}}`,
+			i, i%500, i%2000, i%700, i%3000, i%10)
+	}
+	sb.WriteString(`]}`)
+	path := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+// benchIndexDict loads the dictionary of a feed of 30000 entries written to dir, and the index built from it saved with its hash
+func benchIndexDict(b *testing.B, dir string) (Dictionary, string, []byte) {
+	feed := benchFeed(b, dir, 30000)
+	dict, err := LoadJSONDictionary(feed)
+	if err != nil {
+		b.Fatal(err)
+	}
+	hash, err := FeedsHash(feed)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewIndex(dict).Save(&buf, hash); err != nil {
+		b.Fatal(err)
+	}
+	return dict, feed, buf.Bytes()
+}
+
+func BenchmarkNewIndex(b *testing.B) {
+	dir, err := ioutil.TempDir("", "cvefeed-index-")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dict, _, _ := benchIndexDict(b, dir)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewIndex(dict)
+	}
+}
+
+// BenchmarkLoadIndex includes hashing the feed, as it has to be done to load the index
+func BenchmarkLoadIndex(b *testing.B) {
+	dir, err := ioutil.TempDir("", "cvefeed-index-")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dict, feed, saved := benchIndexDict(b, dir)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash, err := FeedsHash(feed)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := LoadIndex(bytes.NewReader(saved), dict, hash); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"io"
+	"os"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// MatchCriteria maps the matchCriteriaId to the match criteria of NVD CPE Match Feed.
+// NVD CVE API 2.0 responses reference the match criteria by their IDs and may leave their version ranges
+// to the CPE Match Feed; such references are resolved when the dictionary is loaded with DictionaryOptions.MatchCriteria.
+type MatchCriteria map[string]*schema.CPEMatchAPIJSON20MatchString
+
+// ParseMatchCriteria parses the match criteria from the CPE Match Feed: a response of NVD CPE Match Criteria API 2.0,
+// possibly gzip-compressed. The CPE names matching the criteria aren't needed to resolve the references, so they aren't kept.
+// Errors are *FeedFormatError or *FeedIOError, as in ParseJSON.
+func ParseMatchCriteria(in io.Reader) (MatchCriteria, error) {
+	mc := make(MatchCriteria)
+	p := &feedParser{in: &errReader{r: in}}
+	if err := p.parseMatchCriteria(mc); err != nil {
+		return nil, err
+	}
+	return mc, nil
+}
+
+// LoadMatchCriteria loads the match criteria from multiple CPE Match Feed files, e.g. the pages of API responses.
+// If the same match criteria is in more than one file, the one from the latter file is kept.
+// Errors are *FeedFormatError or *FeedIOError of the first file which failed to load.
+func LoadMatchCriteria(paths ...string) (MatchCriteria, error) {
+	mc := make(MatchCriteria)
+	for _, path := range paths {
+		if err := loadMatchCriteriaFile(path, mc); err != nil {
+			return nil, err
+		}
+	}
+	return mc, nil
+}
+
+func loadMatchCriteriaFile(path string, mc MatchCriteria) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return &FeedIOError{Path: path, Err: err}
+	}
+	defer f.Close()
+	p := &feedParser{parseOptions: parseOptions{path: path}, in: &errReader{r: f}}
+	return p.parseMatchCriteria(mc)
+}
+
+// parseMatchCriteria decodes the elements of matchStrings array into mc
+func (p *feedParser) parseMatchCriteria(mc MatchCriteria) error {
+	return p.parseArrays(map[string]func(*feedDecoder) error{
+		"matchStrings": func(dec *feedDecoder) error {
+			if ok, err := p.expectArray(dec, "matchStrings"); !ok {
+				return err
+			}
+			for dec.More() {
+				offset := dec.InputOffset()
+				var item schema.CPEMatchAPIJSON20Item
+				if err := dec.Decode(&item); err != nil {
+					return p.fail(err, offset, "")
+				}
+				if m := item.MatchString; m != nil && m.MatchCriteriaID != "" {
+					m.Matches = nil
+					mc[m.MatchCriteriaID] = m
+				}
+			}
+			return p.expectDelim(dec, ']')
+		},
+	})
+}
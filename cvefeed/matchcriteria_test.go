@@ -0,0 +1,145 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+var testJSONmatchCriteria = `{"resultsPerPage":2,"startIndex":0,"totalResults":2,"format":"NVD_CPEMatchString","version":"2.0","timestamp":"2023-01-01T00:00:00.000","matchStrings":[
+{"matchString":{"matchCriteriaId":"00000000-0000-0000-0000-000000000001","criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"1.0","versionEndExcluding":"1.5",
+ "lastModified":"2023-01-01T00:00:00.000","created":"2023-01-01T00:00:00.000","status":"Active",
+ "matches":[{"cpeName":"cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*","cpeNameId":"10000000-0000-0000-0000-000000000001"}]}},
+{"matchString":{"matchCriteriaId":"00000000-0000-0000-0000-000000000002","criteria":"cpe:2.3:a:acme:gadget:*:*:*:*:*:*:*:*","versionEndIncluding":"2.0","status":"Active"}}]}`
+
+// CVE-2020-0001 leaves the range to the match feed, CVE-2020-0002 has its own and CVE-2020-0003 only the reference
+var testJSONapi20criteria = `{"resultsPerPage":3,"startIndex":0,"totalResults":3,"format":"NVD_CVE","version":"2.0","timestamp":"2023-01-01T00:00:00.000","vulnerabilities":[
+{"cve":{"id":"CVE-2020-0001","published":"2020-01-01T00:00:00.000","lastModified":"2020-01-01T00:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"Flaw in acme widget."}],
+ "configurations":[{"nodes":[{"operator":"OR","cpeMatch":[
+   {"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","matchCriteriaId":"00000000-0000-0000-0000-000000000001"}]}]}]}},
+{"cve":{"id":"CVE-2020-0002","published":"2020-01-01T00:00:00.000","lastModified":"2020-01-01T00:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"Another flaw in acme widget."}],
+ "configurations":[{"nodes":[{"operator":"OR","cpeMatch":[
+   {"vulnerable":true,"criteria":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionStartIncluding":"3.0","matchCriteriaId":"00000000-0000-0000-0000-000000000001"}]}]}]}},
+{"cve":{"id":"CVE-2020-0003","published":"2020-01-01T00:00:00.000","lastModified":"2020-01-01T00:00:00.000","vulnStatus":"Analyzed",
+ "descriptions":[{"lang":"en","value":"Flaw in acme gadget."}],
+ "configurations":[{"nodes":[{"operator":"OR","cpeMatch":[
+   {"vulnerable":true,"criteria":"","matchCriteriaId":"00000000-0000-0000-0000-000000000002"}]}]}]}}]}`
+
+func TestParseMatchCriteria(t *testing.T) {
+	mc, err := ParseMatchCriteria(bytes.NewBufferString(testJSONmatchCriteria))
+	if err != nil {
+		t.Fatalf("could not parse match criteria: %v", err)
+	}
+	if len(mc) != 2 {
+		t.Fatalf("expected 2 match criteria, got %d", len(mc))
+	}
+	m := mc["00000000-0000-0000-0000-000000000001"]
+	if m == nil {
+		t.Fatal("match criteria 00000000-0000-0000-0000-000000000001 is missing")
+	}
+	if m.Criteria != "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*" || m.VersionStartIncluding != "1.0" || m.VersionEndExcluding != "1.5" {
+		t.Errorf("wrong match criteria: %+v", m)
+	}
+	if m.Matches != nil {
+		t.Errorf("matching CPE names should be dropped, got %v", m.Matches)
+	}
+
+	_, err = ParseMatchCriteria(bytes.NewBufferString(`{"matchStrings":[{"matchString":{"matchCriteriaId":1}}]}`))
+	if _, ok := err.(*FeedFormatError); !ok {
+		t.Fatalf("expected *FeedFormatError, got %v", err)
+	}
+}
+
+func TestLoadJSONDictionaryMatchCriteria(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(testJSONmatchCriteria))
+	zw.Close()
+	files := map[string][]byte{
+		"cpematch.json.gz": gz.Bytes(),
+		"cves.json":        []byte(testJSONapi20criteria),
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mc, err := LoadMatchCriteria(filepath.Join(dir, "cpematch.json.gz"))
+	if err != nil {
+		t.Fatalf("could not load match criteria: %v", err)
+	}
+	if _, err := LoadMatchCriteria(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("loading missing match criteria should fail")
+	}
+
+	cases := []struct {
+		cpe      string
+		resolved []string
+		inline   []string
+	}{
+		{"cpe:/a:acme:widget:1.2", []string{"CVE-2020-0001"}, []string{"CVE-2020-0001"}},
+		{"cpe:/a:acme:widget:1.5", nil, []string{"CVE-2020-0001"}},
+		{"cpe:/a:acme:widget:3.1", []string{"CVE-2020-0002"}, []string{"CVE-2020-0001", "CVE-2020-0002"}},
+		{"cpe:/a:acme:gadget:1.0", []string{"CVE-2020-0003"}, nil},
+		{"cpe:/a:acme:gadget:2.1", nil, nil},
+	}
+	for _, opts := range []DictionaryOptions{{MatchCriteria: mc}, {}} {
+		dict, err := LoadJSONDictionaryWithOptions(opts, filepath.Join(dir, "cves.json"))
+		if err != nil {
+			t.Fatalf("could not load dictionary: %v", err)
+		}
+		for _, c := range cases {
+			expect := c.resolved
+			if opts.MatchCriteria == nil {
+				expect = c.inline
+			}
+			attr, err := wfn.UnbindURI(c.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			dict.Each(func(id string, v Vuln) bool {
+				if len(v.Match([]*wfn.Attributes{attr}, false)) != 0 {
+					got = append(got, id)
+				}
+				return true
+			})
+			if len(got) != len(expect) {
+				t.Errorf("%s (resolved: %t): expected %v, got %v", c.cpe, opts.MatchCriteria != nil, expect, got)
+				continue
+			}
+			for i := range got {
+				if got[i] != expect[i] {
+					t.Errorf("%s (resolved: %t): expected %v, got %v", c.cpe, opts.MatchCriteria != nil, expect, got)
+					break
+				}
+			}
+		}
+	}
+}
@@ -123,6 +123,106 @@ func TestMatchJSONsmartVersionMatching(t *testing.T) {
 	}
 }
 
+func TestMatchJSONand(t *testing.T) {
+	flash := &wfn.Attributes{Part: "a", Vendor: "adobe", Product: "flash_player", Version: "28\\.0\\.0\\.137"}
+	flashFixed := &wfn.Attributes{Part: "a", Vendor: "adobe", Product: "flash_player", Version: "28\\.0\\.0\\.161"}
+	windows := &wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "windows"}
+	linux := &wfn.Attributes{Part: "o", Vendor: "linux", Product: "linux_kernel"}
+	ios := &wfn.Attributes{Part: "o", Vendor: "apple", Product: "iphone_os", Version: "11\\.0"}
+	cases := []struct {
+		Inventory []*wfn.Attributes
+		Matches   []*wfn.Attributes
+	}{
+		{
+			Inventory: []*wfn.Attributes{flash},
+		},
+		{
+			Inventory: []*wfn.Attributes{windows},
+		},
+		{
+			Inventory: []*wfn.Attributes{flash, ios},
+		},
+		{
+			Inventory: []*wfn.Attributes{flashFixed, windows},
+		},
+		// the platforms (vulnerable:false) aren't reported as matched
+		{
+			Inventory: []*wfn.Attributes{flash, windows},
+			Matches:   []*wfn.Attributes{flash},
+		},
+		{
+			Inventory: []*wfn.Attributes{ios, linux, flash},
+			Matches:   []*wfn.Attributes{flash},
+		},
+	}
+	items, err := ParseJSON(bytes.NewBufferString(testJSONdictAND))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			mm := items[0].Match(c.Inventory, false)
+			if len(mm) != len(c.Matches) {
+				t.Fatalf("expected %d matches, got %d matches", len(c.Matches), len(mm))
+			}
+			if len(mm) > 0 && !matchesAll(mm, c.Matches) {
+				t.Fatalf("wrong match: expected %v, got %v", c.Matches, mm)
+			}
+			for _, m := range mm {
+				if m.Part == "o" {
+					t.Fatalf("platform %v reported as matched", m)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchJSONnotVulnerable(t *testing.T) {
+	flash := &wfn.Attributes{Part: "a", Vendor: "adobe", Product: "flash_player", Version: "28\\.0\\.0\\.137"}
+	flashFixed := &wfn.Attributes{Part: "a", Vendor: "adobe", Product: "flash_player", Version: "28\\.0\\.0\\.161"}
+	windows := &wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "windows"}
+	linux := &wfn.Attributes{Part: "o", Vendor: "linux", Product: "linux_kernel"}
+	cases := []struct {
+		Inventory []*wfn.Attributes
+		Matches   []*wfn.Attributes
+	}{
+		// the only matching cpe_match is a non-vulnerable one
+		{
+			Inventory: []*wfn.Attributes{linux},
+		},
+		{
+			Inventory: []*wfn.Attributes{windows},
+		},
+		{
+			Inventory: []*wfn.Attributes{flashFixed, windows, linux},
+		},
+		{
+			Inventory: []*wfn.Attributes{flash, windows},
+			Matches:   []*wfn.Attributes{flash},
+		},
+	}
+	items, err := ParseJSON(bytes.NewBufferString(testJSONdictNotVulnerable))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			mm := items[0].Match(c.Inventory, false)
+			if len(mm) != len(c.Matches) {
+				t.Fatalf("expected %d matches, got %d matches", len(c.Matches), len(mm))
+			}
+			if len(mm) > 0 && !matchesAll(mm, c.Matches) {
+				t.Fatalf("wrong match: expected %v, got %v", c.Matches, mm)
+			}
+			for _, m := range mm {
+				if m.Part == "o" {
+					t.Fatalf("platform %v reported as matched", m)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkMatchJSON(b *testing.B) {
 	inventory := []*wfn.Attributes{
 		{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp3"},
@@ -283,3 +383,135 @@ func matchesAll(src, tgt []*wfn.Attributes) bool {
 	}
 	return true
 }
+
+// CVE-2018-4878: Adobe Flash Player is vulnerable only when running on one of the listed OSes
+var testJSONdictAND = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "1",
+"CVE_data_timestamp" : "2018-07-31T07:00Z",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "CVE-2018-4878",
+        "ASSIGNER" : "psirt@adobe.com"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "AND",
+          "children" : [
+            {
+              "operator" : "OR",
+              "cpe_match" : [
+                {
+                  "vulnerable" : true,
+                  "cpe23Uri" : "cpe:2.3:a:adobe:flash_player:*:*:*:*:*:*:*:*",
+                  "versionEndIncluding" : "28.0.0.137"
+                }
+              ]
+            },
+            {
+              "operator" : "OR",
+              "cpe_match" : [
+                {
+                  "vulnerable" : false,
+                  "cpe23Uri" : "cpe:2.3:o:apple:mac_os_x:-:*:*:*:*:*:*:*"
+                },
+                {
+                  "vulnerable" : false,
+                  "cpe23Uri" : "cpe:2.3:o:google:chrome_os:-:*:*:*:*:*:*:*"
+                },
+                {
+                  "vulnerable" : false,
+                  "cpe23Uri" : "cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"
+                },
+                {
+                  "vulnerable" : false,
+                  "cpe23Uri" : "cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:*:*"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    },
+    "publishedDate" : "2018-02-06T09:29Z",
+    "lastModifiedDate" : "2018-03-07T02:29Z"
+  }
+]
+}
+`
+
+var testJSONdictNotVulnerable = `{
+"CVE_data_type" : "CVE",
+"CVE_data_format" : "MITRE",
+"CVE_data_version" : "4.0",
+"CVE_data_numberOfCVEs" : "1",
+"CVE_data_timestamp" : "2018-07-31T07:00Z",
+"CVE_Items" : [
+  {
+    "cve" : {
+      "data_type" : "CVE",
+      "data_format" : "MITRE",
+      "data_version" : "4.0",
+      "CVE_data_meta" : {
+        "ID" : "CVE-2018-4878",
+        "ASSIGNER" : "psirt@adobe.com"
+      }
+    },
+    "configurations" : {
+      "CVE_data_version" : "4.0",
+      "nodes" : [
+        {
+          "operator" : "AND",
+          "children" : [
+            {
+              "operator" : "OR",
+              "cpe_match" : [
+                {
+                  "vulnerable" : true,
+                  "cpe23Uri" : "cpe:2.3:a:adobe:flash_player:*:*:*:*:*:*:*:*",
+                  "versionEndIncluding" : "28.0.0.137"
+                }
+              ]
+            },
+            {
+              "operator" : "OR",
+              "cpe_match" : [
+                {
+                  "vulnerable" : false,
+                  "cpe23Uri" : "cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:*:*"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "operator" : "OR",
+          "cpe_match" : [
+            {
+              "vulnerable" : false,
+              "cpe23Uri" : "cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"
+            },
+            {
+              "vulnerable" : false,
+              "cpe23Uri" : "cpe:2.3:o:microsoft:windows:-:*:*:*:*:*:*:*"
+            }
+          ]
+        }
+      ]
+    },
+    "publishedDate" : "2018-02-06T09:29Z",
+    "lastModifiedDate" : "2018-03-07T02:29Z"
+  }
+]
+}
+`
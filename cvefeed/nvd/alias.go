@@ -0,0 +1,156 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// alias makes the CPE names matching from (in part, vendor and product) also match as the ones named as to
+type alias struct {
+	from, to wfn.Attributes
+}
+
+// AddAlias makes v match the CPE names which are known to the feed under another name: the ones matching from
+// in part, vendor and product are also matched with these attributes replaced by the ones of to, e.g.
+// vendor elastic and product ANY aliased to vendor elasticsearch and product ANY makes cpe:/a:elastic:kibana:6.0
+// also match as cpe:/a:elasticsearch:kibana:6.0. ANY attributes of from match any value, ANY attributes of to
+// leave the value as is. Aliased names which matched are reported as the original ones.
+// It isn't safe to call while v is being matched.
+func (v *Vuln) AddAlias(from, to *wfn.Attributes) {
+	if v == nil || from == nil || to == nil {
+		return
+	}
+	v.aliases = append(v.aliases, alias{from: *from, to: *to})
+}
+
+// Match is a part of the wfn.Matcher interface, attrs are matched along with their aliases, see AddAlias
+func (v *Vuln) Match(attrs []*wfn.Attributes, requireVersion bool) []*wfn.Attributes {
+	if len(v.aliases) == 0 {
+		return v.Matcher.Match(attrs, requireVersion)
+	}
+	expanded, origin := v.expandAliases(attrs)
+	return unalias(v.Matcher.Match(expanded, requireVersion), origin)
+}
+
+// Config is a part of the wfn.Matcher interface; besides the names in the configuration,
+// it returns the ones they are aliases of, so that indexes built from it find v by any of them
+func (v *Vuln) Config() []*wfn.Attributes {
+	config := v.Matcher.Config()
+	if len(v.aliases) == 0 {
+		return config
+	}
+	config = config[:len(config):len(config)]
+	for _, cpe := range config {
+		if cpe == nil {
+			continue
+		}
+		for _, a := range v.aliases {
+			if matchesProduct(&a.to, cpe) {
+				config = append(config, renameProduct(cpe, &a.from))
+			}
+		}
+	}
+	return config
+}
+
+// expandAliases returns attrs followed by their aliases, along with the originals of the aliases
+func (v *Vuln) expandAliases(attrs []*wfn.Attributes) ([]*wfn.Attributes, map[*wfn.Attributes]*wfn.Attributes) {
+	var expanded []*wfn.Attributes
+	var origin map[*wfn.Attributes]*wfn.Attributes
+	for _, attr := range attrs {
+		if attr == nil {
+			continue
+		}
+		for _, a := range v.aliases {
+			if !matchesProduct(&a.from, attr) {
+				continue
+			}
+			if expanded == nil {
+				expanded = append([]*wfn.Attributes(nil), attrs...)
+				origin = map[*wfn.Attributes]*wfn.Attributes{}
+			}
+			aliased := renameProduct(attr, &a.to)
+			expanded = append(expanded, aliased)
+			origin[aliased] = attr
+		}
+	}
+	if expanded == nil {
+		return attrs, nil
+	}
+	return expanded, origin
+}
+
+// unalias replaces the aliased attributes in matches with their originals
+func unalias(matches []*wfn.Attributes, origin map[*wfn.Attributes]*wfn.Attributes) []*wfn.Attributes {
+	for i, m := range matches {
+		if attr, ok := origin[m]; ok {
+			matches[i] = attr
+		}
+	}
+	return matches
+}
+
+// matchesProduct returns true if attr has the part, vendor and product of pattern, unless they're ANY in pattern
+func matchesProduct(pattern, attr *wfn.Attributes) bool {
+	return matchesValue(pattern.Part, attr.Part) &&
+		matchesValue(pattern.Vendor, attr.Vendor) &&
+		matchesValue(pattern.Product, attr.Product)
+}
+
+// matchesValue returns true if pattern is ANY or equal to value, ignoring ASCII case and needless quoting
+func matchesValue(pattern, value string) bool {
+	if pattern == wfn.Any || pattern == value {
+		return true
+	}
+	if pattern == wfn.NA || value == wfn.NA || value == wfn.Any {
+		return false
+	}
+	i, j := 0, 0
+	for ; i < len(pattern) && j < len(value); i, j = i+1, j+1 {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			i++
+		}
+		if value[j] == '\\' && j+1 < len(value) {
+			j++
+		}
+		if lowerASCII(pattern[i]) != lowerASCII(value[j]) {
+			return false
+		}
+	}
+	return i == len(pattern) && j == len(value)
+}
+
+func lowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// renameProduct returns a copy of attr with part, vendor and product replaced by the ones of name, unless they're ANY
+func renameProduct(attr, name *wfn.Attributes) *wfn.Attributes {
+	renamed := *attr
+	if name.Part != wfn.Any {
+		renamed.Part = name.Part
+	}
+	if name.Vendor != wfn.Any {
+		renamed.Vendor = name.Vendor
+	}
+	if name.Product != wfn.Any {
+		renamed.Product = name.Product
+	}
+	return &renamed
+}
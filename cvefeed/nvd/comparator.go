@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import "fmt"
+
+// VersionComparator compares versions v1 and v2 of software.
+// Returns -1 if v1 < v2, 1 if v1 > v2 and 0 if v1 == v2.
+type VersionComparator func(v1, v2 string) int
+
+// VersionUnknownPolicy tells how the CPE names of unknown version (ANY) match the cpe_match entries
+type VersionUnknownPolicy int
+
+const (
+	// VersionUnknownDefault matches ANY version with any specific version, and compares it to the version ranges
+	// as an empty version, which is lower than any other: it's within the ranges without the lower bound only
+	VersionUnknownDefault VersionUnknownPolicy = iota
+	// VersionUnknownConservative matches ANY version with any specific version and any version range:
+	// software of unknown version is reported as potentially vulnerable
+	VersionUnknownConservative
+	// VersionUnknownStrict matches ANY version only with ANY version without version ranges
+	VersionUnknownStrict
+)
+
+// String implements fmt.Stringer interface
+func (p VersionUnknownPolicy) String() string {
+	switch p {
+	case VersionUnknownDefault:
+		return "default"
+	case VersionUnknownConservative:
+		return "conservative"
+	case VersionUnknownStrict:
+		return "strict"
+	default:
+		return fmt.Sprintf("VersionUnknownPolicy(%d)", int(p))
+	}
+}
+
+// ParseVersionUnknownPolicy parses the policy from its name, as returned by String
+func ParseVersionUnknownPolicy(s string) (VersionUnknownPolicy, error) {
+	for _, p := range []VersionUnknownPolicy{VersionUnknownDefault, VersionUnknownConservative, VersionUnknownStrict} {
+		if s == p.String() {
+			return p, nil
+		}
+	}
+	return VersionUnknownDefault, fmt.Errorf("unknown version policy %q: must be one of default, conservative or strict", s)
+}
+
+// comparators holds how versions are compared when matching a vulnerability: the version comparators
+// by vendor and product, and the policy for unknown versions
+type comparators struct {
+	m              map[string]VersionComparator
+	versionUnknown VersionUnknownPolicy
+}
+
+func comparatorKey(vendor, product string) string {
+	return vendor + ":" + product
+}
+
+// get returns the comparator for vendor and product, smartVerCmp if none was set
+func (c *comparators) get(vendor, product string) VersionComparator {
+	if c != nil {
+		if cmp := c.m[comparatorKey(vendor, product)]; cmp != nil {
+			return cmp
+		}
+	}
+	return smartVerCmp
+}
+
+// versionUnknownPolicy returns the policy for unknown versions
+func (c *comparators) versionUnknownPolicy() VersionUnknownPolicy {
+	if c == nil {
+		return VersionUnknownDefault
+	}
+	return c.versionUnknown
+}
+
+// SetVersionUnknownPolicy sets how v is matched by the CPE names of unknown (ANY) version.
+// It isn't safe to call while v is being matched.
+func (v *Vuln) SetVersionUnknownPolicy(p VersionUnknownPolicy) {
+	if v == nil || v.comparators == nil {
+		return
+	}
+	v.comparators.versionUnknown = p
+}
+
+// SetVersionComparator makes v compare the versions of the given vendor and product with cmp
+// when matching version ranges, instead of CompareSmartVersions; nil cmp restores the default.
+// Vendor and product are WFN attribute values, as in the feed. It isn't safe to call while v is being matched.
+func (v *Vuln) SetVersionComparator(vendor, product string, cmp VersionComparator) {
+	if v == nil || v.comparators == nil {
+		return
+	}
+	key := comparatorKey(vendor, product)
+	if cmp == nil {
+		delete(v.comparators.m, key)
+		return
+	}
+	if v.comparators.m == nil {
+		v.comparators.m = make(map[string]VersionComparator)
+	}
+	v.comparators.m[key] = cmp
+}
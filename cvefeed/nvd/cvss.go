@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"math"
+
+	"github.com/facebookincubator/nvdtools/cvss2"
+	"github.com/facebookincubator/nvdtools/cvss3"
+)
+
+// CVSSScoreTolerance is how much the base score stated in the feed may differ from the one computed from its vector,
+// e.g. because of the rounding differences between the versions of CVSS v3 (roundup of v3.0 and v3.1)
+const CVSSScoreTolerance = 0.1
+
+// CVSSConsistent recomputes the base scores from the CVSS v3 and v2 vectors of the vulnerability and tells if they
+// match the base scores stated in the feed, within CVSSScoreTolerance. The computed and stated scores are those
+// of the first inconsistent metric, v3 before v2, or else of v3 if it's set, or else of v2; an unparseable vector
+// is inconsistent and its computed score is 0. The vulnerability without any vectors is consistent.
+func (v *Vuln) CVSSConsistent() (ok bool, computed, stated float64) {
+	type metric struct {
+		vector string
+		score  float64
+		base   func(string) (float64, error)
+	}
+	var metrics []metric
+	if c := v.cvssv3(); c != nil && c.VectorString != "" {
+		metrics = append(metrics, metric{c.VectorString, c.BaseScore, cvss3BaseScore})
+	}
+	if c := v.cvssv2(); c != nil && c.VectorString != "" {
+		metrics = append(metrics, metric{c.VectorString, c.BaseScore, cvss2BaseScore})
+	}
+	for i, m := range metrics {
+		c, err := m.base(m.vector)
+		if err != nil || math.Abs(c-m.score) > CVSSScoreTolerance+1e-9 {
+			return false, c, m.score
+		}
+		if i == 0 {
+			computed, stated = c, m.score
+		}
+	}
+	return true, computed, stated
+}
+
+func cvss3BaseScore(vector string) (float64, error) {
+	v, err := cvss3.VectorFromString(vector)
+	if err != nil {
+		return 0, err
+	}
+	if err := v.Validate(); err != nil {
+		return 0, err
+	}
+	return v.BaseScore(), nil
+}
+
+func cvss2BaseScore(vector string) (float64, error) {
+	v, err := cvss2.VectorFromStringStrict(vector)
+	if err != nil {
+		return 0, err
+	}
+	return v.BaseScore(), nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// cvssItem returns the feed entry with the given CVSS v3 and v2 vectors and base scores; empty vectors are omitted
+func cvssItem(v3 string, score3 float64, v2 string, score2 float64) *schema.NVDCVEFeedJSON10DefCVEItem {
+	item := &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE:            &schema.CVEJSON40{CVEDataMeta: &schema.CVEJSON40CVEDataMeta{ID: "CVE-2019-0001"}},
+		Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{},
+		Impact:         &schema.NVDCVEFeedJSON10DefImpact{},
+	}
+	if v3 != "" {
+		item.Impact.BaseMetricV3 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &schema.CVSSV30{Version: "3.1", VectorString: v3, BaseScore: score3},
+		}
+	}
+	if v2 != "" {
+		item.Impact.BaseMetricV2 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			CVSSV2: &schema.CVSSV20{Version: "2.0", VectorString: v2, BaseScore: score2},
+		}
+	}
+	return item
+}
+
+func TestVulnCVSSConsistent(t *testing.T) {
+	const (
+		v3crit = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H" // 9.8
+		v3med  = "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N" // 6.1
+		v2med  = "AV:N/AC:M/Au:N/C:N/I:P/A:N"                   // 4.3
+		v2high = "AV:N/AC:L/Au:N/C:P/I:P/A:P"                   // 7.5
+	)
+	cases := []struct {
+		name     string
+		item     *schema.NVDCVEFeedJSON10DefCVEItem
+		ok       bool
+		computed float64
+		stated   float64
+	}{
+		{"consistent", cvssItem(v3med, 6.1, v2med, 4.3), true, 6.1, 6.1},
+		{"consistent v2 only", cvssItem("", 0, v2high, 7.5), true, 7.5, 7.5},
+		{"within tolerance", cvssItem(v3crit, 9.7, "", 0), true, 9.8, 9.7},
+		{"inconsistent v3", cvssItem(v3crit, 5.0, v2med, 4.3), false, 9.8, 5.0},
+		{"inconsistent v2", cvssItem(v3med, 6.1, v2high, 10.0), false, 7.5, 10.0},
+		{"unparseable vector", cvssItem("CVSS:3.1/AV:X", 5.0, "", 0), false, 0, 5.0},
+		{"no vectors", cvssItem("", 0, "", 0), true, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ok, computed, stated := ToVuln(c.item).CVSSConsistent()
+			if ok != c.ok || computed != c.computed || stated != c.stated {
+				t.Fatalf("got %t, %v, %v, expected %t, %v, %v", ok, computed, stated, c.ok, c.computed, c.stated)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nvd implements the matching of NVD CVE JSON feed entries against CPE names.
+//
+// It also provides CompareSmartVersions, a heuristic comparison of free-form software versions, as found in the
+// versionStartIncluding, versionEndExcluding etc. fields of the feed. The versions are split into parts on any
+// punctuation character; the parts are compared left to right, a longer leading run of digits winning over
+// a shorter one, and remaining characters being compared lexically. If all parts are equal, the version with
+// more parts is greater, unless its extra parts are all zeros ("2.0" == "2.0.0") or start with a pre-release tag
+// (alpha, beta, rc, pre, dev, snapshot), possibly after zero parts, in which case it is lesser ("2.0.0-rc1" < "2.0").
+// Build metadata after '+' is ignored.
+// Versions which both consist of digits only are compared as integers, so "007" == "7".
+//
+// The comparison assumes both versions follow the same convention: it works for "95SE" vs "98SP1" or "16.3.2" vs "3.7.0".
+// Mixing conventions gives a defined, if not always meaningful, result: "2000" > "11.7", because 2000 has more digits than 11.
+//
+// A leading "v", as in "v1.2.3", makes the version lesser than any version starting with a digit;
+// CompareSmartVersionsMode with SmartVersionStripV ignores it, so "v1.2.3" == "1.2.3".
+// SmartVersionEpoch compares the epochs of package versions such as "1:2.3.4" first; modes are selected for the
+// matching with SmartVersionComparator.
+package nvd
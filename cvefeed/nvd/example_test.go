@@ -0,0 +1,47 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd_test
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+)
+
+func ExampleCompareSmartVersions() {
+	fmt.Println(nvd.CompareSmartVersions("1.0.14", "1.0.4"))
+	fmt.Println(nvd.CompareSmartVersions("95SE", "98SP1"))
+	fmt.Println(nvd.CompareSmartVersions("1.2.0-rc1", "1.2.0"))
+	fmt.Println(nvd.CompareSmartVersions("1.2.0+build5", "1.2.0"))
+	// known limitation: more digits always win
+	fmt.Println(nvd.CompareSmartVersions("2000", "11.7"))
+	// Output:
+	// 1
+	// -1
+	// -1
+	// 0
+	// 1
+}
+
+func ExampleCompareSmartVersions_sort() {
+	versions := []string{"16.3.2", "3.7.0", "3.7.0-beta", "3.10", "1.0"}
+	sort.Slice(versions, func(i, j int) bool {
+		return nvd.CompareSmartVersions(versions[i], versions[j]) < 0
+	})
+	fmt.Println(versions)
+	// Output:
+	// [1.0 3.7.0-beta 3.7.0 3.10 16.3.2]
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// Interner shares identical cpe_match criteria, i.e. the same CPE name with the same version bounds,
+// between the vulnerabilities converted with it: NVD feeds repeat them across many CVEs, and each copy
+// would be parsed and kept in memory on its own otherwise. Matching isn't affected.
+// The zero value is ready to use; Interner is safe for concurrent use.
+type Interner struct {
+	mu sync.Mutex
+	m  map[string]*cpeCriteria
+}
+
+// ToVuln is like ToVulnWithLogger, but the criteria of cpe_match entries are shared with the identical ones
+// of the vulnerabilities converted with in before. Nil Interner doesn't share anything.
+func (in *Interner) ToVuln(cve *schema.NVDCVEFeedJSON10DefCVEItem, log logging.Logger) *Vuln {
+	return ToVulnWithOptions(cve, ConvertOptions{Logger: log, Interner: in})
+}
+
+// Len returns the number of distinct criteria known to in
+func (in *Interner) Len() int {
+	if in == nil {
+		return 0
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.m)
+}
+
+// criteria returns the criteria of the cpe_match entry, the ones known to in if they're identical
+func (in *Interner) criteria(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch) (*cpeCriteria, error) {
+	if in == nil {
+		return newCPECriteria(nvdMatch)
+	}
+	key := strings.Join([]string{
+		nvdMatch.Cpe23Uri,
+		nvdMatch.Cpe22Uri,
+		nvdMatch.VersionStartIncluding,
+		nvdMatch.VersionStartExcluding,
+		nvdMatch.VersionEndIncluding,
+		nvdMatch.VersionEndExcluding,
+	}, "\x00")
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if c, ok := in.m[key]; ok {
+		return c, nil
+	}
+	c, err := newCPECriteria(nvdMatch)
+	if err != nil {
+		return nil, err
+	}
+	if in.m == nil {
+		in.m = make(map[string]*cpeCriteria)
+	}
+	in.m[key] = c
+	return c, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// internerItem returns a feed entry for foo:bar and baz:qux up to 2.0, and for the product of its own
+func internerItem(i int) *schema.NVDCVEFeedJSON10DefCVEItem {
+	return &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{CVEDataMeta: &schema.CVEJSON40CVEDataMeta{ID: fmt.Sprintf("CVE-2019-%04d", i)}},
+		Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{
+			Nodes: []*schema.NVDCVEFeedJSON10DefNode{
+				{
+					Operator: "OR",
+					CPEMatch: []*schema.NVDCVEFeedJSON10DefCPEMatch{
+						{Cpe23Uri: "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0", Vulnerable: true},
+						{Cpe23Uri: fmt.Sprintf("cpe:2.3:a:foo:product%d:*:*:*:*:*:*:*:*", i), Vulnerable: true},
+					},
+				},
+				{
+					Operator: "AND",
+					Children: []*schema.NVDCVEFeedJSON10DefNode{
+						{Operator: "OR", CPEMatch: []*schema.NVDCVEFeedJSON10DefCPEMatch{
+							// same CPE as above, but different bounds
+							{Cpe23Uri: "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*", VersionEndIncluding: "2.0", Vulnerable: true},
+						}},
+						{Operator: "OR", CPEMatch: []*schema.NVDCVEFeedJSON10DefCPEMatch{
+							{Cpe23Uri: "cpe:2.3:o:linux:linux_kernel:*:*:*:*:*:*:*:*", Vulnerable: false},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInterner(t *testing.T) {
+	var in Interner
+	var wg sync.WaitGroup
+	interned := make([]*Vuln, 50)
+	for i := range interned {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			interned[i] = in.ToVuln(internerItem(i), nil)
+		}(i)
+	}
+	wg.Wait()
+	// foo:bar twice, linux_kernel and the product of every entry
+	if n := in.Len(); n != 3+len(interned) {
+		t.Fatalf("expected %d distinct criteria, got %d", 3+len(interned), n)
+	}
+
+	parse := func(s string) *wfn.Attributes {
+		attr, err := wfn.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return attr
+	}
+	inputs := [][]*wfn.Attributes{
+		{parse("cpe:/a:foo:bar:1.0")},
+		{parse("cpe:/a:foo:bar:2.0")},
+		{parse("cpe:/a:foo:bar:2.0"), parse("cpe:/o:linux:linux_kernel:5.0")},
+		{parse("cpe:/a:foo:bar:3.0"), parse("cpe:/o:linux:linux_kernel:5.0")},
+		{parse("cpe:/a:foo:product7")},
+		{parse("cpe:/o:linux:linux_kernel:5.0")},
+	}
+	for i, v := range interned {
+		expect := ToVuln(internerItem(i))
+		for _, attrs := range inputs {
+			if got, want := len(v.Match(attrs, false)), len(expect.Match(attrs, false)); got != want {
+				t.Errorf("%s: %v: %d matches with interned criteria, %d without", v.ID(), attrs, got, want)
+			}
+		}
+	}
+
+	// the criteria are shared, but not the comparators
+	config1, config2 := interned[1].Config(), interned[2].Config()
+	if config1[0] != config2[0] {
+		t.Error("identical criteria weren't shared")
+	}
+	if config1[0] == config1[2] {
+		t.Error("criteria with different bounds were shared")
+	}
+	interned[1].SetVersionComparator("foo", "bar", func(v1, v2 string) int { return -1 })
+	attrs := inputs[3]
+	if len(interned[1].Match(attrs, false)) == 0 || len(interned[2].Match(attrs, false)) != 0 {
+		t.Error("version comparator of one entry affected another")
+	}
+}
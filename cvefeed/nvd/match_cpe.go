@@ -23,8 +23,15 @@ import (
 
 // cpeMatch is a wrapper around the actual NVDCVEFeedJSON10DefCPEMatch
 type cpeMatch struct {
+	*cpeCriteria
+	vulnerable  bool
+	comparators *comparators
+}
+
+// cpeCriteria is what a cpe_match entry matches: the CPE name and the version ranges;
+// identical criteria can be shared by the entries of different vulnerabilities, see Interner
+type cpeCriteria struct {
 	*wfn.Attributes
-	vulnerable            bool
 	versionEndExcluding   string
 	versionEndIncluding   string
 	versionStartExcluding string
@@ -32,8 +39,19 @@ type cpeMatch struct {
 	hasVersionRanges      bool
 }
 
-// Matcher returns an object which knows how to match attributes
-func cpeMatcher(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch) (wfn.Matcher, error) {
+// Matcher returns an object which knows how to match attributes;
+// version ranges are matched with the comparators registered for the vendor and product, if any.
+// The criteria of the entry are shared with the identical ones converted with in before, if in isn't nil.
+func cpeMatcher(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch, cmps *comparators, in *Interner) (wfn.Matcher, error) {
+	criteria, err := in.criteria(nvdMatch)
+	if err != nil {
+		return nil, err
+	}
+	return &cpeMatch{cpeCriteria: criteria, vulnerable: nvdMatch.Vulnerable, comparators: cmps}, nil
+}
+
+// newCPECriteria parses the criteria of the cpe_match entry
+func newCPECriteria(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch) (*cpeCriteria, error) {
 	parse := func(uri string) (*wfn.Attributes, error) {
 		if uri == "" {
 			return nil, fmt.Errorf("can't parse empty uri")
@@ -42,31 +60,34 @@ func cpeMatcher(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch) (wfn.Matcher, erro
 	}
 
 	// parse
-	match := cpeMatch{vulnerable: nvdMatch.Vulnerable}
+	var criteria cpeCriteria
 	var err error
-	if match.Attributes, err = parse(nvdMatch.Cpe23Uri); err != nil {
-		if match.Attributes, err = parse(nvdMatch.Cpe22Uri); err != nil {
+	if criteria.Attributes, err = parse(nvdMatch.Cpe23Uri); err != nil {
+		if criteria.Attributes, err = parse(nvdMatch.Cpe22Uri); err != nil {
 			return nil, fmt.Errorf("unable to parse both cpe2.2 and cpe2.3")
 		}
 	}
 
-	match.versionEndExcluding = nvdMatch.VersionEndExcluding
-	match.versionEndIncluding = nvdMatch.VersionEndIncluding
-	match.versionStartExcluding = nvdMatch.VersionStartExcluding
-	match.versionStartIncluding = nvdMatch.VersionStartIncluding
+	criteria.versionEndExcluding = nvdMatch.VersionEndExcluding
+	criteria.versionEndIncluding = nvdMatch.VersionEndIncluding
+	criteria.versionStartExcluding = nvdMatch.VersionStartExcluding
+	criteria.versionStartIncluding = nvdMatch.VersionStartIncluding
 
-	if match.versionStartIncluding != "" || match.versionStartExcluding != "" ||
-		match.versionEndIncluding != "" || match.versionEndExcluding != "" {
-		match.hasVersionRanges = true
+	if criteria.versionStartIncluding != "" || criteria.versionStartExcluding != "" ||
+		criteria.versionEndIncluding != "" || criteria.versionEndExcluding != "" {
+		criteria.hasVersionRanges = true
 	}
 
-	return &match, nil
+	return &criteria, nil
 }
 
-// Match is part of the Matcher interface
+// Match is part of the Matcher interface.
+// Non-vulnerable CPEs (e.g. the platform the vulnerable software runs on) are matched the same way as the vulnerable ones:
+// their presence in attrs is what is required to satisfy an AND configuration; see vulnerableNodeMatcher
+// for how they're kept from making a finding on their own.
 func (cm *cpeMatch) Match(attrs []*wfn.Attributes, requireVersion bool) (matches []*wfn.Attributes) {
 	for _, attr := range attrs {
-		if cm.match(attr, requireVersion) == cm.vulnerable {
+		if cm.match(attr, requireVersion) {
 			matches = append(matches, attr)
 		}
 	}
@@ -75,7 +96,7 @@ func (cm *cpeMatch) Match(attrs []*wfn.Attributes, requireVersion bool) (matches
 
 // Match implements wfn.Matcher interface
 func (cm *cpeMatch) match(attr *wfn.Attributes, requireVersion bool) bool {
-	if cm == nil || cm.Attributes == nil {
+	if cm == nil || cm.cpeCriteria == nil || cm.Attributes == nil {
 		return false
 	}
 
@@ -93,6 +114,22 @@ func (cm *cpeMatch) match(attr *wfn.Attributes, requireVersion bool) bool {
 		return false
 	}
 
+	if attr.Version == wfn.Any {
+		switch cm.comparators.versionUnknownPolicy() {
+		case VersionUnknownConservative:
+			return true
+		case VersionUnknownStrict:
+			return cm.Attributes.Version == wfn.Any && !cm.hasVersionRanges
+		}
+	}
+
+	// NVD names the hardware without version (NA), the firmware it runs is a separate operating system entry;
+	// the version of a hardware CPE, usually the firmware's in inventories, doesn't keep it from matching then.
+	// The version ranges of hardware entries, if any, are matched as for the other parts.
+	if cm.Attributes.Part == "h" && cm.Attributes.Version == wfn.NA && !cm.hasVersionRanges {
+		return true
+	}
+
 	if cm.Attributes.Version == wfn.Any {
 		if !cm.hasVersionRanges {
 			// if version is any and doesn't have version ranges, then it matches any
@@ -110,22 +147,31 @@ func (cm *cpeMatch) match(attr *wfn.Attributes, requireVersion bool) bool {
 		return false
 	}
 
-	// match version to ranges
+	// the CPE names not applicable to versions (NA) aren't within any version range, not even within
+	// the ranges without the lower bound, i.e. "all versions before the fix", where NA would compare
+	// lower than any version
+	if attr.Version == wfn.NA {
+		return false
+	}
+
+	// match version to ranges; without the lower bound, any version lower than the upper one is within the range,
+	// the pre-releases of the upper one included (see smartVerCmp)
 	ver := wfn.StripSlashes(attr.Version)
 
 	matches := true
+	cmp := cm.comparators.get(cm.Attributes.Vendor, cm.Attributes.Product)
 
 	if cm.versionStartIncluding != "" {
-		matches = matches && smartVerCmp(ver, cm.versionStartIncluding) >= 0
+		matches = matches && cmp(ver, cm.versionStartIncluding) >= 0
 	}
 	if cm.versionStartExcluding != "" {
-		matches = matches && smartVerCmp(ver, cm.versionStartExcluding) > 0
+		matches = matches && cmp(ver, cm.versionStartExcluding) > 0
 	}
 	if cm.versionEndIncluding != "" {
-		matches = matches && smartVerCmp(ver, cm.versionEndIncluding) <= 0
+		matches = matches && cmp(ver, cm.versionEndIncluding) <= 0
 	}
 	if cm.versionEndExcluding != "" {
-		matches = matches && smartVerCmp(ver, cm.versionEndExcluding) < 0
+		matches = matches && cmp(ver, cm.versionEndExcluding) < 0
 	}
 
 	return matches
@@ -0,0 +1,240 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestCPEMatchVersionRanges(t *testing.T) {
+	cases := []struct {
+		startIncluding, startExcluding, endIncluding, endExcluding string
+		version                                                    string
+		match                                                      bool
+	}{
+		// start including, end including
+		{startIncluding: "1.0", endIncluding: "2.0", version: "1.0", match: true},
+		{startIncluding: "1.0", endIncluding: "2.0", version: "2.0", match: true},
+		{startIncluding: "1.0", endIncluding: "2.0", version: "1.5", match: true},
+		{startIncluding: "1.0", endIncluding: "2.0", version: "0.9", match: false},
+		{startIncluding: "1.0", endIncluding: "2.0", version: "2.0.1", match: false},
+		// start including, end excluding
+		{startIncluding: "1.0", endExcluding: "2.0", version: "1.0", match: true},
+		{startIncluding: "1.0", endExcluding: "2.0", version: "2.0", match: false},
+		{startIncluding: "1.0", endExcluding: "2.0", version: "2.0.0", match: false},
+		{startIncluding: "1.0", endExcluding: "2.0", version: "1.9.9", match: true},
+		// start excluding, end including
+		{startExcluding: "1.0", endIncluding: "2.0", version: "1.0", match: false},
+		{startExcluding: "1.0", endIncluding: "2.0", version: "1.0.0", match: false},
+		{startExcluding: "1.0", endIncluding: "2.0", version: "1.0.1", match: true},
+		{startExcluding: "1.0", endIncluding: "2.0", version: "2.0", match: true},
+		// start excluding, end excluding
+		{startExcluding: "1.0", endExcluding: "2.0", version: "1.0", match: false},
+		{startExcluding: "1.0", endExcluding: "2.0", version: "2.0", match: false},
+		{startExcluding: "1.0", endExcluding: "2.0", version: "1.5", match: true},
+		// open ranges
+		{startIncluding: "1.0", version: "1.0", match: true},
+		{startExcluding: "1.0", version: "1.0", match: false},
+		{endIncluding: "2.0", version: "2.0", match: true},
+		{endExcluding: "2.0", version: "2.0", match: false},
+		// open start: all versions before the fix, pre-releases of the fix included
+		{endExcluding: "2.0", version: "0", match: true},
+		{endExcluding: "2.0", version: "0.0.0", match: true},
+		{endExcluding: "2.0", version: "1.9.9", match: true},
+		{endExcluding: "2.0", version: "2.0-rc1", match: true},
+		{endExcluding: "2.0", version: "2.0.0-beta2", match: true},
+		{endExcluding: "2.0", version: "2.0rc1", match: true},
+		{endExcluding: "2.0", version: "2.0.0", match: false},
+		{endExcluding: "2.0", version: "2.0.1-rc1", match: false},
+		{endExcluding: "2.0.0", version: "2.0-rc1", match: true},
+		{endExcluding: "2.0-rc2", version: "2.0-rc1", match: true},
+		{endExcluding: "2.0-rc2", version: "2.0", match: false},
+		{endExcluding: "0.1", version: "0", match: true},
+		{endExcluding: "0.1", version: "0.1-alpha", match: true},
+		{endExcluding: "0", version: "0.0", match: false},
+		{endIncluding: "0", version: "0.0", match: true},
+		{endIncluding: "2.0", version: "2.0.0-rc1", match: true},
+		{endIncluding: "2.0", version: "2.0.0", match: true},
+		{endIncluding: "2.0", version: "2.0.1-rc1", match: false},
+	}
+	for _, c := range cases {
+		name := fmt.Sprintf("%s in [%s,(%s,%s],%s)", c.version, c.startIncluding, c.startExcluding, c.endIncluding, c.endExcluding)
+		t.Run(name, func(t *testing.T) {
+			m, err := cpeMatcher(&schema.NVDCVEFeedJSON10DefCPEMatch{
+				Cpe23Uri:              "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*",
+				VersionStartIncluding: c.startIncluding,
+				VersionStartExcluding: c.startExcluding,
+				VersionEndIncluding:   c.endIncluding,
+				VersionEndExcluding:   c.endExcluding,
+				Vulnerable:            true,
+			}, nil, nil)
+			if err != nil {
+				t.Fatalf("couldn't create matcher: %v", err)
+			}
+			ver, err := wfn.WFNize(c.version)
+			if err != nil {
+				t.Fatalf("couldn't wfnize version %q: %v", c.version, err)
+			}
+			attrs := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product", Version: ver}}
+			if matched := len(m.Match(attrs, true)) > 0; matched != c.match {
+				t.Fatalf("expected match to be %t, got %t", c.match, matched)
+			}
+		})
+	}
+}
+
+func TestCPEMatchVersionRangesNA(t *testing.T) {
+	attrs := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product", Version: wfn.NA}}
+	for _, entry := range []*schema.NVDCVEFeedJSON10DefCPEMatch{
+		{Cpe23Uri: "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0"},
+		{Cpe23Uri: "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", VersionEndIncluding: "0"},
+		{Cpe23Uri: "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0"},
+	} {
+		m, err := cpeMatcher(entry, nil, nil)
+		if err != nil {
+			t.Fatalf("couldn't create matcher: %v", err)
+		}
+		if matches := m.Match(attrs, false); len(matches) != 0 {
+			t.Errorf("version NA shouldn't be within the range of %+v", entry)
+		}
+	}
+}
+
+func TestCPEMatchTargetSW(t *testing.T) {
+	m, err := cpeMatcher(&schema.NVDCVEFeedJSON10DefCPEMatch{
+		Cpe23Uri:            "cpe:2.3:a:vendor:product:*:*:*:*:*:android:*:*",
+		VersionEndExcluding: "2.0",
+		Vulnerable:          true,
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("couldn't create matcher: %v", err)
+	}
+	cases := []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:2.3:a:vendor:product:1.0:*:*:*:*:android:*:*", true},
+		{"cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:vendor:product:1.0:*:*:*:*:iphone_os:*:*", false},
+		{"cpe:2.3:a:vendor:product:1.0:*:*:*:*:-:*:*", false},
+		{"cpe:2.3:a:vendor:product:2.0:*:*:*:*:android:*:*", false},
+	}
+	for _, c := range cases {
+		t.Run(c.cpe, func(t *testing.T) {
+			attr, err := wfn.UnbindFmtString(c.cpe)
+			if err != nil {
+				t.Fatalf("couldn't parse %q: %v", c.cpe, err)
+			}
+			if matched := len(m.Match([]*wfn.Attributes{attr}, false)) > 0; matched != c.match {
+				t.Fatalf("expected match to be %t, got %t", c.match, matched)
+			}
+		})
+	}
+}
+
+func TestCPEMatchHardware(t *testing.T) {
+	cases := []struct {
+		name  string
+		entry *schema.NVDCVEFeedJSON10DefCPEMatch
+		cpe   string
+		match bool
+	}{
+		// version ranges apply to the hardware as to the other parts, with their boundaries
+		{"range/below", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:*:*:*:*:*:*:*:*", VersionEndIncluding: "9.8.4"}, "cpe:2.3:h:vendor:device:9.8.3:*:*:*:*:*:*:*", true},
+		{"range/at end", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:*:*:*:*:*:*:*:*", VersionEndIncluding: "9.8.4"}, "cpe:2.3:h:vendor:device:9.8.4:*:*:*:*:*:*:*", true},
+		{"range/above", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:*:*:*:*:*:*:*:*", VersionEndIncluding: "9.8.4"}, "cpe:2.3:h:vendor:device:9.8.4.1:*:*:*:*:*:*:*", false},
+		{"range/other device", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:*:*:*:*:*:*:*:*", VersionEndIncluding: "9.8.4"}, "cpe:2.3:h:vendor:other_device:9.8.3:*:*:*:*:*:*:*", false},
+		// hardware of NA version matches the hardware of any version
+		{"NA/version", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:-:*:*:*:*:*:*:*"}, "cpe:2.3:h:vendor:device:9.8.4:*:*:*:*:*:*:*", true},
+		{"NA/NA", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:-:*:*:*:*:*:*:*"}, "cpe:2.3:h:vendor:device:-:*:*:*:*:*:*:*", true},
+		{"NA/target_hw", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:h:vendor:device:-:*:*:*:*:*:x86:*"}, "cpe:2.3:h:vendor:device:9.8.4:*:*:*:*:*:arm:*", false},
+		// but the software of NA version doesn't
+		{"NA/application", &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: "cpe:2.3:a:vendor:product:-:*:*:*:*:*:*:*"}, "cpe:2.3:a:vendor:product:9.8.4:*:*:*:*:*:*:*", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := cpeMatcher(c.entry, nil, nil)
+			if err != nil {
+				t.Fatalf("couldn't create matcher: %v", err)
+			}
+			attr, err := wfn.UnbindFmtString(c.cpe)
+			if err != nil {
+				t.Fatalf("couldn't parse %q: %v", c.cpe, err)
+			}
+			if matched := len(m.Match([]*wfn.Attributes{attr}, true)) > 0; matched != c.match {
+				t.Fatalf("expected match to be %t, got %t", c.match, matched)
+			}
+		})
+	}
+}
+
+func TestCPEMatchVersionUnknownPolicy(t *testing.T) {
+	entries := map[string]*schema.NVDCVEFeedJSON10DefCPEMatch{
+		"upper bound":    {Cpe23Uri: "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0"},
+		"both bounds":    {Cpe23Uri: "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.0", VersionEndExcluding: "2.0"},
+		"version":        {Cpe23Uri: "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*"},
+		"any version":    {Cpe23Uri: "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*"},
+		"other products": {Cpe23Uri: "cpe:2.3:a:vendor:other:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0"},
+	}
+	cases := []struct {
+		policy  VersionUnknownPolicy
+		matched []string
+	}{
+		{VersionUnknownDefault, []string{"any version", "upper bound", "version"}},
+		{VersionUnknownConservative, []string{"any version", "both bounds", "upper bound", "version"}},
+		{VersionUnknownStrict, []string{"any version"}},
+	}
+	unknown := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product"}}
+	known := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product", Version: "1\\.5"}}
+	for _, c := range cases {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			var matched []string
+			for name, entry := range entries {
+				m, err := cpeMatcher(entry, &comparators{versionUnknown: c.policy}, nil)
+				if err != nil {
+					t.Fatalf("couldn't create matcher: %v", err)
+				}
+				if len(m.Match(unknown, false)) > 0 {
+					matched = append(matched, name)
+				}
+				// the policy only applies to unknown versions
+				if name == "both bounds" && len(m.Match(known, false)) == 0 {
+					t.Errorf("version 1.5 didn't match %s", name)
+				}
+			}
+			sort.Strings(matched)
+			if !reflect.DeepEqual(matched, c.matched) {
+				t.Fatalf("got %v, expected %v", matched, c.matched)
+			}
+		})
+	}
+}
+
+func TestParseVersionUnknownPolicy(t *testing.T) {
+	for _, p := range []VersionUnknownPolicy{VersionUnknownDefault, VersionUnknownConservative, VersionUnknownStrict} {
+		if got, err := ParseVersionUnknownPolicy(p.String()); err != nil || got != p {
+			t.Errorf("%v: got %v, %v", p, got, err)
+		}
+	}
+	if _, err := ParseVersionUnknownPolicy("lenient"); err == nil {
+		t.Error("unknown policy was parsed")
+	}
+}
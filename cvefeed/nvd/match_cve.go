@@ -16,26 +16,68 @@ package nvd
 
 import (
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
 var cveRegex = regexp.MustCompile("CVE-[0-9]{4}-[0-9]{4,}")
 
+// rejectPrefix starts the description of the CVEs rejected by NVD
+const rejectPrefix = "** REJECT **"
+
+// ToVuln converts the feed entry into Vuln; the problems with its configurations are not reported
 func ToVuln(cve *schema.NVDCVEFeedJSON10DefCVEItem) *Vuln {
+	return ToVulnWithLogger(cve, logging.Nop)
+}
+
+// ToVulnWithLogger is like ToVuln, but reports the problems with the configurations of the entry, e.g. unknown
+// node operators, to log
+func ToVulnWithLogger(cve *schema.NVDCVEFeedJSON10DefCVEItem, log logging.Logger) *Vuln {
+	return ToVulnWithOptions(cve, ConvertOptions{Logger: log})
+}
+
+// ConvertOptions control how the feed entries are converted into Vuln
+type ConvertOptions struct {
+	// Logger, if set, receives the problems with the configurations of the entry
+	Logger logging.Logger
+	// Interner, if set, shares the criteria of cpe_match entries with the identical ones converted with it before
+	Interner *Interner
+	// MaxConfigurationDepth limits the nesting of configuration nodes: the nodes nested deeper are skipped
+	// with a warning, as well as the ones contained in themselves; DefaultMaxConfigurationDepth if not positive
+	MaxConfigurationDepth int
+}
+
+// ToVulnWithOptions is like ToVuln, but the entry is converted as per opts
+func ToVulnWithOptions(cve *schema.NVDCVEFeedJSON10DefCVEItem, opts ConvertOptions) *Vuln {
+	maxDepth := opts.MaxConfigurationDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxConfigurationDepth
+	}
+	c := &converter{
+		cmps:     &comparators{},
+		log:      logging.OrNop(opts.Logger),
+		interner: opts.Interner,
+		path:     nodePath{maxDepth: maxDepth},
+	}
 	var ms []wfn.Matcher
 	for _, node := range cve.Configurations.Nodes {
 		if node != nil {
-			if m, err := nodeMatcher(node); err == nil {
+			if m, err := c.vulnerableNodeMatcher(node); err == nil {
 				ms = append(ms, m)
 			}
 		}
 	}
 
 	return &Vuln{
-		cveItem: cve,
-		Matcher: wfn.MatchAny(ms...),
+		cveItem:     cve,
+		Matcher:     wfn.MatchAny(ms...),
+		comparators: c.cmps,
+		maxDepth:    maxDepth,
+		cpeMatchers: c.matchers,
 	}
 }
 
@@ -43,6 +85,12 @@ func ToVuln(cve *schema.NVDCVEFeedJSON10DefCVEItem) *Vuln {
 type Vuln struct {
 	cveItem *schema.NVDCVEFeedJSON10DefCVEItem
 	wfn.Matcher
+	comparators *comparators
+	aliases     []alias
+	maxDepth    int // of the configuration nodes, as converted
+	// cpeMatchers are the matchers of the cpe_match entries built by the conversion,
+	// so that the entries aren't parsed again when they're reported one by one
+	cpeMatchers map[*schema.NVDCVEFeedJSON10DefCPEMatch]wfn.Matcher
 }
 
 // ID is a part of the cvefeed.Vuln Interface
@@ -53,6 +101,38 @@ func (v *Vuln) ID() string {
 	return v.cveItem.CVE.CVEDataMeta.ID
 }
 
+// CVEItem returns the feed entry the vulnerability was created from; it must not be modified
+func (v *Vuln) CVEItem() *schema.NVDCVEFeedJSON10DefCVEItem {
+	if v == nil {
+		return nil
+	}
+	return v.cveItem
+}
+
+// LastModified returns the time the vulnerability was last modified at, zero time if it's unknown
+func (v *Vuln) LastModified() time.Time {
+	if v == nil || v.cveItem == nil {
+		return time.Time{}
+	}
+	t, err := schema.ParseTime(v.cveItem.LastModifiedDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Published returns the time the vulnerability was published at, zero time if it's unknown
+func (v *Vuln) Published() time.Time {
+	if v == nil || v.cveItem == nil {
+		return time.Time{}
+	}
+	t, err := schema.ParseTime(v.cveItem.PublishedDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // CVEs is a part of the cvefeed.Vuln Interface
 func (v *Vuln) CVEs() []string {
 	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil {
@@ -84,7 +164,8 @@ func (v *Vuln) CVEs() []string {
 	return unique(cves)
 }
 
-// CWEs is a part of the cvefeed.Vuln Interface
+// CWEs is a part of the cvefeed.Vuln Interface.
+// CWE IDs are collected from all problemtype_data entries in the order they appear in the feed, without duplicates
 func (v *Vuln) CWEs() []string {
 	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil || v.cveItem.CVE.Problemtype == nil {
 		return nil
@@ -95,9 +176,10 @@ func (v *Vuln) CWEs() []string {
 	for _, ptd := range v.cveItem.CVE.Problemtype.ProblemtypeData {
 		if ptd != nil {
 			for _, desc := range ptd.Description {
-				if desc != nil {
-					if desc.Lang == "en" {
-						cwes = append(cwes, desc.Value)
+				if desc != nil && desc.Lang == "en" {
+					// NVD-CWE-noinfo and NVD-CWE-Other are kept as is
+					if cwe := strings.TrimSpace(desc.Value); cwe != "" {
+						cwes = append(cwes, cwe)
 					}
 				}
 			}
@@ -107,6 +189,52 @@ func (v *Vuln) CWEs() []string {
 	return unique(cwes)
 }
 
+// Reference is a link to a resource about the vulnerability, e.g. a patch or an advisory
+type Reference struct {
+	URL    string   `json:"url"`
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// HasTag returns true if the reference is tagged with tag, ignoring the case
+func (r Reference) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// References is a part of the cvefeed.Vuln Interface.
+// References are returned in the order they appear in the feed; references with an empty URL are skipped
+// and duplicate URLs are merged into the first one, with the tags of all of them.
+func (v *Vuln) References() []Reference {
+	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil || v.cveItem.CVE.References == nil {
+		return nil
+	}
+
+	var refs []Reference
+	seen := make(map[string]int) // URL -> index in refs
+	for _, refd := range v.cveItem.CVE.References.ReferenceData {
+		if refd == nil || refd.URL == "" {
+			continue
+		}
+		i, ok := seen[refd.URL]
+		if !ok {
+			seen[refd.URL] = len(refs)
+			refs = append(refs, Reference{URL: refd.URL, Source: refd.Refsource})
+			i = len(refs) - 1
+		}
+		for _, tag := range refd.Tags {
+			if tag != "" && !refs[i].HasTag(tag) {
+				refs[i].Tags = append(refs[i].Tags, tag)
+			}
+		}
+	}
+	return refs
+}
+
 // CVSSv2BaseScore is a part of the cvefeed.Vuln Interface
 func (v *Vuln) CVSSv2BaseScore() float64 {
 	if c := v.cvssv2(); c != nil {
@@ -139,6 +267,27 @@ func (v *Vuln) CVSSv3Vector() string {
 	return ""
 }
 
+// CVSSv3Severity returns CVSS v3 base severity stated in the feed, see cvefeed.CVSSv3Severity
+func (v *Vuln) CVSSv3Severity() string {
+	if c := v.cvssv3(); c != nil {
+		return c.BaseSeverity
+	}
+	return ""
+}
+
+// Rejected is a part of the cvefeed.Vuln Interface
+func (v *Vuln) Rejected() bool {
+	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil || v.cveItem.CVE.Description == nil {
+		return false
+	}
+	for _, desc := range v.cveItem.CVE.Description.DescriptionData {
+		if desc != nil && desc.Lang == "en" && strings.HasPrefix(strings.TrimSpace(desc.Value), rejectPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // unique returns unique strings from input
 func unique(ss []string) []string {
 	var us []string
@@ -167,3 +316,81 @@ func (v *Vuln) cvssv3() *schema.CVSSV30 {
 	}
 	return v.cveItem.Impact.BaseMetricV3.CVSSV3
 }
+
+// MatchedCPEs returns the vulnerable cpe_match entries of the configuration which match attr,
+// in the order they appear in the feed; entries under negated nodes are skipped.
+// Unlike Match, every entry is checked on its own, regardless of the node operators.
+func (v *Vuln) MatchedCPEs(attr *wfn.Attributes, requireVersion bool) []*schema.NVDCVEFeedJSON10DefCPEMatch {
+	if attr == nil {
+		return nil
+	}
+	var matched []*schema.NVDCVEFeedJSON10DefCPEMatch
+	attrs, _ := v.expandAliases([]*wfn.Attributes{attr})
+	v.walkCPEMatches(func(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch, m wfn.Matcher, _ []string) {
+		if nvdMatch.Vulnerable && len(m.Match(attrs, requireVersion)) != 0 {
+			matched = append(matched, nvdMatch)
+		}
+	})
+	return matched
+}
+
+// Explanation tells which cpe_match entry of the configuration matched the attributes
+type Explanation struct {
+	// CPEMatch is the entry as it appears in the feed, with the version bounds
+	CPEMatch *schema.NVDCVEFeedJSON10DefCPEMatch
+	// Attributes are the ones which matched the entry
+	Attributes *wfn.Attributes
+	// Operators of the nodes from the top-level one down to the one holding the entry, e.g. [AND OR]
+	Operators []string
+}
+
+// Explain returns the cpe_match entries of the configuration which match any of attrs, vulnerable or not,
+// in the order they appear in the feed; entries under negated nodes are skipped.
+func (v *Vuln) Explain(attrs []*wfn.Attributes, requireVersion bool) []Explanation {
+	var explanations []Explanation
+	expanded, origin := v.expandAliases(attrs)
+	v.walkCPEMatches(func(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch, m wfn.Matcher, operators []string) {
+		for _, attr := range unalias(m.Match(expanded, requireVersion), origin) {
+			explanations = append(explanations, Explanation{
+				CPEMatch:   nvdMatch,
+				Attributes: attr,
+				Operators:  append([]string(nil), operators...),
+			})
+		}
+	})
+	return explanations
+}
+
+// walkCPEMatches calls fn for every cpe_match entry which can be parsed, skipping negated nodes
+// and the ones skipped when v was converted: nested too deep or contained in themselves
+func (v *Vuln) walkCPEMatches(fn func(*schema.NVDCVEFeedJSON10DefCPEMatch, wfn.Matcher, []string)) {
+	if v == nil || v.cveItem == nil || v.cveItem.Configurations == nil {
+		return
+	}
+	path := nodePath{maxDepth: v.maxDepth}
+	if path.maxDepth <= 0 {
+		path.maxDepth = DefaultMaxConfigurationDepth
+	}
+	var walk func(nodes []*schema.NVDCVEFeedJSON10DefNode, operators []string)
+	walk = func(nodes []*schema.NVDCVEFeedJSON10DefNode, operators []string) {
+		for _, node := range nodes {
+			if node == nil || node.Negate || path.enter(node) != nil {
+				continue
+			}
+			ops := append(operators[:len(operators):len(operators)], node.Operator)
+			for _, nvdMatch := range node.CPEMatch {
+				if nvdMatch == nil {
+					continue
+				}
+				if m, ok := v.cpeMatchers[nvdMatch]; ok {
+					fn(nvdMatch, m, ops)
+				} else if m, err := cpeMatcher(nvdMatch, v.comparators, nil); err == nil {
+					fn(nvdMatch, m, ops)
+				}
+			}
+			walk(node.Children, ops)
+			path.leave()
+		}
+	}
+	walk(v.cveItem.Configurations.Nodes, nil)
+}
@@ -0,0 +1,274 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestVulnCWEs(t *testing.T) {
+	var item schema.NVDCVEFeedJSON10DefCVEItem
+	if err := json.Unmarshal([]byte(testCVEItemCWEs), &item); err != nil {
+		t.Fatalf("couldn't parse the CVE: %v", err)
+	}
+	expect := []string{"CWE-79", "CWE-352", "NVD-CWE-noinfo", "NVD-CWE-Other"}
+	if got := ToVuln(&item).CWEs(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("got %q, expected %q", got, expect)
+	}
+}
+
+func TestVulnReferences(t *testing.T) {
+	var item schema.NVDCVEFeedJSON10DefCVEItem
+	if err := json.Unmarshal([]byte(testCVEItemReferences), &item); err != nil {
+		t.Fatalf("couldn't parse the CVE: %v", err)
+	}
+	expect := []Reference{
+		{URL: "https://example.com/commit/1", Source: "MISC", Tags: []string{"Patch", "Third Party Advisory"}},
+		{URL: "https://example.com/advisory", Source: "CONFIRM", Tags: []string{"Vendor Advisory"}},
+		{URL: "https://example.com/blog", Source: "MISC"},
+	}
+	refs := ToVuln(&item).References()
+	if !reflect.DeepEqual(refs, expect) {
+		t.Fatalf("got %+v, expected %+v", refs, expect)
+	}
+	if !refs[1].HasTag("vendor advisory") || refs[2].HasTag("Patch") {
+		t.Fatal("HasTag returned unexpected results")
+	}
+}
+
+// testLogger records the messages logged at warning level
+type testLogger struct {
+	warnings []string
+}
+
+func (l *testLogger) Debugf(string, ...interface{}) {}
+func (l *testLogger) Infof(string, ...interface{})  {}
+func (l *testLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+func (l *testLogger) Errorf(string, ...interface{}) {}
+
+func TestToVulnLogger(t *testing.T) {
+	var item schema.NVDCVEFeedJSON10DefCVEItem
+	if err := json.Unmarshal([]byte(testCVEItemUnknownOperator), &item); err != nil {
+		t.Fatalf("couldn't parse the CVE: %v", err)
+	}
+
+	var logger testLogger
+	v := ToVulnWithLogger(&item, &logger)
+	expect := []string{`unknown operator, defaulting to OR: got "XOR"`}
+	if !reflect.DeepEqual(logger.warnings, expect) {
+		t.Fatalf("got warnings %q, expected %q", logger.warnings, expect)
+	}
+	attr, err := wfn.Parse("cpe:/a:foo:bar:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Match([]*wfn.Attributes{attr}, false); len(got) != 1 {
+		t.Fatalf("unknown operator should default to OR, got matches %v", got)
+	}
+
+	// nothing is logged to stderr by default
+	f, err := ioutil.TempFile("", "nvd-stderr-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	stderr := os.Stderr
+	os.Stderr = f
+	ToVuln(&item)
+	os.Stderr = stderr
+	if fi, err := f.Stat(); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() != 0 {
+		t.Fatalf("ToVuln wrote %d bytes to stderr", fi.Size())
+	}
+}
+
+func TestToVulnConfigurationDepth(t *testing.T) {
+	parse := func(s string) []*wfn.Attributes {
+		attr, err := wfn.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []*wfn.Attributes{attr}
+	}
+	bar, baz := parse("cpe:/a:foo:bar:1.0"), parse("cpe:/a:foo:baz:1.0")
+	cpeMatch := func(product string) []*schema.NVDCVEFeedJSON10DefCPEMatch {
+		return []*schema.NVDCVEFeedJSON10DefCPEMatch{{Cpe23Uri: "cpe:2.3:a:foo:" + product + ":*:*:*:*:*:*:*:*", Vulnerable: true}}
+	}
+	item := func(root *schema.NVDCVEFeedJSON10DefNode) *schema.NVDCVEFeedJSON10DefCVEItem {
+		return &schema.NVDCVEFeedJSON10DefCVEItem{
+			CVE:            &schema.CVEJSON40{CVEDataMeta: &schema.CVEJSON40CVEDataMeta{ID: "CVE-2019-3004"}},
+			Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{Nodes: []*schema.NVDCVEFeedJSON10DefNode{root}},
+		}
+	}
+
+	// foo:bar at the top, foo:baz 1000 levels below
+	const depth = 1000
+	root := &schema.NVDCVEFeedJSON10DefNode{Operator: "OR", CPEMatch: cpeMatch("bar")}
+	node := root
+	for i := 1; i < depth; i++ {
+		child := &schema.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+		node.Children = []*schema.NVDCVEFeedJSON10DefNode{child}
+		node = child
+	}
+	node.CPEMatch = cpeMatch("baz")
+
+	var logger testLogger
+	v := ToVulnWithOptions(item(root), ConvertOptions{Logger: &logger})
+	expect := []string{fmt.Sprintf("skipping configuration node: configuration nodes are nested deeper than %d", DefaultMaxConfigurationDepth)}
+	if !reflect.DeepEqual(logger.warnings, expect) {
+		t.Fatalf("got warnings %q, expected %q", logger.warnings, expect)
+	}
+	if len(v.Match(bar, false)) == 0 || len(v.Match(baz, false)) != 0 {
+		t.Fatal("only the nodes within the depth limit should match")
+	}
+	if got := v.MatchedCPEs(baz[0], false); len(got) != 0 {
+		t.Fatalf("node beyond the depth limit matched: %v", got)
+	}
+	v = ToVulnWithOptions(item(root), ConvertOptions{MaxConfigurationDepth: depth})
+	if len(v.Match(baz, false)) == 0 || len(v.MatchedCPEs(baz[0], false)) == 0 {
+		t.Fatal("node within the raised depth limit didn't match")
+	}
+
+	// the node containing itself
+	root = &schema.NVDCVEFeedJSON10DefNode{Operator: "OR", CPEMatch: cpeMatch("bar")}
+	root.Children = []*schema.NVDCVEFeedJSON10DefNode{{Operator: "AND", Children: []*schema.NVDCVEFeedJSON10DefNode{root}}}
+	logger = testLogger{}
+	v = ToVulnWithOptions(item(root), ConvertOptions{Logger: &logger})
+	expect = []string{"skipping configuration node: configuration node is its own descendant"}
+	if !reflect.DeepEqual(logger.warnings, expect) {
+		t.Fatalf("got warnings %q, expected %q", logger.warnings, expect)
+	}
+	if len(v.Match(bar, false)) == 0 || len(v.MatchedCPEs(bar[0], false)) != 1 {
+		t.Fatal("cyclic configuration didn't match")
+	}
+}
+
+func TestVulnMatchFirmware(t *testing.T) {
+	var item schema.NVDCVEFeedJSON10DefCVEItem
+	if err := json.Unmarshal([]byte(testCVEItemFirmware), &item); err != nil {
+		t.Fatalf("couldn't parse the CVE: %v", err)
+	}
+	v := ToVuln(&item)
+	cases := []struct {
+		cpes  []string
+		match bool
+	}{
+		{[]string{"cpe:/o:vendor:device_firmware:9.8.4", "cpe:/h:vendor:device:9.8.4"}, true},
+		{[]string{"cpe:/o:vendor:device_firmware:9.8.4", "cpe:/h:vendor:device:-"}, true},
+		{[]string{"cpe:/o:vendor:device_firmware:9.8.4.1", "cpe:/h:vendor:device:9.8.4.1"}, false},
+		{[]string{"cpe:/o:vendor:device_firmware:9.8.4", "cpe:/h:vendor:other_device:9.8.4"}, false},
+		// the firmware version is only known from the firmware entry
+		{[]string{"cpe:/h:vendor:device:9.8.4"}, false},
+		{[]string{"cpe:/o:vendor:device_firmware:9.8.4"}, false},
+	}
+	for _, c := range cases {
+		var attrs []*wfn.Attributes
+		for _, cpe := range c.cpes {
+			attr, err := wfn.Parse(cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			attrs = append(attrs, attr)
+		}
+		if matched := len(v.Match(attrs, false)) > 0; matched != c.match {
+			t.Errorf("%q: expected match to be %t, got %t", c.cpes, c.match, matched)
+		}
+	}
+}
+
+// testCVEItemFirmware is vulnerable firmware, up to 9.8.4, running on the hardware it's made for
+var testCVEItemFirmware = `{
+  "cve": {"CVE_data_meta": {"ID": "CVE-2019-3006"}},
+  "configurations": {
+    "CVE_data_version": "4.0",
+    "nodes": [
+      {
+        "operator": "AND",
+        "children": [
+          {
+            "operator": "OR",
+            "cpe_match": [
+              {"vulnerable": true, "cpe23Uri": "cpe:2.3:o:vendor:device_firmware:*:*:*:*:*:*:*:*", "versionEndIncluding": "9.8.4"}
+            ]
+          },
+          {
+            "operator": "OR",
+            "cpe_match": [
+              {"vulnerable": false, "cpe23Uri": "cpe:2.3:h:vendor:device:-:*:*:*:*:*:*:*"}
+            ]
+          }
+        ]
+      }
+    ]
+  }
+}`
+
+var testCVEItemUnknownOperator = `{
+  "cve": {"CVE_data_meta": {"ID": "CVE-2019-3003"}},
+  "configurations": {
+    "CVE_data_version": "4.0",
+    "nodes": [
+      {
+        "operator": "XOR",
+        "cpe_match": [
+          {"vulnerable": true, "cpe22Uri": "cpe:/a:foo:bar:1.0", "cpe23Uri": "cpe:2.3:a:foo:bar:1.0:*:*:*:*:*:*:*"},
+          {"vulnerable": true, "cpe22Uri": "cpe:/a:foo:baz:1.0", "cpe23Uri": "cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*"}
+        ]
+      }
+    ]
+  }
+}`
+
+var testCVEItemCWEs = `{
+  "cve": {
+    "CVE_data_meta": {"ID": "CVE-2019-3001"},
+    "problemtype": {
+      "problemtype_data": [
+        {"description": [{"lang": "en", "value": "CWE-79"}, {"lang": "en", "value": "CWE-352"}]},
+        {"description": [{"lang": "en", "value": "CWE-79"}, {"lang": "en", "value": " "}, {"lang": "fr", "value": "CWE-20"}]},
+        {"description": [{"lang": "en", "value": "NVD-CWE-noinfo"}, {"lang": "en", "value": "NVD-CWE-Other"}]}
+      ]
+    }
+  },
+  "configurations": {"CVE_data_version": "4.0", "nodes": []}
+}`
+
+var testCVEItemReferences = `{
+  "cve": {
+    "CVE_data_meta": {"ID": "CVE-2019-3002"},
+    "references": {
+      "reference_data": [
+        {"url": "https://example.com/commit/1", "name": "https://example.com/commit/1", "refsource": "MISC", "tags": ["Patch"]},
+        {"url": "https://example.com/advisory", "name": "https://example.com/advisory", "refsource": "CONFIRM", "tags": ["Vendor Advisory"]},
+        {"url": "", "name": "no url", "refsource": "MISC", "tags": ["Exploit"]},
+        {"url": "https://example.com/commit/1", "name": "https://example.com/commit/1", "refsource": "CONFIRM", "tags": ["patch", "Third Party Advisory"]},
+        {"url": "https://example.com/blog", "name": "https://example.com/blog", "refsource": "MISC", "tags": []}
+      ]
+    }
+  },
+  "configurations": {"CVE_data_version": "4.0", "nodes": []}
+}`
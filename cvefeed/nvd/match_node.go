@@ -16,30 +16,91 @@ package nvd
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
-// Matcher returns an object which knows how to match attributes
-func nodeMatcher(node *schema.NVDCVEFeedJSON10DefNode) (wfn.Matcher, error) {
+// DefaultMaxConfigurationDepth is the maximum nesting depth of configuration nodes, unless set in ConvertOptions;
+// NVD doesn't nest them deeper than a few levels
+const DefaultMaxConfigurationDepth = 32
+
+// nodePath is the path from the top-level configuration node to the one being converted;
+// it guards against too deeply nested or cyclic configurations
+type nodePath struct {
+	nodes    []*schema.NVDCVEFeedJSON10DefNode
+	maxDepth int
+}
+
+// enter adds node to the path, or returns an error if it's nested too deep or it's already on the path
+func (p *nodePath) enter(node *schema.NVDCVEFeedJSON10DefNode) error {
+	if len(p.nodes) >= p.maxDepth {
+		return fmt.Errorf("configuration nodes are nested deeper than %d", p.maxDepth)
+	}
+	for _, n := range p.nodes {
+		if n == node {
+			return fmt.Errorf("configuration node is its own descendant")
+		}
+	}
+	p.nodes = append(p.nodes, node)
+	return nil
+}
+
+// leave removes the last entered node from the path
+func (p *nodePath) leave() {
+	p.nodes = p.nodes[:len(p.nodes)-1]
+}
+
+// converter converts the configuration nodes of a single feed entry into matchers
+type converter struct {
+	cmps     *comparators
+	log      logging.Logger
+	interner *Interner
+	path     nodePath
+	matchers map[*schema.NVDCVEFeedJSON10DefCPEMatch]wfn.Matcher // of the cpe_match entries converted so far
+}
+
+// cpeMatcher returns the matcher of the cpe_match entry, converting every entry only once
+func (c *converter) cpeMatcher(match *schema.NVDCVEFeedJSON10DefCPEMatch) (wfn.Matcher, error) {
+	if m, ok := c.matchers[match]; ok {
+		return m, nil
+	}
+	m, err := cpeMatcher(match, c.cmps, c.interner)
+	if err != nil {
+		return nil, err
+	}
+	if c.matchers == nil {
+		c.matchers = map[*schema.NVDCVEFeedJSON10DefCPEMatch]wfn.Matcher{}
+	}
+	c.matchers[match] = m
+	return m, nil
+}
+
+// Matcher returns an object which knows how to match attributes.
+// The nodes nested too deep, or contained in themselves, are skipped with a warning.
+func (c *converter) nodeMatcher(node *schema.NVDCVEFeedJSON10DefNode) (wfn.Matcher, error) {
 	if node == nil {
 		return nil, fmt.Errorf("node is nil")
 	}
+	if err := c.path.enter(node); err != nil {
+		c.log.Warnf("skipping configuration node: %v", err)
+		return nil, err
+	}
+	defer c.path.leave()
 
 	var ms []wfn.Matcher
 	for _, match := range node.CPEMatch {
 		if match != nil {
-			if m, err := cpeMatcher(match); err == nil {
+			if m, err := c.cpeMatcher(match); err == nil {
 				ms = append(ms, m)
 			}
 		}
 	}
 	for _, child := range node.Children {
 		if child != nil {
-			if m, err := nodeMatcher(child); err == nil {
+			if m, err := c.nodeMatcher(child); err == nil {
 				ms = append(ms, m)
 			}
 		}
@@ -53,7 +114,7 @@ func nodeMatcher(node *schema.NVDCVEFeedJSON10DefNode) (wfn.Matcher, error) {
 
 	switch strings.ToUpper(node.Operator) {
 	default:
-		log.Printf("unknown operator, defaulting to OR: got %q", node.Operator)
+		c.log.Warnf("unknown operator, defaulting to OR: got %q", node.Operator)
 		fallthrough
 	case "OR":
 		m = wfn.MatchAny(ms...)
@@ -67,3 +128,62 @@ func nodeMatcher(node *schema.NVDCVEFeedJSON10DefNode) (wfn.Matcher, error) {
 
 	return m, nil
 }
+
+// vulnerableNodeMatcher returns a matcher of the top-level node which matches only if, besides the node itself,
+// one of its vulnerable cpe_match entries matches: entries with vulnerable set to false (e.g. the platform
+// the vulnerable software runs on) only constrain AND configurations and can't make a finding on their own.
+// Entries under negated nodes aren't considered vulnerable.
+func (c *converter) vulnerableNodeMatcher(node *schema.NVDCVEFeedJSON10DefNode) (wfn.Matcher, error) {
+	m, err := c.nodeMatcher(node)
+	if err != nil {
+		return nil, err
+	}
+	var vulnerable []wfn.Matcher
+	walkNodes([]*schema.NVDCVEFeedJSON10DefNode{node}, c.path.maxDepth, func(node *schema.NVDCVEFeedJSON10DefNode) {
+		for _, match := range node.CPEMatch {
+			if match != nil && match.Vulnerable {
+				if m, err := c.cpeMatcher(match); err == nil {
+					vulnerable = append(vulnerable, m)
+				}
+			}
+		}
+	})
+	if len(vulnerable) == 0 {
+		return nil, fmt.Errorf("no vulnerable cpe_match entries in node")
+	}
+	return &vulnerableMatcher{Matcher: m, vulnerable: wfn.MatchAny(vulnerable...)}, nil
+}
+
+// walkNodes calls fn for the nodes and their descendants, depth first, skipping negated nodes,
+// as well as the nodes nested deeper than maxDepth or contained in themselves
+func walkNodes(nodes []*schema.NVDCVEFeedJSON10DefNode, maxDepth int, fn func(*schema.NVDCVEFeedJSON10DefNode)) {
+	path := nodePath{maxDepth: maxDepth}
+	var walk func([]*schema.NVDCVEFeedJSON10DefNode)
+	walk = func(nodes []*schema.NVDCVEFeedJSON10DefNode) {
+		for _, node := range nodes {
+			if node == nil || node.Negate || path.enter(node) != nil {
+				continue
+			}
+			fn(node)
+			walk(node.Children)
+			path.leave()
+		}
+	}
+	walk(nodes)
+}
+
+// vulnerableMatcher returns the attributes matched by vulnerable, provided that the embedded Matcher matches;
+// the attributes matched only by the platform (vulnerable:false) entries of the node aren't returned
+type vulnerableMatcher struct {
+	wfn.Matcher
+	vulnerable wfn.Matcher
+}
+
+// Match is part of the Matcher interface
+func (vm *vulnerableMatcher) Match(attrs []*wfn.Attributes, requireVersion bool) []*wfn.Attributes {
+	matches := vm.Matcher.Match(attrs, requireVersion)
+	if len(matches) == 0 {
+		return nil
+	}
+	return vm.vulnerable.Match(matches, requireVersion)
+}
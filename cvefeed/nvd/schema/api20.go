@@ -0,0 +1,423 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+	"time"
+)
+
+// APITimeLayout is the layout of timestamps in NVD CVE API 2.0 responses.
+const APITimeLayout = "2006-01-02T15:04:05.000"
+
+// CVEAPIJSON20 is a response of NVD CVE API 2.0.
+type CVEAPIJSON20 struct {
+	ResultsPerPage  int                          `json:"resultsPerPage"`
+	StartIndex      int                          `json:"startIndex"`
+	TotalResults    int                          `json:"totalResults"`
+	Format          string                       `json:"format"`
+	Version         string                       `json:"version"`
+	Timestamp       string                       `json:"timestamp"`
+	Vulnerabilities []*CVEAPIJSON20Vulnerability `json:"vulnerabilities"`
+}
+
+// CVEAPIJSON20Vulnerability is an element of the vulnerabilities array of NVD CVE API 2.0 response.
+type CVEAPIJSON20Vulnerability struct {
+	CVE *CVEAPIJSON20CVEItem `json:"cve"`
+}
+
+// CVEAPIJSON20CVEItem is a CVE as returned by NVD CVE API 2.0.
+type CVEAPIJSON20CVEItem struct {
+	ID               string                       `json:"id"`
+	SourceIdentifier string                       `json:"sourceIdentifier,omitempty"`
+	Published        string                       `json:"published"`
+	LastModified     string                       `json:"lastModified"`
+	VulnStatus       string                       `json:"vulnStatus,omitempty"`
+	Descriptions     []*CVEJSON40LangString       `json:"descriptions"`
+	Metrics          *CVEAPIJSON20Metrics         `json:"metrics,omitempty"`
+	Weaknesses       []*CVEAPIJSON20Weakness      `json:"weaknesses,omitempty"`
+	Configurations   []*CVEAPIJSON20Configuration `json:"configurations,omitempty"`
+	References       []*CVEAPIJSON20Reference     `json:"references,omitempty"`
+}
+
+// CVEAPIJSON20Metrics holds the CVSS metrics from all sources.
+type CVEAPIJSON20Metrics struct {
+	CVSSMetricV31 []*CVEAPIJSON20CVSSMetricV3 `json:"cvssMetricV31,omitempty"`
+	CVSSMetricV30 []*CVEAPIJSON20CVSSMetricV3 `json:"cvssMetricV30,omitempty"`
+	CVSSMetricV2  []*CVEAPIJSON20CVSSMetricV2 `json:"cvssMetricV2,omitempty"`
+}
+
+// CVEAPIJSON20CVSSMetricV3 is a CVSS v3.x metric from a single source.
+type CVEAPIJSON20CVSSMetricV3 struct {
+	Source              string   `json:"source"`
+	Type                string   `json:"type"`
+	CVSSData            *CVSSV30 `json:"cvssData"`
+	ExploitabilityScore float64  `json:"exploitabilityScore,omitempty"`
+	ImpactScore         float64  `json:"impactScore,omitempty"`
+}
+
+// CVEAPIJSON20CVSSMetricV2 is a CVSS v2 metric from a single source.
+type CVEAPIJSON20CVSSMetricV2 struct {
+	Source                  string   `json:"source"`
+	Type                    string   `json:"type"`
+	CVSSData                *CVSSV20 `json:"cvssData"`
+	BaseSeverity            string   `json:"baseSeverity,omitempty"`
+	ExploitabilityScore     float64  `json:"exploitabilityScore,omitempty"`
+	ImpactScore             float64  `json:"impactScore,omitempty"`
+	AcInsufInfo             bool     `json:"acInsufInfo,omitempty"`
+	ObtainAllPrivilege      bool     `json:"obtainAllPrivilege,omitempty"`
+	ObtainUserPrivilege     bool     `json:"obtainUserPrivilege,omitempty"`
+	ObtainOtherPrivilege    bool     `json:"obtainOtherPrivilege,omitempty"`
+	UserInteractionRequired bool     `json:"userInteractionRequired,omitempty"`
+}
+
+// CVEAPIJSON20Weakness lists the CWEs assigned by a single source.
+type CVEAPIJSON20Weakness struct {
+	Source      string                 `json:"source"`
+	Type        string                 `json:"type"`
+	Description []*CVEJSON40LangString `json:"description"`
+}
+
+// CVEAPIJSON20Configuration is a set of nodes combined with the operator (OR, if not set).
+type CVEAPIJSON20Configuration struct {
+	Operator string              `json:"operator,omitempty"`
+	Negate   bool                `json:"negate,omitempty"`
+	Nodes    []*CVEAPIJSON20Node `json:"nodes"`
+}
+
+// CVEAPIJSON20Node is a configuration node, it has no children unlike the 1.x feed nodes.
+type CVEAPIJSON20Node struct {
+	Operator string                  `json:"operator"`
+	Negate   bool                    `json:"negate,omitempty"`
+	CPEMatch []*CVEAPIJSON20CPEMatch `json:"cpeMatch"`
+}
+
+// CVEAPIJSON20CPEMatch is a match criteria of a configuration node.
+type CVEAPIJSON20CPEMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Criteria              string `json:"criteria"`
+	MatchCriteriaID       string `json:"matchCriteriaId,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+}
+
+// CPEMatchAPIJSON20 is a response of NVD CPE Match Criteria API 2.0, a page of the CPE Match Feed.
+type CPEMatchAPIJSON20 struct {
+	ResultsPerPage int                      `json:"resultsPerPage"`
+	StartIndex     int                      `json:"startIndex"`
+	TotalResults   int                      `json:"totalResults"`
+	Format         string                   `json:"format"`
+	Version        string                   `json:"version"`
+	Timestamp      string                   `json:"timestamp"`
+	MatchStrings   []*CPEMatchAPIJSON20Item `json:"matchStrings"`
+}
+
+// CPEMatchAPIJSON20Item is an element of the matchStrings array of NVD CPE Match Criteria API 2.0 response.
+type CPEMatchAPIJSON20Item struct {
+	MatchString *CPEMatchAPIJSON20MatchString `json:"matchString"`
+}
+
+// CPEMatchAPIJSON20MatchString is a match criteria referenced by matchCriteriaId from the CVE configurations.
+type CPEMatchAPIJSON20MatchString struct {
+	Criteria              string                    `json:"criteria"`
+	MatchCriteriaID       string                    `json:"matchCriteriaId"`
+	VersionStartExcluding string                    `json:"versionStartExcluding,omitempty"`
+	VersionStartIncluding string                    `json:"versionStartIncluding,omitempty"`
+	VersionEndExcluding   string                    `json:"versionEndExcluding,omitempty"`
+	VersionEndIncluding   string                    `json:"versionEndIncluding,omitempty"`
+	LastModified          string                    `json:"lastModified,omitempty"`
+	CPELastModified       string                    `json:"cpeLastModified,omitempty"`
+	Created               string                    `json:"created,omitempty"`
+	Status                string                    `json:"status,omitempty"`
+	Matches               []*CPEMatchAPIJSON20Match `json:"matches,omitempty"`
+}
+
+// CPEMatchAPIJSON20Match is a CPE name from the CPE dictionary matching the match criteria.
+type CPEMatchAPIJSON20Match struct {
+	CPEName   string `json:"cpeName"`
+	CPENameID string `json:"cpeNameId"`
+}
+
+// CVEAPIJSON20Reference is a reference of the CVE.
+type CVEAPIJSON20Reference struct {
+	URL    string   `json:"url"`
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// rejectedStatus is the vulnStatus of CVEs rejected by NVD
+const rejectedStatus = "Rejected"
+
+// ToFeed converts the CVE into the NVD CVE JSON 1.x feed entry with the same data:
+// configurations become top-level nodes with the configuration nodes as children, the metric returned by
+// Metrics.PrimaryV3 becomes baseMetricV3 and the one returned by Metrics.PrimaryV2 becomes baseMetricV2;
+// the other metrics, see Metrics.OtherV3 and OtherV2, aren't converted. The description of rejected CVEs is prefixed with "** REJECT **",
+// as in the 1.x feeds. Entry always has configurations, even if empty.
+func (cve *CVEAPIJSON20CVEItem) ToFeed() *NVDCVEFeedJSON10DefCVEItem {
+	return cve.ToFeedResolved(nil)
+}
+
+// ToFeedResolved is like ToFeed, but the cpeMatch entries referencing the match criteria by their matchCriteriaId
+// are resolved with criteria, which maps the IDs to the match criteria from the CPE Match Feed: the entry which has
+// no version bounds of its own takes the bounds, and the criteria if it has none, from the referenced match criteria.
+// The entries referencing the match criteria missing from criteria are converted as they are.
+func (cve *CVEAPIJSON20CVEItem) ToFeedResolved(criteria map[string]*CPEMatchAPIJSON20MatchString) *NVDCVEFeedJSON10DefCVEItem {
+	item := &NVDCVEFeedJSON10DefCVEItem{
+		CVE: &CVEJSON40{
+			CVEDataMeta: &CVEJSON40CVEDataMeta{
+				ID:       cve.ID,
+				ASSIGNER: cve.SourceIdentifier,
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &CVEJSON40Description{},
+			Problemtype: &CVEJSON40Problemtype{},
+			References:  &CVEJSON40References{},
+		},
+		Configurations: &NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+		},
+		Impact:           &NVDCVEFeedJSON10DefImpact{},
+		PublishedDate:    apiTime(cve.Published),
+		LastModifiedDate: apiTime(cve.LastModified),
+	}
+
+	for _, desc := range cve.Descriptions {
+		if desc == nil {
+			continue
+		}
+		d := *desc
+		if cve.VulnStatus == rejectedStatus && d.Lang == "en" && !strings.HasPrefix(strings.TrimSpace(d.Value), "** REJECT **") {
+			d.Value = "** REJECT ** " + d.Value
+		}
+		item.CVE.Description.DescriptionData = append(item.CVE.Description.DescriptionData, &d)
+	}
+
+	for _, w := range cve.Weaknesses {
+		if w != nil {
+			item.CVE.Problemtype.ProblemtypeData = append(item.CVE.Problemtype.ProblemtypeData,
+				&CVEJSON40ProblemtypeProblemtypeData{Description: w.Description})
+		}
+	}
+
+	for _, ref := range cve.References {
+		if ref != nil {
+			item.CVE.References.ReferenceData = append(item.CVE.References.ReferenceData, &CVEJSON40Reference{
+				Name:      ref.URL,
+				Refsource: ref.Source,
+				Tags:      ref.Tags,
+				URL:       ref.URL,
+			})
+		}
+	}
+
+	for _, conf := range cve.Configurations {
+		if conf == nil {
+			continue
+		}
+		operator := conf.Operator
+		if operator == "" {
+			operator = "OR"
+		}
+		node := &NVDCVEFeedJSON10DefNode{
+			Operator: operator,
+			Negate:   conf.Negate,
+		}
+		for _, n := range conf.Nodes {
+			if n != nil {
+				node.Children = append(node.Children, n.toFeed(criteria))
+			}
+		}
+		// a configuration of a single node is that node, as in the 1.x feeds
+		if len(node.Children) == 1 && !node.Negate {
+			node = node.Children[0]
+		}
+		item.Configurations.Nodes = append(item.Configurations.Nodes, node)
+	}
+
+	if cve.Metrics != nil {
+		if m := cve.Metrics.PrimaryV3(); m != nil {
+			item.Impact.BaseMetricV3 = &NVDCVEFeedJSON10DefImpactBaseMetricV3{
+				CVSSV3:              m.CVSSData,
+				ExploitabilityScore: m.ExploitabilityScore,
+				ImpactScore:         m.ImpactScore,
+			}
+		}
+		if m := cve.Metrics.PrimaryV2(); m != nil {
+			item.Impact.BaseMetricV2 = &NVDCVEFeedJSON10DefImpactBaseMetricV2{
+				AcInsufInfo:             m.AcInsufInfo,
+				CVSSV2:                  m.CVSSData,
+				ExploitabilityScore:     m.ExploitabilityScore,
+				ImpactScore:             m.ImpactScore,
+				ObtainAllPrivilege:      m.ObtainAllPrivilege,
+				ObtainOtherPrivilege:    m.ObtainOtherPrivilege,
+				ObtainUserPrivilege:     m.ObtainUserPrivilege,
+				Severity:                m.BaseSeverity,
+				UserInteractionRequired: m.UserInteractionRequired,
+			}
+		}
+	}
+
+	return item
+}
+
+func (n *CVEAPIJSON20Node) toFeed(criteria map[string]*CPEMatchAPIJSON20MatchString) *NVDCVEFeedJSON10DefNode {
+	node := &NVDCVEFeedJSON10DefNode{
+		Operator: n.Operator,
+		Negate:   n.Negate,
+	}
+	for _, m := range n.CPEMatch {
+		if m != nil {
+			node.CPEMatch = append(node.CPEMatch, m.toFeed(criteria))
+		}
+	}
+	return node
+}
+
+func (m *CVEAPIJSON20CPEMatch) toFeed(criteria map[string]*CPEMatchAPIJSON20MatchString) *NVDCVEFeedJSON10DefCPEMatch {
+	match := &NVDCVEFeedJSON10DefCPEMatch{
+		Cpe23Uri:              m.Criteria,
+		VersionStartExcluding: m.VersionStartExcluding,
+		VersionStartIncluding: m.VersionStartIncluding,
+		VersionEndExcluding:   m.VersionEndExcluding,
+		VersionEndIncluding:   m.VersionEndIncluding,
+		Vulnerable:            m.Vulnerable,
+	}
+	ref := criteria[m.MatchCriteriaID]
+	if m.MatchCriteriaID == "" || ref == nil {
+		return match
+	}
+	if match.Cpe23Uri == "" {
+		match.Cpe23Uri = ref.Criteria
+	}
+	if match.VersionStartExcluding == "" && match.VersionStartIncluding == "" &&
+		match.VersionEndExcluding == "" && match.VersionEndIncluding == "" {
+		match.VersionStartExcluding = ref.VersionStartExcluding
+		match.VersionStartIncluding = ref.VersionStartIncluding
+		match.VersionEndExcluding = ref.VersionEndExcluding
+		match.VersionEndIncluding = ref.VersionEndIncluding
+	}
+	return match
+}
+
+// NVDSource is the source of the metrics scored by NVD itself
+const NVDSource = "nvd@nist.gov"
+
+// primaryRank ranks the metric of type and source for the selection of the primary metric, higher is better:
+// the Primary metric from NVD, then the Primary metric from any other source; other metrics aren't ranked (0)
+func primaryRank(typ, source string) int {
+	switch {
+	case typ == "Primary" && source == NVDSource:
+		return 2
+	case typ == "Primary":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// PrimaryV3 returns the CVSS v3 metric reported for the CVE: the Primary metric from NVD, or else the Primary
+// metric from any other source; of the equally ranked ones CVSS v3.1 is preferred over v3.0.
+// The Secondary metrics aren't selected, the 1.x feeds don't report the scores of the CNAs either.
+// Returns nil if there is no Primary v3 metric with the CVSS data.
+func (ms *CVEAPIJSON20Metrics) PrimaryV3() *CVEAPIJSON20CVSSMetricV3 {
+	var best *CVEAPIJSON20CVSSMetricV3
+	bestRank := 0
+	for _, m := range ms.v3() {
+		if rank := primaryRank(m.Type, m.Source); rank > bestRank {
+			best, bestRank = m, rank
+		}
+	}
+	return best
+}
+
+// PrimaryV2 returns the CVSS v2 metric reported for the CVE, selected as in PrimaryV3
+func (ms *CVEAPIJSON20Metrics) PrimaryV2() *CVEAPIJSON20CVSSMetricV2 {
+	var best *CVEAPIJSON20CVSSMetricV2
+	bestRank := 0
+	for _, m := range ms.v2() {
+		if rank := primaryRank(m.Type, m.Source); rank > bestRank {
+			best, bestRank = m, rank
+		}
+	}
+	return best
+}
+
+// OtherV3 returns the CVSS v3 metrics with the CVSS data other than the one returned by PrimaryV3,
+// such as the Secondary metrics from the CNAs; v3.1 metrics come first
+func (ms *CVEAPIJSON20Metrics) OtherV3() []*CVEAPIJSON20CVSSMetricV3 {
+	primary := ms.PrimaryV3()
+	var other []*CVEAPIJSON20CVSSMetricV3
+	for _, m := range ms.v3() {
+		if m != primary {
+			other = append(other, m)
+		}
+	}
+	return other
+}
+
+// OtherV2 returns the CVSS v2 metrics with the CVSS data other than the one returned by PrimaryV2
+func (ms *CVEAPIJSON20Metrics) OtherV2() []*CVEAPIJSON20CVSSMetricV2 {
+	primary := ms.PrimaryV2()
+	var other []*CVEAPIJSON20CVSSMetricV2
+	for _, m := range ms.v2() {
+		if m != primary {
+			other = append(other, m)
+		}
+	}
+	return other
+}
+
+// v3 returns CVSS v3.1 and then v3.0 metrics which have the CVSS data
+func (ms *CVEAPIJSON20Metrics) v3() []*CVEAPIJSON20CVSSMetricV3 {
+	if ms == nil {
+		return nil
+	}
+	var v3 []*CVEAPIJSON20CVSSMetricV3
+	for _, list := range [][]*CVEAPIJSON20CVSSMetricV3{ms.CVSSMetricV31, ms.CVSSMetricV30} {
+		for _, m := range list {
+			if m != nil && m.CVSSData != nil {
+				v3 = append(v3, m)
+			}
+		}
+	}
+	return v3
+}
+
+// v2 returns CVSS v2 metrics which have the CVSS data
+func (ms *CVEAPIJSON20Metrics) v2() []*CVEAPIJSON20CVSSMetricV2 {
+	if ms == nil {
+		return nil
+	}
+	var v2 []*CVEAPIJSON20CVSSMetricV2
+	for _, m := range ms.CVSSMetricV2 {
+		if m != nil && m.CVSSData != nil {
+			v2 = append(v2, m)
+		}
+	}
+	return v2
+}
+
+// apiTime converts API timestamp to TimeLayout, it's returned as is if it can't be parsed
+func apiTime(s string) string {
+	t, err := time.Parse(APITimeLayout, s)
+	if err != nil {
+		return s
+	}
+	return t.Format(TimeLayout)
+}
@@ -14,9 +14,36 @@
 
 package schema
 
+import (
+	"fmt"
+	"time"
+)
+
 // TimeLayout is the layout of NVD CVE timestamps.
 const TimeLayout = "2006-01-02T15:04Z"
 
+// timeLayouts are the layouts of timestamps found in NVD data: feeds, with and without seconds
+// or with the timezone offset instead of Z (older feeds omit the colon in it), and CVE API 2.0 responses,
+// which are in UTC
+var timeLayouts = []string{
+	TimeLayout,
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02T15:04:05.999999999Z0700",
+	APITimeLayout,
+}
+
+// ParseTime parses NVD timestamp in any of the layouts NVD uses.
+func ParseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't parse NVD timestamp %q", s)
+}
+
 // NVDCVEFeedJSON10DefCPEName was auto-generated.
 // CPE name.
 type NVDCVEFeedJSON10DefCPEName struct {
@@ -15,17 +15,74 @@
 package nvd
 
 import (
+	"strconv"
 	"strings"
 )
 
+// CompareSmartVersions compares stringified versions of software v1 and v2
+// as described in the package documentation.
+// Returns -1 if v1 < v2, 1 if v1 > v2 and 0 if v1 == v2.
+func CompareSmartVersions(v1, v2 string) int {
+	return smartVerCmp(v1, v2)
+}
+
+// SmartVersionMode modifies how CompareSmartVersionsMode compares versions; modes are combined with |
+type SmartVersionMode uint
+
+const (
+	// SmartVersionStripV ignores a single leading 'v' or 'V' which immediately precedes a digit,
+	// so "v1.2.3" == "1.2.3"; versions like "version" are compared as they are
+	SmartVersionStripV SmartVersionMode = 1 << iota
+	// SmartVersionEpoch compares the leading "N:" epochs of RPM or Debian package versions first, e.g. "1:2.3.4";
+	// an absent epoch equals 0, so "1:1.0" > "9.9" and "0:1.0" == "1.0"
+	SmartVersionEpoch
+)
+
+// CompareSmartVersionsMode is like CompareSmartVersions, but the versions are compared as per mode.
+// Returns -1 if v1 < v2, 1 if v1 > v2 and 0 if v1 == v2.
+func CompareSmartVersionsMode(v1, v2 string, mode SmartVersionMode) int {
+	if mode&SmartVersionEpoch != 0 {
+		var e1, e2 int
+		e1, v1 = parseEpoch(v1)
+		e2, v2 = parseEpoch(v2)
+		if e1 > e2 {
+			return 1
+		}
+		if e2 > e1 {
+			return -1
+		}
+	}
+	if mode&SmartVersionStripV != 0 {
+		v1, v2 = stripVPrefix(v1), stripVPrefix(v2)
+	}
+	return smartVerCmp(v1, v2)
+}
+
+// SmartVersionComparator returns the comparator which compares versions with CompareSmartVersionsMode,
+// e.g. to be set with Vuln.SetVersionComparator for the software tagging its releases as "v1.2.3"
+func SmartVersionComparator(mode SmartVersionMode) VersionComparator {
+	return func(v1, v2 string) int {
+		return CompareSmartVersionsMode(v1, v2, mode)
+	}
+}
+
 // smartVerCmp compares stringified versions of software.
 // It tries to do the right thing for any type of versioning,
 // assuming v1 and v2 have the same version convension.
-// It will return meaningful result for "95SE" vs "98SP1" or for "16.3.2" vs. "3.7.0",
-// but not for "2000" vs "11.7".
+// It will return meaningful result for "95SE" vs "98SP1" or for "16.3.2" vs. "3.7.0".
+// Versions which are both a single run of digits are compared as integers, e.g. "2000" > "11" and "007" == "7";
+// otherwise the versions are compared part by part, and the part with more digits is greater,
+// so "2000" > "11.7", as 2000 is greater than 11.
+// Pre-release suffixes (alpha, beta, rc, pre, dev, snapshot) rank below the same version
+// without the suffix, e.g. "1.0.0-rc1" < "1.0.0", and build metadata after '+' is ignored.
 // Returns -1 if v1 < v2, 1 if v1 > v2 and 0 if v1 == v2.
 func smartVerCmp(v1, v2 string) int {
-	for s1, s2 := v1, v2; len(s1) > 0 && len(s2) > 0; {
+	v1, v2 = stripBuildMeta(v1), stripBuildMeta(v2)
+	if isNumeric(v1) && isNumeric(v2) {
+		return numericCmp(v1, v2)
+	}
+	s1, s2 := v1, v2
+	for len(s1) > 0 && len(s2) > 0 {
 		num1, cmpTo1, skip1 := parseVerParts(s1)
 		num2, cmpTo2, skip2 := parseVerParts(s2)
 		if num1 > num2 {
@@ -34,12 +91,31 @@ func smartVerCmp(v1, v2 string) int {
 		if num2 > num1 {
 			return -1
 		}
-		if cmp := strings.Compare(s1[:cmpTo1], s2[:cmpTo2]); cmp != 0 {
-			return cmp
+		p1, p2 := s1[:cmpTo1], s2[:cmpTo2]
+		if p1 != p2 {
+			// "0rc1" vs "0": the part that only adds a pre-release tag is lesser
+			if strings.HasPrefix(p1, p2) && isPreRelease(p1[len(p2):]) {
+				return -1
+			}
+			if strings.HasPrefix(p2, p1) && isPreRelease(p2[len(p1):]) {
+				return 1
+			}
+			return strings.Compare(p1, p2)
 		}
 		s1 = s1[skip1:]
 		s2 = s2[skip2:]
 	}
+	// the version which continues with a pre-release tag is lesser, after zero parts too: "2.0.0-rc1" < "2.0"
+	if isPreRelease(trimZeroParts(s1)) {
+		return -1
+	}
+	if isPreRelease(trimZeroParts(s2)) {
+		return 1
+	}
+	// trailing zero parts don't count, i.e. "2.0" == "2.0.0"
+	if isZeroParts(s1) && isZeroParts(s2) {
+		return 0
+	}
 	// everything is equal so far, the longest wins
 	if len(v1) > len(v2) {
 		return 1
@@ -50,6 +126,28 @@ func smartVerCmp(v1, v2 string) int {
 	return 0
 }
 
+// parseEpoch splits the version into the leading "N:" epoch and the rest.
+// If the version has no epoch, 0 and the version itself are returned.
+func parseEpoch(v string) (int, string) {
+	i := strings.IndexByte(v, ':')
+	if i < 1 {
+		return 0, v
+	}
+	epoch, err := strconv.Atoi(v[:i])
+	if err != nil || epoch < 0 {
+		return 0, v
+	}
+	return epoch, v[i+1:]
+}
+
+// stripVPrefix removes the leading 'v' or 'V' if a digit follows it, e.g. "v1.2" becomes "1.2".
+func stripVPrefix(v string) string {
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') && v[1] >= '0' && v[1] <= '9' {
+		return v[1:]
+	}
+	return v
+}
+
 // parseVerParts returns the length of consecutive run of digits in the beginning of the string,
 // the last non-separator chararcted (which should be compared), and index at which the version part (major, minor etc.) ends,
 // i.e. the position of the dot or end of the line.
@@ -65,17 +163,80 @@ func parseVerParts(v string) (int, int, int) {
 		return num, num, num
 	}
 	// Any punctuation separates the parts.
-	skip := strings.IndexFunc(v, func(b rune) bool {
-		// !"#$%&'()*+,-./ are dec 33 to 47, :;<=>?@ are dec 58 to 64, [\]^_` are dec 91 to 96 and {|}~ are dec 123 to 126.
-		// So, punctuation is in dec 33-126 range except 48-57, 65-90 and 97-122 gaps.
-		// This inverse logic allows for early short-circuting for most of the chars and shaves ~20ns in benchmarks.
-		return b >= '!' && b <= '~' &&
-			!(b > '/' && b < ':' ||
-				b > '@' && b < '[' ||
-				b > '`' && b < '{')
-	})
+	skip := strings.IndexFunc(v, isSeparator)
 	if skip == -1 {
 		return num, len(v), len(v)
 	}
 	return num, skip, skip + 1
 }
+
+// preReleaseTokens are the suffixes which denote a version preceding the release.
+var preReleaseTokens = []string{"alpha", "beta", "rc", "pre", "dev", "snapshot"}
+
+// isPreRelease returns true if v starts with one of the pre-release tokens (case-insensitive).
+func isPreRelease(v string) bool {
+	for _, tok := range preReleaseTokens {
+		if len(v) >= len(tok) && strings.EqualFold(v[:len(tok)], tok) {
+			return true
+		}
+	}
+	return false
+}
+
+// isZeroParts returns true if v consists of zero version parts only, e.g. ".0.0"
+func isZeroParts(v string) bool {
+	for _, b := range v {
+		if b != '0' && !isSeparator(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumeric returns true if v is a non-empty run of digits.
+func isNumeric(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if v[i] < '0' || v[i] > '9' {
+			return false
+		}
+	}
+	return len(v) > 0
+}
+
+// numericCmp compares runs of digits as integers of any length, ignoring leading zeros.
+func numericCmp(v1, v2 string) int {
+	v1, v2 = strings.TrimLeft(v1, "0"), strings.TrimLeft(v2, "0")
+	if len(v1) > len(v2) {
+		return 1
+	}
+	if len(v2) > len(v1) {
+		return -1
+	}
+	return strings.Compare(v1, v2)
+}
+
+// stripBuildMeta removes the semver build metadata, i.e. everything after the '+'.
+func stripBuildMeta(v string) string {
+	if i := strings.IndexByte(v, '+'); i != -1 {
+		return v[:i]
+	}
+	return v
+}
+
+// trimZeroParts removes the leading separators and zero version parts, e.g. ".0.0-rc1" becomes "rc1".
+func trimZeroParts(v string) string {
+	return strings.TrimLeftFunc(v, func(r rune) bool {
+		return r == '0' || isSeparator(r)
+	})
+}
+
+// isSeparator returns true if b is a punctuation character.
+func isSeparator(b rune) bool {
+	// !"#$%&'()*+,-./ are dec 33 to 47, :;<=>?@ are dec 58 to 64, [\]^_` are dec 91 to 96 and {|}~ are dec 123 to 126.
+	// So, punctuation is in dec 33-126 range except 48-57, 65-90 and 97-122 gaps.
+	// This inverse logic allows for early short-circuting for most of the chars and shaves ~20ns in benchmarks.
+	return b >= '!' && b <= '~' &&
+		!(b > '/' && b < ':' ||
+			b > '@' && b < '[' ||
+			b > '`' && b < '{')
+}
@@ -40,6 +40,31 @@ func TestSmartVerCmp(t *testing.T) {
 		{"5-6", "5-16", -1},
 		{"5-a1", "5a1", -1}, // meh, kind of makes sense
 		{"5-a1", "5.a1", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0", "1.0.0-beta2", 1},
+		{"1.0.0-rc2", "1.0.0-rc1", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-SNAPSHOT", "1.0.0", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0.0-rc1", "1.0.1", -1},
+		{"1.0.0-dev", "0.9.9", 1},
+		{"1.0.0+build5", "1.0.0", 0},
+		{"1.0.0+build5", "1.0.0+build6", 0},
+		{"2000", "11", 1},
+		{"11", "2000", -1},
+		{"2000", "11.7", 1},
+		{"007", "7", 0},
+		{"007", "8", -1},
+		{"0", "000", 0},
+		{"12345678901234567890", "12345678901234567891", -1},
+		{"1.0.0-rc1+build5", "1.0.0", -1},
+		{"2.0", "2.0.0", 0},
+		{"2.0.0.0", "2", 0},
+		{"2.0.1", "2.0", 1},
+		{"2.0.0-rc1", "2.0", -1},
+		{"2.0", "2.0.0rc1", 1},
+		{"1.0.0-beta", "1", -1},
+		{"2.0.0.1-rc1", "2.0", 1},
 	}
 	for _, c := range cases {
 		t.Run(fmt.Sprintf("%q vs %q", c.v1, c.v2), func(t *testing.T) {
@@ -50,6 +75,64 @@ func TestSmartVerCmp(t *testing.T) {
 	}
 }
 
+func TestCompareSmartVersionsEpoch(t *testing.T) {
+	cases := []struct {
+		v1, v2 string
+		ret    int
+	}{
+		{"1:1.0", "2.0", 1},
+		{"1:1.0", "9.9", 1},
+		{"9.9", "1:1.0", -1},
+		{"2:0.1", "1:5.0", 1},
+		{"1:5.0", "2:0.1", -1},
+		{"0:1.0", "1.0", 0},
+		{"1:1.0", "1:1.0", 0},
+		{"1:1.0.1", "1:1.0", 1},
+		{"3:1.0-rc1", "3:1.0", -1},
+		{"1.0", "1.1", -1},
+		{"a:1.0", "1.0", -1}, // not an epoch, compared as is
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q", c.v1, c.v2), func(t *testing.T) {
+			if ret := CompareSmartVersionsMode(c.v1, c.v2, SmartVersionEpoch); ret != c.ret {
+				t.Fatalf("expected %d, got %d", c.ret, ret)
+			}
+		})
+	}
+}
+
+func TestCompareSmartVersionsMode(t *testing.T) {
+	cases := []struct {
+		v1, v2 string
+		mode   SmartVersionMode
+		ret    int
+	}{
+		{"v1.2.3", "1.2.3", SmartVersionStripV, 0},
+		{"V1.2.3", "v1.2.3", SmartVersionStripV, 0},
+		{"v2", "v1", SmartVersionStripV, 1},
+		{"v1.10", "1.9", SmartVersionStripV, 1},
+		{"1.0", "v1.0.1", SmartVersionStripV, -1},
+		{"version", "ersion", SmartVersionStripV, 1}, // not a version prefix, left untouched
+		{"vv1", "v1", SmartVersionStripV, -1},
+		{"v", "", SmartVersionStripV, 1},
+		{"v1.2.3", "1.2.3", 0, -1},
+		{"v2", "v1", 0, 1},
+		{"1:v2.0", "v9.0", SmartVersionEpoch | SmartVersionStripV, 1},
+		{"1:v2.0", "1:2.0", SmartVersionEpoch | SmartVersionStripV, 0},
+		{"1:2.0", "9.0", 0, -1},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q (mode %d)", c.v1, c.v2, c.mode), func(t *testing.T) {
+			if ret := CompareSmartVersionsMode(c.v1, c.v2, c.mode); ret != c.ret {
+				t.Fatalf("expected %d, got %d", c.ret, ret)
+			}
+			if ret := SmartVersionComparator(c.mode)(c.v1, c.v2); ret != c.ret {
+				t.Fatalf("comparator: expected %d, got %d", c.ret, ret)
+			}
+		})
+	}
+}
+
 func BenchmarkSmartVerCmp(b *testing.B) {
 	cases := []struct {
 		v1, v2 string
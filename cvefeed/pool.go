@@ -0,0 +1,118 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"sync"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// Pools of the objects which parseJSON drops once the feed, or an entry of it, is parsed.
+// Feed entries in 1.x format are never pooled, because they're owned by the Vuln they're converted to.
+
+var bufReaderPool = sync.Pool{
+	New: func() interface{} { return bufio.NewReader(nil) },
+}
+
+// gzip.Reader can only be created from a valid gzip stream, so the pool has no New function
+var gzipReaderPool sync.Pool
+
+var apiCVEPool = sync.Pool{
+	New: func() interface{} { return new(schema.CVEAPIJSON20CVEItem) },
+}
+
+// feedReader reads the feed, possibly gunzipping it, and returns its readers to the pools on Close
+type feedReader struct {
+	io.Reader
+	br *bufio.Reader
+	zr *gzip.Reader
+}
+
+func newFeedReader(in io.Reader) *feedReader {
+	br := bufReaderPool.Get().(*bufio.Reader)
+	br.Reset(in)
+	return &feedReader{Reader: br, br: br}
+}
+
+// gunzip makes r read the gzip'ed stream
+func (r *feedReader) gunzip() error {
+	zr, ok := gzipReaderPool.Get().(*gzip.Reader)
+	if !ok {
+		var err error
+		if zr, err = gzip.NewReader(r.br); err != nil {
+			return err
+		}
+	} else if err := zr.Reset(r.br); err != nil {
+		gzipReaderPool.Put(zr)
+		return err
+	}
+	r.Reader, r.zr = zr, zr
+	return nil
+}
+
+// Close implements io.Closer interface
+func (r *feedReader) Close() error {
+	var err error
+	if r.zr != nil {
+		err = r.zr.Close()
+		gzipReaderPool.Put(r.zr)
+	}
+	// don't keep the input reachable from the pool
+	r.br.Reset(nil)
+	bufReaderPool.Put(r.br)
+	return err
+}
+
+func getAPICVE() *schema.CVEAPIJSON20CVEItem {
+	return apiCVEPool.Get().(*schema.CVEAPIJSON20CVEItem)
+}
+
+func putAPICVE(cve *schema.CVEAPIJSON20CVEItem) {
+	resetAPICVE(cve)
+	apiCVEPool.Put(cve)
+}
+
+// resetAPICVE zeroes cve, keeping the arrays backing its slices for the next entry.
+// The elements of those arrays are cleared as well, so the decoder allocates every element anew:
+// otherwise it would decode into the old element, leaving the fields missing from the next entry as they were
+// and changing the data the Vuln converted from the previous entry may still refer to.
+func resetAPICVE(cve *schema.CVEAPIJSON20CVEItem) {
+	descriptions := cve.Descriptions[:cap(cve.Descriptions)]
+	for i := range descriptions {
+		descriptions[i] = nil
+	}
+	weaknesses := cve.Weaknesses[:cap(cve.Weaknesses)]
+	for i := range weaknesses {
+		weaknesses[i] = nil
+	}
+	configurations := cve.Configurations[:cap(cve.Configurations)]
+	for i := range configurations {
+		configurations[i] = nil
+	}
+	references := cve.References[:cap(cve.References)]
+	for i := range references {
+		references[i] = nil
+	}
+	*cve = schema.CVEAPIJSON20CVEItem{
+		Descriptions:   descriptions[:0],
+		Weaknesses:     weaknesses[:0],
+		Configurations: configurations[:0],
+		References:     references[:0],
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+func TestResetAPICVE(t *testing.T) {
+	var resp schema.CVEAPIJSON20
+	if err := json.Unmarshal([]byte(testJSONapi20), &resp); err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range resp.Vulnerabilities {
+		cve, id := item.CVE, item.CVE.ID
+		resetAPICVE(cve)
+		v := reflect.ValueOf(cve).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			f, name := v.Field(i), v.Type().Field(i).Name
+			if f.Kind() != reflect.Slice {
+				if !reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+					t.Errorf("%s: %s was not reset: %v", id, name, f.Interface())
+				}
+				continue
+			}
+			if f.Len() != 0 {
+				t.Errorf("%s: %s was not reset: %d elements left", id, name, f.Len())
+			}
+			f = f.Slice(0, f.Cap())
+			for j := 0; j < f.Len(); j++ {
+				if !f.Index(j).IsNil() {
+					t.Errorf("%s: %s was not reset: element %d of the backing array is set", id, name, j)
+				}
+			}
+		}
+	}
+}
+
+// TestParseJSONAPI20Pooled checks that the entries decoded into the pooled objects don't share any data:
+// each entry is parsed as if it was the only one in the response
+func TestParseJSONAPI20Pooled(t *testing.T) {
+	var resp schema.CVEAPIJSON20
+	if err := json.Unmarshal([]byte(testJSONapi20), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var bare schema.CVEAPIJSON20Vulnerability
+	if err := json.Unmarshal([]byte(testJSONapi20Bare), &bare); err != nil {
+		t.Fatal(err)
+	}
+	var items []*schema.CVEAPIJSON20Vulnerability
+	for _, item := range resp.Vulnerabilities {
+		items = append(items, item, &bare)
+	}
+	parse := func(items ...*schema.CVEAPIJSON20Vulnerability) []Vuln {
+		data, err := json.Marshal(&schema.CVEAPIJSON20{Vulnerabilities: items})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vulns, err := ParseJSON(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vulns
+	}
+	// parse them all first, so the later entries would overwrite the data of the earlier ones, if shared
+	vulns := parse(items...)
+	if len(vulns) != len(items) {
+		t.Fatalf("got %d entries, expected %d", len(vulns), len(items))
+	}
+	for i, item := range items {
+		expect := parse(item)[0].(*nvd.Vuln).CVEItem()
+		got := vulns[i].(*nvd.Vuln).CVEItem()
+		if !reflect.DeepEqual(got, expect) {
+			t.Errorf("entry %d (%s) differs when parsed alone:\n%+v\n%+v", i, expect.CVE.CVEDataMeta.ID, got, expect)
+		}
+	}
+}
+
+var testJSONapi20Bare = `{"cve":{"id":"CVE-2019-2999","published":"2019-05-01T07:00:00.000","lastModified":"2019-05-01T07:00:00.000",
+"configurations":[{"nodes":[{"operator":"OR","cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:acme:gadget:*:*:*:*:*:*:*:*"}]}]}]}}`
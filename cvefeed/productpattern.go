@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// PatternInput is an inventory entry whose product name may not map cleanly to the CPE product names,
+// e.g. "microsoft_windows_server_2019" rather than "windows_server_2019"
+type PatternInput struct {
+	CPE *wfn.Attributes
+	// Product, if set, is matched against the product names of the vulnerabilities, with the quoting removed,
+	// when CPE doesn't match any vulnerability as it is; the other attributes of CPE are matched as usual
+	Product *regexp.Regexp
+}
+
+// GetWithProductPatterns is like Get, but the inputs which match nothing on their own (along with the other inputs)
+// are retried with the product names of the vulnerabilities matching their Product pattern instead of their own.
+// This is a deliberate relaxation of the matching, for the imprecise inventory data only: the inputs matched
+// strictly are reported as by Get, and the vulnerabilities matched that way aren't matched again.
+// Results found by the patterns aren't cached; they are reported with the inputs as given, not the retried ones.
+func (c *Cache) GetWithProductPatterns(inputs []PatternInput) []MatchResult {
+	res, _ := c.GetWithProductPatternsContext(context.Background(), inputs)
+	return res
+}
+
+// GetWithProductPatternsContext is like GetWithProductPatterns, but matching stops as soon as ctx is done,
+// and ctx.Err() is returned then
+func (c *Cache) GetWithProductPatternsContext(ctx context.Context, inputs []PatternInput) ([]MatchResult, error) {
+	cpes := make([]*wfn.Attributes, len(inputs))
+	for i, in := range inputs {
+		cpes[i] = in.CPE
+	}
+	results, err := c.GetContext(ctx, cpes)
+	if err != nil {
+		return nil, err
+	}
+
+	// the inputs are looked up by value, as cached results hold equal copies of them, not the ones given
+	matched := map[string]bool{}
+	reported := map[string]bool{}
+	for _, r := range results {
+		reported[r.CVE.ID()] = true
+		for _, attr := range r.CPEs {
+			if attr != nil {
+				matched[attr.BindToFmtString()] = true
+			}
+		}
+	}
+	var products []string
+	var retries []*wfn.Attributes
+	retried := map[*wfn.Attributes]*wfn.Attributes{} // retried input -> input as given
+	for _, in := range inputs {
+		if in.CPE == nil || in.Product == nil || matched[in.CPE.BindToFmtString()] {
+			continue
+		}
+		if products == nil {
+			products = c.productNames()
+		}
+		for _, product := range products {
+			if product != in.CPE.Product && in.Product.MatchString(wfn.StripSlashes(product)) {
+				attr := *in.CPE
+				attr.Product = product
+				retries = append(retries, &attr)
+				retried[&attr] = in.CPE
+			}
+		}
+	}
+	if len(retries) == 0 {
+		return results, nil
+	}
+
+	found, err := c.matchDict(ctx, append(cpes, retries...), c.candidates(retries))
+	if err != nil {
+		return nil, err
+	}
+	// results may be the cached ones, which mustn't be modified
+	results = append([]MatchResult(nil), results...)
+	for _, r := range found {
+		if reported[r.CVE.ID()] {
+			continue
+		}
+		for i, attr := range r.CPEs {
+			if in, ok := retried[attr]; ok {
+				r.CPEs[i] = in
+			}
+		}
+		r.CPEs = uniqueAttrs(r.CPEs)
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CVE.ID() < results[j].CVE.ID() })
+	return results, nil
+}
+
+// productNames returns the product names the vulnerabilities are indexed by, or else the ones found
+// in the dictionary, sorted
+func (c *Cache) productNames() []string {
+	seen := map[string]bool{}
+	if c.Idx != nil {
+		for product := range c.Idx {
+			seen[product] = true
+		}
+	} else {
+		for _, v := range c.Dict {
+			for _, attr := range v.Config() {
+				seen[attr.Product] = true
+			}
+		}
+	}
+	delete(seen, wfn.Any)
+	delete(seen, wfn.NA)
+	products := make([]string, 0, len(seen))
+	for product := range seen {
+		products = append(products, product)
+	}
+	sort.Strings(products)
+	return products
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestGetWithProductPatterns(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictPatterns))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	server := &wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "microsoft_windows_server_2019", Version: wfn.NA}
+	widget := &wfn.Attributes{Part: "a", Vendor: "acme", Product: "widget", Version: "1\\.3"}
+	pattern := regexp.MustCompile(`(?i)windows.?server.?2019`)
+
+	for _, idx := range []bool{false, true} {
+		cache := NewCache(dict)
+		if idx {
+			cache.Idx = NewIndex(dict)
+		}
+		// strict matching misses the server
+		if res := cache.Get([]*wfn.Attributes{server}); len(res) != 0 {
+			t.Fatalf("index %t: expected no strict matches, got %v", idx, res)
+		}
+		res := cache.GetWithProductPatterns([]PatternInput{{CPE: server, Product: pattern}, {CPE: widget}})
+		expect := map[string]*wfn.Attributes{"TESTVE-2020-0001": server, "TESTVE-2020-0003": widget}
+		if len(res) != len(expect) {
+			t.Fatalf("index %t: expected %d results, got %v", idx, len(expect), res)
+		}
+		for _, r := range res {
+			if cpe := expect[r.CVE.ID()]; len(r.CPEs) != 1 || r.CPEs[0] != cpe {
+				t.Errorf("index %t: %s matched %v, expected %v", idx, r.CVE.ID(), r.CPEs, cpe)
+			}
+		}
+	}
+
+	// the pattern isn't used for the inputs matched strictly
+	cache := NewCache(dict)
+	res := cache.GetWithProductPatterns([]PatternInput{{CPE: widget, Product: regexp.MustCompile(`.`)}})
+	if len(res) != 1 || res[0].CVE.ID() != "TESTVE-2020-0003" {
+		t.Fatalf("expected only the strict match of the widget, got %v", res)
+	}
+	// nor the product names it doesn't match
+	res = cache.GetWithProductPatterns([]PatternInput{{CPE: server, Product: regexp.MustCompile(`^server$`)}})
+	if len(res) != 0 {
+		t.Fatalf("expected no matches, got %v", res)
+	}
+}
+
+func TestGetWithProductPatternsCached(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictPatterns))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	cache := NewCache(dict)
+	pattern := regexp.MustCompile(`windows_server`)
+	// the second call is served from the cache, which holds the CPE of the first one
+	for i := 0; i < 2; i++ {
+		server := &wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "windows_server_2016", Version: wfn.NA}
+		res := cache.GetWithProductPatterns([]PatternInput{{CPE: server, Product: pattern}})
+		if len(res) != 1 || res[0].CVE.ID() != "TESTVE-2020-0002" {
+			t.Fatalf("call %d: expected only the strict match TESTVE-2020-0002, got %v", i+1, res)
+		}
+	}
+
+	// the relaxed matches don't leak into the cached results
+	server := &wfn.Attributes{Part: "o", Vendor: "microsoft", Product: "microsoft_windows_server_2019", Version: wfn.NA}
+	for i := 0; i < 2; i++ {
+		res := cache.GetWithProductPatterns([]PatternInput{{CPE: server, Product: regexp.MustCompile(`windows_server_2019`)}})
+		if len(res) != 1 || res[0].CVE.ID() != "TESTVE-2020-0001" {
+			t.Fatalf("call %d: expected TESTVE-2020-0001, got %v", i+1, res)
+		}
+	}
+	if res := cache.Get([]*wfn.Attributes{server}); len(res) != 0 {
+		t.Fatalf("expected no strict matches, got %v", res)
+	}
+}
+
+var testJSONdictPatterns = `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_data_numberOfCVEs":"3","CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2020-0001","ASSIGNER":"cve@mitre.org"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[
+   {"vulnerable":true,"cpe23Uri":"cpe:2.3:o:microsoft:windows_server_2019:-:*:*:*:*:*:*:*"}]}]}},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2020-0002","ASSIGNER":"cve@mitre.org"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[
+   {"vulnerable":true,"cpe23Uri":"cpe:2.3:o:microsoft:windows_server_2016:-:*:*:*:*:*:*:*"}]}]}},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"TESTVE-2020-0003","ASSIGNER":"cve@mitre.org"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[
+   {"vulnerable":true,"cpe23Uri":"cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0"}]}]}}]}`
@@ -0,0 +1,92 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// PublishedOrModified tells which date Dictionary.Recent orders the entries by
+type PublishedOrModified int
+
+const (
+	// ByPublished orders the entries by the time they were published at, see Published
+	ByPublished PublishedOrModified = iota
+	// ByModified orders the entries by the time they were last modified at, see LastModified
+	ByModified
+)
+
+// date returns the date of v to order it by
+func (by PublishedOrModified) date(v Vuln) time.Time {
+	if by == ByModified {
+		return LastModified(v)
+	}
+	return Published(v)
+}
+
+// datedVuln is a vulnerability along with the date it's ordered by
+type datedVuln struct {
+	date time.Time
+	vuln Vuln
+}
+
+// newer returns true if a comes before b in Recent: it's more recent or, if dated the same, has a lesser ID
+func (a datedVuln) newer(b datedVuln) bool {
+	if !a.date.Equal(b.date) {
+		return a.date.After(b.date)
+	}
+	return a.vuln.ID() < b.vuln.ID()
+}
+
+// oldestFirst is a heap of dated vulnerabilities with the one which comes last in Recent on top
+type oldestFirst []datedVuln
+
+func (h oldestFirst) Len() int            { return len(h) }
+func (h oldestFirst) Less(i, j int) bool  { return h[j].newer(h[i]) }
+func (h oldestFirst) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *oldestFirst) Push(x interface{}) { *h = append(*h, x.(datedVuln)) }
+func (h *oldestFirst) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Recent returns at most n most recent entries of d, by the time they were published at or last modified at,
+// newest first; the entries dated the same are sorted by ID. The entries which don't tell the date come last.
+// Only n entries are kept while d is scanned, so it's cheap for the small n even for the whole NVD.
+func (d Dictionary) Recent(n int, by PublishedOrModified) []Vuln {
+	if n <= 0 {
+		return nil
+	}
+	h := make(oldestFirst, 0, n)
+	for _, v := range d {
+		dv := datedVuln{by.date(v), v}
+		if len(h) < n {
+			heap.Push(&h, dv)
+		} else if dv.newer(h[0]) {
+			h[0] = dv
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Slice(h, func(i, j int) bool { return h[i].newer(h[j]) })
+	recent := make([]Vuln, len(h))
+	for i, dv := range h {
+		recent[i] = dv.vuln
+	}
+	return recent
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDictionaryRecent(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdictRecent))
+	}, "")
+	if err != nil {
+		t.Fatalf("could not load test JSON feed: %v", err)
+	}
+	cases := []struct {
+		n      int
+		by     PublishedOrModified
+		expect []string
+	}{
+		{n: 3, by: ByPublished, expect: []string{"CVE-2020-0003", "CVE-2020-0001", "CVE-2020-0002"}},
+		{n: 10, by: ByPublished, expect: []string{"CVE-2020-0003", "CVE-2020-0001", "CVE-2020-0002", "CVE-2019-0001", "CVE-2020-0004"}},
+		{n: 2, by: ByModified, expect: []string{"CVE-2019-0001", "CVE-2020-0002"}},
+		{n: 5, by: ByModified, expect: []string{"CVE-2019-0001", "CVE-2020-0002", "CVE-2020-0003", "CVE-2020-0001", "CVE-2020-0004"}},
+		{n: 1, by: ByModified, expect: []string{"CVE-2019-0001"}},
+		{n: 0, by: ByPublished},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, v := range dict.Recent(c.n, c.by) {
+			got = append(got, v.ID())
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("Recent(%d, %d): got %v, expected %v", c.n, c.by, got, c.expect)
+		}
+	}
+}
+
+// CVE-2020-0001 and CVE-2020-0002 are published at the same time, CVE-2020-0004 doesn't tell the dates
+var testJSONdictRecent = `{"CVE_data_type":"CVE","CVE_data_format":"MITRE","CVE_data_version":"4.0","CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2020-0002"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]},
+ "publishedDate":"2020-02-01T00:00Z","lastModifiedDate":"2020-09-01T00:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2019-0001"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]},
+ "publishedDate":"2019-05-01T00:00Z","lastModifiedDate":"2020-12-01T00:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2020-0004"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]}},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2020-0003"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]},
+ "publishedDate":"2020-03-01T00:00Z","lastModifiedDate":"2020-03-01T00:00Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2020-0001"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[]},
+ "publishedDate":"2020-02-01T00:00Z","lastModifiedDate":"2020-02-01T00:00Z"}]}`
@@ -0,0 +1,176 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package server serves matching of CPE names against a CVE dictionary over HTTP.
+//
+// Endpoints are:
+//
+//	POST /match   matches the CPE names of {"cpes": [...]}, URIs or formatted strings, and responds with
+//	              {"findings": [...]}, a cvefeed.Finding per CVE, sorted by CVE ID
+//	POST /reload  reloads the dictionary; the current one is kept, and matched against meanwhile, if loading fails
+//	GET  /healthz responds with the number of entries in the dictionary
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// maxRequestSize limits the size of the match request body
+const maxRequestSize = 1 << 20
+
+// MatchRequest is the body of the match request
+type MatchRequest struct {
+	CPEs []string `json:"cpes"`
+}
+
+// MatchResponse is the response to the match request
+type MatchResponse struct {
+	Findings []cvefeed.Finding `json:"findings"`
+}
+
+// StatusResponse is the response to the health check and reload requests
+type StatusResponse struct {
+	Entries int `json:"entries"`
+}
+
+// errorResponse is the response to the failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server is an http.Handler serving the endpoints described in the package documentation
+type Server struct {
+	dict *cvefeed.SyncDictionary
+	load func() (cvefeed.Dictionary, error)
+	mux  *http.ServeMux
+	// reloads are serialized, so the concurrent requests don't load the feeds several times at once
+	reloadMu sync.Mutex
+	logger   logging.Logger
+}
+
+// New loads the dictionary with load and creates a Server matching against it; load is also called on every reload.
+// newCache creates the cache for each loaded dictionary, as in cvefeed.NewSyncDictionary.
+func New(load func() (cvefeed.Dictionary, error), newCache func(cvefeed.Dictionary) *cvefeed.Cache) (*Server, error) {
+	dict, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("server: can't load dictionary: %v", err)
+	}
+	s := &Server{
+		dict:   cvefeed.NewSyncDictionary(dict, newCache),
+		load:   load,
+		mux:    http.NewServeMux(),
+		logger: logging.Nop,
+	}
+	s.mux.HandleFunc("/match", s.handleMatch)
+	s.mux.HandleFunc("/reload", s.handleReload)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	return s, nil
+}
+
+// SetLogger sets the logger which receives the failed reloads and responses, they are discarded by default.
+// Returns a pointer to the instance of Server, for easy chaining.
+func (s *Server) SetLogger(logger logging.Logger) *Server {
+	s.logger = logging.OrNop(logger)
+	return s
+}
+
+// ServeHTTP implements http.Handler interface
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleMatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+	var req MatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("bad request: %v", err))
+		return
+	}
+	cpes := make([]*wfn.Attributes, 0, len(req.CPEs))
+	for _, name := range req.CPEs {
+		attr, err := wfn.Parse(name)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("can't parse CPE name %q: %v", name, err))
+			return
+		}
+		cpes = append(cpes, attr)
+	}
+	// matching stops if the client goes away
+	findings, err := s.dict.FindingsContext(r.Context(), cpes)
+	if err != nil {
+		s.writeError(w, http.StatusServiceUnavailable, fmt.Errorf("matching aborted: %v", err))
+		return
+	}
+	for i := range findings {
+		findings[i].CPEs = sortedCPEs(findings[i].CPEs)
+	}
+	resp := MatchResponse{Findings: append([]cvefeed.Finding{}, findings...)}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// sortedCPEs returns a copy of cpes sorted by their formatted string binding
+func sortedCPEs(cpes []*wfn.Attributes) []*wfn.Attributes {
+	sorted := append([]*wfn.Attributes{}, cpes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].BindToFmtString() < sorted[j].BindToFmtString()
+	})
+	return sorted
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+	s.reloadMu.Lock()
+	err := s.dict.Reload(s.load)
+	s.reloadMu.Unlock()
+	if err != nil {
+		s.logger.Errorf("server: reload failed: %v", err)
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("reload failed: %v", err))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, StatusResponse{Entries: len(s.dict.Dictionary())})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, StatusResponse{Entries: len(s.dict.Dictionary())})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, code int, err error) {
+	s.writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Errorf("server: can't write response: %v", err)
+	}
+}
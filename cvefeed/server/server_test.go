@@ -0,0 +1,203 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// testLoader loads the feeds one by one on every call, the last one is loaded forever
+type testLoader struct {
+	mu    sync.Mutex
+	feeds []string
+}
+
+func (l *testLoader) load() (cvefeed.Dictionary, error) {
+	l.mu.Lock()
+	feed := l.feeds[0]
+	if len(l.feeds) > 1 {
+		l.feeds = l.feeds[1:]
+	}
+	l.mu.Unlock()
+	if feed == "" {
+		return nil, fmt.Errorf("broken feed")
+	}
+	return cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(strings.NewReader(feed))
+	}, "")
+}
+
+func do(t *testing.T, s http.Handler, method, path, body string, v interface{}) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if v != nil {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: can't decode response: %v", method, path, err)
+		}
+	}
+	return w.Code
+}
+
+func matchCVEs(t *testing.T, s http.Handler, cpes ...string) []string {
+	body, err := json.Marshal(MatchRequest{CPEs: cpes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp MatchResponse
+	if code := do(t, s, http.MethodPost, "/match", string(body), &resp); code != http.StatusOK {
+		t.Fatalf("match request failed with %d", code)
+	}
+	ids := []string{}
+	for _, f := range resp.Findings {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func TestMatch(t *testing.T) {
+	l := &testLoader{feeds: []string{testFeed1}}
+	s, err := New(l.load, nil)
+	if err != nil {
+		t.Fatalf("can't create server: %v", err)
+	}
+	const req = `{"cpes": ["cpe:/a:foo:bar:1.0", "cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*"]}`
+	var resp MatchResponse
+	if code := do(t, s, http.MethodPost, "/match", req, &resp); code != http.StatusOK {
+		t.Fatalf("match request failed with %d", code)
+	}
+	bar := wfn.Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1\\.0"}
+	baz := wfn.Attributes{Part: "a", Vendor: "foo", Product: "baz", Version: "1\\.0"}
+	range1 := cvefeed.RangeMatch{ID: "CVE-2019-0001", CPE: "cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0"}
+	range2 := cvefeed.RangeMatch{ID: "CVE-2019-0002", CPE: "cpe:2.3:a:foo:*:*:*:*:*:*:*:*:*", VersionEndExcluding: "2.0"}
+	expect := []cvefeed.Finding{
+		{ID: "CVE-2019-0001", CPEs: []*wfn.Attributes{&bar}, Ranges: []cvefeed.RangeMatch{range1}, Range: &range1, CWEs: []string{"CWE-79"}, CVSS3: 6.1, CVSS3Severity: "MEDIUM"},
+		{ID: "CVE-2019-0002", CPEs: []*wfn.Attributes{&bar, &baz}, Ranges: []cvefeed.RangeMatch{range2, range2}, Range: &range2},
+	}
+	if !reflect.DeepEqual(resp.Findings, expect) {
+		t.Fatalf("got\n%+v\nexpected\n%+v", resp.Findings, expect)
+	}
+	// findings are served as marshaled by cvefeed.Finding
+	var raw struct {
+		Findings []map[string]interface{} `json:"findings"`
+	}
+	do(t, s, http.MethodPost, "/match", req, &raw)
+	if len(raw.Findings) != 2 || raw.Findings[0]["cve"] != "CVE-2019-0001" || raw.Findings[0]["cvss3"] != 6.1 {
+		t.Fatalf("unexpected findings %v", raw.Findings)
+	}
+	if matches, ok := raw.Findings[1]["matches"].([]interface{}); !ok || len(matches) != 2 || matches[1] != "cpe:2.3:a:foo:baz:1.0:*:*:*:*:*:*:*" {
+		t.Fatalf("unexpected matches %v", raw.Findings[1]["matches"])
+	}
+
+	if got := matchCVEs(t, s, "cpe:/a:acme:qux:1.0"); len(got) != 0 {
+		t.Fatalf("expected no findings, got %v", got)
+	}
+
+	var errResp errorResponse
+	if code := do(t, s, http.MethodPost, "/match", `{"cpes": ["cpe:/a:foo:bar:1.0", "foo:bar"]}`, &errResp); code != http.StatusBadRequest {
+		t.Fatalf("expected bad CPE name to fail with %d, got %d", http.StatusBadRequest, code)
+	}
+	if !strings.Contains(errResp.Error, `"foo:bar"`) {
+		t.Fatalf("error doesn't name the bad CPE: %q", errResp.Error)
+	}
+	if code := do(t, s, http.MethodPost, "/match", `{"cpes": `, nil); code != http.StatusBadRequest {
+		t.Fatalf("expected bad JSON to fail with %d, got %d", http.StatusBadRequest, code)
+	}
+	if code := do(t, s, http.MethodGet, "/match", "", nil); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected GET to fail with %d, got %d", http.StatusMethodNotAllowed, code)
+	}
+}
+
+func TestReload(t *testing.T) {
+	l := &testLoader{feeds: []string{testFeed1, testFeed2, ""}}
+	s, err := New(l.load, nil)
+	if err != nil {
+		t.Fatalf("can't create server: %v", err)
+	}
+	var status StatusResponse
+	if code := do(t, s, http.MethodGet, "/healthz", "", &status); code != http.StatusOK || status.Entries != 2 {
+		t.Fatalf("health check: got %d with %d entries, expected %d with 2", code, status.Entries, http.StatusOK)
+	}
+
+	// match concurrently with the reloads
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				body := `{"cpes": ["cpe:/a:foo:bar:1.0"]}`
+				req := httptest.NewRequest(http.MethodPost, "/match", bytes.NewBufferString(body))
+				w := httptest.NewRecorder()
+				s.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Errorf("concurrent match failed with %d", w.Code)
+					return
+				}
+			}
+		}()
+	}
+
+	if code := do(t, s, http.MethodPost, "/reload", "", &status); code != http.StatusOK || status.Entries != 1 {
+		t.Fatalf("reload: got %d with %d entries, expected %d with 1", code, status.Entries, http.StatusOK)
+	}
+	if got, expect := matchCVEs(t, s, "cpe:/a:foo:bar:1.0"), []string{"CVE-2019-0003"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("after reload: got %v, expected %v", got, expect)
+	}
+	if code := do(t, s, http.MethodPost, "/reload", "", nil); code != http.StatusInternalServerError {
+		t.Fatalf("expected broken reload to fail with %d, got %d", http.StatusInternalServerError, code)
+	}
+	close(stop)
+	wg.Wait()
+	if got, expect := matchCVEs(t, s, "cpe:/a:foo:bar:1.0"), []string{"CVE-2019-0003"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("after failed reload: got %v, expected %v", got, expect)
+	}
+	if code := do(t, s, http.MethodGet, "/reload", "", nil); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected GET to fail with %d, got %d", http.StatusMethodNotAllowed, code)
+	}
+}
+
+func TestNewFails(t *testing.T) {
+	l := &testLoader{feeds: []string{""}}
+	if _, err := New(l.load, nil); err == nil {
+		t.Fatal("expected New to fail if the dictionary can't be loaded")
+	}
+}
+
+var testFeed1 = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0002"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:*:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]}},
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0001"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0","problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-79"}]}]}},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]},"impact":{"baseMetricV3":{"cvssV3":{"baseScore":6.1,"version":"3.0"}}}}
+]}`
+
+var testFeed2 = `{"CVE_data_format":"MITRE","CVE_data_type":"CVE","CVE_data_version":"4.0","CVE_Items":[
+{"cve":{"CVE_data_meta":{"ID":"CVE-2019-0003"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0"},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe_match":[{"cpe23Uri":"cpe:2.3:a:foo:bar:*:*:*:*:*:*:*:*","versionEndExcluding":"2.0","vulnerable":true}],"operator":"OR"}]}}
+]}`
@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"sync"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// SyncDictionary holds a Dictionary which can be matched against concurrently and reloaded at the same time.
+// Dictionaries are never modified once they're held: reload swaps a freshly built dictionary in, so the matches
+// which are in progress complete against the snapshot they started with.
+type SyncDictionary struct {
+	mu       sync.RWMutex
+	dict     Dictionary
+	cache    *Cache
+	newCache func(Dictionary) *Cache
+}
+
+// NewSyncDictionary creates new SyncDictionary holding dict.
+// newCache is called to create a Cache for dict and for every reloaded dictionary, e.g. to configure
+// its size or index; if it's nil, NewCache is used.
+func NewSyncDictionary(dict Dictionary, newCache func(Dictionary) *Cache) *SyncDictionary {
+	if newCache == nil {
+		newCache = NewCache
+	}
+	return &SyncDictionary{dict: dict, cache: newCache(dict), newCache: newCache}
+}
+
+// Dictionary returns the current snapshot of the dictionary; it must not be modified
+func (sd *SyncDictionary) Dictionary() Dictionary {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict
+}
+
+// Get returns CVEs matching the CPE names in the current snapshot of the dictionary, see Cache.Get
+func (sd *SyncDictionary) Get(cpes []*wfn.Attributes) []MatchResult {
+	sd.mu.RLock()
+	cache := sd.cache
+	sd.mu.RUnlock()
+	return cache.Get(cpes)
+}
+
+// GetContext is like Get, but matching stops with ctx.Err() as soon as ctx is done, see Cache.GetContext
+func (sd *SyncDictionary) GetContext(ctx context.Context, cpes []*wfn.Attributes) ([]MatchResult, error) {
+	sd.mu.RLock()
+	cache := sd.cache
+	sd.mu.RUnlock()
+	return cache.GetContext(ctx, cpes)
+}
+
+// FindingsContext is like GetContext, but returns the results resolved into findings, see Cache.FindingsContext
+func (sd *SyncDictionary) FindingsContext(ctx context.Context, cpes []*wfn.Attributes) ([]Finding, error) {
+	sd.mu.RLock()
+	cache := sd.cache
+	sd.mu.RUnlock()
+	return cache.FindingsContext(ctx, cpes)
+}
+
+// Swap replaces the dictionary with dict and returns the previous one.
+// The cache for the new dictionary is created before the lock is taken, so the readers aren't blocked meanwhile.
+func (sd *SyncDictionary) Swap(dict Dictionary) Dictionary {
+	cache := sd.newCache(dict)
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	old := sd.dict
+	sd.dict, sd.cache = dict, cache
+	return old
+}
+
+// Reload loads a new dictionary with load and swaps it in.
+// If load fails, the error is returned and the current dictionary is kept.
+func (sd *SyncDictionary) Reload(load func() (Dictionary, error)) error {
+	dict, err := load()
+	if err != nil {
+		return err
+	}
+	sd.Swap(dict)
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestSyncDictionaryReload(t *testing.T) {
+	small, _ := benchmarkDict(10)
+	large, inputs := benchmarkDict(20)
+	// CVE-2019-5 is in both dictionaries, CVE-2019-15 only in the large one
+	cpes := []*wfn.Attributes{inputs[5][0], inputs[15][0]}
+
+	sd := NewSyncDictionary(small, func(d Dictionary) *Cache {
+		return NewCache(d).SetMaxSize(1 << 20)
+	})
+	stop := make(chan struct{})
+	errs := make(chan error, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				ids := matchIDs(sd.Get(cpes))
+				if got := fmt.Sprint(ids); got != "[CVE-2019-5/1]" && got != "[CVE-2019-15/1 CVE-2019-5/1]" {
+					errs <- fmt.Errorf("unexpected matches %v", ids)
+					return
+				}
+				if d := sd.Dictionary(); len(d) != len(small) && len(d) != len(large) {
+					errs <- fmt.Errorf("unexpected dictionary of %d entries", len(d))
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		next := small
+		if i%2 == 0 {
+			next = large
+		}
+		if err := sd.Reload(func() (Dictionary, error) { return next, nil }); err != nil {
+			t.Fatalf("reload failed: %v", err)
+		}
+	}
+	if err := sd.Reload(func() (Dictionary, error) { return nil, fmt.Errorf("broken feed") }); err == nil {
+		t.Fatal("expected reload to fail")
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := len(sd.Dictionary()); got != len(small) {
+		t.Fatalf("failed reload replaced the dictionary: got %d entries, expected %d", got, len(small))
+	}
+}
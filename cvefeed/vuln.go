@@ -1,9 +1,16 @@
 package cvefeed
 
 import (
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvss3"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
+// Reference is a link to a resource about the vulnerability, e.g. a patch or an advisory
+type Reference = nvd.Reference
+
 // Vuln is a vulnerability interface
 type Vuln interface {
 	// vulnerability should also be able to match attributes
@@ -14,6 +21,8 @@ type Vuln interface {
 	CVEs() []string
 	// CWEs returns all CWEs for this vulnerability
 	CWEs() []string
+	// References returns the links to resources about the vulnerability, with their tags (e.g. Patch)
+	References() []Reference
 	// CVSSv2BaseScore returns CVSS v2 base score
 	CVSSv2BaseScore() float64
 	// CVSSv2BaseScore returns CVSS v2 vector
@@ -22,6 +31,9 @@ type Vuln interface {
 	CVSSv3BaseScore() float64
 	// CVSSv2BaseScore returns CVSS v3 vector
 	CVSSv3Vector() string
+	// Rejected returns true if the vulnerability was rejected by NVD (its description starts with "** REJECT **");
+	// disputed entries aren't considered rejected
+	Rejected() bool
 }
 
 // MergeVuln combines two Vulns:
@@ -30,14 +42,16 @@ type Vuln interface {
 // the returned vuln matches attributes if x matches AND y doesn't
 func OverrideVuln(v, override Vuln) Vuln {
 	return &overriden{
-		Vuln:    v,
-		matcher: &andMatcher{v, wfn.DontMatch(override)},
+		Vuln:     v,
+		override: override,
+		matcher:  &andMatcher{v, wfn.DontMatch(override)},
 	}
 }
 
 type overriden struct {
 	Vuln
-	matcher wfn.Matcher
+	override Vuln
+	matcher  wfn.Matcher
 }
 
 // Match is a part of the wfn.Matcher interface
@@ -64,3 +78,84 @@ func (m *andMatcher) Match(attrs []*wfn.Attributes, requireVersion bool) []*wfn.
 func (m *andMatcher) Config() []*wfn.Attributes {
 	return append(m.m1.Config(), m.m2.Config()...)
 }
+
+// unwrapOverrides returns the original vulnerability of the overriden one:
+// overrides only restrict matching, the configuration comes from the original.
+func unwrapOverrides(v Vuln) Vuln {
+	for {
+		o, ok := v.(*overriden)
+		if !ok {
+			return v
+		}
+		v = o.Vuln
+	}
+}
+
+// explainer is implemented by vulnerabilities which can tell which of their cpe_match entries matched
+type explainer interface {
+	Explain(attrs []*wfn.Attributes, requireVersion bool) []nvd.Explanation
+}
+
+// Explain returns the cpe_match entries of vulnerability v which match attrs, see nvd.Vuln.Explain.
+// It returns nil if v doesn't come from an NVD JSON feed.
+func Explain(v Vuln, attrs []*wfn.Attributes, requireVersion bool) []nvd.Explanation {
+	if e, ok := unwrapOverrides(v).(explainer); ok {
+		return e.Explain(attrs, requireVersion)
+	}
+	return nil
+}
+
+// publicationReporter is implemented by vulnerabilities which know when they were published
+type publicationReporter interface {
+	Published() time.Time
+}
+
+// Published returns the time vulnerability v was published at, zero time if it's unknown
+func Published(v Vuln) time.Time {
+	if r, ok := unwrapOverrides(v).(publicationReporter); ok {
+		return r.Published()
+	}
+	return time.Time{}
+}
+
+// LastModified returns the time vulnerability v was last modified at, zero time if it's unknown
+func LastModified(v Vuln) time.Time {
+	if r, ok := unwrapOverrides(v).(modificationReporter); ok {
+		return r.LastModified()
+	}
+	return time.Time{}
+}
+
+// cvssChecker is implemented by vulnerabilities which can check their CVSS base scores against their vectors
+type cvssChecker interface {
+	CVSSConsistent() (ok bool, computed, stated float64)
+}
+
+// CVSSConsistent tells if the CVSS base scores of vulnerability v match the ones computed from its vectors,
+// see nvd.Vuln.CVSSConsistent; the vulnerability which can't tell is considered consistent, with no scores
+func CVSSConsistent(v Vuln) (ok bool, computed, stated float64) {
+	if c, ok := unwrapOverrides(v).(cvssChecker); ok {
+		return c.CVSSConsistent()
+	}
+	return true, 0, 0
+}
+
+// severityReporter is implemented by vulnerabilities which state the severity of their CVSS v3 base score
+type severityReporter interface {
+	CVSSv3Severity() string
+}
+
+// CVSSv3Severity returns CVSS v3 base severity of vulnerability v (NONE, LOW, MEDIUM, HIGH or CRITICAL):
+// the one stated by v if any, otherwise the one of its CVSSv3BaseScore; empty if v isn't scored with CVSS v3
+func CVSSv3Severity(v Vuln) string {
+	if r, ok := unwrapOverrides(v).(severityReporter); ok {
+		if severity := r.CVSSv3Severity(); severity != "" {
+			return severity
+		}
+	}
+	score := v.CVSSv3BaseScore()
+	if score == 0 && v.CVSSv3Vector() == "" {
+		return ""
+	}
+	return cvss3.Severity(score)
+}
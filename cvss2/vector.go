@@ -66,6 +66,7 @@ func VectorFromString(str string) (Vector, error) {
 	str = strings.TrimPrefix(str, prefix)
 	str = strings.TrimSuffix(str, suffix)
 	parseables := v.parseables()
+	seen := make(map[string]bool, len(parseables))
 
 	for _, part := range strings.Split(str, partSeparator) {
 		tmp := strings.Split(part, metricSeparator)
@@ -76,14 +77,43 @@ func VectorFromString(str string) (Vector, error) {
 		metric, value := tmp[0], tmp[1]
 		if p, ok := parseables[metric]; !ok {
 			return v, fmt.Errorf("undefined metric %s with value %s", metric, value)
+		} else if seen[metric] {
+			return v, fmt.Errorf("metric %s defined more than once", metric)
+		} else if value == "" {
+			return v, fmt.Errorf("no value for metric %s", metric)
 		} else if err := p.parse(value); err != nil {
 			return v, fmt.Errorf("error occurred while parsing metric %s: %v", metric, err)
 		}
+		seen[metric] = true
 	}
 
 	return v, nil
 }
 
+// VectorFromStringStrict is like VectorFromString, but also returns an error if any of the base metrics is missing
+// It should be used for vectors coming from third party feeds, where a partial vector is an error
+func VectorFromStringStrict(str string) (Vector, error) {
+	v, err := VectorFromString(str)
+	if err != nil {
+		return v, err
+	}
+	if err = v.Validate(); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// Normalize parses the vector and returns it with the metrics in the order of the specification, without the ones
+// which aren't defined (ND), and in parentheses: equivalent vectors normalize to the same string.
+// It returns an error if the vector can't be parsed, as with VectorFromStringStrict
+func Normalize(str string) (string, error) {
+	v, err := VectorFromStringStrict(str)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
 // Absorb will override only metrics in the current vector from the one given which are defined
 // If the other vector specifies only a single metric with all others undefined, the resulting
 // vector will contain all metrics it previously did, with only the new one overriden
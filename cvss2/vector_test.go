@@ -60,6 +60,73 @@ func TestFromString(t *testing.T) {
 	}
 }
 
+func TestFromStringStrict(t *testing.T) {
+	cases := []struct {
+		name string
+		str  string
+		fail bool
+	}{
+		{"base", "AV:N/AC:L/Au:N/C:P/I:P/A:P", false},
+		{"full", "(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/RL:OF/RC:C/CDP:LM/TD:H/CR:M/IR:M/AR:H)", false},
+		{"any order", "(AR:H/E:F/CDP:LM/AV:N/A:P/RC:C/AC:L/TD:H/I:P/CR:M/Au:N/RL:OF/IR:M/C:P)", false},
+		{"bad value", "(AV:X/AC:L/Au:N/C:P/I:P/A:P)", true},
+		{"empty value", "(AV:N/AC:L/Au:N/C:/I:P/A:P)", true},
+		{"missing C", "(AV:N/AC:L/Au:N/I:P/A:P)", true},
+		{"duplicate AV", "(AV:N/AV:L/AC:L/Au:N/C:P/I:P/A:P)", true},
+		{"duplicate E", "(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/E:F)", true},
+		{"unknown metric", "(AV:N/AC:L/Au:N/C:P/I:P/A:P/XX:N)", true},
+		{"lowercase metric", "(av:N/AC:L/Au:N/C:P/I:P/A:P)", true},
+		{"no separator", "(AV:N/AC:L/Au:N/C:P/I:P/A)", true},
+		{"empty", "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := VectorFromStringStrict(c.str)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got vector %s", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse vector: %v", err)
+			}
+			// metrics are always serialized in the same order
+			if v2, _ := VectorFromString(v.String()); v2 != v {
+				t.Errorf("vector %s doesn't survive a round trip", v)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	vectors := []string{
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/RL:OF/RC:C/CR:M",
+		"(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/RL:OF/RC:C/CR:M)",
+		"(CR:M/RC:C/RL:OF/E:F/A:P/I:P/C:P/Au:N/AC:L/AV:N)",
+		"(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/RL:OF/RC:C/CDP:ND/TD:ND/CR:M/IR:ND/AR:ND)",
+	}
+	expect := "(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F/RL:OF/RC:C/CR:M)"
+	for _, str := range vectors {
+		if got, err := Normalize(str); err != nil {
+			t.Errorf("%s: unable to normalize vector: %v", str, err)
+		} else if got != expect {
+			t.Errorf("%s: got %s, expected %s", str, got, expect)
+		}
+	}
+
+	for _, str := range []string{
+		"(AV:N/AC:L/Au:N/I:P/A:P)",
+		"(AV:N/AV:L/AC:L/Au:N/C:P/I:P/A:P)",
+		"",
+	} {
+		if got, err := Normalize(str); err == nil {
+			t.Errorf("%q: expected an error, got %s", str, got)
+		}
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		// all possible metrics are defined in this string
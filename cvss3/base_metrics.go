@@ -16,6 +16,7 @@ package cvss3
 
 import "fmt"
 
+// BaseMetrics holds the base metrics of a vector, undefined metrics are zero values
 type BaseMetrics struct {
 	AttackVector
 	AttackComplexity
@@ -27,6 +28,7 @@ type BaseMetrics struct {
 	Availability
 }
 
+// AttackVector is the AV base metric
 type AttackVector int
 
 const (
@@ -62,6 +64,7 @@ func (av *AttackVector) parse(str string) error {
 	return fmt.Errorf("illegal attack vector code %s", str)
 }
 
+// AttackComplexity is the AC base metric
 type AttackComplexity int
 
 const (
@@ -95,6 +98,7 @@ func (ac *AttackComplexity) parse(str string) error {
 	return fmt.Errorf("illegal attack complexity code %s", str)
 }
 
+// PrivilegesRequired is the PR base metric
 type PrivilegesRequired int
 
 const (
@@ -132,6 +136,7 @@ func (pr *PrivilegesRequired) parse(str string) error {
 	return fmt.Errorf("illegal privileges required code %s", str)
 }
 
+// UserInteraction is the UI base metric
 type UserInteraction int
 
 const (
@@ -165,6 +170,7 @@ func (ui *UserInteraction) parse(str string) error {
 	return fmt.Errorf("illegal user interaction code %s", str)
 }
 
+// Scope is the S base metric
 type Scope int
 
 const (
@@ -193,6 +199,7 @@ func (s *Scope) parse(str string) error {
 	return fmt.Errorf("illegal scope code %s", str)
 }
 
+// Confidentiality is the C base metric
 type Confidentiality int
 
 const (
@@ -227,6 +234,7 @@ func (c *Confidentiality) parse(str string) error {
 	return fmt.Errorf("illegal confidentiality code %s", str)
 }
 
+// Integrity is the I base metric
 type Integrity int
 
 const (
@@ -261,6 +269,7 @@ func (i *Integrity) parse(str string) error {
 	return fmt.Errorf("illegal integrity code %s", str)
 }
 
+// Availability is the A base metric
 type Availability int
 
 const (
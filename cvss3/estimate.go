@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+import (
+	"math"
+
+	"github.com/facebookincubator/nvdtools/cvss2"
+)
+
+// Penalties of the confidence of the estimate, for the v2 metrics which don't tell the v3 ones for sure
+const (
+	// user interaction and scope have no counterparts in v2 and are always guessed
+	estimatePenaltyBase = 0.2
+	// medium access complexity covers both low complexity with user interaction and high complexity
+	estimatePenaltyMediumComplexity = 0.15
+	// multiple authentication has no v3 counterpart, high privileges are the closest
+	estimatePenaltyMultipleAuthentication = 0.1
+	// partial impact covers both low and, in v3 terms, a good deal of high impact
+	estimatePenaltyPartialImpact = 0.05
+)
+
+// EstimateFromV2 makes a best-effort estimate of the CVSS v3.1 base metrics of the vulnerability which only has
+// the CVSS v2 vector, e.g. an old NVD entry. The returned vector is an estimate, not an assessment: confidence,
+// between 0 and 1, tells how much of it is guesswork, and it's never 1. The mapping is:
+//   - AV: local, adjacent network and network map to local, adjacent and network;
+//   - AC: low maps to low; medium maps to low, with lower confidence, and high maps to high;
+//   - Au: none, single and multiple map to PR none, low and high, the latter with lower confidence;
+//   - UI and S are none and unchanged, as v2 doesn't tell them;
+//   - C, I and A: none, partial and complete map to none, low, with lower confidence, and high.
+//
+// The zero vector and 0 confidence are returned if any of the v2 base metrics is missing.
+// The estimate only depends on the base metrics of v2.
+func EstimateFromV2(v2 cvss2.Vector) (v3 Vector, confidence float64) {
+	if v2.Validate() != nil {
+		return Vector{}, 0
+	}
+	confidence = 1 - estimatePenaltyBase
+	v3.Version = Version31
+
+	switch v2.AccessVector {
+	case cvss2.AccessVectorLocal:
+		v3.AttackVector = AttackVectorLocal
+	case cvss2.AccessVectorAdjecentNetwork:
+		v3.AttackVector = AttackVectorAdjecent
+	default:
+		v3.AttackVector = AttackVectorNetwork
+	}
+
+	switch v2.AccessComplexity {
+	case cvss2.AccessComplexityHigh:
+		v3.AttackComplexity = AttackComplexityHigh
+	case cvss2.AccessComplexityMedium:
+		v3.AttackComplexity = AttackComplexityLow
+		confidence -= estimatePenaltyMediumComplexity
+	default:
+		v3.AttackComplexity = AttackComplexityLow
+	}
+
+	switch v2.Authentication {
+	case cvss2.AuthenticationMultiple:
+		v3.PrivilegesRequired = PrivilegesRequiredHigh
+		confidence -= estimatePenaltyMultipleAuthentication
+	case cvss2.AuthenticationSingle:
+		v3.PrivilegesRequired = PrivilegesRequiredLow
+	default:
+		v3.PrivilegesRequired = PrivilegesRequiredNone
+	}
+
+	v3.UserInteraction = UserInteractionNone
+	v3.Scope = ScopeUnchanged
+
+	switch v2.ConfidentialityImpact {
+	case cvss2.ConfidentialityImpactNone:
+		v3.Confidentiality = ConfidentialityNone
+	case cvss2.ConfidentialityImpactPartial:
+		v3.Confidentiality = ConfidentialityLow
+		confidence -= estimatePenaltyPartialImpact
+	default:
+		v3.Confidentiality = ConfidentialityHigh
+	}
+
+	switch v2.IntegrityImpact {
+	case cvss2.IntegerityImpactNone:
+		v3.Integrity = IntegrityNone
+	case cvss2.IntegrityImpactPartial:
+		v3.Integrity = IntegrityLow
+		confidence -= estimatePenaltyPartialImpact
+	default:
+		v3.Integrity = IntegrityHigh
+	}
+
+	switch v2.AvailabilityImpact {
+	case cvss2.AvailabilityImpactNone:
+		v3.Availability = AvailabilityNone
+	case cvss2.AvailabilityImpactPartial:
+		v3.Availability = AvailabilityLow
+		confidence -= estimatePenaltyPartialImpact
+	default:
+		v3.Availability = AvailabilityHigh
+	}
+
+	// the penalties are in hundredths, don't let the floating point errors show
+	return v3, math.Round(confidence*100) / 100
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss2"
+)
+
+func TestEstimateFromV2(t *testing.T) {
+	cases := []struct {
+		v2         string
+		v3         string
+		confidence float64
+	}{
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0.8},
+		{"AV:N/AC:M/Au:N/C:N/I:P/A:N", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:L/A:N", 0.6},
+		{"AV:L/AC:L/Au:N/C:P/I:P/A:P", "CVSS:3.1/AV:L/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:L", 0.65},
+		{"AV:A/AC:H/Au:S/C:N/I:N/A:C", "CVSS:3.1/AV:A/AC:H/PR:L/UI:N/S:U/C:N/I:N/A:H", 0.8},
+		{"AV:N/AC:M/Au:M/C:P/I:N/A:N", "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:L/I:N/A:N", 0.5},
+		// temporal and environmental metrics don't matter
+		{"(AV:N/AC:L/Au:N/C:C/I:C/A:C/E:F/RL:OF/RC:C)", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0.8},
+	}
+	for _, c := range cases {
+		t.Run(c.v2, func(t *testing.T) {
+			v2, err := cvss2.VectorFromString(c.v2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v3, confidence := EstimateFromV2(v2)
+			if v3.String() != c.v3 {
+				t.Errorf("expected %s, got %s", c.v3, v3)
+			}
+			if confidence != c.confidence {
+				t.Errorf("expected confidence %.2f, got %.2f", c.confidence, confidence)
+			}
+			if err := v3.Validate(); err != nil {
+				t.Errorf("estimated vector is invalid: %v", err)
+			}
+			for i := 0; i < 10; i++ {
+				if again, conf := EstimateFromV2(v2); again != v3 || conf != confidence {
+					t.Fatalf("estimate isn't deterministic: %s (%f), then %s (%f)", v3, confidence, again, conf)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateFromV2Partial(t *testing.T) {
+	v2, err := cvss2.VectorFromString("AV:N/AC:L/Au:N")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v3, confidence := EstimateFromV2(v2); v3 != (Vector{}) || confidence != 0 {
+		t.Errorf("partial vector shouldn't be estimated, got %s (%f)", v3, confidence)
+	}
+}
@@ -24,6 +24,24 @@ func roundUp(x float64) float64 {
 	return math.Ceil(x*10) / 10
 }
 
+// roundUp31 is the Roundup function defined in CVSS v3.1 specification, appendix A
+// it avoids floating point artifacts such as 9.200000000000001 being rounded up to 9.3
+func roundUp31(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// round uses the rounding function of the vector's version
+func (v Vector) round(x float64) float64 {
+	if v.Version == Version31 {
+		return roundUp31(x)
+	}
+	return roundUp(x)
+}
+
 // Validate should be called before calculating any scores on vector
 // If there's an error, there's no guarantee that a call to *Score() won't panic
 func (v Vector) Validate() error {
@@ -58,14 +76,14 @@ func (v Vector) Score() float64 {
 // BaseScore returns base score of the vector
 func (v Vector) BaseScore() float64 {
 	i, e := v.impactScore(), v.exploitabilityScore()
-	if i < 0 {
+	if i <= 0 {
 		return 0
 	}
 	c := 1.0
 	if v.baseScopeChanged() {
 		c = 1.08
 	}
-	return roundUp(math.Min(c*(e+i), 10.0))
+	return v.round(math.Min(c*(e+i), 10.0))
 }
 
 func (v Vector) impactScore() float64 {
@@ -90,7 +108,7 @@ func (v Vector) exploitabilityScore() float64 {
 
 // TemporalScore returns temporal score of the vector
 func (v Vector) TemporalScore() float64 {
-	return roundUp(v.BaseScore() *
+	return v.round(v.BaseScore() *
 		v.TemporalMetrics.ExploitCodeMaturity.weight() *
 		v.TemporalMetrics.RemediationLevel.weight() *
 		v.TemporalMetrics.ReportConfidence.weight())
@@ -99,7 +117,7 @@ func (v Vector) TemporalScore() float64 {
 // EnvironmentalScore returns environmental score of the vector
 func (v Vector) EnvironmentalScore() float64 {
 	i, e := v.modifiedImpactScore(), v.modifiedExploitabilityScore()
-	if i < 0 {
+	if i <= 0 {
 		return 0
 	}
 	c := 1.0
@@ -107,7 +125,7 @@ func (v Vector) EnvironmentalScore() float64 {
 		c = 1.08
 	}
 
-	return roundUp(roundUp(math.Min(c*(e+i), 10.0)) *
+	return v.round(v.round(math.Min(c*(e+i), 10.0)) *
 		v.TemporalMetrics.ExploitCodeMaturity.weight() *
 		v.TemporalMetrics.RemediationLevel.weight() *
 		v.TemporalMetrics.ReportConfidence.weight())
@@ -141,6 +159,10 @@ func (v Vector) modifiedImpactScore() float64 {
 		0.915,
 	)
 	if v.modifiedScopeChanged() {
+		if v.Version == Version31 {
+			// changed in CVSS v3.1
+			return 7.52*(iscModified-0.029) - 3.25*math.Pow((iscModified*0.9731-0.02), 13)
+		}
 		return 7.52*(iscModified-0.029) - 3.25*math.Pow((iscModified-0.02), 15)
 	} else {
 		return 6.42 * iscModified
@@ -127,3 +127,104 @@ func TestScores(t *testing.T) {
 		})
 	}
 }
+
+func TestTemporalEnvironmentalScores(t *testing.T) {
+	// not defined metrics are neutral, modified metrics override their base counterpart
+	cases := []struct {
+		name          string
+		str           string
+		base          float64
+		temporal      float64
+		environmental float64
+	}{
+		// specification examples document, CVE-2013-1937
+		{"base only", "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, 6.1, 6.1},
+		{"no impact", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, 0, 0},
+		{"modified no impact", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/MC:N/MI:N/MA:N", 9.8, 9.8, 0},
+		{"all not defined", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:X/RL:X/RC:X/CR:X/IR:X/AR:X/MAV:X/MAC:X/MPR:X/MUI:X/MS:X/MC:X/MI:X/MA:X", 9.8, 9.8, 9.8},
+		{"modified attack vector", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/MAV:P", 9.8, 9.8, 6.8},
+		// privileges required weight follows modified scope
+		{"modified scope", "CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N/MS:C", 6.5, 6.5, 7.7},
+		{"modified scope and privileges", "CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N/MPR:H/MS:C", 6.5, 6.5, 6.8},
+		{"requirements", "CVSS:3.0/AV:L/AC:H/PR:L/UI:R/S:U/C:L/I:L/A:L/E:F/RL:W/RC:R/CR:H/IR:H/AR:H/MAV:N/MAC:L/MUI:N", 4.2, 3.8, 6.7},
+		{"3.1", "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:L/I:L/A:N/E:P/RL:O/RC:C/MC:H", 6.4, 5.8, 7.6},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := VectorFromString(c.str)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if vbs := v.BaseScore(); vbs != c.base {
+				t.Errorf("base: expected %.1f, got %.1f", c.base, vbs)
+			}
+			if vts := v.TemporalScore(); vts != c.temporal {
+				t.Errorf("temporal: expected %.1f, got %.1f", c.temporal, vts)
+			}
+			if ves := v.EnvironmentalScore(); ves != c.environmental {
+				t.Errorf("environmental: expected %.1f, got %.1f", c.environmental, ves)
+			}
+		})
+	}
+}
+
+func TestRoundUp31(t *testing.T) {
+	cases := map[float64]float64{
+		4.0:               4.0,
+		4.00001:           4.1,
+		4.02:              4.1,
+		9.200000000000001: 9.2, // roundUp gives 9.3
+		9.21:              9.3,
+	}
+
+	for x, expected := range cases {
+		t.Run(fmt.Sprintf("roundUp31(%v)=%.1f", x, expected), func(t *testing.T) {
+			if actual := roundUp31(x); expected != actual {
+				t.Errorf("expected %.1f, actual %.1f", expected, actual)
+			}
+		})
+	}
+}
+
+func TestVersionScores(t *testing.T) {
+	// the same metrics scored against both versions of the specification, only vectors
+	// where the scores differ are listed. Validated on https://www.first.org/cvss/calculator/3.0
+	// and https://www.first.org/cvss/calculator/3.1
+	cases := []struct {
+		metrics       string
+		version       Version
+		base          float64
+		temporal      float64
+		environmental float64
+	}{
+		// roundup of 10.0*0.92 = 9.200000000000001
+		{"AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H/E:H/RL:U/RC:U", Version30, 10.0, 9.3, 9.3},
+		{"AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H/E:H/RL:U/RC:U", Version31, 10.0, 9.2, 9.2},
+		// modified impact formula when modified scope is changed
+		{"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N/IR:L/MS:C", Version30, 9.1, 9.1, 9.6},
+		{"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N/IR:L/MS:C", Version31, 9.1, 9.1, 9.5},
+	}
+
+	for _, c := range cases {
+		str := prefix + c.version.String() + partSeparator + c.metrics
+		t.Run(str, func(t *testing.T) {
+			v, err := VectorFromString(str)
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if v.Version != c.version {
+				t.Fatalf("expected version %s, got %s", c.version, v.Version)
+			}
+			if vbs := v.BaseScore(); vbs != c.base {
+				t.Errorf("base: expected %.1f, got %.1f", c.base, vbs)
+			}
+			if vts := v.TemporalScore(); vts != c.temporal {
+				t.Errorf("temporal: expected %.1f, got %.1f", c.temporal, vts)
+			}
+			if ves := v.EnvironmentalScore(); ves != c.environmental {
+				t.Errorf("environmental: expected %.1f, got %.1f", c.environmental, ves)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+// Severity returns the qualitative severity rating of a CVSS v3 score, as defined by the specification:
+// NONE (0.0), LOW (0.1-3.9), MEDIUM (4.0-6.9), HIGH (7.0-8.9) or CRITICAL (9.0-10.0)
+// An empty string is returned for scores out of range
+func Severity(score float64) string {
+	switch {
+	case score < 0.0 || score > 10.0:
+		return ""
+	case score == 0.0:
+		return "NONE"
+	case score < 4.0:
+		return "LOW"
+	case score < 7.0:
+		return "MEDIUM"
+	case score < 9.0:
+		return "HIGH"
+	default:
+		return "CRITICAL"
+	}
+}
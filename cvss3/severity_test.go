@@ -0,0 +1,47 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSeverity(t *testing.T) {
+	cases := []struct {
+		score    float64
+		severity string
+	}{
+		{-0.1, ""},
+		{0.0, "NONE"},
+		{0.1, "LOW"},
+		{3.9, "LOW"},
+		{4.0, "MEDIUM"},
+		{6.9, "MEDIUM"},
+		{7.0, "HIGH"},
+		{8.9, "HIGH"},
+		{9.0, "CRITICAL"},
+		{10.0, "CRITICAL"},
+		{10.1, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%.1f", c.score), func(t *testing.T) {
+			if severity := Severity(c.score); severity != c.severity {
+				t.Errorf("expected %q, got %q", c.severity, severity)
+			}
+		})
+	}
+}
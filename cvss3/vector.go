@@ -20,13 +20,40 @@ import (
 )
 
 const (
-	prefix          = "CVSS:3.0/"
+	prefix          = "CVSS:"
 	partSeparator   = "/"
 	metricSeparator = ":"
 )
 
+// Version is the CVSS v3 specification version used to compute scores
+type Version int
+
+// Supported versions, the zero value is 3.0
+const (
+	Version30 Version = iota // CVSS v3.0
+	Version31                // CVSS v3.1
+)
+
+var codeVersion = []string{"3.0", "3.1"}
+
+// String returns the version as it appears in the vector prefix
+func (ver Version) String() string {
+	return codeVersion[ver]
+}
+
+func (ver *Version) parse(str string) error {
+	idx, found := findIndex(str, codeVersion)
+	if found {
+		*ver = Version(idx)
+		return nil
+	}
+	return fmt.Errorf("unsupported CVSS version %s", str)
+}
+
 // Vector represents a CVSSv3 vector, holds all metrics inside (base, temporal and environmental)
 type Vector struct {
+	// Version is detected from the vector prefix, vectors without it are 3.0
+	Version Version
 	BaseMetrics
 	TemporalMetrics
 	EnvironmentalMetrics
@@ -36,7 +63,7 @@ type Vector struct {
 // it shouldn't depend on the order of metrics
 func (v Vector) String() string {
 	var sb strings.Builder
-	fmt.Fprint(&sb, prefix)
+	fmt.Fprint(&sb, prefix, v.Version, partSeparator)
 
 	defineables := v.definables()
 
@@ -59,10 +86,21 @@ func (v Vector) String() string {
 
 // VectorFromString will parse a string into a Vector, or return an error if it can't be parsed
 func VectorFromString(str string) (Vector, error) {
+	var v Vector
+
 	// remove prefix if exists
-	str = strings.TrimPrefix(strings.ToUpper(str), prefix)
+	str = strings.ToUpper(str)
+	if strings.HasPrefix(str, prefix) {
+		tmp := strings.SplitN(strings.TrimPrefix(str, prefix), partSeparator, 2)
+		if err := v.Version.parse(tmp[0]); err != nil {
+			return v, err
+		}
+		if len(tmp) != 2 {
+			return v, fmt.Errorf("no metrics after prefix %s%s", prefix, tmp[0])
+		}
+		str = tmp[1]
+	}
 
-	var v Vector
 	parseables := v.parseables()
 
 	for _, part := range strings.Split(str, partSeparator) {
@@ -82,6 +120,20 @@ func VectorFromString(str string) (Vector, error) {
 	return v, nil
 }
 
+// Normalize parses the vector and returns it with the metrics in the order of the specification, without the ones
+// which aren't defined (X), and with the version prefix, 3.0 if it has none: equivalent vectors normalize to the same
+// string. It returns an error if the vector can't be parsed or any of the base metrics is missing
+func Normalize(str string) (string, error) {
+	v, err := VectorFromString(str)
+	if err != nil {
+		return "", err
+	}
+	if err = v.Validate(); err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
 // Absorb will override only metrics in the current vector from the one given which are defined
 // If the other vector specifies only a single metric with all others undefined, the resulting
 // vector will contain all metrics it previously did, with only the new one overriden
@@ -46,6 +46,8 @@ func TestFromString(t *testing.T) {
 		"CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:U/C:N/I:L/A:N/E:U/RL:T/RC:C",
 		"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:L/I:N/A:N/E:U/RL:O/RC:C",
 		"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H/RL:T/RC:C",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N/IR:L/MS:C",
 	}
 
 	for i, str := range cases {
@@ -60,6 +62,85 @@ func TestFromString(t *testing.T) {
 	}
 }
 
+func TestFromStringVersion(t *testing.T) {
+	cases := []struct {
+		str     string
+		version Version
+		fail    bool
+	}{
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Version30, false},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Version31, false},
+		{"cvss:3.1/av:n/ac:l/pr:n/ui:n/s:u/c:h/i:h/a:h", Version31, false},
+		{"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Version30, false},
+		{"CVSS:3.2/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0, true},
+		{"CVSS:2.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0, true},
+		{"CVSS:3.1", 0, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.str, func(t *testing.T) {
+			v, err := VectorFromString(c.str)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got vector %s", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to parse vector: %v", err)
+			}
+			if v.Version != c.version {
+				t.Errorf("expected version %s, got %s", c.version, v.Version)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		vectors []string
+		expect  string
+	}{
+		{
+			[]string{
+				"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+				"CVSS:3.1/C:H/I:H/A:H/AV:N/AC:L/PR:N/UI:N/S:U",
+				"cvss:3.1/s:u/ui:n/pr:n/ac:l/av:n/a:h/i:h/c:h",
+				"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:X/RL:X/RC:X/MAV:X",
+			},
+			"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		},
+		{
+			[]string{
+				"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H/E:H/RL:O/RC:C/CR:H/MAV:N",
+				"CVSS:3.0/MAV:N/RC:C/CR:H/E:H/RL:O/A:H/I:H/C:H/S:U/UI:N/PR:L/AC:L/AV:L/IR:X",
+				"AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H/MAV:N/CR:H/E:H/RL:O/RC:C",
+			},
+			"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H/E:H/RL:O/RC:C/CR:H/MAV:N",
+		},
+	}
+	for _, c := range cases {
+		for _, str := range c.vectors {
+			if got, err := Normalize(str); err != nil {
+				t.Errorf("%s: unable to normalize vector: %v", str, err)
+			} else if got != c.expect {
+				t.Errorf("%s: got %s, expected %s", str, got, c.expect)
+			}
+		}
+	}
+
+	for _, str := range []string{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:Q",
+		"CVSS:3.2/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"",
+	} {
+		if got, err := Normalize(str); err == nil {
+			t.Errorf("%q: expected an error, got %s", str, got)
+		}
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		// all possible metrics are defined in this string
@@ -77,3 +158,38 @@ func TestAbsorb(t *testing.T) {
 		t.Errorf("when absorbing only defined values from another vector, it shouldn't override undefined ones")
 	}
 }
+
+func TestBaseMetrics(t *testing.T) {
+	str := "CVSS:3.1/AV:N/AC:H/PR:N/UI:R/S:C/C:L/I:N/A:H"
+	v, err := VectorFromString(str)
+	if err != nil {
+		t.Fatalf("unable to parse vector: %v", err)
+	}
+	expect := BaseMetrics{
+		AttackVector:       AttackVectorNetwork,
+		AttackComplexity:   AttackComplexityHigh,
+		PrivilegesRequired: PrivilegesRequiredNone,
+		UserInteraction:    UserInteractionRequired,
+		Scope:              ScopeChanged,
+		Confidentiality:    ConfidentialityLow,
+		Integrity:          IntegrityNone,
+		Availability:       AvailabilityHigh,
+	}
+	if v.BaseMetrics != expect {
+		t.Errorf("wrong base metrics parsed from %s: got %+v, expected %+v", str, v.BaseMetrics, expect)
+	}
+	codes := []string{
+		v.AttackVector.String(), v.AttackComplexity.String(), v.PrivilegesRequired.String(), v.UserInteraction.String(),
+		v.Scope.String(), v.Confidentiality.String(), v.Integrity.String(), v.Availability.String(),
+	}
+	if got := fmt.Sprint(codes); got != "[N H N R C L N H]" {
+		t.Errorf("wrong metric codes: %s", got)
+	}
+	if v.String() != str {
+		t.Errorf("vector.String() should be the same thing it was parsed from.\nGot:\t%s\nExpect:\t%s", v, str)
+	}
+	// vectors built from the typed metrics serialize canonically
+	if got := (Vector{Version: Version31, BaseMetrics: expect}).String(); got != str {
+		t.Errorf("vector built from metrics: got %s, expected %s", got, str)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging defines the interface the nvdtools libraries log through.
+//
+// The libraries don't log anywhere by default: the Logger is injected where the messages are reported,
+// e.g. into cvefeed.DictionaryOptions, cvefeed.Cache or nvd.SourceConfig. The command line tools use Flog,
+// which keeps their output as it's configured with flog flags (-v, -logtostderr and so on); the provider
+// converters, which always logged with the standard log package, use Std.
+package logging
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/facebookincubator/flog"
+)
+
+// Logger receives the messages of the libraries, formatted as by fmt.Printf, at four levels of severity
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Nop discards all the messages
+var Nop Logger = nop{}
+
+// Flog logs the messages with flog: debug messages at verbosity level 1, the others at their severity
+var Flog Logger = flogLogger{}
+
+// Std logs all the messages but the debug ones with the standard log package
+var Std Logger = stdLogger{}
+
+// OrNop returns l, or Nop if l is nil
+func OrNop(l Logger) Logger {
+	if l == nil {
+		return Nop
+	}
+	return l
+}
+
+type nop struct{}
+
+func (nop) Debugf(string, ...interface{}) {}
+func (nop) Infof(string, ...interface{})  {}
+func (nop) Warnf(string, ...interface{})  {}
+func (nop) Errorf(string, ...interface{}) {}
+
+type flogLogger struct{}
+
+func (flogLogger) Debugf(format string, args ...interface{}) { flog.V(1).Infof(format, args...) }
+func (flogLogger) Infof(format string, args ...interface{})  { flog.Infof(format, args...) }
+func (flogLogger) Warnf(format string, args ...interface{})  { flog.Warningf(format, args...) }
+func (flogLogger) Errorf(format string, args ...interface{}) { flog.Errorf(format, args...) }
+
+type stdLogger struct{}
+
+// the call depth of 2 makes log.Lshortfile report the caller of the Logger
+func (stdLogger) Debugf(string, ...interface{}) {}
+func (stdLogger) Infof(format string, args ...interface{}) {
+	log.Output(2, fmt.Sprintf(format, args...))
+}
+func (stdLogger) Warnf(format string, args ...interface{}) {
+	log.Output(2, fmt.Sprintf(format, args...))
+}
+func (stdLogger) Errorf(format string, args ...interface{}) {
+	log.Output(2, fmt.Sprintf(format, args...))
+}
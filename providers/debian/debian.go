@@ -0,0 +1,208 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debian provides a converter for Debian Security Tracker data to nvd.
+//
+// The data is the JSON served at https://security-tracker.debian.org/tracker/data/json: the issues (CVEs and
+// Debian's TEMP- placeholders) of every source package, with their status and fixed version in every release.
+// NVD version ranges don't account for the fixes Debian backports, so matching the installed packages
+// against the converted feed, with Debian version comparison, reports only the issues which are still open
+// in their release.
+package debian
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+
+	"github.com/pkg/errors"
+)
+
+// trackerData maps source package names to their issues by ID
+type trackerData map[string]map[string]*issue
+
+type issue struct {
+	Description string              `json:"description"`
+	DebianBug   int                 `json:"debianbug"`
+	Releases    map[string]*release `json:"releases"`
+}
+
+type release struct {
+	Status       string `json:"status"`
+	FixedVersion string `json:"fixed_version"`
+}
+
+// notAffected is the fixed version of the releases whose packages were never vulnerable
+const notAffected = "0"
+
+// Convert converts Debian Security Tracker data read from r to NVD CVE JSON 1.0 format.
+//
+// There's an item per issue, with a cpe_match entry per source package and release it affects: releases where
+// the issue is resolved are vulnerable before the fixed version, the ones where it's open (or undetermined)
+// are vulnerable in any version. The CPE names are made with PackageCPE. Issues which don't affect any release
+// are left out; items are sorted by ID. The bounds are Debian package versions: dictionaries loaded from
+// the feed should compare them with CompareVersions, see SetVersionComparators.
+func Convert(r io.Reader) (*schema.NVDCVEFeedJSON10, error) {
+	var data trackerData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, errors.Wrap(err, "cannot decode Debian Security Tracker data")
+	}
+
+	items := make(map[string]*schema.NVDCVEFeedJSON10DefCVEItem)
+	pkgs := make([]string, 0, len(data))
+	for pkg := range data {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		for id, iss := range data[pkg] {
+			if iss == nil {
+				continue
+			}
+			matches, err := iss.cpeMatches(pkg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot convert %s of %s", id, pkg)
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			item, ok := items[id]
+			if !ok {
+				item = newItem(id, iss)
+				items[id] = item
+			}
+			node := item.Configurations.Nodes[0]
+			node.CPEMatch = append(node.CPEMatch, matches...)
+		}
+	}
+
+	feed := &schema.NVDCVEFeedJSON10{CVEItems: make([]*schema.NVDCVEFeedJSON10DefCVEItem, 0, len(items))}
+	for _, item := range items {
+		feed.CVEItems = append(feed.CVEItems, item)
+	}
+	sort.Slice(feed.CVEItems, func(i, j int) bool {
+		return feed.CVEItems[i].CVE.CVEDataMeta.ID < feed.CVEItems[j].CVE.CVEDataMeta.ID
+	})
+	return feed, nil
+}
+
+// PackageCPE returns the CPE name of the source package in Debian release, as used by Convert.
+// Vendor and product are the ones of deb Package URL (see purl.ToCPE), the release codename (e.g. bullseye)
+// is the other attribute. Unlike purl.ToCPE, the version is kept as it is, along with the epoch and the
+// Debian revision, as the backported fixes are only seen in the revision; CPE names of the installed packages
+// should be made with the full version too.
+// Empty release or version is ANY.
+func PackageCPE(pkg, release, version string) (*wfn.Attributes, error) {
+	if pkg == "" {
+		return nil, errors.New("package has no name")
+	}
+	attrs, err := purl.ToCPE("pkg:deb/debian/" + url.PathEscape(pkg))
+	if err != nil {
+		return nil, err
+	}
+	if attrs.Version, err = wfn.WFNize(version); err != nil {
+		return nil, errors.Wrapf(err, "cannot wfn-ize version: %q", version)
+	}
+	if attrs.Other, err = wfn.WFNize(release); err != nil {
+		return nil, errors.Wrapf(err, "cannot wfn-ize release: %q", release)
+	}
+	return attrs, nil
+}
+
+func newItem(id string, iss *issue) *schema.NVDCVEFeedJSON10DefCVEItem {
+	refs := []*schema.CVEJSON40Reference{
+		{
+			Name: id,
+			URL:  "https://security-tracker.debian.org/tracker/" + url.PathEscape(id),
+		},
+	}
+	if iss.DebianBug != 0 {
+		bug := fmt.Sprintf("https://bugs.debian.org/%d", iss.DebianBug)
+		refs = append(refs, &schema.CVEJSON40Reference{Name: bug, URL: bug})
+	}
+	return &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{
+				ID:       id,
+				ASSIGNER: "Debian",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &schema.CVEJSON40Description{
+				DescriptionData: []*schema.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: iss.Description,
+					},
+				},
+			},
+			References: &schema.CVEJSON40References{ReferenceData: refs},
+		},
+		Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+			Nodes:          []*schema.NVDCVEFeedJSON10DefNode{{Operator: "OR"}},
+		},
+	}
+}
+
+// cpeMatches returns the cpe_match entries of the releases of the source package affected by the issue
+func (iss *issue) cpeMatches(pkg string) ([]*schema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	names := make([]string, 0, len(iss.Releases))
+	for name := range iss.Releases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var matches []*schema.NVDCVEFeedJSON10DefCPEMatch
+	for _, name := range names {
+		rel := iss.Releases[name]
+		if rel == nil {
+			continue
+		}
+		var fixed string
+		switch rel.Status {
+		case "resolved":
+			if rel.FixedVersion == "" || rel.FixedVersion == notAffected {
+				continue
+			}
+			fixed = rel.FixedVersion
+		case "open", "undetermined":
+		default:
+			return nil, errors.Errorf("unknown status %q in %s", rel.Status, name)
+		}
+		cpe, err := PackageCPE(pkg, name, "")
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &schema.NVDCVEFeedJSON10DefCPEMatch{
+			CPEName: []*schema.NVDCVEFeedJSON10DefCPEName{
+				{
+					Cpe22Uri: cpe.BindToURI(),
+					Cpe23Uri: cpe.BindToFmtString(),
+				},
+			},
+			Cpe23Uri:            cpe.BindToFmtString(),
+			VersionEndExcluding: fixed,
+			Vulnerable:          true,
+		})
+	}
+	return matches, nil
+}
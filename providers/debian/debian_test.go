@@ -0,0 +1,161 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testTrackerData = `{
+  "openssl": {
+    "CVE-2021-3711": {
+      "description": "SM2 decryption buffer overflow",
+      "scope": "remote",
+      "debianbug": 992686,
+      "releases": {
+        "buster": {"status": "resolved", "repositories": {"buster": "1.1.1d-0+deb10u7"}, "fixed_version": "1.1.1d-0+deb10u7", "urgency": "not yet assigned"},
+        "bullseye": {"status": "resolved", "repositories": {"bullseye": "1.1.1k-1+deb11u1"}, "fixed_version": "1.1.1k-1+deb11u1", "urgency": "not yet assigned"},
+        "stretch": {"status": "resolved", "repositories": {"stretch": "1.1.0l-1~deb9u1"}, "fixed_version": "0", "urgency": "not yet assigned"}
+      }
+    },
+    "TEMP-0000000-F7A20F": {
+      "description": "not affecting any release",
+      "releases": {
+        "bullseye": {"status": "resolved", "repositories": {"bullseye": "1.1.1k-1"}, "fixed_version": "0", "urgency": "unimportant"}
+      }
+    }
+  },
+  "curl": {
+    "CVE-2021-22945": {
+      "description": "UAF and double-free in MQTT sending",
+      "scope": "remote",
+      "releases": {
+        "bookworm": {"status": "resolved", "repositories": {"bookworm": "1:7.79.1-1"}, "fixed_version": "1:7.79.1-1", "urgency": "not yet assigned"},
+        "bullseye": {"status": "open", "repositories": {"bullseye": "7.74.0-1.3"}, "urgency": "low"}
+      }
+    }
+  }
+}`
+
+func TestConvert(t *testing.T) {
+	feed, err := Convert(strings.NewReader(testTrackerData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.CVEItems) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.CVEItems))
+	}
+	dict := cvefeed.Dictionary{}
+	for _, item := range feed.CVEItems {
+		dict[item.CVE.CVEDataMeta.ID] = nvd.ToVuln(item)
+	}
+	SetVersionComparators(dict)
+	cases := []struct {
+		cve     string
+		refs    int
+		match   [][3]string // package, release, version
+		noMatch [][3]string
+	}{
+		{
+			cve:  "CVE-2021-22945",
+			refs: 1,
+			match: [][3]string{
+				{"curl", "bookworm", "7.74.0-1"},
+				{"curl", "bookworm", "1:7.79.0-1"},
+				// open in bullseye
+				{"curl", "bullseye", "7.74.0-1.3"},
+				{"curl", "bullseye", "7.99.0-1"},
+			},
+			noMatch: [][3]string{
+				{"curl", "bookworm", "1:7.79.1-1"},
+				{"curl", "buster", "7.64.0-4"},
+				{"openssl", "bullseye", "7.74.0-1.3"},
+			},
+		},
+		{
+			cve:  "CVE-2021-3711",
+			refs: 2,
+			match: [][3]string{
+				{"openssl", "bullseye", "1.1.1k-1"},
+				{"openssl", "buster", "1.1.1d-0+deb10u6"},
+			},
+			noMatch: [][3]string{
+				// backported fixes
+				{"openssl", "bullseye", "1.1.1k-1+deb11u1"},
+				{"openssl", "buster", "1.1.1d-0+deb10u7"},
+				// not affected
+				{"openssl", "stretch", "1.1.0l-1~deb9u1"},
+			},
+		},
+	}
+	for i, c := range cases {
+		item := feed.CVEItems[i]
+		if id := item.CVE.CVEDataMeta.ID; id != c.cve {
+			t.Fatalf("item %d: expected %s, got %s", i, c.cve, id)
+		}
+		if n := len(item.CVE.References.ReferenceData); n != c.refs {
+			t.Errorf("%s: expected %d references, got %d", c.cve, c.refs, n)
+		}
+		vuln := dict[c.cve].(*nvd.Vuln)
+		for _, m := range c.match {
+			if !matches(t, vuln, m) {
+				t.Errorf("%s: %v should match", c.cve, m)
+			}
+		}
+		for _, m := range c.noMatch {
+			if matches(t, vuln, m) {
+				t.Errorf("%s: %v shouldn't match", c.cve, m)
+			}
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	cases := []string{
+		`[]`,
+		`{"openssl": {"CVE-2021-3711": {"releases": {"bullseye": {"status": "fixed"}}}}}`,
+	}
+	for _, c := range cases {
+		if _, err := Convert(strings.NewReader(c)); err == nil {
+			t.Errorf("%s: expected an error", c)
+		}
+	}
+}
+
+func TestPackageCPE(t *testing.T) {
+	attrs, err := PackageCPE("openssh", "bullseye", "1:8.4p1-5+deb11u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := attrs.BindToFmtString(), `cpe:2.3:a:openssh:openssh:1\:8.4p1-5\+deb11u1:*:*:*:*:*:*:bullseye`; got != expect {
+		t.Errorf("got %s, expected %s", got, expect)
+	}
+	if _, err := PackageCPE("", "bullseye", "1.0"); err == nil {
+		t.Error("expected an error for the package with no name")
+	}
+}
+
+func matches(t *testing.T, vuln *nvd.Vuln, m [3]string) bool {
+	attrs, err := PackageCPE(m[0], m[1], m[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(vuln.Match([]*wfn.Attributes{attrs}, false)) != 0
+}
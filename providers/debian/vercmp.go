@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+// CompareVersions compares two Debian package versions, [epoch:]upstream_version[-debian_revision],
+// the way dpkg does. It returns 0 if a and b are equal, 1 if a is newer than b and -1 if b is newer than a.
+//
+// Epochs are compared as integers, an absent epoch is 0; then upstream versions and Debian revisions
+// are compared segment by segment: non-digit segments are compared character by character with letters
+// sorting before non-letters, and tilde (~) before anything, even the end of the segment, so 1.0~rc1 is
+// older than 1.0; digit segments are compared as integers.
+func CompareVersions(a, b string) int {
+	ea, ua, ra := parseVersion(a)
+	eb, ub, rb := parseVersion(b)
+	switch {
+	case ea > eb:
+		return 1
+	case ea < eb:
+		return -1
+	}
+	if c := verrevcmp(ua, ub); c != 0 {
+		return c
+	}
+	return verrevcmp(ra, rb)
+}
+
+// SetVersionComparators makes all entries of dict compare the versions of their packages with CompareVersions,
+// as the version ranges of a feed converted from Debian Security Tracker data are ones of Debian package versions.
+// It only sets the comparators for the vendors and products of every entry's own configuration, so it should
+// be called on the dictionary of such a feed, before it is used for matching.
+// The versions of the matched CPE names can be quoted, e.g. 1\:2.3-4: the quoting is ignored.
+func SetVersionComparators(dict cvefeed.Dictionary) {
+	for id, v := range dict {
+		entry := cvefeed.Dictionary{id: v}
+		seen := make(map[string]bool)
+		for _, attrs := range v.Config() {
+			key := attrs.Vendor + ":" + attrs.Product
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			entry.SetVersionComparator(attrs.Vendor, attrs.Product, compareQuoted)
+		}
+	}
+}
+
+// compareQuoted compares a and b with CompareVersions, ignoring their WFN quoting;
+// Debian versions can't have backslashes otherwise
+func compareQuoted(a, b string) int {
+	return CompareVersions(strings.Replace(a, `\`, "", -1), strings.Replace(b, `\`, "", -1))
+}
+
+// parseVersion splits [epoch:]upstream_version[-debian_revision] into its components
+func parseVersion(v string) (epoch int, upstream, revision string) {
+	if i := strings.IndexByte(v, ':'); i != -1 {
+		if e, err := strconv.Atoi(v[:i]); err == nil && e >= 0 {
+			epoch, v = e, v[i+1:]
+		}
+	}
+	if i := strings.LastIndexByte(v, '-'); i != -1 {
+		return epoch, v[:i], v[i+1:]
+	}
+	return epoch, v, ""
+}
+
+// verrevcmp compares upstream versions or Debian revisions as dpkg's function of the same name
+func verrevcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isDigit(a[i]) || j < len(b) && !isDigit(b[j]) {
+			ac, bc := order(a, i), order(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// order returns the weight of s[i] in non-digit segments: the end of the string and digits are 0,
+// tilde sorts before them, letters after them and other characters after letters
+func order(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	switch c := s[i]; {
+	case isDigit(c):
+		return 0
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/download"
 	"github.com/facebookincubator/nvdtools/stats"
@@ -37,6 +38,9 @@ import (
 
 const (
 	acceptVersion = "2.6"
+
+	// DefaultPageSize is the number of records requested at once by default
+	DefaultPageSize = 1000
 )
 
 // Client struct
@@ -45,6 +49,8 @@ type Client struct {
 	publicKey string
 	baseURL   string
 	userAgent string
+	pageSize  int
+	logger    logging.Logger
 	m         sync.Mutex
 }
 
@@ -58,9 +64,23 @@ func NewClient(baseURL, userAgent, publicKey, privateKey string) (*Client, error
 		publicKey: publicKey,
 		baseURL:   baseURL,
 		userAgent: userAgent,
+		pageSize:  DefaultPageSize,
+		logger:    logging.Nop,
 	}, nil
 }
 
+// SetPageSize sets the number of records requested at once; 0 disables pagination
+func (c *Client) SetPageSize(pageSize int) *Client {
+	c.pageSize = pageSize
+	return c
+}
+
+// SetLogger sets the logger the sync progress and the failed requests are reported to; nothing is logged by default
+func (c *Client) SetLogger(logger logging.Logger) *Client {
+	c.logger = logging.OrNop(logger)
+	return c
+}
+
 // Request will fetch the given endpoint and return the response
 func (c *Client) Request(endpoint string) (io.Reader, error) {
 	req, err := http.NewRequest("GET", c.baseURL+endpoint, nil)
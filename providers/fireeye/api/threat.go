@@ -17,7 +17,6 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
@@ -41,13 +40,13 @@ func (c *Client) FetchAllThreatReportsSince(since int64) (<-chan *schema.Report,
 		params := params
 		go func() {
 			defer wgReportIDs.Done()
-			log.Printf("Fetching: %s\n", params)
+			c.logger.Infof("Fetching: %s", params)
 			if rIDs, err := c.fetchReportIDs(params); err == nil {
 				for _, rID := range rIDs {
 					reportIDs <- rID
 				}
 			} else {
-				log.Println(err)
+				c.logger.Errorf("%v", err)
 			}
 		}()
 	}
@@ -72,7 +71,7 @@ func (c *Client) FetchAllThreatReportsSince(since int64) (<-chan *schema.Report,
 				reports <- report
 			} else {
 				stats.IncrementCounter("report.error")
-				log.Println(err)
+				c.logger.Errorf("%v", err)
 			}
 		}()
 	}
@@ -17,7 +17,6 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
@@ -25,46 +24,81 @@ import (
 	"github.com/facebookincubator/nvdtools/stats"
 )
 
-// FetchAllVulnerabilities will fetch all vulnerabilities with specified parameters
+// FetchAllVulnerabilities will fetch all vulnerabilities with specified parameters.
+// All of them are fetched before returning, so that a failed request fails the whole fetch
+// instead of passing on the vulnerabilities fetched until then as if they were all.
 func (c *Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible, error) {
 	parameters := newParametersSince(since)
 	if err := parameters.validate(); err != nil {
 		return nil, err
 	}
 
-	output := make(chan runner.Convertible)
+	batches := parameters.batchBy(ninetyDays)
+	vulns := make([][]*schema.Vulnerability, len(batches))
+	errs := make([]error, len(batches))
 	wg := sync.WaitGroup{}
 
-	for _, params := range parameters.batchBy(ninetyDays) {
-		params := params
+	for i, params := range batches {
+		i, params := i, params
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			log.Printf("Fetching: %s\n", params)
+			c.logger.Infof("Fetching: %s", params)
 			vs, err := c.fetchVulnerabilities(params)
 			if err != nil {
-				log.Printf("error while fetching %s: %v", params, err)
+				errs[i] = fmt.Errorf("error while fetching %s: %v", params, err)
 				return
 			}
-			numVulns := len(vs)
-			log.Printf("Adding %d vulns\n", numVulns)
-			stats.IncrementCounterBy("vulnerabilities", int64(numVulns))
-			for _, v := range vs {
-				output <- v
-			}
+			c.logger.Infof("Adding %d vulns", len(vs))
+			vulns[i] = vs
 		}()
 	}
+	wg.Wait()
+
+	numVulns := 0
+	for i := range batches {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		numVulns += len(vulns[i])
+	}
+	stats.IncrementCounterBy("vulnerabilities", int64(numVulns))
 
-	go func() {
-		defer close(output)
-		wg.Wait()
-	}()
+	output := make(chan runner.Convertible, numVulns)
+	for _, vs := range vulns {
+		for _, v := range vs {
+			output <- v
+		}
+	}
+	close(output)
 
 	return output, nil
 }
 
+// fetchVulnerabilities fetches all pages of vulnerabilities in the time range.
+// If fetching a page fails, vulnerabilities from the previous pages are returned along with the error.
 func (c *Client) fetchVulnerabilities(parameters timeRangeParameters) ([]*schema.Vulnerability, error) {
-	resp, err := c.Request(fmt.Sprintf("/view/vulnerability?%s", parameters.query()))
+	var vulnerabilities []*schema.Vulnerability
+	for offset := 0; ; {
+		query := parameters.query()
+		if c.pageSize > 0 {
+			query = fmt.Sprintf("%s&limit=%d&offset=%d", query, c.pageSize, offset)
+		}
+		page, err := c.fetchVulnerabilitiesPage(query)
+		if err != nil {
+			return vulnerabilities, fmt.Errorf("offset %d: %v", offset, err)
+		}
+		vulnerabilities = append(vulnerabilities, page...)
+		// only a page shorter than requested is the last one: a full page may be followed by an empty one
+		if c.pageSize <= 0 || len(page) < c.pageSize {
+			return vulnerabilities, nil
+		}
+		offset += len(page)
+	}
+}
+
+func (c *Client) fetchVulnerabilitiesPage(query string) ([]*schema.Vulnerability, error) {
+	resp, err := c.Request(fmt.Sprintf("/view/vulnerability?%s", query))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,127 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+)
+
+// vulnerabilityServer serves total vulnerabilities with limit and offset, failing requests at failAt offset
+func vulnerabilityServer(t *testing.T, total, failAt int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path != "/view/vulnerability" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if offset == failAt {
+			json.NewEncoder(w).Encode(schema.Result{
+				Success: false,
+				Message: schema.ResultErrorMessage{Error: "boom", Description: "page failed"},
+			})
+			return
+		}
+		vulns := []*schema.Vulnerability{}
+		for i := offset; i < total && (limit == 0 || i < offset+limit); i++ {
+			vulns = append(vulns, &schema.Vulnerability{ReportID: fmt.Sprintf("report-%d", i)})
+		}
+		json.NewEncoder(w).Encode(schema.Result{Success: true, Message: vulns})
+	}))
+}
+
+func TestFetchVulnerabilitiesPages(t *testing.T) {
+	cases := []struct {
+		total, pageSize int
+		requests        int32
+	}{
+		{total: 3, pageSize: 2, requests: 2},
+		{total: 4, pageSize: 2, requests: 3}, // exactly full pages
+		{total: 0, pageSize: 2, requests: 1},
+		{total: 5, pageSize: 0, requests: 1}, // pagination disabled
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%d/%d", c.total, c.pageSize), func(t *testing.T) {
+			var requests int32
+			srv := vulnerabilityServer(t, c.total, -1, &requests)
+			defer srv.Close()
+			client, err := NewClient(srv.URL, "test", "public", "private")
+			if err != nil {
+				t.Fatal(err)
+			}
+			vulns, err := client.SetPageSize(c.pageSize).FetchAllVulnerabilities(time.Now().Add(-time.Hour).Unix())
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen := map[string]bool{}
+			for v := range vulns {
+				seen[v.ID()] = true
+			}
+			if len(seen) != c.total {
+				t.Errorf("expected %d vulnerabilities, got %d", c.total, len(seen))
+			}
+			if requests := atomic.LoadInt32(&requests); requests != c.requests {
+				t.Errorf("expected %d requests, got %d", c.requests, requests)
+			}
+		})
+	}
+}
+
+func TestFetchVulnerabilitiesPageError(t *testing.T) {
+	var requests int32
+	srv := vulnerabilityServer(t, 5, 2, &requests)
+	defer srv.Close()
+	client, err := NewClient(srv.URL, "test", "public", "private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := newParametersSince(time.Now().Add(-time.Hour).Unix())
+	vulns, err := client.SetPageSize(2).fetchVulnerabilities(params)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(vulns) != 2 {
+		t.Fatalf("expected vulnerabilities of the first page, got %d", len(vulns))
+	}
+}
+
+func TestFetchAllVulnerabilitiesPageError(t *testing.T) {
+	var requests int32
+	srv := vulnerabilityServer(t, 5, 2, &requests)
+	defer srv.Close()
+	client, err := NewClient(srv.URL, "test", "public", "private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vulns, err := client.SetPageSize(2).FetchAllVulnerabilities(time.Now().Add(-time.Hour).Unix())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if vulns != nil {
+		t.Fatal("vulnerabilities of the first page shouldn't be returned")
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
 const (
@@ -25,6 +26,11 @@ const (
 )
 
 func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return item.ConvertWithLogger(logging.Nop)
+}
+
+// ConvertWithLogger is like Convert, but it reports the scores it can't parse to log
+func (item *Vulnerability) ConvertWithLogger(log logging.Logger) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 	nvdItem := nvd.NVDCVEFeedJSON10DefCVEItem{
 		CVE: &nvd.CVEJSON40{
 			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
@@ -45,8 +51,8 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 		Impact: &nvd.NVDCVEFeedJSON10DefImpact{
 			BaseMetricV2: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{
 				CVSSV2: &nvd.CVSSV20{
-					BaseScore:     extractCVSSBaseScore(item),
-					TemporalScore: extractCVSSTemporalScore(item),
+					BaseScore:     extractCVSSBaseScore(item, log),
+					TemporalScore: extractCVSSTemporalScore(item, log),
 					VectorString:  extractCVSSVectorString(item),
 				},
 			},
@@ -15,20 +15,20 @@
 package schema
 
 import (
-	"log"
 	"strconv"
 	"strings"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
-func extractCVSSBaseScore(item *Vulnerability) float64 {
-	return strToFloat(item.CvssBaseScore)
+func extractCVSSBaseScore(item *Vulnerability, log logging.Logger) float64 {
+	return strToFloat(item.CvssBaseScore, log)
 }
 
-func extractCVSSTemporalScore(item *Vulnerability) float64 {
-	return strToFloat(item.CvssTemporalScore)
+func extractCVSSTemporalScore(item *Vulnerability, log logging.Logger) float64 {
+	return strToFloat(item.CvssTemporalScore, log)
 }
 
 func extractCVSSVectorString(item *Vulnerability) string {
@@ -43,10 +43,10 @@ func convertTime(fireeyeTime int64) string {
 	return time.Unix(fireeyeTime, 0).Format(nvd.TimeLayout)
 }
 
-func strToFloat(str string) float64 {
+func strToFloat(str string, log logging.Logger) float64 {
 	f, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		log.Println(err)
+		log.Warnf("%v", err)
 		f = float64(0)
 	}
 	return f
@@ -17,12 +17,12 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"sync"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/flexera/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/download"
 	"github.com/facebookincubator/nvdtools/providers/lib/rate"
@@ -38,6 +38,7 @@ type Client struct {
 	userAgent string
 	apiKey    string
 	limiter   rate.Limiter
+	logger    logging.Logger
 }
 
 const (
@@ -45,8 +46,6 @@ const (
 	advisoriesEndpoint = "/api/advisories"
 	numFetchers        = 4
 	requestsPerMinute  = 240
-	numRequestRetries  = 3
-	retryDelay         = 1 * time.Second
 )
 
 // NewClient creates a new Client object with given properties
@@ -56,9 +55,16 @@ func NewClient(baseURL, userAgent, apiKey string) Client {
 		userAgent: userAgent,
 		apiKey:    apiKey,
 		limiter:   rate.BurstyLimiter(time.Minute, requestsPerMinute),
+		logger:    logging.Nop,
 	}
 }
 
+// SetLogger sets the logger the sync progress and the failed requests are reported to; nothing is logged by default
+func (c *Client) SetLogger(logger logging.Logger) *Client {
+	c.logger = logging.OrNop(logger)
+	return c
+}
+
 // FetchAllVulnerabilities will fetch all advisories since given time
 // we first fetch all pages and just collect all identifiers found on them and
 // push them into the `identifiers` channel. Then we start fetchers which take
@@ -71,7 +77,7 @@ func (c Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible,
 	}
 
 	numPages := (totalAdvisories-1)/pageSize + 1
-	log.Printf("starting sync for %d advisories over %d pages\n", totalAdvisories, numPages)
+	c.logger.Infof("starting sync for %d advisories over %d pages", totalAdvisories, numPages)
 
 	identifiers := make(chan string, totalAdvisories)
 	advisories := make(chan runner.Convertible, totalAdvisories)
@@ -87,7 +93,7 @@ func (c Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible,
 					identifiers <- element.AdvisoryIdentifier
 				}
 			} else {
-				log.Println(errors.Wrapf(err, "failed to fetch page %d advisory list", p))
+				c.logger.Errorf("%v", errors.Wrapf(err, "failed to fetch page %d advisory list", p))
 			}
 		}(page + 1)
 	}
@@ -107,7 +113,7 @@ func (c Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible,
 				if err == nil {
 					advisories <- advisory
 				} else {
-					log.Println(errors.Wrapf(err, "failed to fetch advisory %s", identifier))
+					c.logger.Errorf("%v", errors.Wrapf(err, "failed to fetch advisory %s", identifier))
 				}
 			}
 		}()
@@ -170,27 +176,14 @@ func (c Client) query(endpoint string, params map[string]interface{}, v interfac
 	}
 	u.RawQuery = query.Encode()
 
-	var resp *http.Response
-	for i := 0; i < numRequestRetries; i++ {
-		c.limiter.Allow() // block until we can make another request
-		resp, err = download.Get(u.String(), http.Header{
-			"Authorization": {c.apiKey},
-			"User-Agent":    {c.userAgent},
-		})
-		if err == nil {
-			break
-		}
-
-		if he, ok := err.(download.Err); !ok {
-			return err
-		} else if he.Code != http.StatusTooManyRequests && he.Code != http.StatusGatewayTimeout {
-			// if it's not any of these two, it's not rate limit so return the error
-			return err
-		}
-		// it is rate limit, just retry after 1 second
-		time.Sleep(retryDelay)
-	}
-	if resp == nil {
+	// throttled requests are retried by the download client, the API limit is applied to every attempt
+	transport := *download.DefaultTransport
+	transport.Limiter = limiters{c.limiter, transport.Limiter}
+	resp, err := download.GetWithClient(&http.Client{Transport: &transport}, u.String(), http.Header{
+		"Authorization": {c.apiKey},
+		"User-Agent":    {c.userAgent},
+	})
+	if err != nil {
 		return err
 	}
 
@@ -203,3 +196,14 @@ func (c Client) query(endpoint string, params map[string]interface{}, v interfac
 
 	return nil
 }
+
+// limiters blocks until all of the limiters allow the request, nil ones are ignored
+type limiters []rate.Limiter
+
+func (ls limiters) Allow() {
+	for _, l := range ls {
+		if l != nil {
+			l.Allow()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type countingLimiter int32
+
+func (l *countingLimiter) Allow() {
+	atomic.AddInt32((*int32)(l), 1)
+}
+
+func TestQueryLimitsRetries(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"count":42}`))
+	}))
+	defer srv.Close()
+
+	var limiter countingLimiter
+	c := NewClient(srv.URL, "test", "key")
+	c.limiter = &limiter
+	n, err := c.getNumberOfAdvisories(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 42 {
+		t.Fatalf("got %d advisories, expected 42", n)
+	}
+	if requests, allowed := atomic.LoadInt32(&requests), atomic.LoadInt32((*int32)(&limiter)); allowed != requests {
+		t.Fatalf("%d of %d requests were limited", allowed, requests)
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ghsa provides a converter for GitHub Security Advisories to nvd.
+//
+// It reads the advisories as returned by the GitHub GraphQL API (securityAdvisories query).
+// The records of github.com/github/advisory-database are in OSV format and can be converted with package osv;
+// both generate the same CPE names for the same packages.
+package ghsa
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/providers/osv"
+	"github.com/facebookincubator/nvdtools/wfn"
+
+	"github.com/pkg/errors"
+)
+
+// Convert converts the advisories from the GraphQL response read from r to NVD CVE JSON 1.0 format.
+// The response is expected to hold data.securityAdvisories.nodes.
+func Convert(r io.Reader) (*schema.NVDCVEFeedJSON10, error) {
+	var resp struct {
+		Data struct {
+			SecurityAdvisories struct {
+				Nodes []*Advisory `json:"nodes"`
+			} `json:"securityAdvisories"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, errors.Wrap(err, "cannot decode GHSA response")
+	}
+	feed := &schema.NVDCVEFeedJSON10{}
+	for _, advisory := range resp.Data.SecurityAdvisories.Nodes {
+		if advisory == nil {
+			continue
+		}
+		cve, err := advisory.Convert()
+		if err != nil {
+			return nil, err
+		}
+		feed.CVEItems = append(feed.CVEItems, cve)
+	}
+	return feed, nil
+}
+
+// ConvertAdvisory converts a single advisory read from r to NVD CVE JSON 1.0 format.
+func ConvertAdvisory(r io.Reader) (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+	var advisory Advisory
+	if err := json.NewDecoder(r).Decode(&advisory); err != nil {
+		return nil, errors.Wrap(err, "cannot decode GHSA advisory")
+	}
+	return advisory.Convert()
+}
+
+// Advisory is a SecurityAdvisory object of the GitHub GraphQL API, only the fields used by the converter
+type Advisory struct {
+	GHSAID      string `json:"ghsaId"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Identifiers []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"identifiers"`
+	CVSS struct {
+		Score        float64 `json:"score"`
+		VectorString string  `json:"vectorString"`
+	} `json:"cvss"`
+	CWEs struct {
+		Nodes []struct {
+			CWEID string `json:"cweId"`
+		} `json:"nodes"`
+	} `json:"cwes"`
+	Permalink   string `json:"permalink"`
+	PublishedAt string `json:"publishedAt"`
+	UpdatedAt   string `json:"updatedAt"`
+	WithdrawnAt string `json:"withdrawnAt"`
+	References  []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+	Vulnerabilities struct {
+		Nodes []*Vulnerability `json:"nodes"`
+	} `json:"vulnerabilities"`
+}
+
+// Vulnerability is a SecurityVulnerability object of the GitHub GraphQL API: the affected versions of a package
+type Vulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	VulnerableVersionRange string `json:"vulnerableVersionRange"`
+}
+
+// ecosystems maps the GraphQL API ecosystems to the OSV ones
+var ecosystems = map[string]string{
+	"ACTIONS":  "GitHub Actions",
+	"COMPOSER": "Packagist",
+	"ERLANG":   "Hex",
+	"GO":       "Go",
+	"MAVEN":    "Maven",
+	"NPM":      "npm",
+	"NUGET":    "NuGet",
+	"PIP":      "PyPI",
+	"PUB":      "Pub",
+	"RUBYGEMS": "RubyGems",
+	"RUST":     "crates.io",
+	"SWIFT":    "SwiftURL",
+}
+
+// rejectPrefix marks the description of withdrawn advisories, the same way NVD marks rejected CVEs
+const rejectPrefix = "** REJECT ** "
+
+// Convert converts the advisory to NVD CVE JSON 1.0 format.
+// Withdrawn advisories are converted with the description marked as rejected.
+func (advisory *Advisory) Convert() (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+	if advisory.GHSAID == "" {
+		return nil, errors.New("GHSA advisory has no ghsaId")
+	}
+
+	modified, err := ghsaTimeToNVD(advisory.UpdatedAt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed updatedAt of %s", advisory.GHSAID)
+	}
+	published, err := ghsaTimeToNVD(advisory.PublishedAt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed publishedAt of %s", advisory.GHSAID)
+	}
+
+	conf, err := advisory.newConfigurations()
+	if err != nil {
+		return nil, err
+	}
+
+	description := advisory.Description
+	if description == "" {
+		description = advisory.Summary
+	}
+	if advisory.WithdrawnAt != "" {
+		description = rejectPrefix + description
+	}
+
+	cve := &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{
+				ID:       advisory.GHSAID,
+				ASSIGNER: "github.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &schema.CVEJSON40Description{
+				DescriptionData: []*schema.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: description,
+					},
+				},
+			},
+			Problemtype: advisory.newProblemType(),
+			References:  advisory.newReferences(),
+		},
+		Configurations:   conf,
+		Impact:           advisory.newImpact(),
+		LastModifiedDate: modified,
+		PublishedDate:    published,
+	}
+
+	return cve, nil
+}
+
+func ghsaTimeToNVD(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(schema.TimeLayout), nil
+}
+
+// newReferences adds the identifiers other than the GHSA ID (e.g. the CVE alias) and the reference URLs
+func (advisory *Advisory) newReferences() *schema.CVEJSON40References {
+	refs := &schema.CVEJSON40References{}
+	addRef := func(name, url string) {
+		refs.ReferenceData = append(refs.ReferenceData, &schema.CVEJSON40Reference{
+			Name: name,
+			URL:  url,
+		})
+	}
+	for _, id := range advisory.Identifiers {
+		if id.Value != advisory.GHSAID {
+			addRef(id.Value, "")
+		}
+	}
+	if advisory.Permalink != "" {
+		addRef(advisory.Summary, advisory.Permalink)
+	}
+	for _, ref := range advisory.References {
+		addRef(ref.URL, ref.URL)
+	}
+	if len(refs.ReferenceData) == 0 {
+		return nil
+	}
+	return refs
+}
+
+func (advisory *Advisory) newProblemType() *schema.CVEJSON40Problemtype {
+	if len(advisory.CWEs.Nodes) == 0 {
+		return nil
+	}
+	ptd := &schema.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range advisory.CWEs.Nodes {
+		ptd.Description = append(ptd.Description, &schema.CVEJSON40LangString{
+			Lang:  "en",
+			Value: cwe.CWEID,
+		})
+	}
+	return &schema.CVEJSON40Problemtype{
+		ProblemtypeData: []*schema.CVEJSON40ProblemtypeProblemtypeData{ptd},
+	}
+}
+
+func (advisory *Advisory) newImpact() *schema.NVDCVEFeedJSON10DefImpact {
+	if advisory.CVSS.VectorString == "" {
+		return nil
+	}
+	cvss := &schema.CVSSV30{
+		BaseScore:    advisory.CVSS.Score,
+		VectorString: advisory.CVSS.VectorString,
+	}
+	if v, err := cvss3.VectorFromString(advisory.CVSS.VectorString); err == nil {
+		cvss.Version = v.Version.String()
+		if cvss.BaseScore == 0 && v.Validate() == nil {
+			cvss.BaseScore = v.BaseScore()
+		}
+	}
+	cvss.BaseSeverity = cvss3.Severity(cvss.BaseScore)
+	return &schema.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: cvss,
+		},
+	}
+}
+
+func (advisory *Advisory) newConfigurations() (*schema.NVDCVEFeedJSON10DefConfigurations, error) {
+	node := &schema.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, vuln := range advisory.Vulnerabilities.Nodes {
+		if vuln == nil {
+			continue
+		}
+		match, err := vuln.cpeMatch()
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot convert vulnerable package of %s", advisory.GHSAID)
+		}
+		node.CPEMatch = append(node.CPEMatch, match)
+	}
+	return &schema.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: "4.0",
+		Nodes:          []*schema.NVDCVEFeedJSON10DefNode{node},
+	}, nil
+}
+
+// cpeMatch converts the vulnerable version range, e.g. ">= 3.7.0, < 4.17.19" or "= 1.0", into a cpe_match entry
+func (vuln *Vulnerability) cpeMatch() (*schema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	ecosystem, ok := ecosystems[vuln.Package.Ecosystem]
+	if !ok {
+		ecosystem = vuln.Package.Ecosystem
+	}
+	cpe, err := osv.PackageCPE(ecosystem, vuln.Package.Name)
+	if err != nil {
+		return nil, err
+	}
+	match := &schema.NVDCVEFeedJSON10DefCPEMatch{Vulnerable: true}
+	for _, constraint := range strings.Split(vuln.VulnerableVersionRange, ",") {
+		constraint = strings.TrimSpace(constraint)
+		if constraint == "" {
+			continue
+		}
+		var op string
+		for _, o := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(constraint, o) {
+				op = o
+				break
+			}
+		}
+		version := strings.TrimSpace(constraint[len(op):])
+		if op == "" || version == "" {
+			return nil, errors.Errorf("malformed version range %q", vuln.VulnerableVersionRange)
+		}
+		switch op {
+		case ">=":
+			match.VersionStartIncluding = version
+		case ">":
+			match.VersionStartExcluding = version
+		case "<=":
+			match.VersionEndIncluding = version
+		case "<":
+			match.VersionEndExcluding = version
+		case "=":
+			if cpe.Version, err = wfn.WFNize(version); err != nil {
+				return nil, errors.Wrapf(err, "cannot wfn-ize version: %q", version)
+			}
+		}
+	}
+	match.Cpe23Uri = cpe.BindToFmtString()
+	match.CPEName = []*schema.NVDCVEFeedJSON10DefCPEName{
+		{
+			Cpe22Uri: cpe.BindToURI(),
+			Cpe23Uri: match.Cpe23Uri,
+		},
+	}
+	return match, nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghsa
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/providers/osv"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestConvert(t *testing.T) {
+	feed, err := Convert(bytes.NewBufferString(testResponse))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.CVEItems) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.CVEItems))
+	}
+
+	item := feed.CVEItems[0]
+	vuln := nvd.ToVuln(item)
+	if id := vuln.ID(); id != "GHSA-p6mc-m468-83gw" {
+		t.Errorf("unexpected ID %q", id)
+	}
+	if cves := vuln.CVEs(); len(cves) != 1 || cves[0] != "CVE-2020-8203" {
+		t.Errorf("expected CVE alias CVE-2020-8203, got %v", cves)
+	}
+	if cwes := vuln.CWEs(); len(cwes) != 1 || cwes[0] != "CWE-770" {
+		t.Errorf("unexpected CWEs %v", cwes)
+	}
+	if v, score := vuln.CVSSv3Vector(), vuln.CVSSv3BaseScore(); v != "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H" || score != 7.4 {
+		t.Errorf("unexpected CVSS v3 %q %.1f", v, score)
+	}
+	if vuln.Rejected() {
+		t.Error("advisory shouldn't be rejected")
+	}
+
+	matches := item.Configurations.Nodes[0].CPEMatch
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 cpe_match, got %d", len(matches))
+	}
+	m := matches[0]
+	if m.VersionStartIncluding != "3.7.0" || m.VersionEndExcluding != "4.17.19" || m.VersionStartExcluding != "" || m.VersionEndIncluding != "" {
+		t.Errorf("version range wasn't preserved: %+v", m)
+	}
+	// same names as generated for OSV records
+	cpe, err := osv.PackageCPE("npm", "lodash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Cpe23Uri != cpe.BindToFmtString() {
+		t.Errorf("CPE %q differs from OSV one %q", m.Cpe23Uri, cpe.BindToFmtString())
+	}
+	attr, _ := wfn.Parse("cpe:2.3:a:lodash:lodash:4.17.15:*:*:*:*:node.js")
+	if len(vuln.Match([]*wfn.Attributes{attr}, false)) == 0 {
+		t.Errorf("%s should match", attr.BindToFmtString())
+	}
+
+	withdrawn := nvd.ToVuln(feed.CVEItems[1])
+	if !withdrawn.Rejected() {
+		t.Error("withdrawn advisory should be rejected")
+	}
+	cpe, _ = osv.PackageCPE("PyPI", "urllib3")
+	cpe.Version = "1\\.26\\.4"
+	if m := feed.CVEItems[1].Configurations.Nodes[0].CPEMatch; len(m) != 2 || m[1].Cpe23Uri != cpe.BindToFmtString() {
+		t.Errorf("exact version wasn't converted, expected %s: %+v", cpe.BindToFmtString(), m)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	cases := map[string]string{
+		"no id":     `{"summary": "no id"}`,
+		"bad range": `{"ghsaId": "GHSA-test", "vulnerabilities": {"nodes": [{"package": {"ecosystem": "NPM", "name": "x"}, "vulnerableVersionRange": "~> 1.0"}]}}`,
+		"bad date":  `{"ghsaId": "GHSA-test", "updatedAt": "yesterday"}`,
+	}
+	for name, advisory := range cases {
+		if _, err := ConvertAdvisory(bytes.NewBufferString(advisory)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+var testResponse = `{
+  "data": {
+    "securityAdvisories": {
+      "nodes": [
+        {
+          "ghsaId": "GHSA-p6mc-m468-83gw",
+          "summary": "Prototype Pollution in lodash",
+          "description": "Versions of lodash prior to 4.17.19 are vulnerable to Prototype Pollution.",
+          "identifiers": [
+            {"type": "GHSA", "value": "GHSA-p6mc-m468-83gw"},
+            {"type": "CVE", "value": "CVE-2020-8203"}
+          ],
+          "cvss": {
+            "score": 7.4,
+            "vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H"
+          },
+          "cwes": {"nodes": [{"cweId": "CWE-770"}]},
+          "permalink": "https://github.com/advisories/GHSA-p6mc-m468-83gw",
+          "publishedAt": "2020-07-15T19:15:48Z",
+          "updatedAt": "2023-11-01T05:04:28Z",
+          "withdrawnAt": null,
+          "references": [
+            {"url": "https://nvd.nist.gov/vuln/detail/CVE-2020-8203"},
+            {"url": "https://github.com/lodash/lodash/issues/4744"}
+          ],
+          "vulnerabilities": {
+            "nodes": [
+              {
+                "package": {"ecosystem": "NPM", "name": "lodash"},
+                "vulnerableVersionRange": ">= 3.7.0, < 4.17.19",
+                "firstPatchedVersion": {"identifier": "4.17.19"}
+              }
+            ]
+          }
+        },
+        {
+          "ghsaId": "GHSA-test-with-drwn",
+          "summary": "Withdrawn advisory",
+          "description": "This advisory was withdrawn.",
+          "identifiers": [{"type": "GHSA", "value": "GHSA-test-with-drwn"}],
+          "cvss": {"score": 0, "vectorString": null},
+          "publishedAt": "2021-06-01T21:18:29Z",
+          "updatedAt": "2021-06-02T10:00:00Z",
+          "withdrawnAt": "2021-06-02T10:00:00Z",
+          "vulnerabilities": {
+            "nodes": [
+              {
+                "package": {"ecosystem": "PIP", "name": "urllib3"},
+                "vulnerableVersionRange": "< 1.26.5"
+              },
+              {
+                "package": {"ecosystem": "PIP", "name": "urllib3"},
+                "vulnerableVersionRange": "= 1.26.4"
+              }
+            ]
+          }
+        }
+      ]
+    }
+  }
+}
+`
@@ -17,12 +17,12 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"sync"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/download"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
@@ -34,6 +34,7 @@ type Client struct {
 	baseUrl   string
 	userAgent string
 	apiKey    string
+	logger    logging.Logger
 }
 
 const (
@@ -47,9 +48,16 @@ func NewClient(baseUrl, userAgent, apiKey string) Client {
 		baseUrl:   baseUrl,
 		userAgent: userAgent,
 		apiKey:    apiKey,
+		logger:    logging.Nop,
 	}
 }
 
+// SetLogger sets the logger the sync progress and the failed requests are reported to; nothing is logged by default
+func (c *Client) SetLogger(logger logging.Logger) *Client {
+	c.logger = logging.OrNop(logger)
+	return c
+}
+
 // FetchAllVulnerabilities will fetch all vulnerabilities from iDefense API
 func (c Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible, error) {
 	sinceStr := time.Unix(since, 0).Format("2006-01-02T15:04:05.000Z")
@@ -72,7 +80,7 @@ func (c Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible,
 	numPages := (totalVulns-1)/pageSize + 1
 
 	// fetch pages concurrently
-	log.Printf("starting sync for %d vulnerabilities over %d pages\n", totalVulns, numPages)
+	c.logger.Infof("starting sync for %d vulnerabilities over %d pages", totalVulns, numPages)
 	wg := sync.WaitGroup{}
 	for page := 1; page <= numPages; page++ {
 		page := page
@@ -86,7 +94,7 @@ func (c Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible,
 				"page":                    page,
 			})
 			if err != nil {
-				log.Printf("failed to get page %d: %v", page, err)
+				c.logger.Errorf("failed to get page %d: %v", page, err)
 				return
 			}
 			for _, vuln := range result.Results {
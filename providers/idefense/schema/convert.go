@@ -16,6 +16,7 @@ package schema
 
 import (
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 
 	"github.com/pkg/errors"
 )
@@ -26,6 +27,11 @@ const (
 
 // Convert implements runner.Convertible interface
 func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return item.ConvertWithLogger(logging.Nop)
+}
+
+// ConvertWithLogger is like Convert, but it reports the affected products it can't convert to log
+func (item *Vulnerability) ConvertWithLogger(log logging.Logger) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 	lastModifiedDate, err := convertTime(item.LastModified)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't convert last modified date")
@@ -35,7 +41,7 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 		return nil, errors.Wrap(err, "can't convert published date")
 	}
 
-	configurations, err := item.makeConfigurations()
+	configurations, err := item.makeConfigurations(log)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't create configurations")
 	}
@@ -124,8 +130,8 @@ func (item *Vulnerability) makeReferences() *nvd.CVEJSON40References {
 	}
 }
 
-func (item *Vulnerability) makeConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
-	configs := item.findConfigurations()
+func (item *Vulnerability) makeConfigurations(log logging.Logger) (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
+	configs := item.findConfigurations(log)
 	if len(configs) == 0 {
 		return nil, errors.New("unable to find any configurations in data")
 	}
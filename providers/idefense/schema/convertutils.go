@@ -15,10 +15,10 @@
 package schema
 
 import (
-	"log"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -46,7 +46,7 @@ func convertTime(idefenseTime string) (string, error) {
 	return t.Format(nvd.TimeLayout), nil
 }
 
-func (item *Vulnerability) findConfigurations() []configuration {
+func (item *Vulnerability) findConfigurations(log logging.Logger) []configuration {
 	configMap := make(map[string]configuration)
 
 	if item.Affects == nil {
@@ -57,7 +57,7 @@ func (item *Vulnerability) findConfigurations() []configuration {
 	for _, vulnTech := range item.Affects.VulnTechs {
 		attrs, err := createAttributes(vulnTech.Part, vulnTech.Vendor, vulnTech.Product)
 		if err != nil {
-			log.Println(err)
+			log.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -77,7 +77,7 @@ func (item *Vulnerability) findConfigurations() []configuration {
 	for _, pkg := range item.Affects.Packages {
 		attrs, err := createAttributes("a", "", pkg.PackageName)
 		if err != nil {
-			log.Println(err)
+			log.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -101,7 +101,7 @@ func (item *Vulnerability) findConfigurations() []configuration {
 	for _, vulnTech := range item.FixedBy.VulnTechs {
 		attrs, err := createAttributes(vulnTech.Part, vulnTech.Vendor, vulnTech.Product)
 		if err != nil {
-			log.Println(err)
+			log.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
@@ -117,7 +117,7 @@ func (item *Vulnerability) findConfigurations() []configuration {
 	for _, pkg := range item.FixedBy.Packages {
 		attrs, err := createAttributes("a", "", pkg.PackageName)
 		if err != nil {
-			log.Println(err)
+			log.Warnf("%v", err)
 			continue
 		}
 		cpe23Uri := attrs.BindToFmtString()
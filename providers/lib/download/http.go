@@ -21,10 +21,12 @@ import (
 	"net/http"
 )
 
-// Client will return a client to be used when making http requests
+var defaultClient = &http.Client{Transport: DefaultTransport}
+
+// Client will return a client to be used when making http requests, it uses DefaultTransport
 // Returns an error if it can't be acquired
 func Client() (*http.Client, error) {
-	return http.DefaultClient, nil
+	return defaultClient, nil
 }
 
 // Err encapsulates stuff from the http.Response
@@ -0,0 +1,145 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/rate"
+)
+
+// Clock provides the time to RetryTransport, so it can be faked in tests
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RetryTransport is an http.RoundTripper which limits the rate of requests and retries the ones
+// which were throttled (429) or failed on the server side (5xx, except for 501).
+// The retries are delayed by the exponential backoff, unless the response has Retry-After header.
+// Requests with a body are only retried if it can be obtained again (http.Request.GetBody).
+type RetryTransport struct {
+	// Transport makes the requests, http.DefaultTransport is used if nil
+	Transport http.RoundTripper
+	// Limiter is called before every request, including retries; no limit is applied if nil
+	Limiter rate.Limiter
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// Backoff is the delay before the first retry, it doubles with every next one.
+	Backoff time.Duration
+	// MaxBackoff caps the delay; the response is returned as is if it asks to retry after a longer one.
+	// It's ignored if 0.
+	MaxBackoff time.Duration
+	// Clock is used for waiting between the retries, the real time is used if nil
+	Clock Clock
+}
+
+// DefaultTransport is used by the client returned by Client
+var DefaultTransport = &RetryTransport{
+	MaxRetries: 3,
+	Backoff:    time.Second,
+	MaxBackoff: 5 * time.Minute,
+}
+
+// SetRateLimit limits the requests made by the client returned by Client to requestsPerSecond; 0 removes the limit
+func SetRateLimit(requestsPerSecond float64) {
+	if requestsPerSecond <= 0 {
+		DefaultTransport.Limiter = nil
+		return
+	}
+	DefaultTransport.Limiter = rate.BurstyLimiter(time.Duration(float64(time.Second)/requestsPerSecond), 1)
+}
+
+// RoundTrip is a part of http.RoundTripper interface
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	clock := t.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	backoff := t.Backoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.WithContext(req.Context())
+			req.Body = body
+		}
+		if t.Limiter != nil {
+			t.Limiter.Allow()
+		}
+		resp, err := transport.RoundTrip(req)
+		if err != nil || !canRetry || attempt >= t.MaxRetries || !retryable(resp.StatusCode) {
+			return resp, err
+		}
+
+		wait := backoff
+		if after, ok := retryAfter(resp.Header.Get("Retry-After"), clock.Now()); ok {
+			if t.MaxBackoff > 0 && after > t.MaxBackoff {
+				return resp, nil
+			}
+			wait = after
+		} else if t.MaxBackoff > 0 && wait > t.MaxBackoff {
+			wait = t.MaxBackoff
+		}
+		// drain the body, so the connection can be reused
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+
+		select {
+		case <-clock.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		backoff *= 2
+	}
+}
+
+func retryable(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500 && code != http.StatusNotImplemented
+}
+
+// retryAfter parses the value of Retry-After header: delay in seconds or HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,179 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now    time.Time
+	waited []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waited = append(c.waited, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// testServer responds with codes in order, then with 200; headers are added to the responses
+func testServer(codes []int, headers map[string]string, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(requests, 1))
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		if n <= len(codes) {
+			w.WriteHeader(codes[n-1])
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		codes    []int
+		headers  map[string]string
+		status   int
+		requests int32
+		waited   []time.Duration
+	}{
+		{
+			name:     "429 then 200",
+			codes:    []int{429},
+			status:   200,
+			requests: 2,
+			waited:   []time.Duration{time.Second},
+		},
+		{
+			name:     "exponential backoff",
+			codes:    []int{503, 502, 500},
+			status:   200,
+			requests: 4,
+			waited:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:     "retries exhausted",
+			codes:    []int{503, 503, 503, 503, 503},
+			status:   503,
+			requests: 4,
+			waited:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:     "retry after seconds",
+			codes:    []int{429},
+			headers:  map[string]string{"Retry-After": "7"},
+			status:   200,
+			requests: 2,
+			waited:   []time.Duration{7 * time.Second},
+		},
+		{
+			name:     "retry after date",
+			codes:    []int{429},
+			headers:  map[string]string{"Retry-After": now.Add(30 * time.Second).Format(http.TimeFormat)},
+			status:   200,
+			requests: 2,
+			waited:   []time.Duration{30 * time.Second},
+		},
+		{
+			name:     "retry after too long",
+			codes:    []int{429},
+			headers:  map[string]string{"Retry-After": "3600"},
+			status:   429,
+			requests: 1,
+		},
+		{
+			name:     "not retryable",
+			codes:    []int{404},
+			status:   404,
+			requests: 1,
+		},
+		{
+			name:     "not implemented",
+			codes:    []int{501},
+			status:   501,
+			requests: 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests int32
+			srv := testServer(c.codes, c.headers, &requests)
+			defer srv.Close()
+			clock := &fakeClock{now: now}
+			client := &http.Client{Transport: &RetryTransport{
+				MaxRetries: 3,
+				Backoff:    time.Second,
+				MaxBackoff: time.Minute,
+				Clock:      clock,
+			}}
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != c.status {
+				t.Errorf("expected status %d, got %d", c.status, resp.StatusCode)
+			}
+			if requests != c.requests {
+				t.Errorf("expected %d requests, got %d", c.requests, requests)
+			}
+			if len(clock.waited) != len(c.waited) {
+				t.Fatalf("expected to wait %v, waited %v", c.waited, clock.waited)
+			}
+			for i := range c.waited {
+				if clock.waited[i] != c.waited[i] {
+					t.Fatalf("expected to wait %v, waited %v", c.waited, clock.waited)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportBody(t *testing.T) {
+	var requests int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &RetryTransport{MaxRetries: 1, Clock: &fakeClock{}}}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(bodies) != 2 || bodies[1] != "payload" {
+		t.Fatalf("expected the body to be sent again, got status %d and bodies %q", resp.StatusCode, bodies)
+	}
+}
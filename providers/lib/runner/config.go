@@ -22,6 +22,7 @@ import (
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/download"
 )
 
 // Config is used to configure the execution of the converter
@@ -31,6 +32,8 @@ type Config struct {
 	download      bool
 	convert       bool
 	downloadSince sinceTS
+	rateLimit     float64
+	retries       int
 }
 
 func (c *Config) addFlags() {
@@ -38,6 +41,8 @@ func (c *Config) addFlags() {
 	flag.StringVar(&c.UserAgent, "user_agent", c.UserAgent, "User agent to be used when sending requests")
 	flag.BoolVar(&c.download, "download", false, "Should the data be downloaded or read from stdin/files")
 	flag.BoolVar(&c.convert, "convert", false, "Should the feed be converted to NVD format or not")
+	flag.Float64Var(&c.rateLimit, "rate_limit", 0, "Maximum number of requests per second sent to the API; 0 removes the limit")
+	flag.IntVar(&c.retries, "retries", download.DefaultTransport.MaxRetries, "How many times to retry throttled (429) or failed (5xx) requests, with exponential backoff")
 	flag.Var(&c.downloadSince, "since", fmt.Sprintf("Since when to download. It can be a timestamp, golang duration or time in %q format. Default is timestamp=0", nvd.TimeLayout))
 }
 
@@ -51,6 +56,12 @@ func (c *Config) validate() error {
 	if !regexp.MustCompile("^[[:ascii:]]+$").MatchString(c.UserAgent) {
 		return fmt.Errorf("User-Agent contains non ascii characters, using default")
 	}
+	if c.rateLimit < 0 {
+		return fmt.Errorf("negative rate limit %f", c.rateLimit)
+	}
+	if c.retries < 0 {
+		return fmt.Errorf("negative number of retries %d", c.retries)
+	}
 	if c.downloadSince < 0 {
 		return fmt.Errorf("negative timestamp used %d", c.downloadSince)
 	}
@@ -25,6 +25,8 @@ import (
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/download"
 	"github.com/facebookincubator/nvdtools/stats"
 )
 
@@ -36,6 +38,13 @@ type Convertible interface {
 	Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error)
 }
 
+// LoggingConvertible is a Convertible which reports the problems it skips over while converting to a logger;
+// the runner converts it with the logger of the standard log package, as it logs itself
+type LoggingConvertible interface {
+	Convertible
+	ConvertWithLogger(logging.Logger) (*nvd.NVDCVEFeedJSON10DefCVEItem, error)
+}
+
 // Read should read the vulnerabilities from the given reader and push them into the channel
 // The contents of the reader should be a slice of structs which are convertibles
 // channel will be created and mustn't be closed
@@ -75,6 +84,8 @@ func (r *Runner) Run() error {
 	if err := r.Config.validate(); err != nil {
 		return fmt.Errorf("config is invalid: %v", err)
 	}
+	download.SetRateLimit(r.Config.rateLimit)
+	download.DefaultTransport.MaxRetries = r.Config.retries
 
 	vulns, err := r.getVulnerabilities()
 	if err != nil {
@@ -150,7 +161,13 @@ func convert(vulns <-chan Convertible) error {
 	defer stats.TrackTime("convert.time", time.Now(), time.Second)
 	var feed nvd.NVDCVEFeedJSON10
 	for vuln := range vulns {
-		converted, err := vuln.Convert()
+		var converted *nvd.NVDCVEFeedJSON10DefCVEItem
+		var err error
+		if lv, ok := vuln.(LoggingConvertible); ok {
+			converted, err = lv.ConvertWithLogger(logging.Std)
+		} else {
+			converted, err = vuln.Convert()
+		}
 		if err != nil {
 			log.Printf("error while converting vuln: %v", err)
 			continue
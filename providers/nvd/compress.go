@@ -0,0 +1,160 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Compression is the format CVE data feeds are stored in the local directory.
+type Compression string
+
+// Supported storage formats.
+const (
+	// CompressAsPublished stores the feeds as they are downloaded from NVD.
+	CompressAsPublished Compression = ""
+	// CompressGzip stores the feeds gzip compressed, recompressing zip feeds.
+	CompressGzip Compression = "gzip"
+	// CompressNone stores the feeds decompressed.
+	CompressNone Compression = "none"
+)
+
+// Set implements the flag.Value interface.
+func (c *Compression) Set(v string) error {
+	switch Compression(v) {
+	case CompressAsPublished, CompressGzip, CompressNone:
+		*c = Compression(v)
+		return nil
+	case "zstd":
+		return fmt.Errorf("zstd compression is not supported by this build of nvdsync")
+	default:
+		return fmt.Errorf("unsupported compression: %q", v)
+	}
+}
+
+// String implements the fmt.Stringer interface.
+func (c Compression) String() string {
+	return string(c)
+}
+
+// stored returns the compression, gz, zip or none, the feed f is stored with.
+func (c Compression) stored(f CVE) string {
+	switch c {
+	case CompressGzip:
+		return "gz"
+	case CompressNone:
+		return "none"
+	default:
+		return f.compression()
+	}
+}
+
+// storedName returns the name of data file, published with the compression of f, once stored with c.
+func (c Compression) storedName(f CVE, name string) string {
+	base := strings.TrimSuffix(name, "."+f.compression())
+	switch s := c.stored(f); s {
+	case "none":
+		return base
+	default:
+		return base + "." + s
+	}
+}
+
+// store converts the downloaded file of feed f to the format of c.
+// Returns the path of the converted file, which is filename itself when no conversion is needed.
+func (c Compression) store(f CVE, filename string) (string, error) {
+	if c.stored(f) == f.compression() {
+		return filename, nil
+	}
+	r, err := openDecompressed(f.compression(), filename)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	out, err := ioutil.TempFile("", "nvdsync-store-")
+	if err != nil {
+		return "", err
+	}
+	if c.stored(f) == "gz" {
+		zw := gzip.NewWriter(out)
+		_, err = io.Copy(zw, r)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	} else {
+		_, err = io.Copy(out, r)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("can't store %q with compression %q: %v", filename, c, err)
+	}
+	return out.Name(), nil
+}
+
+// openDecompressed opens filename, stored with compression gz, zip or none, for reading its decompressed contents.
+func openDecompressed(compression, filename string) (io.ReadCloser, error) {
+	switch compression {
+	case "gz":
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return readCloser{zr, f}, nil
+	case "zip":
+		f, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, err
+		}
+		if len(f.File) != 1 {
+			f.Close()
+			return nil, fmt.Errorf(
+				"unexpected number of files in zip %q: want 1, have %d",
+				filename, len(f.File),
+			)
+		}
+		ff, err := f.File[0].Open()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return readCloser{ff, f}, nil
+	default:
+		return os.Open(filename)
+	}
+}
+
+// readCloser reads from r and closes c.
+type readCloser struct {
+	io.Reader
+	c io.Closer
+}
+
+func (rc readCloser) Close() error {
+	return rc.c.Close()
+}
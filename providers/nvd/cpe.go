@@ -19,14 +19,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/download"
 )
 
 // CPE defines the CPE data feed for synchronization.
@@ -133,14 +133,15 @@ func (cf cpeFile) baseURL(src SourceConfig) string {
 func (cf cpeFile) Sync(ctx context.Context, src SourceConfig, localdir string) error {
 	baseURL := cf.baseURL(src)
 	sourceURL := baseURL + cf.DataFile
-	needsUpdate, err := cf.needsUpdate(ctx, sourceURL, localdir)
+	log := logging.OrNop(src.Logger)
+	needsUpdate, err := cf.needsUpdate(ctx, log, sourceURL, localdir)
 	if err != nil {
 		return err
 	}
 	if !needsUpdate {
 		return nil
 	}
-	etag, tempDataFilename, err := cf.download(ctx, sourceURL)
+	etag, tempDataFilename, err := cf.download(ctx, log, sourceURL)
 	if err != nil {
 		return err
 	}
@@ -165,13 +166,17 @@ func (cf cpeFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	return nil
 }
 
-func (cf cpeFile) needsUpdate(ctx context.Context, targetURL, localdir string) (bool, error) {
-	flog.V(1).Infof("checking etag for %q", targetURL)
+func (cf cpeFile) needsUpdate(ctx context.Context, log logging.Logger, targetURL, localdir string) (bool, error) {
+	log.Debugf("checking etag for %q", targetURL)
 	req, err := httpNewRequestContext(ctx, "HEAD", targetURL)
 	if err != nil {
 		return false, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	client, err := download.Client()
+	if err != nil {
+		return false, fmt.Errorf("can't obtain http client: %v", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return false, err
 	}
@@ -185,25 +190,29 @@ func (cf cpeFile) needsUpdate(ctx context.Context, targetURL, localdir string) (
 	}
 	etagBytes, err := ioutil.ReadFile(filepath.Join(localdir, cf.EtagFile))
 	if err != nil {
-		flog.V(1).Infof("etag file %q for not exist in %q, needs sync", cf.EtagFile, localdir)
+		log.Debugf("etag file %q for not exist in %q, needs sync", cf.EtagFile, localdir)
 		return true, nil
 	}
 	localEtag := string(etagBytes)
 	if localEtag != remoteEtag {
-		flog.V(1).Infof("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, localEtag, remoteEtag)
+		log.Debugf("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, localEtag, remoteEtag)
 		return true, nil
 	}
 	return false, nil
 }
 
 // download file from targetURL, returns etag and path to local file.
-func (cf cpeFile) download(ctx context.Context, targetURL string) (string, string, error) {
-	flog.V(1).Infof("downloading data file %q", targetURL)
-	req, err := http.NewRequest("GET", targetURL, nil)
+func (cf cpeFile) download(ctx context.Context, log logging.Logger, targetURL string) (string, string, error) {
+	log.Debugf("downloading data file %q", targetURL)
+	req, err := httpNewRequestContext(ctx, "GET", targetURL)
 	if err != nil {
 		return "", "", err
 	}
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	client, err := download.Client()
+	if err != nil {
+		return "", "", fmt.Errorf("can't obtain http client: %v", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", "", err
 	}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"sync"
 	"testing"
 )
 
@@ -53,9 +54,49 @@ func TestCPE(t *testing.T) {
 	}
 }
 
+// the requests made by the CPE sync are retried by the download client
+func TestCPERetry(t *testing.T) {
+	td, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+
+	handler := &cpeFlakyTestServer{failed: map[string]bool{}}
+	ts, src := httptestNewServer(handler)
+	defer ts.Close()
+
+	if err := cpe23xmlGz.Sync(context.Background(), src, td); err != nil {
+		t.Fatal(err)
+	}
+	if len(handler.failed) != 2 {
+		t.Fatalf("expected the HEAD and GET requests to fail once, got %v", handler.failed)
+	}
+}
+
 type cpeTestServer struct{}
 
 func (ts cpeTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Etag", "foobar")
 	fmt.Fprintf(w, "hello, world")
 }
+
+// cpeFlakyTestServer fails every first request of a method and path with 503
+type cpeFlakyTestServer struct {
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+func (ts *cpeFlakyTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.mu.Lock()
+	key := r.Method + " " + r.URL.Path
+	failed := ts.failed[key]
+	ts.failed[key] = true
+	ts.mu.Unlock()
+	if !failed {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	cpeTestServer{}.ServeHTTP(w, r)
+}
@@ -33,8 +33,8 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/download"
-	"github.com/facebookincubator/flog"
 )
 
 // CVE defines the CVE data feed for synchronization.
@@ -138,6 +138,27 @@ func (c CVE) Sync(ctx context.Context, src SourceConfig, localdir string) error
 	return nil
 }
 
+// Incremental returns a Syncer that only synchronizes the modified and
+// recent feeds of c, for fast updates of an existing local mirror.
+func (c CVE) Incremental() Syncer {
+	return cveIncremental{c}
+}
+
+type cveIncremental struct {
+	CVE
+}
+
+// Sync synchronizes the modified and recent CVE feeds to a local directory.
+func (c cveIncremental) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	files := cveFileList(c.CVE)
+	for _, f := range files[len(files)-2:] { // recent, modified
+		if err := f.Sync(ctx, src, localdir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func cveFileList(c CVE) []cveFile {
 	filefmt := func(version, suffix, encoding, compression string) string {
 		s := fmt.Sprintf("nvdcve-%s-%s.%s", version, suffix, encoding)
@@ -227,9 +248,10 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	if err != nil {
 		return err
 	}
+	log := logging.OrNop(src.Logger)
 	remoteMetaURL := baseURL + cf.MetaFile
-	flog.V(1).Infof("checking meta file %q for updates to %q", cf.MetaFile, cf.DataFile)
-	remoteMeta, needsUpdate, err := cf.needsUpdate(ctx, remoteMetaURL, localdir)
+	log.Debugf("checking meta file %q for updates to %q", cf.MetaFile, cf.DataFile)
+	remoteMeta, needsUpdate, err := cf.needsUpdate(ctx, log, remoteMetaURL, localdir, src.Compress)
 	if err != nil {
 		return err
 	}
@@ -237,11 +259,16 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 		return nil
 	}
 	remoteFileURL := baseURL + cf.DataFile
-	tempDataFilename, err := cf.downloadAndVerify(ctx, remoteMeta, remoteFileURL)
+	tempDataFilename, err := cf.downloadAndVerify(ctx, log, remoteMeta, remoteFileURL, !src.NoVerify)
 	if err != nil {
 		return err
 	}
 	defer os.Remove(tempDataFilename)
+	storedDataFilename, err := src.Compress.store(cf.CVE, tempDataFilename)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(storedDataFilename)
 
 	// write metadata file
 	metaFilename := filepath.Join(localdir, cf.MetaFile)
@@ -251,10 +278,10 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	}
 
 	// write data file
-	dataFilename := filepath.Join(localdir, cf.DataFile)
+	dataFilename := filepath.Join(localdir, src.Compress.storedName(cf.CVE, cf.DataFile))
 	bakDataFilename := dataFilename + ".bak"
 	xRename(dataFilename, bakDataFilename)
-	if err = xRename(tempDataFilename, dataFilename); err != nil {
+	if err = xRename(storedDataFilename, dataFilename); err != nil {
 		xRename(bakDataFilename, dataFilename)
 		return err
 	}
@@ -262,15 +289,17 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	return nil
 }
 
-func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir string) (*metaFile, bool, error) {
-	flog.V(1).Infof("downloading meta file %q", remoteMetaURL)
+// needsUpdate compares the remote meta file to the local one, and the local data file,
+// stored with compression c, to the local meta file.
+func (cf cveFile) needsUpdate(ctx context.Context, log logging.Logger, remoteMetaURL, localdir string, c Compression) (*metaFile, bool, error) {
+	log.Debugf("downloading meta file %q", remoteMetaURL)
 	remoteMeta, err := newMetaFromURL(ctx, remoteMetaURL)
 	if err != nil {
 		return nil, false, err
 	}
 	metaFilename := filepath.Join(localdir, cf.MetaFile)
 	if _, err := os.Stat(metaFilename); os.IsNotExist(err) {
-		flog.V(1).Infof("meta file %q does not exist in %q, needs sync", cf.MetaFile, localdir)
+		log.Debugf("meta file %q does not exist in %q, needs sync", cf.MetaFile, localdir)
 		return &remoteMeta, true, nil
 	}
 	localMeta, err := newMetaFromFile(metaFilename)
@@ -278,30 +307,35 @@ func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir strin
 		return nil, false, err
 	}
 	if !localMeta.Equal(remoteMeta) {
-		flog.V(1).Infof("data file %q needs update in %q: local%+v != remote%+v", cf.DataFile, localdir, localMeta, remoteMeta)
+		log.Debugf("data file %q needs update in %q: local%+v != remote%+v", cf.DataFile, localdir, localMeta, remoteMeta)
 		return &remoteMeta, true, nil
 	}
-	dataFilename := filepath.Join(localdir, cf.DataFile)
+	dataFile := c.storedName(cf.CVE, cf.DataFile)
+	dataFilename := filepath.Join(localdir, dataFile)
 	fi, err := os.Stat(dataFilename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			flog.V(1).Infof("data file %q does not exist in %q, needs sync", cf.DataFile, localdir)
+			log.Debugf("data file %q does not exist in %q, needs sync", dataFile, localdir)
 			return &remoteMeta, true, nil
 		}
 		return nil, false, err
 	}
-	var sizeOK bool
+	// feeds recompressed locally don't have the size published in the meta file, only their hash is checked
+	sizeOK := true
 	var hashFunc func(filename string) (string, error)
-	switch cf.compression() {
+	switch c.stored(cf.CVE) {
 	case "gz":
-		sizeOK = fi.Size() == int64(localMeta.GzSize)
+		sizeOK = cf.compression() != "gz" || fi.Size() == int64(localMeta.GzSize)
 		hashFunc = gunzipFileAndComputeSHA256
 	case "zip":
 		sizeOK = fi.Size() == int64(localMeta.ZipSize)
 		hashFunc = unzipFileAndComputeSHA256
+	case "none":
+		sizeOK = fi.Size() == int64(localMeta.Size)
+		hashFunc = fileComputeSHA256
 	}
 	if !sizeOK {
-		flog.V(1).Infof("data file %q needs update in %q: size mismatch", cf.DataFile, localdir)
+		log.Debugf("data file %q needs update in %q: size mismatch", dataFile, localdir)
 		return &remoteMeta, true, nil
 	}
 	hash, err := hashFunc(dataFilename)
@@ -309,20 +343,21 @@ func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir strin
 		return nil, false, err
 	}
 	if hash != localMeta.SHA256 {
-		flog.V(1).Infof("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, hash, localMeta.SHA256)
+		log.Debugf("data file %q needs update in %q: hash mismatch %q != %q", dataFile, localdir, hash, localMeta.SHA256)
 		return &remoteMeta, true, nil
 	}
 	return &remoteMeta, false, nil
 }
 
 // downloadAndVerify downloads a remote file into a temporary local file, and performs checksum using size and hash from m.
+// The checksum is skipped when verify is false.
 // Returns the path to the local file.
-func (cf cveFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFileURL string) (string, error) {
+func (cf cveFile) downloadAndVerify(ctx context.Context, log logging.Logger, m *metaFile, remoteFileURL string, verify bool) (string, error) {
 	req, err := httpNewRequestContext(ctx, "GET", remoteFileURL)
 	if err != nil {
 		return "", err
 	}
-	flog.V(1).Infof("downloading data file %q", remoteFileURL)
+	log.Debugf("downloading data file %q", remoteFileURL)
 	client, err := download.Client()
 	if err != nil {
 		return "", fmt.Errorf("can't obtain http client: %v", err)
@@ -345,7 +380,7 @@ func (cf cveFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFile
 		wantSize = int64(m.ZipSize)
 		hashFunc = unzipFileAndComputeSHA256
 	}
-	if resp.ContentLength != wantSize {
+	if verify && resp.ContentLength != -1 && resp.ContentLength != wantSize {
 		return "", fmt.Errorf(
 			"unexpected size for %q (%s): want %d, have %d",
 			remoteFileURL, resp.Status, wantSize, resp.ContentLength,
@@ -355,21 +390,44 @@ func (cf cveFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFile
 	if err != nil {
 		return "", err
 	}
-	_, err = io.Copy(dataFile, resp.Body)
+	// gzip compressed data is decompressed and hashed as it's downloaded, instead of reading the file again;
+	// the feed is stored compressed regardless
+	var hash string
+	var hashErr error
+	counter := &countingWriter{w: dataFile}
+	if verify && cf.compression() == "gz" {
+		hash, hashErr = gunzipAndComputeSHA256(io.TeeReader(resp.Body, counter))
+	}
+	_, err = io.Copy(counter, resp.Body)
+	n := counter.n
 	dataFile.Close()
 	if err != nil {
+		os.Remove(dataFile.Name())
 		return "", err
 	}
-	hash, err := hashFunc(dataFile.Name())
-	if err != nil {
-		defer os.Remove(dataFile.Name()) // TODO: delet?
-		return "", err
+	if !verify {
+		log.Warnf("not verifying %q against its meta file", remoteFileURL)
+		return dataFile.Name(), nil
+	}
+	if n != wantSize {
+		os.Remove(dataFile.Name())
+		return "", fmt.Errorf(
+			"truncated download of %q: want %d bytes, have %d",
+			remoteFileURL, wantSize, n,
+		)
+	}
+	if hash == "" && hashErr == nil {
+		hash, hashErr = hashFunc(dataFile.Name())
+	}
+	if hashErr != nil {
+		os.Remove(dataFile.Name())
+		return "", fmt.Errorf("can't compute sha256 of %q: %v", remoteFileURL, hashErr)
 	}
 	if hash != m.SHA256 {
-		defer os.Remove(dataFile.Name()) // TODO: delet?
+		os.Remove(dataFile.Name())
 		return "", fmt.Errorf(
-			"unexpected hash for %q (%s): want %q, have %q",
-			remoteFileURL, resp.Status, m.SHA256, hash,
+			"sha256 mismatch for %q: meta file has %q, decompressed data has %q",
+			remoteFileURL, m.SHA256, hash,
 		)
 	}
 	return dataFile.Name(), nil
@@ -539,6 +597,18 @@ func newMetaFromFile(filename string) (metaFile, error) {
 	return m, nil
 }
 
+// countingWriter writes to w and counts the bytes written.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
 func computeSHA256(r io.Reader) (string, error) {
 	hasher := sha256.New()
 	_, err := io.Copy(hasher, r)
@@ -549,6 +619,15 @@ func computeSHA256(r io.Reader) (string, error) {
 	return strings.ToUpper(hex.EncodeToString(hash)), nil
 }
 
+func fileComputeSHA256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return computeSHA256(f)
+}
+
 func gunzipAndComputeSHA256(r io.Reader) (string, error) {
 	f, err := gzip.NewReader(r)
 	if err != nil {
@@ -22,6 +22,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -35,6 +37,14 @@ var (
 		"sha256:B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9",
 	}, "\r\n")
 
+	cveBadHashMetaFile = strings.Join([]string{
+		"lastModifiedDate:2018-03-16T23:05:50-04:00",
+		"size:11",
+		"zipSize:169",
+		"gzSize:33",
+		"sha256:0000000000000000000000000000000000000000000000000000000000000000",
+	}, "\r\n")
+
 	cveGoldenDataFileGz = []byte{
 		0x1f, 0x8b, 0x08, 0x08, 0x42, 0x4d, 0xac, 0x5a, 0x02, 0x03, 0x66, 0x00,
 		0xcb, 0x48, 0xcd, 0xc9, 0xc9, 0x57, 0x28, 0xcf, 0x2f, 0xca, 0x49, 0x01,
@@ -92,15 +102,217 @@ func TestCVE(t *testing.T) {
 	}
 }
 
+func TestCVEVerify(t *testing.T) {
+	cases := []struct {
+		name     string
+		meta     string
+		noVerify bool
+		wantErr  string
+	}{
+		{"MatchingHash", cveGoldenMetaFile, false, ""},
+		{"MismatchingHash", cveBadHashMetaFile, false, "sha256 mismatch"},
+		{"MismatchingHashNoVerify", cveBadHashMetaFile, true, ""},
+	}
+
+	for _, c := range cases {
+		for _, compression := range []string{"gz", "zip"} {
+			t.Run(c.name+"/"+compression, func(t *testing.T) {
+				td, err := ioutil.TempDir("", "nvdsync-")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer os.RemoveAll(td)
+
+				handler := &cveTestServer{compression: compression, meta: c.meta}
+				ts, src := httptestNewServer(handler)
+				defer ts.Close()
+				src.NoVerify = c.noVerify
+
+				cve := cve10jsonGz
+				if compression == "zip" {
+					cve = cve10jsonZip
+				}
+				f := cveFileList(cve)[0]
+				err = f.Sync(context.Background(), src, td)
+				if c.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+						t.Fatalf("want error containing %q, have %v", c.wantErr, err)
+					}
+					if _, err := os.Stat(filepath.Join(td, f.DataFile)); !os.IsNotExist(err) {
+						t.Fatalf("data file %q should not have been written", f.DataFile)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := os.Stat(filepath.Join(td, f.DataFile)); err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+func TestCVESkipUnchanged(t *testing.T) {
+	td, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+
+	handler := &cveTestServer{compression: "gz"}
+	ts, src := httptestNewServer(handler)
+	defer ts.Close()
+
+	f := cveFileList(cve10jsonGz)[0]
+	if err = f.Sync(context.Background(), src, td); err != nil {
+		t.Fatal(err)
+	}
+	if handler.downloads != 1 {
+		t.Fatalf("first sync: want 1 data download, have %d", handler.downloads)
+	}
+	if _, err = os.Stat(filepath.Join(td, f.MetaFile)); err != nil {
+		t.Fatalf("meta file was not persisted: %v", err)
+	}
+
+	// local meta and data match the remote meta: no download
+	if err = f.Sync(context.Background(), src, td); err != nil {
+		t.Fatal(err)
+	}
+	if handler.downloads != 1 {
+		t.Fatalf("second sync: want 1 data download, have %d", handler.downloads)
+	}
+
+	// remote meta changed: download again
+	handler.meta = strings.Replace(cveGoldenMetaFile, "2018-03-16", "2018-03-17", 1)
+	if err = f.Sync(context.Background(), src, td); err != nil {
+		t.Fatal(err)
+	}
+	if handler.downloads != 2 {
+		t.Fatalf("third sync: want 2 data downloads, have %d", handler.downloads)
+	}
+}
+
+func TestCVEIncremental(t *testing.T) {
+	td, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+
+	handler := &cveTestServer{compression: "gz"}
+	ts, src := httptestNewServer(handler)
+	defer ts.Close()
+
+	if err = cve10jsonGz.Incremental().Sync(context.Background(), src, td); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"nvdcve-1.0-modified.json.gz",
+		"nvdcve-1.0-modified.meta",
+		"nvdcve-1.0-recent.json.gz",
+		"nvdcve-1.0-recent.meta",
+	}
+	fi, err := ioutil.ReadDir(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := make([]string, len(fi))
+	for i := range fi {
+		have[i] = fi[i].Name()
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Fatalf("unexpected files in local dir:\nwant: %q\nhave: %q", want, have)
+	}
+}
+
+func TestCVECompress(t *testing.T) {
+	cases := []struct {
+		compress Compression
+		stored   map[string]string // published compression to stored data file
+	}{
+		{CompressAsPublished, map[string]string{"gz": "nvdcve-1.0-2002.json.gz", "zip": "nvdcve-1.0-2002.json.zip"}},
+		{CompressGzip, map[string]string{"gz": "nvdcve-1.0-2002.json.gz", "zip": "nvdcve-1.0-2002.json.gz"}},
+		{CompressNone, map[string]string{"gz": "nvdcve-1.0-2002.json", "zip": "nvdcve-1.0-2002.json"}},
+	}
+
+	for _, c := range cases {
+		for _, compression := range []string{"gz", "zip"} {
+			t.Run(fmt.Sprintf("%q/%s", c.compress, compression), func(t *testing.T) {
+				td, err := ioutil.TempDir("", "nvdsync-")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer os.RemoveAll(td)
+
+				handler := &cveTestServer{compression: compression}
+				ts, src := httptestNewServer(handler)
+				defer ts.Close()
+				src.Compress = c.compress
+
+				cve := cve10jsonGz
+				if compression == "zip" {
+					cve = cve10jsonZip
+				}
+				f := cveFileList(cve)[0]
+				for i := 0; i < 2; i++ {
+					if err = f.Sync(context.Background(), src, td); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if handler.downloads != 1 {
+					t.Fatalf("stored data file was not verified: want 1 data download, have %d", handler.downloads)
+				}
+
+				name := filepath.Join(td, c.stored[compression])
+				r, err := openDecompressed(filepath.Ext(name)[1:], name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer r.Close()
+				b, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := "hello world"; string(b) != want {
+					t.Fatalf("unexpected stored data: want %q, have %q", want, b)
+				}
+			})
+		}
+	}
+}
+
+func TestCompressionSet(t *testing.T) {
+	var c Compression
+	for _, v := range []string{"gzip", "none", ""} {
+		if err := c.Set(v); err != nil || c != Compression(v) {
+			t.Fatalf("Set(%q): have %q, %v", v, c, err)
+		}
+	}
+	for _, v := range []string{"zstd", "gz", "bzip2"} {
+		if err := c.Set(v); err == nil {
+			t.Fatalf("Set(%q): want error", v)
+		}
+	}
+}
+
 type cveTestServer struct {
 	compression string
+	meta        string // defaults to cveGoldenMetaFile
+	downloads   int    // number of data files served
 }
 
-func (ts cveTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (ts *cveTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if strings.HasSuffix(r.URL.Path, ".meta") {
-		io.Copy(w, bytes.NewBufferString(cveGoldenMetaFile))
+		meta := ts.meta
+		if meta == "" {
+			meta = cveGoldenMetaFile
+		}
+		io.Copy(w, bytes.NewBufferString(meta))
 		return
 	}
+	ts.downloads++
 	switch ts.compression {
 	case "gz":
 		io.Copy(w, bytes.NewBuffer(cveGoldenDataFileGz))
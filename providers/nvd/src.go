@@ -18,6 +18,8 @@ import (
 	"flag"
 	"os"
 	"reflect"
+
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
 // SourceConfig is the configuration of the NVD data feed source.
@@ -26,6 +28,17 @@ type SourceConfig struct {
 	Host        string `envconfig:"NVDSYNC_HOST" default:"nvd.nist.gov"`
 	CVEFeedPath string `envconfig:"NVDSYNC_CVE_FEED_PATH" default:"/feeds/{{.Encoding}}/cve/{{.Version}}/"`
 	CPEFeedPath string `envconfig:"NVDSYNC_CPE_FEED_PATH" default:"/feeds/xml/cpe/dictionary/"`
+
+	// NoVerify disables checking downloaded CVE feeds against the
+	// size and sha256 published in their .meta files.
+	NoVerify bool
+
+	// Compress is the format CVE feeds are stored in locally,
+	// as published by default. Verification is done on their decompressed data.
+	Compress Compression
+
+	// Logger, if set, receives the progress of the sync and its warnings, they are discarded otherwise.
+	Logger logging.Logger
 }
 
 // NewSourceConfig creates and initializes a new SourceConfig with values from envconfig.
@@ -44,6 +57,9 @@ func NewSourceConfig() *SourceConfig {
 	p := reflect.ValueOf(sc).Elem()
 	for i := 0; i < p.NumField(); i++ {
 		field := t.Field(i)
+		if field.Tag.Get("envconfig") == "" {
+			continue
+		}
 		value := reflect.ValueOf(valueFromStructTag(field))
 		p.Field(i).Set(value)
 	}
@@ -57,4 +73,7 @@ func (src *SourceConfig) AddFlags(fs *flag.FlagSet) {
 	flag.StringVar(&src.Host, "src_host", src.Host, "source host\nenv: NVDSYNC_HOST")
 	flag.StringVar(&src.CVEFeedPath, "src_cve_feed_path", src.CVEFeedPath, "source path for CVE feeds\nenv: NVDSYNC_CVE_FEED_PATH")
 	flag.StringVar(&src.CPEFeedPath, "src_cpe_feed_path", src.CPEFeedPath, "source path for CPE feeds\nenv: NVDSYNC_CPE_FEED_PATH")
+	flag.BoolVar(&src.NoVerify, "no_verify", src.NoVerify, "do not verify size and sha256 of downloaded CVE feeds against their meta files")
+	flag.Var(flag.Lookup("no_verify").Value, "no-verify", "same as -no_verify")
+	flag.Var(&src.Compress, "compress", "store CVE feeds locally compressed with gzip, or decompressed with none (default: as published)")
 }
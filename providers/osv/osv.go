@@ -0,0 +1,331 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package osv provides a converter for OSV (https://osv.dev) advisories to nvd.
+package osv
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss2"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+
+	"github.com/pkg/errors"
+)
+
+// Convert scans a directory recursively for OSV JSON records and converts them to NVD CVE JSON 1.0 format.
+func Convert(dir string) (*schema.NVDCVEFeedJSON10, error) {
+	feed := &schema.NVDCVEFeedJSON10{}
+
+	walker := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		cve, err := ConvertAdvisory(f)
+		if err != nil {
+			return errors.Wrapf(err, "error parsing file: %s", path)
+		}
+		feed.CVEItems = append(feed.CVEItems, cve)
+		return nil
+	}
+
+	if err := filepath.Walk(dir, walker); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// ConvertAdvisory converts the OSV JSON record from r to NVD CVE JSON 1.0 format.
+//
+// Affected packages are mapped to CPEs by PackageCPE, e.g. npm package lodash is
+// cpe:2.3:a:lodash:lodash:*:*:*:*:*:node.js:*:*, the same as pkg:npm/lodash is translated by purl.ToCPE. ECOSYSTEM and SEMVER ranges become version bounds of
+// the cpe_match entries, GIT ranges are ignored; the explicitly listed versions are only used when
+// a package has no such ranges. CVSS vectors of the record are scored; vectors which can't be parsed are ignored.
+func ConvertAdvisory(r io.Reader) (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+	var item advisory
+	if err := json.NewDecoder(r).Decode(&item); err != nil {
+		return nil, errors.Wrap(err, "cannot decode OSV record")
+	}
+	return item.Convert()
+}
+
+// advisory is the OSV schema, only the fields used by the converter.
+// Ref: https://ossf.github.io/osv-schema/
+type advisory struct {
+	ID         string      `json:"id"`
+	Modified   string      `json:"modified"`
+	Published  string      `json:"published"`
+	Aliases    []string    `json:"aliases"`
+	Summary    string      `json:"summary"`
+	Details    string      `json:"details"`
+	Severity   []severity  `json:"severity"`
+	Affected   []affected  `json:"affected"`
+	References []reference `json:"references"`
+}
+
+type severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []affectedRange `json:"ranges"`
+	Versions []string        `json:"versions"`
+}
+
+type affectedRange struct {
+	Type   string  `json:"type"`
+	Events []event `json:"events"`
+}
+
+type event struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+type reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+func (item *advisory) Convert() (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+	if item.ID == "" {
+		return nil, errors.New("OSV record has no id")
+	}
+
+	modified, err := osvTimeToNVD(item.Modified)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed modified date of %s", item.ID)
+	}
+	published, err := osvTimeToNVD(item.Published)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed published date of %s", item.ID)
+	}
+
+	conf, err := item.newConfigurations()
+	if err != nil {
+		return nil, err
+	}
+
+	description := item.Details
+	if description == "" {
+		description = item.Summary
+	}
+
+	cve := &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{
+				ID:       item.ID,
+				ASSIGNER: "osv.dev",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &schema.CVEJSON40Description{
+				DescriptionData: []*schema.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: description,
+					},
+				},
+			},
+			References: item.newReferences(),
+		},
+		Configurations:   conf,
+		Impact:           item.newImpact(),
+		LastModifiedDate: modified,
+		PublishedDate:    published,
+	}
+
+	return cve, nil
+}
+
+func osvTimeToNVD(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(schema.TimeLayout), nil
+}
+
+func (item *advisory) newReferences() *schema.CVEJSON40References {
+	if len(item.Aliases) == 0 && len(item.References) == 0 {
+		return nil
+	}
+	refs := &schema.CVEJSON40References{
+		ReferenceData: make([]*schema.CVEJSON40Reference, 0, len(item.Aliases)+len(item.References)),
+	}
+	for _, alias := range item.Aliases {
+		refs.ReferenceData = append(refs.ReferenceData, &schema.CVEJSON40Reference{Name: alias})
+	}
+	for _, ref := range item.References {
+		refs.ReferenceData = append(refs.ReferenceData, &schema.CVEJSON40Reference{Name: ref.URL, URL: ref.URL})
+	}
+	return refs
+}
+
+func (item *advisory) newImpact() *schema.NVDCVEFeedJSON10DefImpact {
+	var impact schema.NVDCVEFeedJSON10DefImpact
+	for _, sev := range item.Severity {
+		switch sev.Type {
+		case "CVSS_V3":
+			v, err := cvss3.VectorFromString(sev.Score)
+			if err != nil || v.Validate() != nil {
+				continue
+			}
+			score := v.BaseScore()
+			impact.BaseMetricV3 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+				CVSSV3: &schema.CVSSV30{
+					BaseScore:    score,
+					BaseSeverity: cvss3.Severity(score),
+					VectorString: sev.Score,
+					Version:      v.Version.String(),
+				},
+			}
+		case "CVSS_V2":
+			v, err := cvss2.VectorFromStringStrict(sev.Score)
+			if err != nil {
+				continue
+			}
+			score := v.BaseScore()
+			impact.BaseMetricV2 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+				CVSSV2: &schema.CVSSV20{
+					BaseScore:    score,
+					VectorString: sev.Score,
+					Version:      "2.0",
+				},
+				Severity: cvss2.Severity(score),
+			}
+		}
+	}
+	if impact.BaseMetricV2 == nil && impact.BaseMetricV3 == nil {
+		return nil
+	}
+	return &impact
+}
+
+func (item *advisory) newConfigurations() (*schema.NVDCVEFeedJSON10DefConfigurations, error) {
+	node := &schema.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, aff := range item.Affected {
+		matches, err := aff.cpeMatches()
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot convert affected package of %s", item.ID)
+		}
+		node.CPEMatch = append(node.CPEMatch, matches...)
+	}
+	return &schema.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: "4.0",
+		Nodes:          []*schema.NVDCVEFeedJSON10DefNode{node},
+	}, nil
+}
+
+// PackageCPE returns the CPE name of the package in OSV ecosystem, as used by ConvertAdvisory.
+// It's named by purl.PackageCPE, the same way as the Package URL of the package is translated by purl.ToCPE.
+// Other providers of package advisories should use it to generate the same names for the same packages.
+func PackageCPE(ecosystem, name string) (wfn.Attributes, error) {
+	cpe, err := purl.PackageCPE(ecosystem, name)
+	if err != nil {
+		return wfn.Attributes{}, err
+	}
+	return *cpe, nil
+}
+
+// cpeMatches converts the affected ranges of a package into cpe_match entries
+func (aff *affected) cpeMatches() ([]*schema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	cpe, err := PackageCPE(aff.Package.Ecosystem, aff.Package.Name)
+	if err != nil {
+		return nil, err
+	}
+	newMatch := func(cpe wfn.Attributes) *schema.NVDCVEFeedJSON10DefCPEMatch {
+		return &schema.NVDCVEFeedJSON10DefCPEMatch{
+			CPEName: []*schema.NVDCVEFeedJSON10DefCPEName{
+				{
+					Cpe22Uri: cpe.BindToURI(),
+					Cpe23Uri: cpe.BindToFmtString(),
+				},
+			},
+			Cpe23Uri:   cpe.BindToFmtString(),
+			Vulnerable: true,
+		}
+	}
+
+	var matches []*schema.NVDCVEFeedJSON10DefCPEMatch
+	for _, r := range aff.Ranges {
+		if r.Type != "ECOSYSTEM" && r.Type != "SEMVER" {
+			continue
+		}
+		// events are sorted: introduced opens a range, fixed or last_affected closes it
+		var open *schema.NVDCVEFeedJSON10DefCPEMatch
+		for _, e := range r.Events {
+			switch {
+			case e.Introduced != "":
+				if open == nil {
+					open = newMatch(cpe)
+				}
+				if e.Introduced != "0" {
+					open.VersionStartIncluding = e.Introduced
+				}
+			case e.Fixed != "" && open != nil:
+				open.VersionEndExcluding = e.Fixed
+				matches = append(matches, open)
+				open = nil
+			case e.LastAffected != "" && open != nil:
+				open.VersionEndIncluding = e.LastAffected
+				matches = append(matches, open)
+				open = nil
+			}
+		}
+		if open != nil {
+			matches = append(matches, open)
+		}
+	}
+	if len(matches) != 0 {
+		return matches, nil
+	}
+
+	for _, version := range aff.Versions {
+		wfnver, err := wfn.WFNize(version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot wfn-ize version: %q", version)
+		}
+		cpe.Version = wfnver
+		matches = append(matches, newMatch(cpe))
+	}
+	return matches, nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package osv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestConvertAdvisory(t *testing.T) {
+	cases := []struct {
+		file     string
+		cve      string
+		score    float64
+		severity string
+		match    []string
+		noMatch  []string
+	}{
+		{
+			file:     "GHSA-p6mc-m468-83gw.json",
+			cve:      "CVE-2020-8203",
+			score:    7.4,
+			severity: "HIGH",
+			match:    []string{"cpe:2.3:a:lodash:lodash:3.7.0:*:*:*:*:node.js", "cpe:2.3:a:lodash:lodash:4.17.15:*:*:*:*:node.js"},
+			noMatch:  []string{"cpe:2.3:a:lodash:lodash:3.6.9:*:*:*:*:node.js", "cpe:2.3:a:lodash:lodash:4.17.19:*:*:*:*:node.js", "cpe:2.3:a:lodash:lodash:4.17.15:*:*:*:*:python"},
+		},
+		{
+			file:     "GHSA-q2q7-5pp4-w6pg.json",
+			cve:      "CVE-2021-33503",
+			score:    7.5,
+			severity: "HIGH",
+			match:    []string{"cpe:2.3:a:urllib3:urllib3:0.3:*:*:*:*:python", "cpe:2.3:a:urllib3:urllib3:1.26.4:*:*:*:*:python"},
+			noMatch:  []string{"cpe:2.3:a:urllib3:urllib3:1.26.5:*:*:*:*:python", "cpe:2.3:a:urllib3:urllib3:2.0.0:*:*:*:*:python"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", c.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			item, err := ConvertAdvisory(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vuln := nvd.ToVuln(item)
+			if cves := vuln.CVEs(); len(cves) != 1 || cves[0] != c.cve {
+				t.Errorf("expected CVEs [%s], got %v", c.cve, cves)
+			}
+			if score := vuln.CVSSv3BaseScore(); score != c.score {
+				t.Errorf("expected CVSS v3 score %.1f, got %.1f", c.score, score)
+			}
+			if severity := vuln.CVSSv3Severity(); severity != c.severity {
+				t.Errorf("expected severity %q, got %q", c.severity, severity)
+			}
+			for _, uri := range c.match {
+				if !matches(t, vuln, uri) {
+					t.Errorf("%s should match", uri)
+				}
+			}
+			for _, uri := range c.noMatch {
+				if matches(t, vuln, uri) {
+					t.Errorf("%s should not match", uri)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertAdvisoryVersions(t *testing.T) {
+	// packages without ECOSYSTEM or SEMVER ranges fall back to the listed versions
+	record := `{
+  "id": "OSV-TEST-1",
+  "modified": "2021-01-01T00:00:00.123Z",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "github.com/acme/widget"},
+    "ranges": [{"type": "GIT", "repo": "https://github.com/acme/widget", "events": [{"introduced": "0"}, {"fixed": "abc123"}]}],
+    "versions": ["1.0.0", "1.0.1"]
+  }]
+}`
+	item, err := ConvertAdvisory(bytes.NewBufferString(record))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.LastModifiedDate != "2021-01-01T00:00Z" {
+		t.Errorf("unexpected modified date %q", item.LastModifiedDate)
+	}
+	if item.Impact != nil {
+		t.Errorf("expected no impact, got %+v", item.Impact)
+	}
+	vuln := nvd.ToVuln(item)
+	if !matches(t, vuln, "cpe:2.3:a:acme:widget:1.0.1:*:*:*:*:go") {
+		t.Error("listed version should match")
+	}
+	if matches(t, vuln, "cpe:2.3:a:acme:widget:1.0.2:*:*:*:*:go") {
+		t.Error("unlisted version should not match")
+	}
+
+	for name, record := range map[string]string{
+		"no id":      `{"affected": []}`,
+		"bad date":   `{"id": "OSV-TEST-2", "modified": "yesterday"}`,
+		"no package": `{"id": "OSV-TEST-3", "affected": [{"package": {"ecosystem": "npm"}}]}`,
+		"not a json": `id: OSV-TEST-4`,
+	} {
+		if _, err := ConvertAdvisory(bytes.NewBufferString(record)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// Package URLs translated by purl.ToCPE match the converted records of their packages
+func TestConvertAdvisoryPackageURL(t *testing.T) {
+	cases := []struct {
+		file    string
+		match   []string
+		noMatch []string
+	}{
+		{
+			file:    "GHSA-p6mc-m468-83gw.json",
+			match:   []string{"pkg:npm/lodash@4.17.15", "pkg:npm/lodash@3.7.0"},
+			noMatch: []string{"pkg:npm/lodash@4.17.19", "pkg:pypi/lodash@4.17.15", "pkg:npm/%40types/lodash@4.17.15"},
+		},
+		{
+			file:    "GHSA-q2q7-5pp4-w6pg.json",
+			match:   []string{"pkg:pypi/urllib3@1.26.4", "pkg:pypi/URLLib3@1.26.4"},
+			noMatch: []string{"pkg:pypi/urllib3@1.26.5", "pkg:npm/urllib3@1.26.4"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", c.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			item, err := ConvertAdvisory(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vuln := nvd.ToVuln(item)
+			for _, p := range c.match {
+				if !matches(t, vuln, purlCPE(t, p)) {
+					t.Errorf("%s should match", p)
+				}
+			}
+			for _, p := range c.noMatch {
+				if matches(t, vuln, purlCPE(t, p)) {
+					t.Errorf("%s should not match", p)
+				}
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	feed, err := Convert("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.CVEItems) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.CVEItems))
+	}
+}
+
+func matches(t *testing.T, vuln *nvd.Vuln, uri string) bool {
+	attr, err := wfn.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(vuln.Match([]*wfn.Attributes{attr}, false)) != 0
+}
+
+// purlCPE returns the formatted string of the CPE name of Package URL p
+func purlCPE(t *testing.T, p string) string {
+	attr, err := purl.ToCPE(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attr.BindToFmtString()
+}
@@ -18,7 +18,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"sync"
@@ -27,6 +26,7 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/download"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 	"github.com/facebookincubator/nvdtools/providers/rbs/schema"
@@ -40,6 +40,7 @@ type Client struct {
 	client    *http.Client
 	baseURL   string
 	userAgent string
+	logger    logging.Logger
 }
 
 func NewClient(clientID, clientSecret, tokenURL, baseURL, userAgent string) (*Client, error) {
@@ -60,11 +61,18 @@ func NewClient(clientID, clientSecret, tokenURL, baseURL, userAgent string) (*Cl
 		client:    conf.Client(ctx),
 		baseURL:   baseURL,
 		userAgent: userAgent,
+		logger:    logging.Nop,
 	}
 
 	return &c, nil
 }
 
+// SetLogger sets the logger the sync progress and the failed requests are reported to; nothing is logged by default
+func (c *Client) SetLogger(logger logging.Logger) *Client {
+	c.logger = logging.OrNop(logger)
+	return c
+}
+
 func (c *Client) FetchAllVulnerabilitiesAfterVulndbID(vulndbID int) (<-chan runner.Convertible, error) {
 	u := fmt.Sprintf("%d/find_next_to_vulndb_id_full", vulndbID)
 
@@ -107,7 +115,7 @@ func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runn
 	numPages := (totalVulns-1)/pageSize + 1
 
 	// fetch pages concurrently
-	log.Printf("starting sync for %d vulnerabilities over %d pages\n", totalVulns, numPages)
+	c.logger.Infof("starting sync for %d vulnerabilities over %d pages", totalVulns, numPages)
 	wg := sync.WaitGroup{}
 	for page := 1; page <= numPages; page++ {
 		page := page
@@ -116,7 +124,7 @@ func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runn
 			defer wg.Done()
 			result, err := fetch(page, pageSize)
 			if err != nil {
-				log.Printf("failed to get page %d: %v", page, err)
+				c.logger.Errorf("failed to get page %d: %v", page, err)
 				return
 			}
 			for _, vuln := range result.Vulnerabilities {
@@ -16,10 +16,10 @@ package schema
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -29,6 +29,11 @@ const (
 )
 
 func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return item.ConvertWithLogger(logging.Nop)
+}
+
+// ConvertWithLogger is like Convert, but it reports the CPEs it can't convert to log
+func (item *Vulnerability) ConvertWithLogger(log logging.Logger) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 	lastModifiedDate, err := convertTime(item.VulndbLastModified)
 	if err != nil {
 		return nil, fmt.Errorf("can't convert last modified date: %v", err)
@@ -60,7 +65,7 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 			Problemtype: &nvd.CVEJSON40Problemtype{},
 			References:  item.makeReferences(),
 		},
-		Configurations:   item.makeConfigurations(),
+		Configurations:   item.makeConfigurations(log),
 		Impact:           impact,
 		LastModifiedDate: lastModifiedDate,
 		PublishedDate:    publishedDate,
@@ -94,7 +99,7 @@ func (item *Vulnerability) makeReferences() *nvd.CVEJSON40References {
 	}
 }
 
-func (item *Vulnerability) makeConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+func (item *Vulnerability) makeConfigurations(log logging.Logger) *nvd.NVDCVEFeedJSON10DefConfigurations {
 	var matches []*nvd.NVDCVEFeedJSON10DefCPEMatch
 
 	for _, vendor := range item.Vendors {
@@ -106,7 +111,7 @@ func (item *Vulnerability) makeConfigurations() *nvd.NVDCVEFeedJSON10DefConfigur
 				for _, cpe := range version.CPEs {
 					c, err := normalizeCPE(cpe.CPE)
 					if err != nil {
-						log.Printf("couldn't normalize cpe %q: %v", cpe.CPE, err)
+						log.Warnf("couldn't normalize cpe %q: %v", cpe.CPE, err)
 						continue
 					}
 					match := &nvd.NVDCVEFeedJSON10DefCPEMatch{
@@ -18,11 +18,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/download"
 	"github.com/facebookincubator/nvdtools/providers/snyk/schema"
 )
@@ -32,6 +32,7 @@ type Client struct {
 	userAgent  string
 	consumerID string
 	secret     string
+	logger     logging.Logger
 }
 
 func NewClient(baseURL, userAgent, consumerID, secret string) Client {
@@ -40,9 +41,16 @@ func NewClient(baseURL, userAgent, consumerID, secret string) Client {
 		secret:     secret,
 		baseURL:    baseURL,
 		userAgent:  userAgent,
+		logger:     logging.Nop,
 	}
 }
 
+// SetLogger sets the logger the undecodable responses are reported to; nothing is logged by default
+func (c *Client) SetLogger(logger logging.Logger) *Client {
+	c.logger = logging.OrNop(logger)
+	return c
+}
+
 func (c *Client) FetchAllVulnerabilities(since int64) (<-chan *schema.Advisory, error) {
 	// since is ignored, always download all from snyk
 	content, err := c.get("vulnerabilities.json")
@@ -56,7 +64,7 @@ func (c *Client) FetchAllVulnerabilities(since int64) (<-chan *schema.Advisory,
 		defer content.Close()
 		var advisories schema.Advisories
 		if err := json.NewDecoder(content).Decode(&advisories); err != nil {
-			log.Printf("can't decode content into advisories: %v", err)
+			c.logger.Errorf("can't decode content into advisories: %v", err)
 			return
 		}
 		for _, advs := range advisories {
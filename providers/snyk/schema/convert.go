@@ -15,9 +15,11 @@
 package schema
 
 import (
-	"log"
+	"strings"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -26,6 +28,11 @@ const (
 )
 
 func (advisory *Advisory) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return advisory.ConvertWithLogger(logging.Nop)
+}
+
+// ConvertWithLogger is like Convert, but it reports the versions and times it can't convert to log
+func (advisory *Advisory) ConvertWithLogger(log logging.Logger) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 	nvdItem := nvd.NVDCVEFeedJSON10DefCVEItem{
 		CVE: &nvd.CVEJSON40{
 			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
@@ -46,17 +53,10 @@ func (advisory *Advisory) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 			Problemtype: advisory.newProblemType(),
 			References:  advisory.newReferences(),
 		},
-		Configurations: advisory.newConfigurations(),
-		Impact: &nvd.NVDCVEFeedJSON10DefImpact{
-			BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
-				CVSSV3: &nvd.CVSSV30{
-					VectorString: advisory.CVSSV3,
-					BaseScore:    advisory.CvssScore,
-				},
-			},
-		},
-		LastModifiedDate: snykTimeToNVD(advisory.ModificationTime),
-		PublishedDate:    snykTimeToNVD(advisory.PublicationTime),
+		Configurations:   advisory.newConfigurations(log),
+		Impact:           advisory.newImpact(),
+		LastModifiedDate: snykTimeToNVD(advisory.ModificationTime, log),
+		PublishedDate:    snykTimeToNVD(advisory.PublicationTime, log),
 	}
 
 	return &nvdItem, nil
@@ -86,8 +86,44 @@ func (advisory *Advisory) newProblemType() *nvd.CVEJSON40Problemtype {
 	return pt
 }
 
+// newImpact converts CVSS v3 vector and score of the advisory; the score is computed from the vector
+// if Snyk didn't provide it. Snyk's severity is only used when there's no score and the vector is missing or broken.
+func (advisory *Advisory) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	cvss := &nvd.CVSSV30{
+		BaseScore:    advisory.CvssScore,
+		VectorString: advisory.CVSSV3,
+	}
+	switch {
+	case advisory.CVSSV3 != "":
+		if v, err := cvss3.VectorFromString(advisory.CVSSV3); err == nil {
+			cvss.Version = v.Version.String()
+			if cvss.BaseScore == 0 && v.Validate() == nil {
+				cvss.BaseScore = v.BaseScore()
+			}
+		}
+		if cvss.BaseScore == 0 && advisory.Severity != "" {
+			// the vector is broken and there's no score to tell the severity by
+			cvss.BaseSeverity = strings.ToUpper(advisory.Severity)
+		} else {
+			cvss.BaseSeverity = cvss3.Severity(cvss.BaseScore)
+		}
+	case advisory.CvssScore != 0:
+		cvss.BaseSeverity = cvss3.Severity(cvss.BaseScore)
+	case advisory.Severity != "":
+		cvss.BaseSeverity = strings.ToUpper(advisory.Severity)
+	default:
+		return nil
+	}
+	return &nvd.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: cvss,
+		},
+	}
+}
+
+// newReferences adds the advisory URL, its references and the CVEs it's linked to
 func (advisory *Advisory) newReferences() *nvd.CVEJSON40References {
-	if len(advisory.References) == 0 {
+	if len(advisory.References) == 0 && len(advisory.Cves) == 0 && advisory.URL == "" {
 		return nil
 	}
 	nrefs := 1 + len(advisory.References) + len(advisory.Cves)
@@ -107,19 +143,21 @@ func (advisory *Advisory) newReferences() *nvd.CVEJSON40References {
 		addRef(ref.Title, ref.URL)
 	}
 	for _, cve := range advisory.Cves {
-		addRef(cve, "")
+		if cve = strings.TrimSpace(cve); cve != "" {
+			addRef(cve, "")
+		}
 	}
 	return refs
 }
 
-func (advisory *Advisory) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+func (advisory *Advisory) newConfigurations(log logging.Logger) *nvd.NVDCVEFeedJSON10DefConfigurations {
 	nodes := []*nvd.NVDCVEFeedJSON10DefNode{
 		&nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"},
 	}
 	var err error
 	var product string
 	if product, err = wfn.WFNize(advisory.Package); err != nil {
-		log.Printf("can't wfnize %q\n", advisory.Package)
+		log.Warnf("can't wfnize %q", advisory.Package)
 		product = advisory.Package
 	}
 	cpe := wfn.Attributes{Part: "a", Product: product}
@@ -128,7 +166,7 @@ func (advisory *Advisory) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurat
 	for _, versions := range advisory.VulnerableVersions {
 		vRanges, err := parseVersionRange(versions)
 		if err != nil {
-			log.Printf("could not generate configuration for item %s, vulnerable ver %q: %v", advisory.SnykID, versions, err)
+			log.Warnf("could not generate configuration for item %s, vulnerable ver %q: %v", advisory.SnykID, versions, err)
 			continue
 		}
 		for _, vRange := range vRanges {
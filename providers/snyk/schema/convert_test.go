@@ -0,0 +1,152 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+)
+
+func TestConvertCVSS(t *testing.T) {
+	var advisories Advisories
+	if err := json.Unmarshal([]byte(testAdvisories), &advisories); err != nil {
+		t.Fatalf("failed to parse advisories: %v", err)
+	}
+	cases := []struct {
+		vector   string
+		score    float64
+		severity string
+		cves     []string
+		cwes     []string
+	}{
+		{"CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H", 7.4, "HIGH", []string{"CVE-2020-8203"}, []string{"CWE-400"}},
+		// score is computed from the vector, which comes under CVSSv3 key
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, "CRITICAL", []string{"CVE-2019-0001", "CVE-2019-0002"}, nil},
+		// no vector, Snyk's severity is used
+		{"", 0, "MEDIUM", nil, []string{"CWE-79", "CWE-80"}},
+		// broken vector and no score, Snyk's severity is used
+		{"CVSS:3.1/AV:X", 0, "HIGH", nil, nil},
+	}
+	items := advisories["js"]
+	if len(items) != len(cases) {
+		t.Fatalf("expected %d advisories, got %d", len(cases), len(items))
+	}
+	for i, c := range cases {
+		t.Run(items[i].ID(), func(t *testing.T) {
+			item, err := items[i].Convert()
+			if err != nil {
+				t.Fatalf("failed to convert: %v", err)
+			}
+			vuln := nvd.ToVuln(item)
+			if got := vuln.CVSSv3Vector(); got != c.vector {
+				t.Errorf("expected vector %q, got %q", c.vector, got)
+			}
+			if got := vuln.CVSSv3BaseScore(); got != c.score {
+				t.Errorf("expected score %.1f, got %.1f", c.score, got)
+			}
+			if got := vuln.CVSSv3Severity(); got != c.severity {
+				t.Errorf("expected severity %q, got %q", c.severity, got)
+			}
+			if got := vuln.CVEs(); fmt.Sprint(got) != fmt.Sprint(c.cves) {
+				t.Errorf("expected CVEs %v, got %v", c.cves, got)
+			}
+			if got := vuln.CWEs(); fmt.Sprint(got) != fmt.Sprint(c.cwes) {
+				t.Errorf("expected CWEs %v, got %v", c.cwes, got)
+			}
+		})
+	}
+}
+
+// warningsLogger records the messages logged at warning level
+type warningsLogger []string
+
+func (l *warningsLogger) Debugf(string, ...interface{}) {}
+func (l *warningsLogger) Infof(string, ...interface{})  {}
+func (l *warningsLogger) Warnf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+func (l *warningsLogger) Errorf(string, ...interface{}) {}
+
+func TestConvertWithLogger(t *testing.T) {
+	advisory := &Advisory{
+		SnykID:             "SNYK-JS-TEST-1",
+		Package:            "test",
+		VulnerableVersions: []string{"[1.0,2.0,3.0)"},
+		ModificationTime:   "yesterday",
+		PublicationTime:    "2019-01-01T00:00:00Z",
+	}
+	var log warningsLogger
+	if _, err := advisory.ConvertWithLogger(&log); err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	if len(log) != 2 {
+		t.Fatalf("expected warnings about the version range and the time, got %q", log)
+	}
+	// Convert doesn't log anywhere
+	if _, err := advisory.Convert(); err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+}
+
+var testAdvisories = `{
+  "js": [
+    {
+      "id": "SNYK-JS-LODASH-567746",
+      "package": "lodash",
+      "title": "Prototype Pollution",
+      "url": "https://snyk.io/vuln/SNYK-JS-LODASH-567746",
+      "severity": "high",
+      "cvssV3": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H",
+      "cvssScore": 7.4,
+      "cves": ["CVE-2020-8203"],
+      "cwes": ["CWE-400"],
+      "references": [{"title": "GitHub Issue", "url": "https://github.com/lodash/lodash/issues/4744"}],
+      "vulnerableVersions": ["<4.17.19"],
+      "modificationTime": "2020-07-15T10:00:00.000000Z",
+      "publicationTime": "2020-07-15T10:00:00.000000Z"
+    },
+    {
+      "id": "SNYK-JS-FOO-1",
+      "package": "foo",
+      "severity": "low",
+      "CVSSv3": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+      "cves": ["CVE-2019-0001", "CVE-2019-0002"],
+      "vulnerableVersions": ["<1.0.0"],
+      "modificationTime": "2019-01-01T00:00:00.000000Z",
+      "publicationTime": "2019-01-01T00:00:00.000000Z"
+    },
+    {
+      "id": "SNYK-JS-BAR-2",
+      "package": "bar",
+      "severity": "medium",
+      "cwes": ["CWE-79", "CWE-80"],
+      "vulnerableVersions": [">=2.0.0 <2.1.0"],
+      "modificationTime": "2019-01-01T00:00:00.000000Z",
+      "publicationTime": "2019-01-01T00:00:00.000000Z"
+    },
+    {
+      "id": "SNYK-JS-BAZ-3",
+      "package": "baz",
+      "severity": "high",
+      "cvssV3": "CVSS:3.1/AV:X",
+      "vulnerableVersions": ["<3.0.0"],
+      "modificationTime": "2019-01-01T00:00:00.000000Z",
+      "publicationTime": "2019-01-01T00:00:00.000000Z"
+    }
+  ]
+}`
@@ -15,10 +15,10 @@
 package schema
 
 import (
-	"log"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
 var snykLayouts = []string{
@@ -26,7 +26,7 @@ var snykLayouts = []string{
 	"2006-01-02T15:04:05.000000Z",
 }
 
-func snykTimeToNVD(s string) string {
+func snykTimeToNVD(s string, log logging.Logger) string {
 	var t time.Time
 	var err error
 
@@ -37,6 +37,6 @@ func snykTimeToNVD(s string) string {
 		}
 	}
 
-	log.Printf("cannot parse snyk time: %v", err)
+	log.Warnf("cannot parse snyk time: %v", err)
 	return s
 }
@@ -0,0 +1,372 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ubuntu provides a converter for Ubuntu OVAL feeds to nvd.
+//
+// It reads the OVAL definitions published for every Ubuntu release at
+// https://security-metadata.canonical.com/oval/, e.g. com.ubuntu.jammy.usn.oval.xml:
+// the Ubuntu Security Notices (USN) with the versions of the binary packages which fix them,
+// and the CVEs they address. Ubuntu backports the fixes, so matching the installed packages against
+// the converted feed is more accurate than against the NVD version ranges.
+package ubuntu
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/providers/debian"
+
+	"github.com/pkg/errors"
+)
+
+// ovalDefinitions is the OVAL definitions document, only the parts used by the converter.
+// Ref: https://oval.mitre.org/language/version5.11/ovaldefinition/documentation/oval-definitions-schema.html
+type ovalDefinitions struct {
+	Definitions []definition       `xml:"definitions>definition"`
+	Tests       []dpkgTest         `xml:"tests>dpkginfo_test"`
+	Objects     []dpkgObject       `xml:"objects>dpkginfo_object"`
+	States      []dpkgState        `xml:"states>dpkginfo_state"`
+	Variables   []constantVariable `xml:"variables>constant_variable"`
+}
+
+type definition struct {
+	ID       string   `xml:"id,attr"`
+	Class    string   `xml:"class,attr"`
+	Metadata metadata `xml:"metadata"`
+	Criteria criteria `xml:"criteria"`
+}
+
+type metadata struct {
+	Title       string          `xml:"title"`
+	Description string          `xml:"description"`
+	References  []ovalReference `xml:"reference"`
+	Advisory    struct {
+		Issued struct {
+			Date string `xml:"date,attr"`
+		} `xml:"issued"`
+		CVEs []advisoryCVE `xml:"cve"`
+	} `xml:"advisory"`
+}
+
+type ovalReference struct {
+	Source string `xml:"source,attr"`
+	RefID  string `xml:"ref_id,attr"`
+	RefURL string `xml:"ref_url,attr"`
+}
+
+type advisoryCVE struct {
+	ID         string `xml:",chardata"`
+	Href       string `xml:"href,attr"`
+	CVSSVector string `xml:"cvss_vector,attr"`
+}
+
+type criteria struct {
+	Criteria   []criteria `xml:"criteria"`
+	Criterions []struct {
+		TestRef string `xml:"test_ref,attr"`
+	} `xml:"criterion"`
+}
+
+type dpkgTest struct {
+	ID     string `xml:"id,attr"`
+	Object struct {
+		Ref string `xml:"object_ref,attr"`
+	} `xml:"object"`
+	State struct {
+		Ref string `xml:"state_ref,attr"`
+	} `xml:"state"`
+}
+
+type dpkgObject struct {
+	ID   string `xml:"id,attr"`
+	Name struct {
+		VarRef string `xml:"var_ref,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"name"`
+}
+
+type dpkgState struct {
+	ID  string `xml:"id,attr"`
+	EVR struct {
+		Operation string `xml:"operation,attr"`
+		Value     string `xml:",chardata"`
+	} `xml:"evr"`
+}
+
+type constantVariable struct {
+	ID     string   `xml:"id,attr"`
+	Values []string `xml:"value"`
+}
+
+const issuedTimeLayout = "2006-01-02"
+
+// Convert converts the OVAL definitions of Ubuntu release read from r to NVD CVE JSON 1.0 format.
+//
+// There's an item per definition with package tests: its ID is the USN (or the CVE, in the CVE OVAL feeds),
+// and the CVEs it addresses are its references. Every package tested by the definition criteria gets
+// a cpe_match entry, vulnerable before the fixed version when the test has one, in any version otherwise;
+// the criteria operators aren't taken into account, except that the checks of the release itself are ignored.
+// The CPE names are made with debian.PackageCPE, with release as the release codename (e.g. jammy), so the
+// dictionaries loaded from the feed should compare package versions with debian.SetVersionComparators.
+func Convert(r io.Reader, release string) (*schema.NVDCVEFeedJSON10, error) {
+	var defs ovalDefinitions
+	if err := xml.NewDecoder(r).Decode(&defs); err != nil {
+		return nil, errors.Wrap(err, "cannot decode OVAL definitions")
+	}
+	c := newConverter(&defs, release)
+	feed := &schema.NVDCVEFeedJSON10{}
+	for i := range defs.Definitions {
+		item, err := c.convert(&defs.Definitions[i])
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			feed.CVEItems = append(feed.CVEItems, item)
+		}
+	}
+	return feed, nil
+}
+
+// converter resolves the references between the parts of OVAL definitions document
+type converter struct {
+	release   string
+	tests     map[string]*dpkgTest
+	objects   map[string]*dpkgObject
+	states    map[string]*dpkgState
+	variables map[string]*constantVariable
+}
+
+func newConverter(defs *ovalDefinitions, release string) *converter {
+	c := &converter{
+		release:   release,
+		tests:     make(map[string]*dpkgTest, len(defs.Tests)),
+		objects:   make(map[string]*dpkgObject, len(defs.Objects)),
+		states:    make(map[string]*dpkgState, len(defs.States)),
+		variables: make(map[string]*constantVariable, len(defs.Variables)),
+	}
+	for i := range defs.Tests {
+		c.tests[defs.Tests[i].ID] = &defs.Tests[i]
+	}
+	for i := range defs.Objects {
+		c.objects[defs.Objects[i].ID] = &defs.Objects[i]
+	}
+	for i := range defs.States {
+		c.states[defs.States[i].ID] = &defs.States[i]
+	}
+	for i := range defs.Variables {
+		c.variables[defs.Variables[i].ID] = &defs.Variables[i]
+	}
+	return c
+}
+
+// convert returns the item of the definition, or nil if the definition doesn't test any packages
+func (c *converter) convert(def *definition) (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+	id := def.itemID()
+	matches, err := c.cpeMatches(&def.Criteria)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot convert %s", id)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	var issued string
+	if date := def.Metadata.Advisory.Issued.Date; date != "" {
+		t, err := time.Parse(issuedTimeLayout, date)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed issued date of %s: %q", id, date)
+		}
+		issued = t.Format(schema.TimeLayout)
+	}
+
+	description := strings.TrimSpace(def.Metadata.Description)
+	if description == "" {
+		description = def.Metadata.Title
+	}
+
+	return &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{
+				ID:       id,
+				ASSIGNER: "Ubuntu",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &schema.CVEJSON40Description{
+				DescriptionData: []*schema.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: description,
+					},
+				},
+			},
+			References: def.newReferences(),
+		},
+		Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+			Nodes: []*schema.NVDCVEFeedJSON10DefNode{
+				{
+					Operator: "OR",
+					CPEMatch: matches,
+				},
+			},
+		},
+		Impact:           def.newImpact(),
+		LastModifiedDate: issued,
+		PublishedDate:    issued,
+	}, nil
+}
+
+// itemID returns the USN of the definition, its CVE if there's no USN, or the definition ID otherwise
+func (def *definition) itemID() string {
+	for _, source := range []string{"USN", "CVE"} {
+		for _, ref := range def.Metadata.References {
+			if ref.Source == source && ref.RefID != "" {
+				return ref.RefID
+			}
+		}
+	}
+	return def.ID
+}
+
+func (def *definition) newReferences() *schema.CVEJSON40References {
+	refs := &schema.CVEJSON40References{}
+	seen := make(map[string]bool)
+	addRef := func(name, url string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		refs.ReferenceData = append(refs.ReferenceData, &schema.CVEJSON40Reference{Name: name, URL: url})
+	}
+	for _, ref := range def.Metadata.References {
+		addRef(ref.RefID, ref.RefURL)
+	}
+	for _, cve := range def.Metadata.Advisory.CVEs {
+		addRef(strings.TrimSpace(cve.ID), cve.Href)
+	}
+	if len(refs.ReferenceData) == 0 {
+		return nil
+	}
+	return refs
+}
+
+// newImpact returns the highest scored CVSS v3 vector of the CVEs the definition addresses
+func (def *definition) newImpact() *schema.NVDCVEFeedJSON10DefImpact {
+	var impact *schema.NVDCVEFeedJSON10DefImpact
+	for _, cve := range def.Metadata.Advisory.CVEs {
+		v, err := cvss3.VectorFromString(cve.CVSSVector)
+		if err != nil || v.Validate() != nil {
+			continue
+		}
+		score := v.BaseScore()
+		if impact != nil && impact.BaseMetricV3.CVSSV3.BaseScore >= score {
+			continue
+		}
+		impact = &schema.NVDCVEFeedJSON10DefImpact{
+			BaseMetricV3: &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+				CVSSV3: &schema.CVSSV30{
+					BaseScore:    score,
+					BaseSeverity: cvss3.Severity(score),
+					VectorString: cve.CVSSVector,
+					Version:      v.Version.String(),
+				},
+			},
+		}
+	}
+	return impact
+}
+
+// cpeMatches returns the cpe_match entries of the packages tested by the criteria, recursively;
+// criteria referring to other tests than dpkginfo ones are ignored
+func (c *converter) cpeMatches(crit *criteria) ([]*schema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	var matches []*schema.NVDCVEFeedJSON10DefCPEMatch
+	for _, cr := range crit.Criterions {
+		test, ok := c.tests[cr.TestRef]
+		if !ok {
+			continue
+		}
+		ms, err := c.testMatches(test)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, ms...)
+	}
+	for i := range crit.Criteria {
+		ms, err := c.cpeMatches(&crit.Criteria[i])
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, ms...)
+	}
+	return matches, nil
+}
+
+// testMatches returns the cpe_match entries of the packages tested by dpkginfo test
+func (c *converter) testMatches(test *dpkgTest) ([]*schema.NVDCVEFeedJSON10DefCPEMatch, error) {
+	obj, ok := c.objects[test.Object.Ref]
+	if !ok {
+		return nil, errors.Errorf("test %s refers to unknown object %q", test.ID, test.Object.Ref)
+	}
+	pkgs := []string{strings.TrimSpace(obj.Name.Value)}
+	if obj.Name.VarRef != "" {
+		v, ok := c.variables[obj.Name.VarRef]
+		if !ok {
+			return nil, errors.Errorf("object %s refers to unknown variable %q", obj.ID, obj.Name.VarRef)
+		}
+		pkgs = v.Values
+	}
+
+	var endExcluding, endIncluding string
+	if test.State.Ref != "" {
+		state, ok := c.states[test.State.Ref]
+		if !ok {
+			return nil, errors.Errorf("test %s refers to unknown state %q", test.ID, test.State.Ref)
+		}
+		switch version := strings.TrimSpace(state.EVR.Value); state.EVR.Operation {
+		case "less than":
+			endExcluding = version
+		case "less than or equal":
+			endIncluding = version
+		default:
+			return nil, errors.Errorf("unsupported operation %q in state %s", state.EVR.Operation, state.ID)
+		}
+	}
+
+	matches := make([]*schema.NVDCVEFeedJSON10DefCPEMatch, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		cpe, err := debian.PackageCPE(strings.TrimSpace(pkg), c.release, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "object %s", obj.ID)
+		}
+		matches = append(matches, &schema.NVDCVEFeedJSON10DefCPEMatch{
+			CPEName: []*schema.NVDCVEFeedJSON10DefCPEName{
+				{
+					Cpe22Uri: cpe.BindToURI(),
+					Cpe23Uri: cpe.BindToFmtString(),
+				},
+			},
+			Cpe23Uri:            cpe.BindToFmtString(),
+			VersionEndExcluding: endExcluding,
+			VersionEndIncluding: endIncluding,
+			Vulnerable:          true,
+		})
+	}
+	return matches, nil
+}
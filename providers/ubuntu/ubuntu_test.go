@@ -0,0 +1,169 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ubuntu
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/providers/debian"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestConvert(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "com.ubuntu.focal.usn.oval.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	feed, err := Convert(f, "focal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the release check isn't an item
+	if len(feed.CVEItems) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.CVEItems))
+	}
+	dict := cvefeed.Dictionary{}
+	for _, item := range feed.CVEItems {
+		dict[item.CVE.CVEDataMeta.ID] = nvd.ToVuln(item)
+	}
+	debian.SetVersionComparators(dict)
+
+	cases := []struct {
+		usn       string
+		cves      []string
+		fixed     map[string]string // package -> fixed version
+		published string
+		score     float64
+		match     [][3]string // package, release, version
+		noMatch   [][3]string
+	}{
+		{
+			usn:  "USN-5549-1",
+			cves: []string{"CVE-2021-46828"},
+			fixed: map[string]string{
+				"libtirpc-common": "0:1.2.5-1ubuntu0.1",
+				"libtirpc-dev":    "0:1.2.5-1ubuntu0.1",
+				"libtirpc3":       "0:1.2.5-1ubuntu0.1",
+			},
+			published: "2022-08-04T00:00Z",
+			score:     7.5,
+			match: [][3]string{
+				{"libtirpc3", "focal", "1.2.5-1"},
+				{"libtirpc3", "", "1.2.5-1"},
+				{"libtirpc-dev", "focal", "1.2.5-1"},
+			},
+			noMatch: [][3]string{
+				{"libtirpc3", "focal", "1.2.5-1ubuntu0.1"},
+				{"libtirpc3", "focal", "1.2.5-1ubuntu1"},
+				{"libtirpc3", "jammy", "1.2.5-1"},
+				{"libtirpc", "focal", "1.2.5-1"},
+			},
+		},
+		{
+			usn:  "USN-5550-1",
+			cves: []string{"CVE-2022-2509", "CVE-2021-4209"},
+			fixed: map[string]string{
+				"libgnutls30": "0:3.6.13-2ubuntu1.7",
+			},
+			published: "2022-08-08T00:00Z",
+			score:     7.5,
+			match: [][3]string{
+				{"libgnutls30", "focal", "3.6.13-2ubuntu1.6"},
+			},
+			noMatch: [][3]string{
+				{"libgnutls30", "focal", "3.6.13-2ubuntu1.7"},
+			},
+		},
+	}
+	for i, c := range cases {
+		item := feed.CVEItems[i]
+		if id := item.CVE.CVEDataMeta.ID; id != c.usn {
+			t.Fatalf("item %d: expected %s, got %s", i, c.usn, id)
+		}
+		vuln := dict[c.usn].(*nvd.Vuln)
+		if cves := vuln.CVEs(); !reflect.DeepEqual(cves, c.cves) {
+			t.Errorf("%s: expected CVEs %v, got %v", c.usn, c.cves, cves)
+		}
+		fixed := map[string]string{}
+		for _, m := range item.Configurations.Nodes[0].CPEMatch {
+			attrs, err := wfn.Parse(m.Cpe23Uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if attrs.Other != "focal" {
+				t.Errorf("%s: expected focal release, got %s", c.usn, m.Cpe23Uri)
+			}
+			fixed[wfn.StripSlashes(attrs.Product)] = m.VersionEndExcluding
+		}
+		if !reflect.DeepEqual(fixed, c.fixed) {
+			t.Errorf("%s: expected fixed versions %v, got %v", c.usn, c.fixed, fixed)
+		}
+		if item.PublishedDate != c.published {
+			t.Errorf("%s: expected published date %s, got %s", c.usn, c.published, item.PublishedDate)
+		}
+		if score := vuln.CVSSv3BaseScore(); score != c.score {
+			t.Errorf("%s: expected CVSS v3 score %.1f, got %.1f", c.usn, c.score, score)
+		}
+		for _, m := range c.match {
+			if !matches(t, vuln, m) {
+				t.Errorf("%s: %v should match", c.usn, m)
+			}
+		}
+		for _, m := range c.noMatch {
+			if matches(t, vuln, m) {
+				t.Errorf("%s: %v shouldn't match", c.usn, m)
+			}
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	const unknownObject = `<oval_definitions>
+  <definitions>
+    <definition id="oval:com.ubuntu.focal:def:1" class="patch">
+      <criteria><criterion test_ref="oval:com.ubuntu.focal:tst:1" /></criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <dpkginfo_test id="oval:com.ubuntu.focal:tst:1"><object object_ref="oval:com.ubuntu.focal:obj:1" /></dpkginfo_test>
+  </tests>
+</oval_definitions>`
+	cases := []string{
+		`<oval_definitions>`,
+		unknownObject,
+		strings.Replace(unknownObject, "</tests>", `</tests>
+  <objects><dpkginfo_object id="oval:com.ubuntu.focal:obj:1"><name var_ref="oval:com.ubuntu.focal:var:1" /></dpkginfo_object></objects>`, 1),
+	}
+	for _, c := range cases {
+		if _, err := Convert(strings.NewReader(c), "focal"); err == nil {
+			t.Errorf("%s: expected an error", c)
+		}
+	}
+}
+
+func matches(t *testing.T, vuln *nvd.Vuln, m [3]string) bool {
+	attrs, err := debian.PackageCPE(m[0], m[1], m[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(vuln.Match([]*wfn.Attributes{attrs}, false)) != 0
+}
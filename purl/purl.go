@@ -0,0 +1,270 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package purl translates Package URLs (https://github.com/package-url/purl-spec), as emitted by SBOM tooling,
+// into CPE names which can be matched against NVD feeds.
+//
+// There's no authoritative mapping between the two, so ToCPE uses heuristics which hold for most packages:
+//
+//	type    vendor                                    product               version              target_sw
+//	maven   organization of the groupId (second       artifactId            as is                ANY
+//	        component, or the only one):
+//	        org.apache.logging.log4j -> apache
+//	npm     scope without @, or the name              name                  as is                node.js
+//	pypi    name                                      name                  as is                python
+//	golang  repository owner on github.com,           last path element,    without v prefix     go
+//	        gitlab.com and bitbucket.org, otherwise   skipping major        and +incompatible
+//	        the first label of the host:              version suffix (/v2)  suffix
+//	        golang.org/x/net -> golang
+//	rpm     name                                      name                  upstream version,    ANY
+//	deb     name                                      name                  without epoch and    ANY
+//	                                                                        release (revision)
+//
+// Vendor and product are lowercased, part is always "a" (application) and a missing version is ANY.
+// PackageCPE names the packages of OSV ecosystems the same way, so that advisories converted with it
+// can be matched against the Package URLs.
+// NVD names often differ from the package names (e.g. log4j-core is known to NVD as apache:log4j), which
+// can be taken care of with cvefeed.Dictionary.AddAlias.
+package purl
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// packageURL holds the components of a Package URL needed to build a CPE name
+type packageURL struct {
+	typ       string
+	namespace []string
+	name      string
+	version   string
+}
+
+// ToCPE translates Package URL into CPE name.
+// It returns an error if purl can't be parsed or if its package type is not supported.
+func ToCPE(purl string) (*wfn.Attributes, error) {
+	p, err := parse(purl)
+	if err != nil {
+		return nil, err
+	}
+	vendor, product, version, targetSW, ok := p.cpeFields()
+	if !ok {
+		return nil, fmt.Errorf("purl: unsupported package type %q in %q", p.typ, purl)
+	}
+	attrs, err := buildCPE(vendor, product, version, targetSW)
+	if err != nil {
+		return nil, fmt.Errorf("purl: can't translate %q: %v", purl, err)
+	}
+	return attrs, nil
+}
+
+// osvEcosystems maps the OSV ecosystems (https://ossf.github.io/osv-schema/#affectedpackage-field),
+// lowercased and without the release suffix (Debian:11), to the package types
+var osvEcosystems = map[string]string{
+	"maven":       "maven",
+	"npm":         "npm",
+	"pypi":        "pypi",
+	"go":          "golang",
+	"debian":      "deb",
+	"ubuntu":      "deb",
+	"red hat":     "rpm",
+	"almalinux":   "rpm",
+	"rocky linux": "rpm",
+}
+
+// PackageCPE returns the CPE name, with any version, of the package named as in the OSV ecosystem, e.g.
+// log4j-core of Maven is "org.apache.logging.log4j:log4j-core". Packages of the ecosystems of the supported
+// package types are named as ToCPE names their Package URLs, other ones get the ecosystem as vendor and the
+// package name as product.
+func PackageCPE(ecosystem, name string) (*wfn.Attributes, error) {
+	if name == "" {
+		return nil, fmt.Errorf("purl: package of %q ecosystem has no name", ecosystem)
+	}
+	var vendor, product, targetSW string
+	eco := strings.ToLower(ecosystem)
+	if i := strings.IndexByte(eco, ':'); i != -1 {
+		eco = eco[:i]
+	}
+	if typ, ok := osvEcosystems[eco]; ok {
+		vendor, product, _, targetSW, _ = osvPackageURL(typ, name).cpeFields()
+	} else {
+		vendor, product = ecosystem, name
+	}
+	attrs, err := buildCPE(vendor, product, "", targetSW)
+	if err != nil {
+		return nil, fmt.Errorf("purl: can't translate package %q of %q ecosystem: %v", name, ecosystem, err)
+	}
+	return attrs, nil
+}
+
+// osvPackageURL splits the name of the package in OSV ecosystem into the components of its Package URL
+func osvPackageURL(typ, name string) *packageURL {
+	p := packageURL{typ: typ, name: name}
+	sep := ""
+	switch typ {
+	case "maven":
+		sep = ":" // groupId:artifactId
+	case "npm", "golang":
+		sep = "/" // @scope/name or module path
+	}
+	if i := strings.LastIndex(name, sep); sep != "" && i != -1 {
+		p.namespace, p.name = strings.Split(name[:i], sep), name[i+1:]
+	}
+	return &p
+}
+
+// cpeFields returns the values of CPE name attributes of the package; ok is false if its type isn't supported
+func (p *packageURL) cpeFields() (vendor, product, version, targetSW string, ok bool) {
+	switch p.typ {
+	case "maven":
+		vendor, product, version = mavenVendor(p), p.name, p.version
+	case "npm":
+		vendor, product, version, targetSW = p.name, p.name, p.version, "node.js"
+		if len(p.namespace) != 0 {
+			vendor = strings.TrimPrefix(p.namespace[0], "@")
+		}
+	case "pypi":
+		vendor, product, version, targetSW = p.name, p.name, p.version, "python"
+	case "golang":
+		vendor, product = golangVendorProduct(p)
+		version, targetSW = golangVersion(p.version), "go"
+	case "rpm", "deb":
+		vendor, product, version = p.name, p.name, upstreamVersion(p.version)
+	default:
+		return "", "", "", "", false
+	}
+	return vendor, product, version, targetSW, true
+}
+
+// buildCPE builds the CPE name of an application, vendor and product are lowercased
+func buildCPE(vendor, product, version, targetSW string) (*wfn.Attributes, error) {
+	return wfn.NewBuilder().
+		Part("a").
+		Vendor(strings.ToLower(vendor)).
+		Product(strings.ToLower(product)).
+		Version(version).
+		TargetSW(targetSW).
+		Build()
+}
+
+// parse splits purl into its components; qualifiers and subpath are ignored
+func parse(purl string) (*packageURL, error) {
+	const scheme = "pkg:"
+	if len(purl) < len(scheme) || !strings.EqualFold(purl[:len(scheme)], scheme) {
+		return nil, fmt.Errorf("purl: %q: scheme must be %q", purl, scheme)
+	}
+	s := strings.TrimLeft(purl[len(scheme):], "/")
+	if i := strings.IndexByte(s, '#'); i != -1 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '?'); i != -1 {
+		s = s[:i]
+	}
+	var p packageURL
+	// the version follows the name, '@' before the last '/' starts a namespace, as in npm scopes
+	if i := strings.LastIndexByte(s, '@'); i > strings.LastIndexByte(s, '/') {
+		v, err := url.PathUnescape(s[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("purl: %q: bad version: %v", purl, err)
+		}
+		p.version = v
+		s = s[:i]
+	}
+	segments := strings.Split(strings.Trim(s, "/"), "/")
+	if len(segments) < 2 || segments[0] == "" {
+		return nil, fmt.Errorf("purl: %q: type and name are required", purl)
+	}
+	p.typ = strings.ToLower(segments[0])
+	for _, seg := range segments[1:] {
+		if seg == "" {
+			continue
+		}
+		v, err := url.PathUnescape(seg)
+		if err != nil {
+			return nil, fmt.Errorf("purl: %q: bad path segment %q: %v", purl, seg, err)
+		}
+		p.namespace = append(p.namespace, v)
+	}
+	if len(p.namespace) == 0 {
+		return nil, fmt.Errorf("purl: %q: name is required", purl)
+	}
+	p.name = p.namespace[len(p.namespace)-1]
+	p.namespace = p.namespace[:len(p.namespace)-1]
+	return &p, nil
+}
+
+// mavenVendor returns the organization of the maven groupId, e.g. apache for org.apache.logging.log4j
+func mavenVendor(p *packageURL) string {
+	if len(p.namespace) == 0 {
+		return p.name
+	}
+	group := strings.Split(p.namespace[0], ".")
+	if len(group) > 1 {
+		return group[1]
+	}
+	return group[0]
+}
+
+var goMajorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// repoHosts are the hosts where go module path is host/owner/repository
+var repoHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+}
+
+// golangVendorProduct returns vendor and product of go module (or package)
+func golangVendorProduct(p *packageURL) (vendor, product string) {
+	path := append(append([]string{}, p.namespace...), p.name)
+	if len(path) > 2 && goMajorVersion.MatchString(path[len(path)-1]) {
+		path = path[:len(path)-1]
+	}
+	product = path[len(path)-1]
+	if len(path) == 1 {
+		return product, product
+	}
+	host := path[0]
+	if repoHosts[strings.ToLower(host)] && len(path) > 2 {
+		return path[1], product
+	}
+	if i := strings.IndexByte(host, '.'); i != -1 {
+		host = host[:i]
+	}
+	return host, product
+}
+
+// golangVersion strips the v prefix and +incompatible suffix off go module version
+func golangVersion(v string) string {
+	v = strings.TrimSuffix(v, "+incompatible")
+	if len(v) > 1 && v[0] == 'v' && v[1] >= '0' && v[1] <= '9' {
+		return v[1:]
+	}
+	return v
+}
+
+// upstreamVersion strips the epoch and the release (revision) off [epoch:]version[-release] string
+func upstreamVersion(v string) string {
+	if i := strings.IndexByte(v, ':'); i != -1 {
+		v = v[i+1:]
+	}
+	if i := strings.LastIndexByte(v, '-'); i != -1 {
+		v = v[:i]
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestToCPE(t *testing.T) {
+	cases := []struct {
+		purl string
+		cpe  string
+	}{
+		// maven
+		{"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", `cpe:2.3:a:apache:log4j-core:2.14.1:*:*:*:*:*:*:*`},
+		{"pkg:maven/junit/junit@4.12?type=jar", `cpe:2.3:a:junit:junit:4.12:*:*:*:*:*:*:*`},
+		{"pkg:maven/com.fasterxml.jackson.core/jackson-databind", `cpe:2.3:a:fasterxml:jackson-databind:*:*:*:*:*:*:*:*`},
+		// npm
+		{"pkg:npm/lodash@4.17.20", `cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:node.js:*:*`},
+		{"pkg:npm/%40angular/core@11.0.5", `cpe:2.3:a:angular:core:11.0.5:*:*:*:*:node.js:*:*`},
+		{"pkg:npm/@angular/core@11.0.5", `cpe:2.3:a:angular:core:11.0.5:*:*:*:*:node.js:*:*`},
+		{"pkg:npm/@angular/core", `cpe:2.3:a:angular:core:*:*:*:*:*:node.js:*:*`},
+		// pypi
+		{"pkg:pypi/Django@3.1.4", `cpe:2.3:a:django:django:3.1.4:*:*:*:*:python:*:*`},
+		// golang
+		{"pkg:golang/github.com/gin-gonic/gin@v1.6.3", `cpe:2.3:a:gin-gonic:gin:1.6.3:*:*:*:*:go:*:*`},
+		{"pkg:golang/github.com/go-redis/redis/v8@v8.4.0", `cpe:2.3:a:go-redis:redis:8.4.0:*:*:*:*:go:*:*`},
+		{"pkg:golang/golang.org/x/net@v0.0.0-20201224014010-6772e930b67b", `cpe:2.3:a:golang:net:0.0.0-20201224014010-6772e930b67b:*:*:*:*:go:*:*`},
+		{"pkg:golang/github.com/docker/docker@v17.12.0-ce+incompatible", `cpe:2.3:a:docker:docker:17.12.0-ce:*:*:*:*:go:*:*`},
+		// rpm
+		{"pkg:rpm/fedora/curl@7.50.3-1.fc25?arch=i386&distro=fedora-25", `cpe:2.3:a:curl:curl:7.50.3:*:*:*:*:*:*:*`},
+		{"pkg:rpm/redhat/openssl@1:1.1.1k-5.el8", `cpe:2.3:a:openssl:openssl:1.1.1k:*:*:*:*:*:*:*`},
+		// deb
+		{"pkg:deb/debian/curl@7.50.3-1?arch=i386&distro=jessie", `cpe:2.3:a:curl:curl:7.50.3:*:*:*:*:*:*:*`},
+		{"pkg:deb/ubuntu/openssh-server@1:8.2p1-4ubuntu0.2", `cpe:2.3:a:openssh-server:openssh-server:8.2p1:*:*:*:*:*:*:*`},
+		// scheme and type are case-insensitive
+		{"PKG:NPM/lodash@4.17.20", `cpe:2.3:a:lodash:lodash:4.17.20:*:*:*:*:node.js:*:*`},
+	}
+	for _, c := range cases {
+		t.Run(c.purl, func(t *testing.T) {
+			attrs, err := ToCPE(c.purl)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := attrs.BindToFmtString(); got != c.cpe {
+				t.Fatalf("wrong CPE:\nhave %s\nwant %s", got, c.cpe)
+			}
+			want, err := wfn.UnbindFmtString(c.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *attrs != *want {
+				t.Fatalf("wrong attributes:\nhave %+v\nwant %+v", attrs, want)
+			}
+		})
+	}
+}
+
+func TestToCPEErrors(t *testing.T) {
+	cases := []string{
+		"pkg:gem/rails@6.1.0",
+		"pkg:docker/library/nginx@1.19",
+		"maven/junit/junit@4.12",
+		"pkg:npm",
+		"pkg:npm/@4.17.20",
+		"pkg:npm/lodash@%zz",
+	}
+	for _, c := range cases {
+		if attrs, err := ToCPE(c); err == nil {
+			t.Errorf("%q: expected an error, got %v", c, attrs)
+		}
+	}
+}
+
+func TestPackageCPE(t *testing.T) {
+	cases := []struct {
+		ecosystem, name string
+		cpe             string
+	}{
+		{"Maven", "org.apache.logging.log4j:log4j-core", `cpe:2.3:a:apache:log4j-core:*:*:*:*:*:*:*:*`},
+		{"npm", "lodash", `cpe:2.3:a:lodash:lodash:*:*:*:*:*:node.js:*:*`},
+		{"npm", "@angular/core", `cpe:2.3:a:angular:core:*:*:*:*:*:node.js:*:*`},
+		{"PyPI", "Django", `cpe:2.3:a:django:django:*:*:*:*:*:python:*:*`},
+		{"Go", "github.com/go-redis/redis/v8", `cpe:2.3:a:go-redis:redis:*:*:*:*:*:go:*:*`},
+		{"Go", "golang.org/x/net", `cpe:2.3:a:golang:net:*:*:*:*:*:go:*:*`},
+		{"Debian:11", "curl", `cpe:2.3:a:curl:curl:*:*:*:*:*:*:*:*`},
+		{"Rocky Linux:8", "openssl", `cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*`},
+		// no package type
+		{"crates.io", "Hyper", `cpe:2.3:a:crates.io:hyper:*:*:*:*:*:*:*:*`},
+	}
+	for _, c := range cases {
+		t.Run(c.ecosystem+"/"+c.name, func(t *testing.T) {
+			attrs, err := PackageCPE(c.ecosystem, c.name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := attrs.BindToFmtString(); got != c.cpe {
+				t.Fatalf("wrong CPE:\nhave %s\nwant %s", got, c.cpe)
+			}
+		})
+	}
+	if attrs, err := PackageCPE("npm", ""); err == nil {
+		t.Errorf("expected an error for a package without name, got %v", attrs)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rpm implements comparison of RPM package versions, as done by librpm.
+package rpm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// VerCmp compares two RPM version (or release) strings using the rpmvercmp algorithm of librpm.
+// It returns 0 if a and b are equal, 1 if a is newer than b and -1 if b is newer than a.
+//
+// Strings are split into alphabetic and numeric segments, all other characters are separators.
+// Numeric segments are compared as integers and are newer than alphabetic ones.
+// Tilde (~) sorts before everything, even the end of the string, so 1.0~rc1 is older than 1.0.
+// Caret (^) sorts after the end of the string but before anything else, so 1.0^git1 is newer
+// than 1.0, but older than 1.0.1.
+func VerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		// tilde sorts before everything else
+		if at(a, i) == '~' || at(b, j) == '~' {
+			if at(a, i) != '~' {
+				return 1
+			}
+			if at(b, j) != '~' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// caret is like tilde, except that the end of string sorts before it
+		if at(a, i) == '^' || at(b, j) == '^' {
+			if i == len(a) {
+				return -1
+			}
+			if j == len(b) {
+				return 1
+			}
+			if at(a, i) != '^' {
+				return 1
+			}
+			if at(b, j) != '^' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i == len(a) || j == len(b) {
+			break
+		}
+
+		// grab the segments of the same type
+		var segA, segB string
+		isNum := isDigit(a[i])
+		if isNum {
+			segA, segB = span(a, i, isDigit), span(b, j, isDigit)
+		} else {
+			segA, segB = span(a, i, isAlpha), span(b, j, isAlpha)
+		}
+		i += len(segA)
+		j += len(segB)
+
+		// numeric segments are newer than alphabetic ones
+		if segB == "" {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		if isNum {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			// whichever number has more digits wins
+			if len(segA) > len(segB) {
+				return 1
+			}
+			if len(segA) < len(segB) {
+				return -1
+			}
+		}
+
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+
+	if i == len(a) && j == len(b) {
+		return 0
+	}
+	// whichever version still has characters left over wins
+	if i == len(a) {
+		return -1
+	}
+	return 1
+}
+
+// EVRCmp compares two [epoch:]version[-release] strings the way librpm compares package versions.
+// A missing epoch is 0. Releases are compared only if both strings have one.
+// It returns 0 if a and b are equal, 1 if a is newer than b and -1 if b is newer than a.
+func EVRCmp(a, b string) int {
+	epochA, verA, relA := parseEVR(a)
+	epochB, verB, relB := parseEVR(b)
+	switch {
+	case epochA > epochB:
+		return 1
+	case epochA < epochB:
+		return -1
+	}
+	if c := VerCmp(verA, verB); c != 0 {
+		return c
+	}
+	if relA == "" || relB == "" {
+		return 0
+	}
+	return VerCmp(relA, relB)
+}
+
+// parseEVR splits [epoch:]version[-release] string into its components.
+func parseEVR(s string) (epoch int, ver, rel string) {
+	ver = s
+	if i := strings.IndexByte(ver, ':'); i != -1 {
+		if n, err := strconv.Atoi(ver[:i]); err == nil {
+			epoch = n
+			ver = ver[i+1:]
+		}
+	}
+	if i := strings.LastIndexByte(ver, '-'); i != -1 {
+		ver, rel = ver[:i], ver[i+1:]
+	}
+	return epoch, ver, rel
+}
+
+// at returns the byte at s[i] or 0 if i is out of range, like a C string terminator.
+func at(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
+
+// span returns the longest prefix of s[i:] where all bytes satisfy f.
+func span(s string, i int, f func(byte) bool) string {
+	j := i
+	for j < len(s) && f(s[j]) {
+		j++
+	}
+	return s[i:j]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
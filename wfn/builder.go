@@ -0,0 +1,110 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import "fmt"
+
+// Builder builds Attributes out of unescaped values, e.g. coming from the user input.
+// Values are taken literally: they're quoted as WFN requires (so * and ? are never wildcards) and spaces become
+// underscores. Logical values are set with Any and NA constants: they're the only values taken as they are.
+// Attributes not set are ANY.
+type Builder struct {
+	attrs Attributes
+	err   error
+}
+
+// NewBuilder creates a Builder of Attributes with all the fields set to ANY
+func NewBuilder() *Builder {
+	return &Builder{attrs: *NewAttributesWithAny()}
+}
+
+// Part sets the part attribute, which must be one of KnownParts ("a", "o" or "h") or Any
+func (b *Builder) Part(v string) *Builder {
+	if _, ok := KnownParts[v]; !ok && v != Any && b.err == nil {
+		b.err = fmt.Errorf("wfn: illegal part %q: must be one of a, o, h or ANY", v)
+	}
+	b.attrs.Part = v
+	return b
+}
+
+// Vendor sets the vendor attribute
+func (b *Builder) Vendor(v string) *Builder { return b.set("vendor", &b.attrs.Vendor, v) }
+
+// Product sets the product attribute
+func (b *Builder) Product(v string) *Builder { return b.set("product", &b.attrs.Product, v) }
+
+// Version sets the version attribute
+func (b *Builder) Version(v string) *Builder { return b.set("version", &b.attrs.Version, v) }
+
+// Update sets the update attribute
+func (b *Builder) Update(v string) *Builder { return b.set("update", &b.attrs.Update, v) }
+
+// Edition sets the edition attribute
+func (b *Builder) Edition(v string) *Builder { return b.set("edition", &b.attrs.Edition, v) }
+
+// Language sets the language attribute
+func (b *Builder) Language(v string) *Builder { return b.set("language", &b.attrs.Language, v) }
+
+// SWEdition sets the sw_edition attribute
+func (b *Builder) SWEdition(v string) *Builder { return b.set("sw_edition", &b.attrs.SWEdition, v) }
+
+// TargetSW sets the target_sw attribute
+func (b *Builder) TargetSW(v string) *Builder { return b.set("target_sw", &b.attrs.TargetSW, v) }
+
+// TargetHW sets the target_hw attribute
+func (b *Builder) TargetHW(v string) *Builder { return b.set("target_hw", &b.attrs.TargetHW, v) }
+
+// Other sets the other attribute
+func (b *Builder) Other(v string) *Builder { return b.set("other", &b.attrs.Other, v) }
+
+// Build returns the built Attributes, or the error of the first illegal value which was set
+func (b *Builder) Build() (*Attributes, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	attrs := b.attrs
+	return &attrs, nil
+}
+
+func (b *Builder) set(name string, attr *string, v string) *Builder {
+	q, err := quoteLiteral(v)
+	if err != nil && b.err == nil {
+		b.err = fmt.Errorf("wfn: illegal %s %q: %v", name, v, err)
+	}
+	*attr = q
+	return b
+}
+
+// quoteLiteral converts the literal value into WFN attribute value; Any and NA are returned as they are
+func quoteLiteral(s string) (string, error) {
+	if s == Any || s == NA {
+		return s, nil
+	}
+	buf := make([]byte, 0, len(s)+len(s)/2)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ':
+			buf = append(buf, '_')
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_':
+			buf = append(buf, c)
+		case c > ' ' && c < 0x7f:
+			buf = append(buf, '\\', c)
+		default:
+			return "", fmt.Errorf("character %q at %d can't be a part of WFN value", c, i)
+		}
+	}
+	return string(buf), nil
+}
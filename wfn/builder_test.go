@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	attrs, err := NewBuilder().Part("a").Vendor("foo bar").Product("c++ compiler").Version("1.2*").Update(NA).TargetHW("x86_64").Build()
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	expect := &Attributes{Part: "a", Vendor: "foo_bar", Product: `c\+\+_compiler`, Version: `1\.2\*`, Update: NA, TargetHW: "x86_64"}
+	if !reflect.DeepEqual(attrs, expect) {
+		t.Fatalf("got %v, expected %v", attrs, expect)
+	}
+	fs := attrs.BindToFmtString()
+	if expect := `cpe:2.3:a:foo_bar:c\+\+_compiler:1.2\*:-:*:*:*:*:x86_64:*`; fs != expect {
+		t.Fatalf("formatted string: got %s, expected %s", fs, expect)
+	}
+	for _, s := range []string{fs, attrs.BindToURI()} {
+		parsed, err := Parse(s)
+		if err != nil {
+			t.Fatalf("can't parse %s: %v", s, err)
+		}
+		if !reflect.DeepEqual(parsed, attrs) {
+			t.Errorf("%s doesn't round-trip: got %v, expected %v", s, parsed, attrs)
+		}
+	}
+	// wildcards in the input are literal
+	if Match(attrs, &Attributes{Part: "a", Vendor: "foo_bar", Product: `c\+\+_compiler`, Version: `1\.2\.3`}) {
+		t.Error("version 1.2* shouldn't match 1.2.3")
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	cases := map[string]*Builder{
+		"unknown part":      NewBuilder().Part("x").Vendor("foo"),
+		"non-ASCII vendor":  NewBuilder().Part("a").Vendor("föo"),
+		"control character": NewBuilder().Part("a").Vendor("foo").Product("bar\tbaz"),
+	}
+	for name, b := range cases {
+		t.Run(name, func(t *testing.T) {
+			if attrs, err := b.Build(); err == nil {
+				t.Fatalf("expected build to fail, got %v", attrs)
+			}
+		})
+	}
+	if _, err := NewBuilder().Part("o").Version("1\n").Part("x").Build(); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Fatalf("expected the version error, got %v", err)
+	}
+	if _, err := NewBuilder().Part(Any).Build(); err != nil {
+		t.Fatalf("part ANY was rejected: %v", err)
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
+
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
 // BindToFmtString binds WFN to formatted string
@@ -41,11 +43,76 @@ func (a Attributes) BindToFmtString() string {
 	return fsbPrefix + strings.Join(parts, ":")
 }
 
+// BindAttrToFmtString binds the value of the attribute with the given name, as in WFN (e.g. "target_hw"),
+// the way BindToFmtString does; it returns false if name isn't one of the 11 WFN attributes.
+func (a Attributes) BindAttrToFmtString(name string) (string, bool) {
+	var v string
+	switch name {
+	case "part":
+		v = a.Part
+	case "vendor":
+		v = a.Vendor
+	case "product":
+		v = a.Product
+	case "version":
+		v = a.Version
+	case "update":
+		v = a.Update
+	case "edition":
+		v = a.Edition
+	case "language":
+		v = a.Language
+	case "sw_edition":
+		v = a.SWEdition
+	case "target_sw":
+		v = a.TargetSW
+	case "target_hw":
+		v = a.TargetHW
+	case "other":
+		v = a.Other
+	default:
+		return "", false
+	}
+	return bindValueFS(v), true
+}
+
+// FmtStringOptions control unbinding of formatted strings
+type FmtStringOptions struct {
+	// Strict makes unbinding validate the whole string against the formatted string grammar before unbinding it:
+	// exactly 11 non-empty components, part being one of a, o, h, * or -, only printable ASCII characters
+	// (no whitespace or control characters), no dangling backslash, unquoted * only at the beginning or the end
+	// of a component and unquoted ? only in runs at the beginning or the end of a component.
+	// Without it, e.g. extra components or control characters are silently accepted, for legacy data.
+	Strict bool
+	// RecoverColons makes unbinding recover the formatted strings with more than 11 components, most likely
+	// because of the unquoted colons in a component, e.g. "cpe:2.3:a:jenkins:pipeline:_groovy:2.61:*:*:*:*:*:*":
+	// the extra components are joined, with the colons quoted, into the version if all of them, along with the
+	// version, start with a digit (e.g. "1:1.1.1", with the epoch), or else into the product, where NVD names
+	// have colons the most (e.g. the Jenkins plugins). This is a best-effort recovery of the malformed data,
+	// it's not done by default.
+	RecoverColons bool
+	// Logger, if set, receives a warning for each recovered formatted string
+	Logger logging.Logger
+}
+
 // UnbindFmtString loads WFN from formatted string
 func UnbindFmtString(s string) (*Attributes, error) {
+	return UnbindFmtStringWithOptions(s, FmtStringOptions{})
+}
+
+// UnbindFmtStringWithOptions is like UnbindFmtString, but unbinding is controlled by opts
+func UnbindFmtStringWithOptions(s string, opts FmtStringOptions) (*Attributes, error) {
 	if !strings.HasPrefix(s, fsbPrefix) {
 		return nil, fmt.Errorf("bad prefix in FSB %q", s)
 	}
+	if opts.RecoverColons {
+		s = recoverColons(s, logging.OrNop(opts.Logger))
+	}
+	if opts.Strict {
+		if err := validateFmtString(s); err != nil {
+			return nil, fmt.Errorf("unbind formatted string %q: %v", s, err)
+		}
+	}
 	attr := &Attributes{}
 	for i, partN := len(fsbPrefix), 0; i < len(s); i, partN = i+1, partN+1 {
 		var err error
@@ -80,6 +147,115 @@ func UnbindFmtString(s string) (*Attributes, error) {
 	return attr, nil
 }
 
+// recoverColons quotes the colons of formatted string s, which starts with fsbPrefix, which most likely
+// separate the parts of the same component if s has too many of them, see FmtStringOptions.RecoverColons
+func recoverColons(s string, log logging.Logger) string {
+	var components []string
+	start := len(fsbPrefix)
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ':':
+			components = append(components, s[start:i])
+			start = i + 1
+		}
+	}
+	components = append(components, s[start:])
+	extra := len(components) - 11
+	if extra <= 0 {
+		return s
+	}
+	// product, unless the version and the extra components look like a version with the epoch
+	at, name := 2, "product"
+	version := true
+	for _, c := range components[3 : 3+extra+1] {
+		if c == "" || !unicode.IsDigit(rune(c[0])) {
+			version = false
+			break
+		}
+	}
+	if version {
+		at, name = 3, "version"
+	}
+	joined := strings.Join(components[at:at+extra+1], "\\:")
+	components = append(components[:at], append([]string{joined}, components[at+extra+1:]...)...)
+	recovered := fsbPrefix + strings.Join(components, ":")
+	log.Warnf("wfn: recovered formatted string %q as %q, unquoted colons attributed to the %s", s, recovered, name)
+	return recovered
+}
+
+// validateFmtString checks s, which starts with fsbPrefix, against the formatted string grammar;
+// the error names the problem and its position (starting at 0) in s
+func validateFmtString(s string) error {
+	components := 0
+	for start := len(fsbPrefix); start <= len(s); {
+		end := start
+		for ; end < len(s) && s[end] != ':'; end++ {
+			c := s[end]
+			switch {
+			case c < 0x21 || c > 0x7e:
+				return fmt.Errorf("illegal character %q at position %d", c, end)
+			case c == '\\':
+				end++
+				if end == len(s) {
+					return fmt.Errorf("unterminated quoting at position %d", end-1)
+				}
+				if c := s[end]; c < 0x21 || c > 0x7e {
+					return fmt.Errorf("illegal character %q at position %d", c, end)
+				}
+			case c == '*':
+				if end != start && end != len(s)-1 && s[end+1] != ':' {
+					return fmt.Errorf("unquoted '*' inside the component at position %d, it's only allowed at the beginning or the end", end)
+				}
+			case c == '?':
+				if !questionMarkAllowed(s, start, end) {
+					return fmt.Errorf("unquoted '?' inside the component at position %d, it's only allowed at the beginning or the end", end)
+				}
+			}
+		}
+		components++
+		if end == start {
+			return fmt.Errorf("empty component %d at position %d", components, start)
+		}
+		if components == 1 {
+			switch s[start:end] {
+			case "a", "o", "h", "*", "-":
+			default:
+				return fmt.Errorf("illegal part %q at position %d, expected one of a, o, h, * or -", s[start:end], start)
+			}
+		}
+		start = end + 1
+	}
+	if components != 11 {
+		return fmt.Errorf("expected 11 components, got %d", components)
+	}
+	return nil
+}
+
+// questionMarkAllowed returns true if unquoted ? at position i of s is a part of a run of question marks
+// at the beginning or at the end of the component which starts at position start
+func questionMarkAllowed(s string, start, i int) bool {
+	// leading run: only question marks since the start of the component
+	leading := true
+	for j := start; j < i; j++ {
+		if s[j] != '?' {
+			leading = false
+			break
+		}
+	}
+	if leading {
+		return true
+	}
+	// trailing run: only question marks till the end of the component
+	for j := i + 1; j < len(s) && s[j] != ':'; j++ {
+		if s[j] != '?' {
+			return false
+		}
+	}
+	return true
+}
+
 // StripSlashes removes escaping of punctuation characters from attribute value
 func StripSlashes(s string) string {
 	out := make([]byte, 0, len(s)) // might be more than we need, but no reallocs
@@ -89,6 +265,9 @@ func StripSlashes(s string) string {
 			case '.', '_', '-': // these pass unquoted
 				continue
 			}
+			// keep the quoted character as is, it might be a backslash itself
+			out = append(out, s[i])
+			i++
 		}
 		out = append(out, byte(s[i]))
 	}
@@ -101,6 +280,9 @@ func bindValueFS(s string) string {
 		return "*"
 	case NA:
 		return "-"
+	case "\\-":
+		// a quoted hyphen must stay quoted, or it binds to NA
+		return s
 	default:
 		return StripSlashes(s)
 	}
@@ -116,8 +298,6 @@ func unbindValueFSAt(s string, at int) (string, int, error) {
 			return Any, at + 1, nil
 		case '-':
 			return NA, at + 1, nil
-		default:
-			return s[at : at+1], at + 1, nil
 		}
 	}
 	return addSlashesAt(s, at)
@@ -129,7 +309,7 @@ func addSlashesAt(s string, at int) (string, int, error) {
 	i := at
 	for ; i < len(s) && s[i] != ':'; i++ {
 		c := s[i]
-		if unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_' {
+		if isUnquotedFS(c) {
 			b = append(b, c)
 			embedded = true
 			continue
@@ -137,7 +317,15 @@ func addSlashesAt(s string, at int) (string, int, error) {
 		switch c {
 		case '\\':
 			i++
-			b = append(b, c, s[i])
+			if i == len(s) {
+				return Any, i, fmt.Errorf("unterminated quoting inside the FSB fragment: %q", s)
+			}
+			if isUnquotedFS(s[i]) {
+				// needlessly quoted character, WFN keeps it unquoted
+				b = append(b, s[i])
+			} else {
+				b = append(b, c, s[i])
+			}
 			embedded = true
 		case '*':
 			// An unquoted asterisk must appear at the beginning or end of the string
@@ -161,3 +349,8 @@ func addSlashesAt(s string, at int) (string, int, error) {
 	}
 	return string(append([]byte{}, b...)), i, nil
 }
+
+// isUnquotedFS returns true if c doesn't need quoting in WFN
+func isUnquotedFS(c byte) bool {
+	return unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_'
+}
@@ -16,6 +16,7 @@ package wfn
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -53,6 +54,14 @@ func TestUnbindFmtString(t *testing.T) {
 			FSB:  "cpe:2.3:a:hp:insight_diagnostics:7.4.*.1570:*:*:*:*:*:*",
 			Fail: true,
 		},
+		{
+			FSB:    `cpe:2.3:a:v\_endor:\_:.:\-:*:*:*:*:*:*:*`,
+			Expect: `wfn:[part="a",vendor="v_endor",product="_",version="\.",update="\-",edition=ANY,language=ANY]`,
+		},
+		{
+			FSB:  `cpe:2.3:a:vendor:product\`,
+			Fail: true,
+		},
 	}
 	for _, tc := range cases {
 		tc := tc
@@ -74,6 +83,108 @@ func TestUnbindFmtString(t *testing.T) {
 	}
 }
 
+func TestUnbindFmtStringStrict(t *testing.T) {
+	cases := []struct {
+		FSB   string
+		Error string // empty if no error expected
+	}{
+		{FSB: "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*"},
+		{FSB: "cpe:2.3:a:microsoft:internet_exp?????:8.*:sp?:*:*:*:*:*:*"},
+		{FSB: "cpe:2.3:a:microsoft:??ternet_explorer:*8:*:*:*:*:*:*:*"},
+		{FSB: `cpe:2.3:a:foo\\bar:big\$money\:tm:2010:*:*:*:special:ipod_touch:80gb:*`},
+		{FSB: "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*:extra", Error: "expected 11 components, got 12"},
+		{FSB: "cpe:2.3:a:vendor:product:1.0", Error: "expected 11 components, got 4"},
+		{FSB: "cpe:2.3:a:vendor::1.0:*:*:*:*:*:*:*", Error: "empty component 3 at position 17"},
+		{FSB: "cpe:2.3:x:vendor:product:1.0:*:*:*:*:*:*:*", Error: `illegal part "x" at position 8`},
+		{FSB: "cpe:2.3:a:vendor:pro duct:1.0:*:*:*:*:*:*:*", Error: `illegal character ' ' at position 20`},
+		{FSB: "cpe:2.3:a:vendor:product\x01:1.0:*:*:*:*:*:*:*", Error: `illegal character '\x01' at position 24`},
+		{FSB: "cpe:2.3:a:vendor:product:1.*.2:*:*:*:*:*:*:*", Error: "unquoted '*' inside the component at position 27"},
+		{FSB: "cpe:2.3:a:vendor:prod?uct:1.0:*:*:*:*:*:*:*", Error: "unquoted '?' inside the component at position 21"},
+		{FSB: `cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:foo\`, Error: "unterminated quoting at position 44"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.FSB, func(t *testing.T) {
+			_, err := UnbindFmtStringWithOptions(tc.FSB, FmtStringOptions{Strict: true})
+			if tc.Error == "" {
+				if err != nil {
+					t.Fatalf("failed to parse FSB: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("FSB parsed successfully, despite error %q was expected", tc.Error)
+			}
+			if !strings.Contains(err.Error(), tc.Error) {
+				t.Fatalf("expected error to contain %q, got %q", tc.Error, err)
+			}
+		})
+	}
+
+	// lenient parsing accepts legacy data
+	if _, err := UnbindFmtString("cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*:extra"); err != nil {
+		t.Fatalf("lenient parsing failed: %v", err)
+	}
+}
+
+func TestUnbindFmtStringRecoverColons(t *testing.T) {
+	cases := []struct {
+		FSB       string
+		Expect    Attributes
+		Recovered bool
+	}{
+		{
+			FSB:       "cpe:2.3:a:jenkins:pipeline:_groovy:2.61:*:*:*:*:jenkins:*:*",
+			Expect:    Attributes{Part: "a", Vendor: "jenkins", Product: "pipeline\\:_groovy", Version: "2\\.61", TargetSW: "jenkins"},
+			Recovered: true,
+		},
+		{
+			FSB:       "cpe:2.3:a:debian:openssl:1:1.1.1n-0:*:*:*:*:*:*:*",
+			Expect:    Attributes{Part: "a", Vendor: "debian", Product: "openssl", Version: "1\\:1\\.1\\.1n\\-0"},
+			Recovered: true,
+		},
+		{
+			FSB:       "cpe:2.3:a:jenkins:pipeline:github:groovy_libraries:1.0:*:*:*:*:jenkins:*:*",
+			Expect:    Attributes{Part: "a", Vendor: "jenkins", Product: "pipeline\\:github\\:groovy_libraries", Version: "1\\.0", TargetSW: "jenkins"},
+			Recovered: true,
+		},
+		{
+			// well-formed strings are unbound as they are
+			FSB:    "cpe:2.3:a:jenkins:pipeline\\:_groovy:2.61:*:*:*:*:jenkins:*:*",
+			Expect: Attributes{Part: "a", Vendor: "jenkins", Product: "pipeline\\:_groovy", Version: "2\\.61", TargetSW: "jenkins"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.FSB, func(t *testing.T) {
+			var logger warnings
+			attr, err := UnbindFmtStringWithOptions(tc.FSB, FmtStringOptions{Strict: true, RecoverColons: true, Logger: &logger})
+			if err != nil {
+				t.Fatalf("failed to parse FSB: %v", err)
+			}
+			if *attr != tc.Expect {
+				t.Fatalf("got %#v, expected %#v", *attr, tc.Expect)
+			}
+			if tc.Recovered != (len(logger) == 1) {
+				t.Fatalf("expected recovery %t, got warnings %q", tc.Recovered, logger)
+			}
+		})
+	}
+
+	// the strict parser rejects the malformed string without the recovery
+	if _, err := UnbindFmtStringWithOptions(cases[0].FSB, FmtStringOptions{Strict: true}); err == nil {
+		t.Fatal("FSB with unquoted colons parsed successfully without the recovery")
+	}
+}
+
+// warnings records the messages logged at warning level
+type warnings []string
+
+func (l *warnings) Debugf(string, ...interface{}) {}
+func (l *warnings) Infof(string, ...interface{})  {}
+func (l *warnings) Warnf(format string, args ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, args...))
+}
+func (l *warnings) Errorf(string, ...interface{}) {}
+
 func BenchmarkUnbindFmtString(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		UnbindFmtString("cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*")
@@ -86,6 +197,7 @@ func TestBindToFmtString(t *testing.T) {
 		"cpe:2.3:a:microsoft:internet_explorer:8.*:sp?:*:*:*:*:*:*",
 		"cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*",
 		`cpe:2.3:a:foo\\bar:big\$\*\?money:2010:*:*:*:special:ipod_touch:80gb:*`,
+		`cpe:2.3:a:foo\\_bar:product:\-:*:*:*:*:*:*:*`,
 	}
 	for n, c := range cases {
 		c := c
@@ -100,3 +212,63 @@ func TestBindToFmtString(t *testing.T) {
 		})
 	}
 }
+
+func TestBindAttrToFmtString(t *testing.T) {
+	attr, err := UnbindFmtString("cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"part":       "a",
+		"version":    "7.4.0.1570",
+		"update":     "-",
+		"edition":    "*",
+		"sw_edition": "online",
+		"target_sw":  "win2003",
+		"target_hw":  "x64",
+		"other":      "*",
+	}
+	for name, expect := range cases {
+		if v, ok := attr.BindAttrToFmtString(name); !ok || v != expect {
+			t.Errorf("%s: expected %q, got %q (%t)", name, expect, v, ok)
+		}
+	}
+	if v, ok := attr.BindAttrToFmtString("arch"); ok {
+		t.Errorf("unknown attribute was bound to %q", v)
+	}
+}
+
+// roundTripFmtStrings must parse back to the same WFN after being bound to
+// formatted string again; they also seed FuzzParseBindRoundTrip
+var roundTripFmtStrings = []string{
+	"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+	"cpe:2.3:a:microsoft:internet_exp?????:8.*:sp?:*:*:*:*:*:*",
+	"cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*",
+	`cpe:2.3:a:foo\\bar:big\$\*\?money:2010:*:*:*:special:ipod_touch:80gb:*`,
+	`cpe:2.3:a:foo\:bar:baz%20qux:\-:*:*:*:*:*:*:*`,
+	`cpe:2.3:o:vendor:product:*1.0?:*:*:*:*:*:*:*`,
+	`cpe:2.3:a:\\_:\_:.:*:*:*:*:*:*:*:*`,
+}
+
+func TestParseBindRoundTrip(t *testing.T) {
+	for _, s := range roundTripFmtStrings {
+		t.Run(s, func(t *testing.T) {
+			testParseBindRoundTrip(t, s)
+		})
+	}
+}
+
+func testParseBindRoundTrip(t *testing.T, s string) {
+	attr, err := Parse(s)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", s, err)
+	}
+	fs := attr.BindToFmtString()
+	attr2, err := Parse(fs)
+	if err != nil {
+		t.Fatalf("failed to parse bound %q (from %q): %v", fs, s, err)
+	}
+	if *attr != *attr2 {
+		t.Fatalf("round trip of %q via %q failed:\n%s\n%s", s, fs, attr, attr2)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON implements json.Marshaler interface: Attributes are marshaled as the formatted string binding
+func (a Attributes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.BindToFmtString())
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface: Attributes are unmarshaled from a string holding
+// the formatted string or URI binding; null leaves a intact
+func (a *Attributes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("wfn: can't unmarshal %s: must be a string", data)
+	}
+	attrs, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = *attrs
+	return nil
+}
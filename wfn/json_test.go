@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAttributesJSON(t *testing.T) {
+	type inventory struct {
+		Host     string        `json:"host"`
+		CPE      Attributes    `json:"cpe"`
+		Optional *Attributes   `json:"optional,omitempty"`
+		Others   []*Attributes `json:"others"`
+	}
+	cpe, err := UnbindFmtString(`cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:\-`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewAttributesWithNA()
+	other.Part = "o"
+	in := inventory{
+		Host:   "web01",
+		CPE:    *cpe,
+		Others: []*Attributes{NewAttributesWithAny(), other},
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"host":"web01","cpe":"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:\\-","others":["cpe:2.3:*:*:*:*:*:*:*:*:*:*:*","cpe:2.3:o:-:-:-:-:-:-:-:-:-:-"]}`
+	if string(data) != expect {
+		t.Fatalf("wrong JSON:\nhave %s\nwant %s", data, expect)
+	}
+
+	var out inventory
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.CPE != in.CPE {
+		t.Errorf("cpe didn't round-trip:\nhave %+v\nwant %+v", out.CPE, in.CPE)
+	}
+	// ANY, NA and quoted hyphen are distinct
+	if out.CPE.Update != NA || out.CPE.Edition != Any || out.CPE.Other != `\-` {
+		t.Errorf("logical values didn't round-trip: %+v", out.CPE)
+	}
+	if out.Optional != nil {
+		t.Errorf("optional cpe should be nil, got %+v", out.Optional)
+	}
+	if len(out.Others) != 2 || *out.Others[0] != *in.Others[0] || *out.Others[1] != *in.Others[1] {
+		t.Errorf("others didn't round-trip: %v", out.Others)
+	}
+
+	// URI binding is accepted too, null is ignored
+	if err := json.Unmarshal([]byte(`{"cpe":"cpe:/a:microsoft:internet_explorer:8.0.6001","optional":null}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.CPE.Version != `8\.0\.6001` || out.CPE.Update != Any {
+		t.Errorf("wrong cpe unmarshaled from URI: %+v", out.CPE)
+	}
+
+	for _, data := range []string{
+		`{"cpe":"microsoft:internet_explorer"}`,
+		`{"cpe":""}`,
+		`{"cpe":42}`,
+		`{"cpe":{"Part":"a"}}`,
+	} {
+		if err := json.Unmarshal([]byte(data), &out); err == nil {
+			t.Errorf("%s: expected an error", data)
+		}
+	}
+}
@@ -24,6 +24,9 @@ const (
 	Subset
 	Equal
 	Superset
+	// Undefined is the relation of attributes which can't be compared, e.g. when the target has wildcards;
+	// it's only returned by Relate
+	Undefined
 )
 
 // HasWildcard returns true if attribute has a wildcard symbol in it
@@ -45,7 +48,7 @@ func HasWildcard(s string) bool {
 	return false
 }
 
-// Relation describes four possible set relations of wfns attribute-value
+// Relation describes possible set relations of wfns attribute-value
 type Relation int
 
 // String return human readable representation of Relation value
@@ -59,6 +62,8 @@ func (r Relation) String() string {
 		return "EQUAL"
 	case Superset:
 		return "SUPERSET"
+	case Undefined:
+		return "UNDEFINED"
 	default:
 		return fmt.Sprintf("Undefined value %d", r)
 	}
@@ -201,12 +206,13 @@ func (c Comparison) IsSuperset() bool {
 
 // Relation returns relation between matched CPE names
 func (c Comparison) Relation() Relation {
-	if c.IsSubset() {
-		return Subset
-	}
+	// IsSubset and IsSuperset are true for equal names too, so check for equality first
 	if c.IsEqual() {
 		return Equal
 	}
+	if c.IsSubset() {
+		return Subset
+	}
 	if c.IsSuperset() {
 		return Superset
 	}
@@ -280,6 +286,52 @@ func Match(src, tgt *Attributes) bool {
 		matchAttr(src.Other, tgt.Other)
 }
 
+// MatchAnySource returns true if any of srcs matches tgt, see Match; srcs are the candidate values of
+// the source, e.g. all CPE names an ambiguously detected product may have
+func MatchAnySource(srcs []*Attributes, tgt *Attributes) bool {
+	for _, src := range srcs {
+		if Match(src, tgt) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAnyTarget is the inverse of MatchAnySource: it returns true if src matches any of tgts
+func MatchAnyTarget(src *Attributes, tgts []*Attributes) bool {
+	for _, tgt := range tgts {
+		if Match(src, tgt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the attributes from set which are equal to or a subset of the pattern,
+// as per Name Matching Specification v.2.3: pattern may contain logical value ANY and
+// wildcards, e.g. product "openssl*" or version "1.2.*".
+// Attributes that can't be compared to the pattern (nil, or containing wildcards) are skipped.
+func Filter(pattern *Attributes, set []*Attributes) []*Attributes {
+	if pattern == nil {
+		return nil
+	}
+	var matches []*Attributes
+	for _, attr := range set {
+		if attr == nil {
+			continue
+		}
+		c, err := Compare(pattern, attr)
+		if err != nil {
+			continue
+		}
+		// superset relation of the pattern includes equality
+		if c.IsSuperset() {
+			matches = append(matches, attr)
+		}
+	}
+	return matches
+}
+
 // CompareAttr calculates a relation between a pair of wfn attribute values.
 // Accordingly to standard, string matching must be insensitive to lexical case,
 // target A-V must not have wildcards.
@@ -312,36 +364,49 @@ func Match(src, tgt *Attributes) bool {
 // | m1 + w     | m2 + w     | undefined    |
 // +----------------------------------------+
 func CompareAttr(src, tgt string) (Relation, error) {
-	if src != NA && src != Any && HasWildcard(tgt) {
+	if HasWildcard(tgt) {
 		return Disjoint, fmt.Errorf("target attribute value cannot contain wildcard")
 	}
-	if src == tgt {
-		return Equal, nil
-	}
-	if src == Any {
-		return Superset, nil
-	}
-	if tgt == Any {
-		return Subset, nil
-	}
-	if src == NA || tgt == NA {
-		return Disjoint, nil
+	if r, ok := compareLogical(src, tgt); ok {
+		return r, nil
 	}
 	return matchStr(src, tgt), nil
 }
 
+// compareLogical returns the relation between src and tgt from the table above
+// if it can be determined without string matching, i.e. if the values are identical
+// or at least one of them is a logical value (ANY or NA).
+// The second return value is false if src and tgt need to be matched as strings.
+func compareLogical(src, tgt string) (Relation, bool) {
+	switch {
+	case src == tgt:
+		// ANY == ANY, NA == NA, i == i
+		return Equal, true
+	case src == Any:
+		// ANY is a superset of NA and of any string
+		return Superset, true
+	case tgt == Any:
+		// NA and any string are subsets of ANY
+		return Subset, true
+	case src == NA || tgt == NA:
+		// NA is disjoint with any string
+		return Disjoint, true
+	default:
+		return Disjoint, false
+	}
+}
+
 // matchAttr returns true if relation between src and tgt is one of Equal, Subset or Superset.
 // It returns false on undefined relations, except when src == tgt byte-by-byte.
 // This is crude but fast(-er) version of CompareAttr.
 func matchAttr(src, tgt string) bool {
-	switch {
-	case src == Any || tgt == Any || src == tgt:
-		return true
-	case src == NA || tgt == NA || HasWildcard(tgt):
+	if r, ok := compareLogical(src, tgt); ok {
+		return r != Disjoint
+	}
+	if HasWildcard(tgt) {
 		return false
-	default:
-		return matchStr(src, tgt) != Disjoint
 	}
+	return matchStr(src, tgt) != Disjoint
 }
 
 func matchStr(s, t string) Relation {
@@ -385,3 +450,96 @@ func matchStr(s, t string) Relation {
 	}
 	return matchesAs
 }
+
+// WildcardMatch returns true if a, which may contain wildcards anywhere within its attribute values,
+// matches tgt attribute by attribute, e.g. version "2.4.*" matches "2.4.1" and "2.4.10", but not "2.5".
+// An unquoted asterisk (*) matches zero or more characters and an unquoted question mark (?) matches
+// exactly one character; as per Name Matching Specification v2.3, a run of question marks at the beginning
+// or at the end of a value matches zero or one character each. Quoted wildcards (\* and \?) are literal.
+// Logical values are compared as in Match. Target values can't contain wildcards.
+func (a *Attributes) WildcardMatch(tgt *Attributes) bool {
+	if a == nil || tgt == nil {
+		return false
+	}
+	return wildcardMatchAttr(a.Part, tgt.Part) && wildcardMatchAttr(a.Vendor, tgt.Vendor) &&
+		wildcardMatchAttr(a.Product, tgt.Product) && wildcardMatchAttr(a.Version, tgt.Version) &&
+		wildcardMatchAttr(a.Update, tgt.Update) && wildcardMatchAttr(a.Edition, tgt.Edition) &&
+		wildcardMatchAttr(a.Language, tgt.Language) && wildcardMatchAttr(a.SWEdition, tgt.SWEdition) &&
+		wildcardMatchAttr(a.TargetHW, tgt.TargetHW) && wildcardMatchAttr(a.TargetSW, tgt.TargetSW) &&
+		wildcardMatchAttr(a.Other, tgt.Other)
+}
+
+// wildcardMatchAttr returns true if attribute value src, which may contain wildcards, matches tgt
+func wildcardMatchAttr(src, tgt string) bool {
+	if r, ok := compareLogical(src, tgt); ok {
+		return r != Disjoint
+	}
+	if HasWildcard(tgt) {
+		return false
+	}
+	return globMatch(globTokens(src, true), globTokens(tgt, false))
+}
+
+// kinds of glob tokens
+const (
+	globChar     = iota // a literal character
+	globAny             // unquoted *, zero or more characters
+	globOne             // unquoted ?, exactly one character
+	globOptional        // unquoted ? at the beginning or the end of a value, zero or one character
+)
+
+type globToken struct {
+	kind int
+	c    byte
+}
+
+// globTokens splits s into logical characters: a quoted character is a single literal one.
+// If wildcards is true, unquoted * and ? are turned into wildcard tokens.
+func globTokens(s string, wildcards bool) []globToken {
+	tokens := make([]globToken, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			tokens = append(tokens, globToken{globChar, lowerASCII(s[i])})
+		case wildcards && c == '*':
+			tokens = append(tokens, globToken{globAny, c})
+		case wildcards && c == '?':
+			tokens = append(tokens, globToken{globOne, c})
+		default:
+			tokens = append(tokens, globToken{globChar, lowerASCII(c)})
+		}
+	}
+	for i := 0; i < len(tokens) && tokens[i].kind == globOne; i++ {
+		tokens[i].kind = globOptional
+	}
+	for i := len(tokens) - 1; i >= 0 && tokens[i].kind == globOne; i-- {
+		tokens[i].kind = globOptional
+	}
+	return tokens
+}
+
+// globMatch returns true if pattern matches all of s
+func globMatch(pattern, s []globToken) bool {
+	// match[j] is true if the pattern tokens seen so far match s[:j]
+	match := make([]bool, len(s)+1)
+	next := make([]bool, len(s)+1)
+	match[0] = true
+	for _, p := range pattern {
+		for j := range next {
+			switch p.kind {
+			case globAny:
+				next[j] = match[j] || j > 0 && next[j-1]
+			case globOptional:
+				next[j] = match[j] || j > 0 && match[j-1]
+			case globOne:
+				next[j] = j > 0 && match[j-1]
+			default:
+				next[j] = j > 0 && match[j-1] && s[j-1].c == p.c
+			}
+		}
+		match, next = next, match
+	}
+	return match[len(s)]
+}
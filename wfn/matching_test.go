@@ -84,6 +84,26 @@ func TestCompare(t *testing.T) {
 			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
 			Expect: Superset,
 		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: Equal,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			Expect: Subset,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:*:*:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*`,
+			Expect: Superset,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: Disjoint,
+		},
 	}
 	for _, c := range cases {
 		t.Run(fmt.Sprintf("%q vs %q", c.Src, c.Tgt), func(t *testing.T) {
@@ -109,6 +129,115 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestCompareAttr(t *testing.T) {
+	cases := []struct {
+		Src    string
+		Tgt    string
+		Fail   bool
+		Expect Relation
+	}{
+		{Src: Any, Tgt: Any, Expect: Equal},
+		{Src: Any, Tgt: NA, Expect: Superset},
+		{Src: Any, Tgt: "foo", Expect: Superset},
+		{Src: NA, Tgt: Any, Expect: Subset},
+		{Src: NA, Tgt: NA, Expect: Equal},
+		{Src: NA, Tgt: "foo", Expect: Disjoint},
+		{Src: "foo", Tgt: Any, Expect: Subset},
+		{Src: "foo", Tgt: NA, Expect: Disjoint},
+		{Src: "foo", Tgt: "foo", Expect: Equal},
+		{Src: "foo", Tgt: "bar", Expect: Disjoint},
+		{Src: "fo*", Tgt: "foo", Expect: Superset},
+		{Src: "fo*", Tgt: Any, Expect: Subset},
+		{Src: "fo*", Tgt: NA, Expect: Disjoint},
+		// target can't have wildcards, relation is undefined
+		{Src: Any, Tgt: "fo*", Fail: true},
+		{Src: NA, Tgt: "fo*", Fail: true},
+		{Src: "foo", Tgt: "fo?", Fail: true},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q", c.Src, c.Tgt), func(t *testing.T) {
+			r, err := CompareAttr(c.Src, c.Tgt)
+			if c.Fail {
+				if err == nil {
+					t.Fatalf("test was expected to fail, but succeeded with %v", r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("test was expected to succeed, but failed: %v", err)
+			}
+			if r != c.Expect {
+				t.Fatalf("CompareAttr returned %v, %v was expected", r, c.Expect)
+			}
+			if m := matchAttr(c.Src, c.Tgt); m != (r != Disjoint) {
+				t.Fatalf("matchAttr returned %t for relation %v", m, r)
+			}
+		})
+	}
+}
+
+func TestMatchWithoutVersion(t *testing.T) {
+	values := []string{Any, NA, "foo"}
+	for _, src := range values {
+		for _, tgt := range values {
+			t.Run(fmt.Sprintf("%q vs %q", src, tgt), func(t *testing.T) {
+				srcAttr := &Attributes{Part: "a", Vendor: "vendor", Product: "product", Update: src}
+				tgtAttr := &Attributes{Part: "a", Vendor: "vendor", Product: "product", Update: tgt}
+				r, err := CompareAttr(src, tgt)
+				if err != nil {
+					t.Fatalf("failed to compare %q to %q: %v", src, tgt, err)
+				}
+				if m := srcAttr.MatchWithoutVersion(tgtAttr); m != (r != Disjoint) {
+					t.Fatalf("MatchWithoutVersion returned %t for relation %v", m, r)
+				}
+				cmp, err := Compare(srcAttr, tgtAttr)
+				if err != nil {
+					t.Fatalf("failed to compare %v to %v: %v", srcAttr, tgtAttr, err)
+				}
+				if cmp.Relation() != r {
+					t.Fatalf("Compare returned %v, %v was expected", cmp.Relation(), r)
+				}
+			})
+		}
+	}
+}
+
+func TestMatchExtendedAttributes(t *testing.T) {
+	set := []func(a *Attributes, v string){
+		func(a *Attributes, v string) { a.SWEdition = v },
+		func(a *Attributes, v string) { a.TargetSW = v },
+		func(a *Attributes, v string) { a.TargetHW = v },
+		func(a *Attributes, v string) { a.Other = v },
+	}
+	cases := []struct {
+		src, tgt string
+		match    bool
+	}{
+		{"arm64", "x86_64", false},
+		{"arm64", "arm64", true},
+		{Any, "arm64", true},
+		{"arm64", Any, true},
+		{NA, "arm64", false},
+		{"arm*", "arm64", true},
+	}
+	for i, setAttr := range set {
+		for _, c := range cases {
+			t.Run(fmt.Sprintf("%d/%q vs %q", i, c.src, c.tgt), func(t *testing.T) {
+				src := &Attributes{Part: "a", Vendor: "openssl", Product: "openssl", Version: "1\\.1\\.1"}
+				tgt := &Attributes{Part: "a", Vendor: "openssl", Product: "openssl", Version: "1\\.1\\.1"}
+				setAttr(src, c.src)
+				setAttr(tgt, c.tgt)
+				if m := Match(src, tgt); m != c.match {
+					t.Errorf("Match returned %t, %t was expected", m, c.match)
+				}
+				if m := src.MatchWithoutVersion(tgt); m != c.match {
+					t.Errorf("MatchWithoutVersion returned %t, %t was expected", m, c.match)
+				}
+			})
+		}
+	}
+}
+
 func BenchmarkCompare(b *testing.B) {
 	src := `cpe:2.3:a:microsoft:*internet_ex??????:8.0.*:sp?:*:*:*:*:*:*`
 	tgt := `cpe:2.3:a:microsoft:internet_explorer:8.1.6001:sp3:*:*:*:*:*:*`
@@ -173,3 +302,159 @@ func BenchmarkHasWildcard(b *testing.B) {
 		HasWildcard(srcAttr.Product)
 	}
 }
+
+func TestMatchAnySource(t *testing.T) {
+	var candidates []*Attributes
+	for _, s := range []string{
+		`cpe:2.3:a:apache:http_server:2.4.41:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:nginx:nginx:1.18.0:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:lighttpd:lighttpd:1.4.55:*:*:*:*:*:*:*`,
+	} {
+		attr, err := UnbindFmtString(s)
+		if err != nil {
+			t.Fatalf("failed to unbind %q: %v", s, err)
+		}
+		candidates = append(candidates, attr)
+	}
+	cases := []struct {
+		tgt    string
+		expect bool
+	}{
+		{`cpe:2.3:a:nginx:nginx:*:*:*:*:*:*:*:*`, true}, // only the second candidate matches
+		{`cpe:2.3:a:apache:http_server:2.4.41:*:*:*:*:*:*:*`, true},
+		{`cpe:2.3:a:nginx:nginx:1.19.0:*:*:*:*:*:*:*`, false},
+		{`cpe:2.3:a:microsoft:iis:*:*:*:*:*:*:*:*`, false},
+	}
+	for _, c := range cases {
+		tgt, err := UnbindFmtString(c.tgt)
+		if err != nil {
+			t.Fatalf("failed to unbind %q: %v", c.tgt, err)
+		}
+		if got := MatchAnySource(candidates, tgt); got != c.expect {
+			t.Errorf("MatchAnySource(candidates, %q): got %t, expected %t", c.tgt, got, c.expect)
+		}
+		if got := MatchAnyTarget(tgt, candidates); got != c.expect {
+			t.Errorf("MatchAnyTarget(%q, candidates): got %t, expected %t", c.tgt, got, c.expect)
+		}
+	}
+	if MatchAnySource(nil, candidates[0]) || MatchAnyTarget(candidates[0], nil) {
+		t.Error("empty candidate set matched")
+	}
+	if MatchAnySource(candidates, nil) || MatchAnyTarget(nil, candidates) {
+		t.Error("nil attributes matched")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	set := []string{
+		`cpe:2.3:a:openssl:openssl:1.0.2k:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:openssl:openssl:1.1.1g:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:openssl:openssl-libs:1.1.1g:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:*:openssl:1.1.1g:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:-:openssl:1.2.0:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:gnu:bash:4.4:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:openssl:openssl:1.2.1:*:*:*:*:*:*:*`,
+		`cpe:2.3:a:openssl:openssl:1.2.*:*:*:*:*:*:*:*`, // has wildcards, can't be filtered
+	}
+	attrs := make([]*Attributes, 0, len(set)+1)
+	for _, s := range set {
+		attr, err := UnbindFmtString(s)
+		if err != nil {
+			t.Fatalf("failed to unbind WFN from FSB %q: %v", s, err)
+		}
+		attrs = append(attrs, attr)
+	}
+	attrs = append(attrs, nil)
+
+	cases := []struct {
+		pattern string
+		want    []int // indices into set
+	}{
+		{`cpe:2.3:*:*:*:*:*:*:*:*:*:*:*`, []int{0, 1, 2, 3, 4, 5, 6}},
+		{`cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*`, []int{0, 1, 6}},
+		{`cpe:2.3:a:*:openssl*:*:*:*:*:*:*:*:*`, []int{0, 1, 2, 3, 4, 6}},
+		{`cpe:2.3:a:openssl:openssl*:*:*:*:*:*:*:*:*`, []int{0, 1, 2, 6}},
+		{`cpe:2.3:a:*:openssl:1.2.*:*:*:*:*:*:*:*`, []int{4, 6}},
+		{`cpe:2.3:a:openssl:openssl:1.1.1g:*:*:*:*:*:*:*`, []int{1}},
+		{`cpe:2.3:a:-:*:*:*:*:*:*:*:*:*`, []int{4}},
+		{`cpe:2.3:o:*:*:*:*:*:*:*:*:*:*`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.pattern, func(t *testing.T) {
+			pattern, err := UnbindFmtString(c.pattern)
+			if err != nil {
+				t.Fatalf("failed to unbind WFN from FSB %q: %v", c.pattern, err)
+			}
+			have := Filter(pattern, attrs)
+			if len(have) != len(c.want) {
+				t.Fatalf("Filter returned %d attributes %v, %d were expected", len(have), have, len(c.want))
+			}
+			for i, j := range c.want {
+				if have[i] != attrs[j] {
+					t.Errorf("Filter returned %v at %d, %v was expected", have[i], i, attrs[j])
+				}
+			}
+		})
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	cases := []struct {
+		Src    string
+		Tgt    string
+		Expect bool
+	}{
+		{Any, "2\\.4\\.1", true},
+		{"2\\.4\\.1", Any, true},
+		{NA, NA, true},
+		{"2\\.4\\.*", NA, false},
+		{"2\\.4\\.*", "2\\.4\\.1", true},
+		{"2\\.4\\.*", "2\\.4\\.10", true},
+		{"2\\.4\\.*", "2\\.4\\.", true},
+		{"2\\.4\\.*", "2\\.5\\.1", false},
+		{"2\\.4*", "2\\.40", true},
+		{"2.4.*", "2\\.4\\.1", true},
+		{"2*1", "2\\.4\\.1", true},
+		{"2*1", "2\\.4\\.2", false},
+		{"*\\.4\\.*", "2\\.4\\.1", true},
+		{"2\\.?\\.1", "2\\.4\\.1", true},
+		{"2\\.?\\.1", "2\\.\\.1", false},
+		{"2\\.?\\.1", "2\\.10\\.1", false},
+		{"2\\.??\\.1", "2\\.10\\.1", true},
+		// ? counts logical characters, quoted ones included
+		{"2?4", "2\\.4", true},
+		// ? at the boundary matches zero or one character
+		{"?bar", "bar", true},
+		{"?bar", "fbar", true},
+		{"?bar", "foobar", false},
+		{"??bar", "fobar", true},
+		{"bar??", "bar", true},
+		{"bar??", "bar12", true},
+		{"bar??", "bar123", false},
+		// quoted wildcards are literal
+		{"foo\\*", "foo\\*", true},
+		{"foo\\*", "foobar", false},
+		{"foo\\?", "foo\\?", true},
+		{"foo\\?", "foox", false},
+		{"foo\\\\*", "foo\\\\bar", true},
+		// case insensitive
+		{"OpenSSL*", "openssl_fips", true},
+		// target can't have wildcards
+		{"foo*", "foo*", true},
+		{"fo*", "foo*", false},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q", c.Src, c.Tgt), func(t *testing.T) {
+			src := NewAttributesWithAny()
+			src.Version = c.Src
+			tgt := NewAttributesWithAny()
+			tgt.Version = c.Tgt
+			if got := src.WildcardMatch(tgt); got != c.Expect {
+				t.Fatalf("WildcardMatch returned %t, %t was expected", got, c.Expect)
+			}
+		})
+	}
+	if (*Attributes)(nil).WildcardMatch(NewAttributesWithAny()) {
+		t.Error("nil attributes shouldn't match")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+// Relate returns the relation between source and target CPE names as per Name Matching Specification v2.3
+// (NISTIR 7696), aggregating the relations of their attribute-values:
+// the names are DISJOINT if any of the attribute relations is DISJOINT; otherwise they're UNDEFINED if any
+// of the relations is UNDEFINED; EQUAL if all of them are EQUAL; SUBSET if all of them are SUBSET or EQUAL;
+// SUPERSET if all of them are SUPERSET or EQUAL, and UNDEFINED if there's a mix of SUBSET and SUPERSET.
+// Unlike CompareAttr, the attribute relation is UNDEFINED rather than an error if the target has wildcards.
+func Relate(src, tgt *Attributes) Relation {
+	if src == nil || tgt == nil {
+		return Undefined
+	}
+	rels := [...]Relation{
+		relateAttr(src.Part, tgt.Part),
+		relateAttr(src.Vendor, tgt.Vendor),
+		relateAttr(src.Product, tgt.Product),
+		relateAttr(src.Version, tgt.Version),
+		relateAttr(src.Update, tgt.Update),
+		relateAttr(src.Edition, tgt.Edition),
+		relateAttr(src.Language, tgt.Language),
+		relateAttr(src.SWEdition, tgt.SWEdition),
+		relateAttr(src.TargetSW, tgt.TargetSW),
+		relateAttr(src.TargetHW, tgt.TargetHW),
+		relateAttr(src.Other, tgt.Other),
+	}
+	var subset, superset, undefined bool
+	for _, r := range rels {
+		switch r {
+		case Disjoint:
+			return Disjoint
+		case Subset:
+			subset = true
+		case Superset:
+			superset = true
+		case Undefined:
+			undefined = true
+		}
+	}
+	switch {
+	case undefined || subset && superset:
+		return Undefined
+	case subset:
+		return Subset
+	case superset:
+		return Superset
+	default:
+		return Equal
+	}
+}
+
+// relateAttr returns the relation between source and target attribute-values as per the table for CompareAttr
+func relateAttr(src, tgt string) Relation {
+	if HasWildcard(tgt) {
+		return Undefined
+	}
+	if r, ok := compareLogical(src, tgt); ok {
+		return r
+	}
+	return matchStr(src, tgt)
+}
+
+// Equal returns true if a and other are EQUAL as per Name Matching Specification v2.3, i.e. if Relate(a, other)
+// would return Equal, ignoring the case of attribute-values. It is a cheaper alternative to Relate for exact
+// comparisons: ANY only equals ANY and NA only equals NA; attributes of other containing unquoted wildcards
+// are never equal, since their relation is UNDEFINED.
+func (a *Attributes) Equal(other *Attributes) bool {
+	if a == nil || other == nil {
+		return false
+	}
+	return equalAttr(a.Part, other.Part) &&
+		equalAttr(a.Vendor, other.Vendor) &&
+		equalAttr(a.Product, other.Product) &&
+		equalAttr(a.Version, other.Version) &&
+		equalAttr(a.Update, other.Update) &&
+		equalAttr(a.Edition, other.Edition) &&
+		equalAttr(a.Language, other.Language) &&
+		equalAttr(a.SWEdition, other.SWEdition) &&
+		equalAttr(a.TargetSW, other.TargetSW) &&
+		equalAttr(a.TargetHW, other.TargetHW) &&
+		equalAttr(a.Other, other.Other)
+}
+
+// equalAttr returns true if attribute-values src and tgt are EQUAL, ignoring ASCII case
+func equalAttr(src, tgt string) bool {
+	if len(src) != len(tgt) || HasWildcard(tgt) {
+		return false
+	}
+	if src == tgt {
+		return true
+	}
+	if src == Any || src == NA || tgt == Any || tgt == NA {
+		return false
+	}
+	for i := 0; i < len(src); i++ {
+		if lowerASCII(src[i]) != lowerASCII(tgt[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRelate(t *testing.T) {
+	cases := []struct {
+		Src    string
+		Tgt    string
+		Expect Relation
+	}{
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: Equal,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:*:*:*:*:*:*:*:*`,
+			Expect: Subset,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.*:sp?:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: Superset,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:mozilla:firefox:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: Disjoint,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: Disjoint,
+		},
+		{
+			// embedded wildcard in the target
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.*:*:*:*:*:*:*:*`,
+			Expect: Undefined,
+		},
+		{
+			// identical patterns are still undefined
+			Src:    `cpe:2.3:a:microsoft:internet_ex*:*:*:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_ex*:*:*:*:*:*:*:*:*`,
+			Expect: Undefined,
+		},
+		{
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:-:*:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.?:*:*:*:*:*:*:*`,
+			Expect: Undefined,
+		},
+		{
+			// some attributes are more general in source, other in target
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:*:sp3:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			Expect: Undefined,
+		},
+		{
+			// disjoint attribute wins over undefined one
+			Src:    `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			Tgt:    `cpe:2.3:a:mozilla:internet_explorer:8.0.*:*:*:*:*:*:*:*`,
+			Expect: Disjoint,
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q vs %q", c.Src, c.Tgt), func(t *testing.T) {
+			srcAttr, err := UnbindFmtString(c.Src)
+			if err != nil {
+				t.Fatalf("failed to unbind WFN from FSB %q: %v", c.Src, err)
+			}
+			tgtAttr, err := UnbindFmtString(c.Tgt)
+			if err != nil {
+				t.Fatalf("failed to unbind WFN from FSB %q: %v", c.Tgt, err)
+			}
+			if r := Relate(srcAttr, tgtAttr); r != c.Expect {
+				t.Fatalf("Relate returned %v, %v was expected", r, c.Expect)
+			}
+		})
+	}
+	if r := Relate(nil, &Attributes{}); r != Undefined {
+		t.Fatalf("Relate returned %v for nil source, %v was expected", r, Undefined)
+	}
+}
+
+func TestAttributesEqual(t *testing.T) {
+	cases := []struct {
+		A      string
+		B      string
+		Expect bool
+	}{
+		{
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: true,
+		},
+		{
+			A:      `cpe:2.3:a:Microsoft:Internet_Explorer:8.0.6001:SP3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: true,
+		},
+		{
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:-`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:-`,
+			Expect: true,
+		},
+		{
+			// ANY is a superset of NA
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*`,
+			Expect: false,
+		},
+		{
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			Expect: false,
+		},
+		{
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*`,
+			Expect: false,
+		},
+		{
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6002:sp3:*:*:*:*:*:*`,
+			Expect: false,
+		},
+		{
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.*:sp3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`,
+			Expect: false,
+		},
+		{
+			// identical patterns are undefined
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.*:sp3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.*:sp3:*:*:*:*:*:*`,
+			Expect: false,
+		},
+		{
+			// quoted wildcards are literal
+			A:      `cpe:2.3:a:microsoft:internet_explorer:8.0.\*:sp3:*:*:*:*:*:*`,
+			B:      `cpe:2.3:a:microsoft:internet_explorer:8.0.\*:sp3:*:*:*:*:*:*`,
+			Expect: true,
+		},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			a, err := UnbindFmtString(c.A)
+			if err != nil {
+				t.Fatalf("can't unbind %q: %v", c.A, err)
+			}
+			b, err := UnbindFmtString(c.B)
+			if err != nil {
+				t.Fatalf("can't unbind %q: %v", c.B, err)
+			}
+			if eq := a.Equal(b); eq != c.Expect {
+				t.Fatalf("%q.Equal(%q) returned %t, %t was expected", c.A, c.B, eq, c.Expect)
+			}
+		})
+	}
+	a := NewAttributesWithAny()
+	if a.Equal(nil) {
+		t.Fatal("Equal returned true for nil")
+	}
+}
@@ -85,24 +85,7 @@ func UnbindURI(s string) (*Attributes, error) {
 				attr.Edition, i, err = unbindValueURIAtTill(s, i, ':')
 				break
 			}
-			i++
-		edition23:
-			for subpartN := 0; i < len(s); i, subpartN = i+1, subpartN+1 {
-				switch subpartN {
-				case 0:
-					attr.Edition, i, err = unbindValueURIAtTill(s, i, '~')
-				case 1:
-					attr.SWEdition, i, err = unbindValueURIAtTill(s, i, '~')
-				case 2:
-					attr.TargetSW, i, err = unbindValueURIAtTill(s, i, '~')
-				case 3:
-					attr.TargetHW, i, err = unbindValueURIAtTill(s, i, '~')
-				case 4:
-					attr.Other, i, err = unbindValueURIAtTill(s, i, ':')
-				default:
-					break edition23
-				}
-			}
+			i, err = attr.unpackEditionAt(s, i+1)
 		case 6:
 			attr.Language, i, err = unbindValueURIAtTill(s, i, ':')
 		}
@@ -113,6 +96,29 @@ func UnbindURI(s string) (*Attributes, error) {
 	return &attr, nil
 }
 
+// unpackEditionAt unpacks the packed edition ~edition~sw_edition~target_sw~target_hw~other
+// which starts in the string s at position at (after the leading '~') into a.
+// It returns the position of the end of the edition component.
+func (a *Attributes) unpackEditionAt(s string, at int) (int, error) {
+	end := strings.IndexByte(s[at:], ':')
+	if end == -1 {
+		end = len(s)
+	} else {
+		end += at
+	}
+	packed := strings.Split(s[at:end], "~")
+	if len(packed) != 5 {
+		return end, fmt.Errorf("packed edition should have 5 components, got %d: %q", len(packed), s[at-1:end])
+	}
+	for i, v := range []*string{&a.Edition, &a.SWEdition, &a.TargetSW, &a.TargetHW, &a.Other} {
+		var err error
+		if *v, _, err = unbindValueURIAtTill(packed[i], 0, '~'); err != nil {
+			return end, err
+		}
+	}
+	return end, nil
+}
+
 func pack(ss []string) string {
 	compat := true
 	for _, s := range ss[1:] {
@@ -127,6 +133,27 @@ func pack(ss []string) string {
 	return "~" + strings.Join(ss, "~")
 }
 
+// PercentEncode binds WFN attribute value v to its form in CPE 2.2 URI, as per NISTIR 7695 section 6.1.2:
+// quoted special characters are percent-encoded (except for '-' and '.', which are bound as is),
+// unquoted wildcards '?' and '*' are bound to %01 and %02; ANY is bound to empty string and NA to "-".
+func PercentEncode(v string) string {
+	return bindValueURI(v)
+}
+
+// PercentDecode unbinds the attribute value s of CPE 2.2 URI into WFN attribute value, reversing PercentEncode.
+// The value is lowercased, as URIs are case-insensitive. It fails if s contains an illegal percent-encoded
+// character, %01 or %02 in the middle of it, or ':' separating the URI components.
+func PercentDecode(s string) (string, error) {
+	v, end, err := unbindValueURIAtTill(strings.ToLower(s), 0, ':')
+	if err != nil {
+		return "", err
+	}
+	if end != len(s) {
+		return "", fmt.Errorf("unbind URI attribute: unexpected ':' at %d in %q", end, s)
+	}
+	return v, nil
+}
+
 // Scans an input string s and applies the following transformations:
 // - pass alphanumeric characters thru untouched
 // - percent-encode quoted non-alphanumerics as needed
@@ -166,7 +193,7 @@ func unbindValueURIAtTill(s string, at int, till byte) (string, int, error) {
 	if at >= len(s) || s[at] == till {
 		return Any, at, nil
 	}
-	if s[at] == '-' {
+	if s[at] == '-' && (at+1 == len(s) || s[at+1] == till) {
 		return NA, at + 1, nil
 	}
 	out := make([]byte, 0, len(s)*2) // assume the worst
@@ -269,6 +296,11 @@ loop:
 			out = append(out, '\\', s[i])
 			embedded = true
 		default:
+			if c := s[i]; !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c == '_' || c == '*' || c == '?') {
+				// not allowed in URI unencoded, but quote it to keep WFN valid;
+				// unencoded wildcards are left as is for compatibility
+				out = append(out, '\\')
+			}
 			out = append(out, s[i])
 			embedded = true
 		}
@@ -53,6 +53,34 @@ func TestUnbindURI(t *testing.T) {
 			URI:    "cpe:/o:microsoft:windows_10:-::~~~~x64~",
 			Expect: `wfn:[part="o",vendor="microsoft",product="windows_10",version=NA,update=ANY,edition=ANY,sw_edition=ANY,target_sw=ANY,target_hw="x64",other=ANY,language=ANY]`,
 		},
+		{
+			URI:    "cpe:/a:hp:insight_diagnostics:7.4.0.1570:u:~e~online~win2003~x64~other:en-us",
+			Expect: `wfn:[part="a",vendor="hp",product="insight_diagnostics",version="7\.4\.0\.1570",update="u",edition="e",sw_edition="online",target_sw="win2003",target_hw="x64",other="other",language="en\-us"]`,
+		},
+		{
+			URI:    "cpe:/a:foo:bar:1.0::~-~-~-~-~-",
+			Expect: `wfn:[part="a",vendor="foo",product="bar",version="1\.0",update=ANY,edition=NA,sw_edition=NA,target_sw=NA,target_hw=NA,other=NA,language=ANY]`,
+		},
+		{
+			URI:    "cpe:/a:foo:bar:-beta:-",
+			Expect: `wfn:[part="a",vendor="foo",product="bar",version="\-beta",update=NA,edition=ANY,language=ANY]`,
+		},
+		{
+			URI:    "cpe:/a:foo%21:bar%3a%5c%25:1.0:%7e:e~x",
+			Expect: `wfn:[part="a",vendor="foo\!",product="bar\:\\\%",version="1\.0",update="\~",edition="e\~x",language=ANY]`,
+		},
+		{
+			URI:  "cpe:/a:foo:bar:1.0::~online~win2003:en",
+			Fail: true,
+		},
+		{
+			URI:  "cpe:/a:foo:bar:1.0::~~~~~~",
+			Fail: true,
+		},
+		{
+			URI:  "cpe:/a:foo:bar:1.0%20",
+			Fail: true,
+		},
 		{
 			URI:  `cpe:/a:foo:boo%02%02`,
 			Fail: true,
@@ -94,6 +122,9 @@ func TestBindToURI(t *testing.T) {
 		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01",
 		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01:limited",
 		"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~",
+		"cpe:/a:hp:insight_diagnostics:7.4.0.1570:u:~e~online~win2003~x64~other:en-us",
+		"cpe:/a:foo%21:bar%3a%5c%25:1.0:%7e:e%7ex",
+		"cpe:/a:foo:bar:-::-:en",
 	}
 	for n, c := range cases {
 		c := c
@@ -108,3 +139,137 @@ func TestBindToURI(t *testing.T) {
 		})
 	}
 }
+
+func TestBindToURIOmission(t *testing.T) {
+	cases := []struct {
+		Attr   Attributes
+		Expect string
+	}{
+		{
+			Attr:   Attributes{Part: "a", Vendor: "foo"},
+			Expect: "cpe:/a:foo",
+		},
+		{
+			Attr:   Attributes{Part: "a", Vendor: "foo", Version: `1\.0`},
+			Expect: "cpe:/a:foo::1.0",
+		},
+		{
+			Attr:   Attributes{Part: "a", Vendor: "foo", Product: "bar", Language: "en"},
+			Expect: "cpe:/a:foo:bar::::en",
+		},
+		{
+			Attr:   Attributes{Part: "a", Vendor: "foo", Product: "bar", Edition: NA},
+			Expect: "cpe:/a:foo:bar:::-",
+		},
+		{
+			Attr:   Attributes{Part: "a", Vendor: "foo", Product: "bar", TargetSW: "android"},
+			Expect: "cpe:/a:foo:bar:::~~~android~~",
+		},
+		{
+			Attr:   Attributes{Part: "a", Vendor: "foo", Product: "bar", Version: "1*", Update: `\?`},
+			Expect: "cpe:/a:foo:bar:1%02:%3f",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Expect, func(t *testing.T) {
+			if out := c.Attr.BindToURI(); out != c.Expect {
+				t.Fatalf("expected %s\ngot %s", c.Expect, out)
+			}
+		})
+	}
+}
+
+func TestPercentEncoding(t *testing.T) {
+	cases := []struct {
+		value   string
+		encoded string
+	}{
+		{Any, ""},
+		{NA, "-"},
+		{"foo_bar1", "foo_bar1"},
+		{`foo\!`, "foo%21"},
+		{`foo\"`, "foo%22"},
+		{`foo\#`, "foo%23"},
+		{`foo\$`, "foo%24"},
+		{`foo\%`, "foo%25"},
+		{`foo\&`, "foo%26"},
+		{`foo\'`, "foo%27"},
+		{`foo\(`, "foo%28"},
+		{`foo\)`, "foo%29"},
+		{`foo\*`, "foo%2a"},
+		{`foo\+`, "foo%2b"},
+		{`foo\,`, "foo%2c"},
+		{`foo\-`, "foo-"},
+		{`foo\.`, "foo."},
+		{`foo\/`, "foo%2f"},
+		{`foo\:`, "foo%3a"},
+		{`foo\;`, "foo%3b"},
+		{`foo\<`, "foo%3c"},
+		{`foo\=`, "foo%3d"},
+		{`foo\>`, "foo%3e"},
+		{`foo\?`, "foo%3f"},
+		{`foo\@`, "foo%40"},
+		{`foo\[`, "foo%5b"},
+		{`foo\\`, "foo%5c"},
+		{`foo\]`, "foo%5d"},
+		{`foo\^`, "foo%5e"},
+		{"foo\\`", "foo%60"},
+		{`foo\{`, "foo%7b"},
+		{`foo\|`, "foo%7c"},
+		{`foo\}`, "foo%7d"},
+		{`foo\~`, "foo%7e"},
+		// unquoted wildcards map to the special forms
+		{"foo*", "foo%02"},
+		{"?foo", "%01foo"},
+		{"??foo*", "%01%01foo%02"},
+		{`8\.*`, "8.%02"},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			if got := PercentEncode(c.value); got != c.encoded {
+				t.Errorf("PercentEncode(%q): expected %q, got %q", c.value, c.encoded, got)
+			}
+			got, err := PercentDecode(c.encoded)
+			if err != nil {
+				t.Fatalf("PercentDecode(%q) failed: %v", c.encoded, err)
+			}
+			if got != c.value {
+				t.Errorf("PercentDecode(%q): expected %q, got %q", c.encoded, c.value, got)
+			}
+		})
+	}
+}
+
+func TestPercentDecode(t *testing.T) {
+	cases := []struct {
+		encoded string
+		value   string
+		fail    bool
+	}{
+		{encoded: "FOO%2A", value: `foo\*`},
+		{encoded: "foo~bar", value: `foo\~bar`},
+		{encoded: "foo%01bar", fail: true},
+		{encoded: "foo%02bar", fail: true},
+		{encoded: "foo%zz", fail: true},
+		{encoded: "foo%7f", fail: true},
+		{encoded: "foo%2", fail: true},
+		{encoded: "foo:bar", fail: true},
+	}
+	for _, c := range cases {
+		t.Run(c.encoded, func(t *testing.T) {
+			got, err := PercentDecode(c.encoded)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("PercentDecode(%q) should fail, got %q", c.encoded, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PercentDecode(%q) failed: %v", c.encoded, err)
+			}
+			if got != c.value {
+				t.Errorf("PercentDecode(%q): expected %q, got %q", c.encoded, c.value, got)
+			}
+		})
+	}
+}
@@ -114,6 +114,10 @@ func WFNize(s string) (string, error) {
 			c >= '0' && c <= '9' ||
 			c == '_' ||
 			strings.IndexByte(allowedPunct, c) != -1 {
+			// colon separates attributes in addSlashesAt, quote it
+			if c == ':' {
+				buf = append(buf, '\\')
+			}
 			buf = append(buf, c)
 		}
 		// handle wildcard characters
@@ -129,6 +133,53 @@ func WFNize(s string) (string, error) {
 	return s, err
 }
 
+// Normalize canonicalizes all attribute values of a in place, so that equal names have the same representation:
+// letters are lowercased, spaces become underscores, needless quoting of letters, digits and underscores is removed
+// and every other character except the unquoted wildcards (*?) is quoted.
+// The logical values ANY and NA are left intact.
+func (a *Attributes) Normalize() {
+	for _, v := range []*string{
+		&a.Part, &a.Vendor, &a.Product, &a.Version, &a.Update, &a.Edition,
+		&a.SWEdition, &a.TargetSW, &a.TargetHW, &a.Other, &a.Language,
+	} {
+		*v = normalizeValue(*v)
+	}
+}
+
+func normalizeValue(s string) string {
+	if s == Any || s == NA {
+		return s
+	}
+	buf := make([]byte, 0, len(s)+len(s)/2)
+	for i := 0; i < len(s); i++ {
+		c := lowerASCII(s[i])
+		quoted := false
+		if c == '\\' && i+1 < len(s) {
+			i++
+			c = lowerASCII(s[i])
+			quoted = true
+		}
+		switch {
+		case c == ' ':
+			buf = append(buf, '_')
+		case c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_':
+			buf = append(buf, c)
+		case !quoted && (c == '*' || c == '?'):
+			buf = append(buf, c)
+		default:
+			buf = append(buf, '\\', c)
+		}
+	}
+	return string(buf)
+}
+
+func lowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
 // String returns a string representation of the wfn
 func (a Attributes) String() string {
 	parts := make([]string, 0, 11)
@@ -28,6 +28,7 @@ func TestWFNize(t *testing.T) {
 		{`1.8.\*`, `1\.8\.*`, false},
 		{"1.*.14", `1\.\*\.14`, false},
 		{`1.\*.14`, "", true},
+		{"2:1.2.3-4", `2\:1\.2\.3\-4`, false},
 	}
 	for _, c := range cases {
 		res, err := WFNize(c.in)
@@ -43,6 +44,41 @@ func TestWFNize(t *testing.T) {
 	}
 }
 
+func TestNormalize(t *testing.T) {
+	a := &Attributes{Part: "a", Vendor: "Apache", Product: `apache\ http\ server`, Version: "2.4.1", Update: NA}
+	b := &Attributes{Part: "a", Vendor: `\apache`, Product: "Apache_HTTP_Server", Version: `2\.4\.1`, Update: NA}
+	a.Normalize()
+	b.Normalize()
+	if *a != *b {
+		t.Fatalf("normalized attributes differ:\n%v\n%v", a, b)
+	}
+	expected := Attributes{Part: "a", Vendor: "apache", Product: "apache_http_server", Version: `2\.4\.1`, Update: NA}
+	if *a != expected {
+		t.Fatalf("got %v, expected %v", a, expected)
+	}
+
+	cases := []struct {
+		in, expected string
+	}{
+		{Any, Any},
+		{NA, NA},
+		{`\-`, `\-`},
+		{`C\+\+`, `c\+\+`},
+		{"c++", `c\+\+`},
+		{`a\\b`, `a\\b`},
+		{`1\.8\.*`, `1\.8\.*`},
+		{`\*foo?`, `\*foo?`},
+		{`O\'Reilly`, `o\'reilly`},
+		{`trailing\`, `trailing\\`},
+	}
+	for _, c := range cases {
+		a := Attributes{Product: c.in}
+		if a.Normalize(); a.Product != c.expected {
+			t.Errorf("Normalize(%q) returned %q, %q was expected", c.in, a.Product, c.expected)
+		}
+	}
+}
+
 func BenchmarkWFNize(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		WFNize("1.8.14.6001")